@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	auth "patika-ecommerce/internal/auth"
+	"patika-ecommerce/internal/cart"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/internal/order"
+	user "patika-ecommerce/internal/user"
+	"patika-ecommerce/pkg/notifier"
+	"strings"
+)
+
+// runCreateAdmin creates an admin account, the password is read from the
+// standard input when it is not given, so it does not end up in the shell history
+func runCreateAdmin(a *app, args []string) error {
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := flags.String("email", "", "email address of the admin")
+	password := flags.String("password", "", "password of the admin, read from the standard input when empty")
+	firstName := flags.String("first-name", "Admin", "first name of the admin")
+	lastName := flags.String("last-name", "User", "last name of the admin")
+	username := flags.String("username", "", "username of the admin, the email address when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("--email is required")
+	}
+	if *username == "" {
+		*username = *email
+	}
+	if err := a.verifySchema(); err != nil {
+		return err
+	}
+
+	if *password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("cannot read the password: %w", err)
+		}
+		*password = strings.TrimRight(line, "\r\n")
+	}
+	if *password == "" {
+		return errors.New("password must not be empty")
+	}
+
+	appNotifier, err := notifier.NewNotifier(a.cfg)
+	if err != nil {
+		return err
+	}
+	userRepo := user.NewUserRepository(a.db)
+	authService := auth.NewAuthService(a.cfg, userRepo, auth.NewRefreshTokenRepository(a.db),
+		auth.NewUserTokenRepository(a.db), auth.NewTwoFactorRepository(a.db), appNotifier)
+	userAdminService := user.NewUserAdminService(userRepo, authService,
+		order.NewOrderRepository(a.db), cart.NewCartRepository(a.db))
+
+	admin, created, err := userAdminService.CreateAdmin(context.Background(), &model.User{
+		FirstName: firstName,
+		LastName:  lastName,
+		Username:  username,
+		Email:     email,
+		Password:  *password,
+	})
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Printf("admin %s created with id %s\n", *admin.Email, admin.ID)
+	} else {
+		fmt.Printf("existing user %s is now an admin, the password is not changed\n", *admin.Email)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	category "patika-ecommerce/internal/category"
+	product "patika-ecommerce/internal/product"
+	"patika-ecommerce/internal/stockalert"
+	"patika-ecommerce/pkg/notifier"
+)
+
+//go:embed seed/categories.csv
+var seedCategories []byte
+
+//go:embed seed/products.csv
+var seedProducts []byte
+
+// runSeed inserts the demo catalog, a database with categories is left alone
+func runSeed(a *app, args []string) error {
+	if err := a.verifySchema(); err != nil {
+		return err
+	}
+
+	categoryRepo := category.NewCategoryrRepository(a.db)
+	categories, err := categoryRepo.GetCategories(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(*categories) > 0 {
+		fmt.Println("the catalog is not empty, the demo catalog is not inserted")
+		return nil
+	}
+
+	created, err := category.NewCategoryService(categoryRepo).CreateBulkCategories(context.Background(), bytes.NewBuffer(seedCategories))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d categories inserted\n", len(created))
+	return importProducts(a, seedProducts)
+}
+
+// runImportCategories inserts the categories of a CSV file, the file is
+// rejected as a whole when a category exists
+func runImportCategories(a *app, args []string) error {
+	content, err := readImportFile(args)
+	if err != nil {
+		return err
+	}
+	if err := a.verifySchema(); err != nil {
+		return err
+	}
+
+	categoryService := category.NewCategoryService(category.NewCategoryrRepository(a.db))
+	created, err := categoryService.CreateBulkCategories(context.Background(), bytes.NewBuffer(content))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d categories imported\n", len(created))
+	return nil
+}
+
+// runImportProducts inserts the products of a CSV file, the products whose
+// SKU exists are skipped
+func runImportProducts(a *app, args []string) error {
+	content, err := readImportFile(args)
+	if err != nil {
+		return err
+	}
+	if err := a.verifySchema(); err != nil {
+		return err
+	}
+	return importProducts(a, content)
+}
+
+// runReindexSearch rebuilds the indexes of the product search
+func runReindexSearch(a *app, args []string) error {
+	if err := a.verifySchema(); err != nil {
+		return err
+	}
+	if err := product.NewProductRepository(a.db).ReindexSearch(context.Background()); err != nil {
+		return err
+	}
+	fmt.Println("product search indexes rebuilt")
+	return nil
+}
+
+// importProducts inserts the products and queues them for the stock alert job
+// of the service, the alerts are not sent by the command
+func importProducts(a *app, content []byte) error {
+	appNotifier, err := notifier.NewNotifier(a.cfg)
+	if err != nil {
+		return err
+	}
+	stockAlertService := stockalert.NewStockAlertService(stockalert.NewStockAlertRepository(a.db), appNotifier)
+
+	importer := product.NewProductImporter(product.NewProductRepository(a.db), category.NewCategoryrRepository(a.db), stockAlertService)
+	result, err := importer.Import(context.Background(), bytes.NewBuffer(content))
+	if result != nil {
+		fmt.Printf("%d products imported, %d skipped\n", result.Imported, result.Skipped)
+	}
+	return err
+}
+
+func readImportFile(args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("a single CSV file is expected")
+	}
+	return os.ReadFile(args[0])
+}
@@ -17,12 +17,15 @@ import (
 // swagger:model ApiErrorResponse
 type APIErrorResponse struct {
 
-	// code
+	// HTTP status code
 	Code int64 `json:"code,omitempty"`
 
-	// a (key, value) map.
+	// a (key, value) map, or a list of ValidationFieldError for validation_failed
 	Details interface{} `json:"details,omitempty"`
 
+	// Stable machine-readable error code, e.g. insufficient_stock
+	ErrorCode string `json:"errorCode,omitempty"`
+
 	// message
 	Message string `json:"message,omitempty"`
 }
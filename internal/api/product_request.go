@@ -28,6 +28,10 @@ type ProductRequest struct {
 	// Required: true
 	Description *string `json:"description"`
 
+	// admins are alerted when stock falls to or below this value
+	// Minimum: 0
+	LowStockThreshold *int64 `json:"lowStockThreshold,omitempty"`
+
 	// name
 	// Required: true
 	Name *string `json:"name"`
@@ -57,6 +61,10 @@ func (m *ProductRequest) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateLowStockThreshold(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if err := m.validateName(formats); err != nil {
 		res = append(res, err)
 	}
@@ -115,6 +123,18 @@ func (m *ProductRequest) validateDescription(formats strfmt.Registry) error {
 	return nil
 }
 
+func (m *ProductRequest) validateLowStockThreshold(formats strfmt.Registry) error {
+	if swag.IsZero(m.LowStockThreshold) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("lowStockThreshold", "body", *m.LowStockThreshold, 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (m *ProductRequest) validateName(formats strfmt.Registry) error {
 
 	if err := validate.Required("name", "body", m.Name); err != nil {
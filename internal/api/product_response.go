@@ -30,6 +30,9 @@ type ProductResponse struct {
 	// Format: uuid
 	ID strfmt.UUID `json:"id,omitempty"`
 
+	// low stock threshold
+	LowStockThreshold *int64 `json:"lowStockThreshold,omitempty"`
+
 	// name
 	Name string `json:"name,omitempty"`
 
@@ -12,6 +12,7 @@ import (
 	"github.com/go-openapi/errors"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/swag"
+	"github.com/go-openapi/validate"
 )
 
 // ProductUpdateRequest product update request
@@ -25,6 +26,10 @@ type ProductUpdateRequest struct {
 	// description
 	Description string `json:"description,omitempty"`
 
+	// admins are alerted when stock falls to or below this value
+	// Minimum: 0
+	LowStockThreshold *int64 `json:"lowStockThreshold,omitempty"`
+
 	// name
 	Name string `json:"name,omitempty"`
 
@@ -34,8 +39,11 @@ type ProductUpdateRequest struct {
 	// sku
 	Sku string `json:"sku,omitempty"`
 
-	// stock
-	Stock int64 `json:"stock,omitempty"`
+	// left unchanged when not sent, 0 empties the stock
+	Stock *int64 `json:"stock,omitempty"`
+
+	// required when stock is changed
+	StockReason string `json:"stockReason,omitempty"`
 }
 
 // Validate validates this product update request
@@ -46,6 +54,10 @@ func (m *ProductUpdateRequest) Validate(formats strfmt.Registry) error {
 		res = append(res, err)
 	}
 
+	if err := m.validateLowStockThreshold(formats); err != nil {
+		res = append(res, err)
+	}
+
 	if len(res) > 0 {
 		return errors.CompositeValidationError(res...)
 	}
@@ -78,6 +90,18 @@ func (m *ProductUpdateRequest) validateCategories(formats strfmt.Registry) error
 	return nil
 }
 
+func (m *ProductUpdateRequest) validateLowStockThreshold(formats strfmt.Registry) error {
+	if swag.IsZero(m.LowStockThreshold) { // not required
+		return nil
+	}
+
+	if err := validate.MinimumInt("lowStockThreshold", "body", *m.LowStockThreshold, 0, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // ContextValidate validate this product update request based on the context it is used
 func (m *ProductUpdateRequest) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
 	var res []error
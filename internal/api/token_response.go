@@ -20,8 +20,14 @@ type TokenResponse struct {
 	// access token
 	AccessToken string `json:"accessToken,omitempty"`
 
+	// Short-lived token exchanged with a code at /login/2fa
+	ChallengeToken string `json:"challengeToken,omitempty"`
+
 	// refresh token
 	RefreshToken string `json:"refreshToken,omitempty"`
+
+	// Set when the password is right but the account has two-factor authentication, the tokens are returned by /login/2fa
+	TwoFactorRequired bool `json:"twoFactorRequired,omitempty"`
 }
 
 // Validate validates this token response
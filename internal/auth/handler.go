@@ -3,10 +3,13 @@ package auth
 import (
 	"patika-ecommerce/internal/api"
 	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
 	"patika-ecommerce/pkg/config"
+	mw "patika-ecommerce/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
 )
 
 type authHandler struct {
@@ -15,15 +18,42 @@ type authHandler struct {
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(r *gin.RouterGroup, cfg *config.Config, authService AuthServiceInterface) {
+func NewAuthHandler(r *gin.RouterGroup, cfg *config.Config, authMiddleware gin.HandlerFunc, authService AuthServiceInterface,
+	loginRateLimit gin.HandlerFunc, registerRateLimit gin.HandlerFunc) {
 	handler := &authHandler{
 		cfg:         cfg,
 		authService: authService,
 	}
 
-	r.POST("/register", handler.register)
-	r.POST("/login", handler.login)
+	// the responses carry the tokens, they are not stored for idempotent replays
+	r.POST("/register", registerRateLimit, handler.register)
+	r.POST("/login", loginRateLimit, handler.login)
+	r.POST("/login/2fa", loginRateLimit, handler.loginTwoFactor)
 	r.POST("/refresh", handler.refreshToken)
+	r.POST("/logout", handler.logout)
+	r.POST("/forgot-password", handler.forgotPassword)
+	r.POST("/reset-password", handler.resetPassword)
+	r.POST("/verify-email", handler.verifyEmail)
+
+	authenticated := r.Group("", authMiddleware)
+	authenticated.POST("/logout-all", handler.logoutAll)
+	authenticated.GET("/sessions", handler.listSessions)
+	authenticated.DELETE("/sessions/:id", handler.revokeSession)
+	authenticated.POST("/verify-email/resend", handler.resendVerification)
+	authenticated.POST("/2fa/enroll", handler.enrollTwoFactor)
+	authenticated.POST("/2fa/confirm", handler.confirmTwoFactor)
+	authenticated.POST("/2fa/recovery-codes", handler.regenerateRecoveryCodes)
+	authenticated.POST("/2fa/disable", handler.disableTwoFactor)
+}
+
+// NewAccountAdminHandler creates the handler of the admin account endpoints
+func NewAccountAdminHandler(r *gin.RouterGroup, cfg *config.Config, authMiddleware gin.HandlerFunc, authService AuthServiceInterface) {
+	handler := &authHandler{
+		cfg:         cfg,
+		authService: authService,
+	}
+
+	r.POST("/users/:id/unlock", authMiddleware, mw.RequirePermission(model.PermissionUserWrite), handler.unlockUser)
 }
 
 // register is used to register a new user
@@ -40,7 +70,7 @@ func (u *authHandler) register(c *gin.Context) {
 		return
 	}
 
-	resp, err := u.authService.Register(RegisterToUser(&reqBody))
+	resp, err := u.authService.Register(c.Request.Context(), RegisterToUser(&reqBody), clientFromContext(c))
 
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
@@ -64,7 +94,7 @@ func (u *authHandler) login(c *gin.Context) {
 		return
 	}
 
-	resp, err := u.authService.Login(LoginToUser(&reqBody))
+	resp, err := u.authService.Login(c.Request.Context(), LoginToUser(&reqBody), clientFromContext(c))
 
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
@@ -74,6 +104,28 @@ func (u *authHandler) login(c *gin.Context) {
 	c.JSON(200, resp)
 }
 
+// loginTwoFactor is used to complete the login with a second factor
+func (u *authHandler) loginTwoFactor(c *gin.Context) {
+	var reqBody api.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	resp, err := u.authService.LoginTwoFactor(c.Request.Context(), *reqBody.ChallengeToken, *reqBody.Code, clientFromContext(c))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, resp)
+}
+
 // refresh is used to refresh the token
 func (u *authHandler) refreshToken(c *gin.Context) {
 	var reqBody api.RefreshToken
@@ -87,7 +139,7 @@ func (u *authHandler) refreshToken(c *gin.Context) {
 		return
 	}
 
-	resp, err := u.authService.RefreshToken(*reqBody.RefreshToken)
+	resp, err := u.authService.RefreshToken(c.Request.Context(), *reqBody.RefreshToken, clientFromContext(c))
 	if err != nil {
 
 		c.JSON(httpErr.ErrorResponse(err))
@@ -96,3 +148,250 @@ func (u *authHandler) refreshToken(c *gin.Context) {
 
 	c.JSON(200, resp)
 }
+
+// logout is used to revoke the session of a refresh token
+func (u *authHandler) logout(c *gin.Context) {
+	var reqBody api.RefreshToken
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := u.authService.Logout(c.Request.Context(), *reqBody.RefreshToken); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// logoutAll is used to revoke every session of the user
+func (u *authHandler) logoutAll(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	if err := u.authService.LogoutAll(c.Request.Context(), user); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// listSessions is used to list the open sessions of the user
+func (u *authHandler) listSessions(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	sessions, err := u.authService.GetSessions(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, RefreshTokensToSessionResponse(sessions))
+}
+
+// revokeSession is used to revoke a session of the user
+func (u *authHandler) revokeSession(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	if err := u.authService.RevokeSession(c.Request.Context(), user, id); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// forgotPassword is used to mail a password reset link
+func (u *authHandler) forgotPassword(c *gin.Context) {
+	var reqBody api.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := u.authService.ForgotPassword(c.Request.Context(), reqBody.Email.String()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// resetPassword is used to set a new password with a reset token
+func (u *authHandler) resetPassword(c *gin.Context) {
+	var reqBody api.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := u.authService.ResetPassword(c.Request.Context(), *reqBody.Token, *reqBody.Password); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// verifyEmail is used to verify the email address with a verification token
+func (u *authHandler) verifyEmail(c *gin.Context) {
+	var reqBody api.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := u.authService.VerifyEmail(c.Request.Context(), *reqBody.Token); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// resendVerification is used to mail a new email verification link
+func (u *authHandler) resendVerification(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	if err := u.authService.ResendVerification(c.Request.Context(), user); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// unlockUser is used to unlock an account locked after failed logins
+func (u *authHandler) unlockUser(c *gin.Context) {
+	admin := c.MustGet("user").(*model.User)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	if err := u.authService.UnlockUser(c.Request.Context(), admin, id); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// enrollTwoFactor is used to create a new TOTP secret
+func (u *authHandler) enrollTwoFactor(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	enrollment, err := u.authService.EnrollTwoFactor(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, TwoFactorEnrollmentToResponse(enrollment))
+}
+
+// confirmTwoFactor is used to enable two-factor authentication
+func (u *authHandler) confirmTwoFactor(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	code, ok := bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := u.authService.ConfirmTwoFactor(c.Request.Context(), user, code)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, &api.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// regenerateRecoveryCodes is used to replace the recovery codes
+func (u *authHandler) regenerateRecoveryCodes(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	code, ok := bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := u.authService.RegenerateRecoveryCodes(c.Request.Context(), user, code)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, &api.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// disableTwoFactor is used to disable two-factor authentication
+func (u *authHandler) disableTwoFactor(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	code, ok := bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	if err := u.authService.DisableTwoFactor(c.Request.Context(), user, code); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// bindTwoFactorCode binds the code of the request, it writes the error response
+// when the request is not valid
+func bindTwoFactorCode(c *gin.Context) (string, bool) {
+	var reqBody api.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return "", false
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return "", false
+	}
+
+	return *reqBody.Code, true
+}
+
+// clientFromContext returns the device of the request
+func clientFromContext(c *gin.Context) *Client {
+	return &Client{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+}
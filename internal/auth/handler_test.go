@@ -2,21 +2,24 @@ package auth
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"patika-ecommerce/internal/api"
+	httpErr "patika-ecommerce/internal/httpErrors"
 	"patika-ecommerce/internal/model"
 	"patika-ecommerce/pkg/config"
 	jwtHelper "patika-ecommerce/pkg/jwt"
+	mw "patika-ecommerce/pkg/middleware"
+	"patika-ecommerce/pkg/notifier"
 	"testing"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
-	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"gorm.io/gorm"
 )
 
 var (
@@ -199,7 +202,7 @@ func Test_authHandler_login(t *testing.T) {
 		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(loginPayloadFaultUserNotFound))
 		authHandler.login(c)
 
-		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
 
 	})
 
@@ -225,7 +228,7 @@ func Test_authHandler_refreshToken(t *testing.T) {
 			},
 			cfg: cfg,
 		}
-		token := jwtHelper.GetAuthToken(&mockAuthService.items[0], cfg).RefreshToken
+		token := mockAuthService.issue(&mockAuthService.items[0]).RefreshToken
 		refreshTokenPayload := []byte(`{"refreshToken":"` + token + `"}`)
 
 		gin.SetMode(gin.TestMode)
@@ -253,7 +256,7 @@ func Test_authHandler_refreshToken(t *testing.T) {
 			},
 			cfg: cfg,
 		}
-		token := jwtHelper.GetAuthToken(&mockAuthService.items[0], cfg).RefreshToken
+		token := mockAuthService.issue(&mockAuthService.items[0]).RefreshToken
 		refreshTokenPayload := []byte(`"refreshToken":"` + token + `"`)
 
 		gin.SetMode(gin.TestMode)
@@ -281,7 +284,7 @@ func Test_authHandler_refreshToken(t *testing.T) {
 			},
 			cfg: cfg,
 		}
-		token := jwtHelper.GetAuthToken(&mockAuthService.items[0], cfg).RefreshToken
+		token := mockAuthService.issue(&mockAuthService.items[0]).RefreshToken
 		refreshTokenPayload := []byte(`{"refresh__Token":"` + token + `"}`)
 
 		gin.SetMode(gin.TestMode)
@@ -309,7 +312,7 @@ func Test_authHandler_refreshToken(t *testing.T) {
 			},
 			cfg: cfg,
 		}
-		// token := jwtHelper.GetAuthToken(&mockAuthService.items[0], cfg).RefreshToken
+		// token := mockAuthService.issue(&mockAuthService.items[0]).RefreshToken
 		refreshTokenPayload := []byte(`{"refreshToken":"` + "token" + `"}`)
 
 		gin.SetMode(gin.TestMode)
@@ -320,20 +323,226 @@ func Test_authHandler_refreshToken(t *testing.T) {
 		c.Request.Header.Set("Content-Type", "application/json")
 		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(refreshTokenPayload))
 		authHandler.refreshToken(c)
-		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 
 }
 
+func Test_authHandler_logout(t *testing.T) {
+	cfg := &config.Config{
+		JWTConfig: config.JWTConfig{SecretKey: "secret", AccessTokenLifeTime: 30, RefreshTokenLifeTime: 30},
+	}
+	user := model.User{Base: model.Base{ID: uuid.New()}, Email: &email}
+
+	tests := []struct {
+		name     string
+		token    func(s *mockAuthService) string
+		wantCode int
+	}{
+		{name: "logout_Success", token: func(s *mockAuthService) string { return s.issue(&user).RefreshToken }, wantCode: http.StatusNoContent},
+		{name: "logout_Failed_unknownToken", token: func(s *mockAuthService) string { return "unknown" }, wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthService := &mockAuthService{cfg: cfg}
+			payload := []byte(`{"refreshToken":"` + tt.token(mockAuthService) + `"}`)
+
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			authHandler := &authHandler{authService: mockAuthService, cfg: cfg}
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/logout", nil)
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(payload))
+			authHandler.logout(c)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func Test_authHandler_listSessions(t *testing.T) {
+	cfg := &config.Config{
+		JWTConfig: config.JWTConfig{SecretKey: "secret", AccessTokenLifeTime: 30, RefreshTokenLifeTime: 30},
+	}
+	user := model.User{Base: model.Base{ID: uuid.New()}, Email: &email}
+	mockAuthService := &mockAuthService{cfg: cfg}
+	mockAuthService.issue(&user)
+	mockAuthService.issue(&user)
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	authHandler := &authHandler{authService: mockAuthService, cfg: cfg}
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/sessions", nil)
+	c.Set("user", &user)
+	authHandler.listSessions(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/logout-all", nil)
+	c.Set("user", &user)
+	authHandler.logoutAll(c)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, 0, len(mockAuthService.refreshTokens))
+}
+
+func Test_authHandler_passwordReset(t *testing.T) {
+	cfg := &config.Config{}
+
+	tests := []struct {
+		name     string
+		path     string
+		payload  string
+		wantCode int
+	}{
+		{name: "forgotPassword_Success", path: "/forgot-password", payload: `{"email":"unknown@example.com"}`, wantCode: http.StatusNoContent},
+		{name: "forgotPassword_Failed_invalidEmail", path: "/forgot-password", payload: `{"email":"unknown"}`, wantCode: http.StatusBadRequest},
+		{name: "resetPassword_Success", path: "/reset-password", payload: `{"token":"valid","password":"123456Aa"}`, wantCode: http.StatusNoContent},
+		{name: "resetPassword_Failed_invalidToken", path: "/reset-password", payload: `{"token":"expired","password":"123456Aa"}`, wantCode: http.StatusBadRequest},
+		{name: "resetPassword_Failed_missingPassword", path: "/reset-password", payload: `{"token":"valid"}`, wantCode: http.StatusBadRequest},
+		{name: "verifyEmail_Success", path: "/verify-email", payload: `{"token":"valid"}`, wantCode: http.StatusNoContent},
+		{name: "verifyEmail_Failed_invalidToken", path: "/verify-email", payload: `{"token":"used"}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			NewAuthHandler(r.Group("/"), cfg, mw.AuthenticationMiddleware(cfg, &mockUserRepository{}), &mockAuthService{cfg: cfg}, noop, noop)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func Test_authHandler_unlockUser(t *testing.T) {
+	cfg := &config.Config{JWTConfig: config.JWTConfig{SecretKey: "secret", AccessTokenLifeTime: 30}}
+	locked := model.User{Base: model.Base{ID: uuid.New()}, Email: &email}
+	support := &model.User{Base: model.Base{ID: uuid.New()}, Email: &email, Roles: []model.UserRole{{Role: model.RoleOrderSupport}}, TwoFactorVerified: true}
+	clerk := &model.User{Base: model.Base{ID: uuid.New()}, Email: &email, Roles: []model.UserRole{{Role: model.RoleInventoryClerk}}, TwoFactorVerified: true}
+	superAdmin := model.User{Base: model.Base{ID: uuid.New()}, Email: &email, Roles: []model.UserRole{{Role: model.RoleSuperAdmin}}, TwoFactorVerified: true}
+
+	tests := []struct {
+		name     string
+		admin    *model.User
+		id       string
+		wantCode int
+	}{
+		{name: "unlock_Success", admin: support, id: locked.ID.String(), wantCode: http.StatusNoContent},
+		{name: "unlock_Failed_unknownUser", admin: support, id: uuid.New().String(), wantCode: http.StatusNotFound},
+		{name: "unlock_Failed_withoutPermission", admin: clerk, id: locked.ID.String(), wantCode: http.StatusForbidden},
+		{name: "unlock_Failed_superAdmin", admin: support, id: superAdmin.ID.String(), wantCode: http.StatusForbidden},
+		{name: "unlock_SuperAdmin_bySuperAdmin", admin: &superAdmin, id: superAdmin.ID.String(), wantCode: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			users := &mockUserRepository{items: []model.User{locked, *support, *clerk, superAdmin}}
+			authService := NewAuthService(cfg, users, &mockRefreshTokenRepository{}, &mockUserTokenRepository{}, &mockTwoFactorRepository{}, notifier.NewLogNotifier())
+			NewAccountAdminHandler(r.Group("/admin"), cfg, mw.AuthenticationMiddleware(cfg, users), authService)
+
+			accessToken, _ := jwtHelper.GenerateAccessToken(tt.admin, cfg)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/admin/users/"+tt.id+"/unlock", nil)
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
+
+func Test_authHandler_twoFactor(t *testing.T) {
+	cfg := &config.Config{JWTConfig: config.JWTConfig{SecretKey: "secret", AccessTokenLifeTime: 30}}
+	user := &model.User{Base: model.Base{ID: uuid.New()}, Email: &email}
+	admin := &model.User{Base: model.Base{ID: uuid.New()}, Email: &email, IsAdmin: true}
+	clerk := &model.User{Base: model.Base{ID: uuid.New()}, Email: &email, Roles: []model.UserRole{{Role: model.RoleInventoryClerk}}}
+
+	tests := []struct {
+		name     string
+		user     *model.User
+		path     string
+		payload  string
+		wantCode int
+		wantBody string
+	}{
+		{name: "loginTwoFactor_Success", path: "/login/2fa", payload: `{"challengeToken":"challenge","code":"valid"}`, wantCode: http.StatusOK, wantBody: `{"accessToken":"access","refreshToken":"refresh"}`},
+		{name: "loginTwoFactor_Failed_invalidCode", path: "/login/2fa", payload: `{"challengeToken":"challenge","code":"123456"}`, wantCode: http.StatusUnauthorized},
+		{name: "loginTwoFactor_Failed_missingChallenge", path: "/login/2fa", payload: `{"code":"valid"}`, wantCode: http.StatusBadRequest},
+		{name: "enroll_Success", user: user, path: "/2fa/enroll", wantCode: http.StatusOK, wantBody: `{"provisioningUri":"otpauth://totp/test","qrCode":"cG5n","secret":"SECRET"}`},
+		{name: "enroll_Failed_anonymous", path: "/2fa/enroll", wantCode: http.StatusUnauthorized},
+		{name: "confirm_Success", user: user, path: "/2fa/confirm", payload: `{"code":"valid"}`, wantCode: http.StatusOK, wantBody: `{"recoveryCodes":["aaaaa-bbbbb"]}`},
+		{name: "confirm_Failed_invalidCode", user: user, path: "/2fa/confirm", payload: `{"code":"123456"}`, wantCode: http.StatusBadRequest},
+		{name: "disable_Success", user: user, path: "/2fa/disable", payload: `{"code":"valid"}`, wantCode: http.StatusNoContent},
+		{name: "disable_Failed_admin", user: admin, path: "/2fa/disable", payload: `{"code":"valid"}`, wantCode: http.StatusForbidden},
+		{name: "disable_Failed_staff", user: clerk, path: "/2fa/disable", payload: `{"code":"valid"}`, wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			users := &mockUserRepository{items: []model.User{*user, *admin, *clerk}}
+			NewAuthHandler(r.Group("/"), cfg, mw.AuthenticationMiddleware(cfg, users), &mockAuthService{cfg: cfg}, noop, noop)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.payload))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.user != nil {
+				accessToken, _ := jwtHelper.GenerateAccessToken(tt.user, cfg)
+				req.Header.Set("Authorization", "Bearer "+accessToken)
+			}
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_jwksHandler_getJWKS(t *testing.T) {
+	cfg := &config.Config{JWTConfig: config.JWTConfig{SecretKey: "secret"}}
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	handler := &jwksHandler{cfg: cfg}
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	handler.getJWKS(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"keys":[]}`, w.Body.String())
+}
+
 type mockAuthService struct {
-	items []model.User
-	cfg   *config.Config
+	items         []model.User
+	cfg           *config.Config
+	refreshTokens map[string]*model.User
 }
 
-var UsernameAlreadyExists = fmt.Errorf("23505")
+var UsernameAlreadyExists = &pgconn.PgError{Code: "23505"}
+
+// issue returns new tokens for the user
+func (a *mockAuthService) issue(user *model.User) api.TokenResponse {
+	if a.refreshTokens == nil {
+		a.refreshTokens = map[string]*model.User{}
+	}
+	refreshToken := uuid.New().String()
+	a.refreshTokens[refreshToken] = user
+
+	accessToken, _ := jwtHelper.GenerateAccessToken(user, a.cfg)
+	return api.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}
+}
 
 // Register is a service that registers a new user
-func (a *mockAuthService) Register(user *model.User) (api.TokenResponse, error) {
+func (a *mockAuthService) Register(ctx context.Context, user *model.User, client *Client) (api.TokenResponse, error) {
 	for _, item := range a.items {
 		if *item.Username == *user.Username || *item.Email == *user.Email {
 			return api.TokenResponse{}, UsernameAlreadyExists
@@ -341,55 +550,131 @@ func (a *mockAuthService) Register(user *model.User) (api.TokenResponse, error)
 	}
 	a.items = append(a.items, *user)
 
-	return jwtHelper.GetAuthToken(user, a.cfg), nil
+	return a.issue(user), nil
 }
 
 // Login is a service that logs in a user
-func (a *mockAuthService) Login(u *model.User) (api.TokenResponse, error) {
+func (a *mockAuthService) Login(ctx context.Context, u *model.User, client *Client) (api.TokenResponse, error) {
 
 	for _, item := range a.items {
 		if *item.Email == *u.Email {
 			if item.Password == u.Password {
-				return jwtHelper.GetAuthToken(&item, a.cfg), nil
+				return a.issue(&item), nil
 			}
 		}
 	}
-	return api.TokenResponse{}, fmt.Errorf("Invalid username or password")
+	return api.TokenResponse{}, httpErr.UnauthorizedError
 }
 
-// RefreshToken is a service that checks if the refresh token is valid and returns a new JWT token
-func (a *mockAuthService) RefreshToken(refreshToken string) (api.TokenResponse, error) {
-	token, err := jwtHelper.ParseToken(refreshToken, a.cfg.JWTConfig.SecretKey)
+// RefreshToken is a service that rotates the refresh token
+func (a *mockAuthService) RefreshToken(ctx context.Context, refreshToken string, client *Client) (api.TokenResponse, error) {
+	user, ok := a.refreshTokens[refreshToken]
+	if !ok {
+		return api.TokenResponse{}, httpErr.UnauthorizedError
+	}
+	delete(a.refreshTokens, refreshToken)
+
+	return a.issue(user), nil
+}
 
-	if err != nil {
-		return api.TokenResponse{}, err
+// Logout is a service that revokes the refresh token
+func (a *mockAuthService) Logout(ctx context.Context, refreshToken string) error {
+	if _, ok := a.refreshTokens[refreshToken]; !ok {
+		return httpErr.UnauthorizedError
 	}
+	delete(a.refreshTokens, refreshToken)
+	return nil
+}
 
-	claims := token.Claims.(jwt.MapClaims)
+// LogoutAll is a service that revokes every refresh token of the user
+func (a *mockAuthService) LogoutAll(ctx context.Context, user *model.User) error {
+	for token, owner := range a.refreshTokens {
+		if owner.ID == user.ID {
+			delete(a.refreshTokens, token)
+		}
+	}
+	return nil
+}
 
-	if int64(claims["ExpiresAt"].(float64)) < time.Now().Unix() {
-		return api.TokenResponse{}, fmt.Errorf("refresh token expired")
+// GetSessions is a service that returns the sessions of the user
+func (a *mockAuthService) GetSessions(ctx context.Context, user *model.User) ([]model.RefreshToken, error) {
+	sessions := []model.RefreshToken{}
+	for _, owner := range a.refreshTokens {
+		if owner.ID == user.ID {
+			sessions = append(sessions, model.RefreshToken{UserID: user.ID, FamilyID: uuid.New()})
+		}
 	}
+	return sessions, nil
+}
+
+// RevokeSession is a service that revokes a session of the user
+func (a *mockAuthService) RevokeSession(ctx context.Context, user *model.User, id uuid.UUID) error {
+	return gorm.ErrRecordNotFound
+}
+
+const validUserToken = "valid"
 
-	userId := claims["UserId"].(string)
+func noop(c *gin.Context) {}
 
-	id, _ := uuid.Parse(userId)
-	user := &model.User{}
+func (a *mockAuthService) ForgotPassword(ctx context.Context, email string) error {
+	return nil
+}
+
+func (a *mockAuthService) ResetPassword(ctx context.Context, token string, password string) error {
+	if token != validUserToken {
+		return httpErr.InvalidUserTokenError
+	}
+	return nil
+}
+
+func (a *mockAuthService) VerifyEmail(ctx context.Context, token string) error {
+	if token != validUserToken {
+		return httpErr.InvalidUserTokenError
+	}
+	return nil
+}
+
+func (a *mockAuthService) ResendVerification(ctx context.Context, user *model.User) error {
+	if user.IsEmailVerified() {
+		return httpErr.EmailAlreadyVerifiedError
+	}
+	return nil
+}
+
+func (a *mockAuthService) UnlockUser(ctx context.Context, admin *model.User, id uuid.UUID) error {
 	for _, item := range a.items {
 		if item.ID == id {
-			user = &item
-			break
+			return nil
 		}
 	}
+	return gorm.ErrRecordNotFound
+}
 
-	if user == nil {
-		return api.TokenResponse{}, err
+func (a *mockAuthService) LoginTwoFactor(ctx context.Context, challenge string, code string, client *Client) (api.TokenResponse, error) {
+	if code != validUserToken {
+		return api.TokenResponse{}, httpErr.UnauthorizedError
 	}
+	return api.TokenResponse{AccessToken: "access", RefreshToken: "refresh"}, nil
+}
 
-	jwtClaimsForAccessToken := jwtHelper.NewJwtClaimsForAccessToken(user, a.cfg.JWTConfig.AccessTokenLifeTime)
-	accesToken := jwtHelper.GenerateToken(jwtClaimsForAccessToken, a.cfg.JWTConfig.SecretKey)
+func (a *mockAuthService) EnrollTwoFactor(ctx context.Context, user *model.User) (*TwoFactorEnrollment, error) {
+	return &TwoFactorEnrollment{Secret: "SECRET", URI: "otpauth://totp/test", QRCode: []byte("png")}, nil
+}
 
-	return api.TokenResponse{
-		AccessToken: accesToken,
-	}, nil
+func (a *mockAuthService) ConfirmTwoFactor(ctx context.Context, user *model.User, code string) ([]string, error) {
+	if code != validUserToken {
+		return nil, httpErr.InvalidTwoFactorCodeError
+	}
+	return []string{"aaaaa-bbbbb"}, nil
+}
+
+func (a *mockAuthService) RegenerateRecoveryCodes(ctx context.Context, user *model.User, code string) ([]string, error) {
+	return a.ConfirmTwoFactor(ctx, user, code)
+}
+
+func (a *mockAuthService) DisableTwoFactor(ctx context.Context, user *model.User, code string) error {
+	if user.IsStaff() {
+		return httpErr.CannotDisableTwoFactorError
+	}
+	return nil
 }
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/pkg/config"
+	jwtHelper "patika-ecommerce/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+type jwksHandler struct {
+	cfg *config.Config
+}
+
+// NewJWKSHandler serves the public keys tokens are verified with
+func NewJWKSHandler(r *gin.RouterGroup, cfg *config.Config) {
+	handler := &jwksHandler{cfg: cfg}
+
+	r.GET("/jwks.json", handler.getJWKS)
+}
+
+// getJWKS returns the JSON Web Key Set of the signing keys
+func (h *jwksHandler) getJWKS(c *gin.Context) {
+	ring, err := jwtHelper.KeyRingFor(h.cfg)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(200, ring.JWKS())
+}
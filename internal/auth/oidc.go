@@ -0,0 +1,389 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"patika-ecommerce/internal/api"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	user "patika-ecommerce/internal/user"
+	"patika-ecommerce/pkg/config"
+	"patika-ecommerce/pkg/tracing"
+	common "patika-ecommerce/pkg/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultOIDCLoginTimeoutMinutes = 10
+	// oidcRequestTimeout bounds the calls to the provider
+	oidcRequestTimeout = 10 * time.Second
+)
+
+var defaultOIDCScopes = []string{"email", "profile"}
+
+// oidcClaims are the ID token claims a user is created from
+type oidcClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	GivenName         string `json:"given_name"`
+	FamilyName        string `json:"family_name"`
+	PreferredUsername string `json:"preferred_username"`
+}
+
+// oidcProvider discovers the endpoints of the provider on the first login, so
+// a provider which is down does not stop the service
+type oidcProvider struct {
+	cfg config.OIDCProviderConfig
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+// discover returns the provider and the OAuth2 config of the client
+func (p *oidcProvider) discover(ctx context.Context) (*oidc.Provider, *oauth2.Config, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider == nil {
+		provider, err := oidc.NewProvider(ctx, p.cfg.IssuerURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		p.provider = provider
+	}
+
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultOIDCScopes
+	}
+	return p.provider, &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     p.provider.Endpoint(),
+		Scopes:       append([]string{oidc.ScopeOpenID}, scopes...),
+	}, nil
+}
+
+type OIDCService struct {
+	cfg         *config.Config
+	authService *AuthService
+	userRepo    user.UserRepositoryInterface
+	oidcRepo    OIDCRepositoryInterface
+	providers   map[string]*oidcProvider
+}
+
+type OIDCServiceInterface interface {
+	AuthorizationURL(ctx context.Context, provider string) (string, error)
+	Callback(ctx context.Context, provider string, code string, state string, client *Client) (api.TokenResponse, error)
+}
+
+// NewOIDCService creates a new OIDCService with the configured providers
+func NewOIDCService(cfg *config.Config, authService *AuthService, userRepo user.UserRepositoryInterface, oidcRepo OIDCRepositoryInterface) *OIDCService {
+	providers := map[string]*oidcProvider{}
+	for _, provider := range cfg.OIDCConfig.Providers {
+		providers[provider.Name] = &oidcProvider{cfg: provider}
+	}
+
+	return &OIDCService{
+		cfg:         cfg,
+		authService: authService,
+		userRepo:    userRepo,
+		oidcRepo:    oidcRepo,
+		providers:   providers,
+	}
+}
+
+// AuthorizationURL starts a login at the provider and returns the URL the
+// user is sent to. The state, the nonce and the PKCE verifier are kept until
+// the callback.
+func (o *OIDCService) AuthorizationURL(ctx context.Context, name string) (string, error) {
+	ctx, span := tracing.Start(ctx, "auth.service.AuthorizationURL")
+	defer span.End()
+
+	provider, ok := o.providers[name]
+	if !ok {
+		return "", httpErr.UnknownOIDCProviderError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcRequestTimeout)
+	defer cancel()
+	_, oauthConfig, err := provider.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state, err := common.GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := common.GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := common.GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	request := &model.OIDCLoginRequest{
+		Provider:     name,
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(o.loginTimeout()),
+	}
+	if err := o.oidcRepo.InsertLoginRequest(ctx, request); err != nil {
+		return "", err
+	}
+
+	return oauthConfig.AuthCodeURL(state,
+		oidc.Nonce(nonce),
+		oauth2.SetAuthURLParam("code_challenge", codeChallenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), nil
+}
+
+// Callback exchanges the code of the provider with the PKCE verifier, checks
+// the ID token and logs in the linked user
+func (o *OIDCService) Callback(ctx context.Context, name string, code string, state string, client *Client) (api.TokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "auth.service.Callback")
+	defer span.End()
+
+	provider, ok := o.providers[name]
+	if !ok {
+		return api.TokenResponse{}, httpErr.UnknownOIDCProviderError
+	}
+
+	request, err := o.oidcRepo.ConsumeLoginRequest(ctx, name, hashToken(state))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.TokenResponse{}, httpErr.InvalidOIDCStateError
+		}
+		return api.TokenResponse{}, err
+	}
+	if request.IsExpired(time.Now()) {
+		return api.TokenResponse{}, httpErr.InvalidOIDCStateError
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oidcRequestTimeout)
+	defer cancel()
+	oidcProvider, oauthConfig, err := provider.discover(ctx)
+	if err != nil {
+		return api.TokenResponse{}, err
+	}
+
+	token, err := oauthConfig.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", request.CodeVerifier))
+	if err != nil {
+		zap.L().Warn("oidc code exchange failed", tracing.Field(ctx), zap.String("provider", name), zap.Error(err))
+		return api.TokenResponse{}, httpErr.UnauthorizedError
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		zap.L().Warn("oidc token response has no id_token", tracing.Field(ctx), zap.String("provider", name))
+		return api.TokenResponse{}, httpErr.UnauthorizedError
+	}
+
+	idToken, err := oidcProvider.Verifier(&oidc.Config{ClientID: provider.cfg.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		zap.L().Warn("oidc id token rejected", tracing.Field(ctx), zap.String("provider", name), zap.Error(err))
+		return api.TokenResponse{}, httpErr.UnauthorizedError
+	}
+	if idToken.Nonce != request.Nonce {
+		zap.L().Warn("oidc id token nonce mismatch", tracing.Field(ctx), zap.String("provider", name))
+		return api.TokenResponse{}, httpErr.UnauthorizedError
+	}
+
+	claims := oidcClaims{}
+	if err := idToken.Claims(&claims); err != nil {
+		return api.TokenResponse{}, err
+	}
+
+	user, err := o.linkedUser(ctx, name, idToken.Subject, &claims)
+	if err != nil {
+		return api.TokenResponse{}, err
+	}
+	if user.IsLocked(time.Now()) {
+		return api.TokenResponse{}, httpErr.AccountLockedError
+	}
+	return o.authService.completeFirstFactor(ctx, user, client)
+}
+
+// linkedUser returns the user linked to the identity. An identity seen for the
+// first time is linked to the user with the same email when the provider has
+// verified it, otherwise a new user is created.
+func (o *OIDCService) linkedUser(ctx context.Context, provider string, subject string, claims *oidcClaims) (*model.User, error) {
+	identity, err := o.oidcRepo.GetExternalIdentity(ctx, provider, subject)
+	if err == nil {
+		user, err := o.userRepo.GetUser(ctx, identity.UserID.String())
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, httpErr.UnauthorizedError
+			}
+			return nil, err
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, httpErr.OIDCEmailRequiredError
+	}
+	identity = &model.ExternalIdentity{Provider: provider, Subject: subject, Email: claims.Email}
+
+	existing, err := o.userRepo.GetUserByEmail(ctx, claims.Email)
+	if err == nil {
+		// anyone can sign up at a provider with any address, so an account is
+		// taken over only with an address the provider has verified
+		if !claims.EmailVerified {
+			return nil, httpErr.OIDCAccountExistsError
+		}
+		identity.UserID = existing.ID
+		if err := o.oidcRepo.InsertExternalIdentity(ctx, identity); err != nil {
+			return nil, err
+		}
+		if !existing.IsEmailVerified() {
+			if err := o.userRepo.MarkEmailVerified(ctx, existing.ID); err != nil {
+				return nil, err
+			}
+		}
+		zap.L().Info("oidc identity linked", tracing.Field(ctx), zap.String("provider", provider), zap.Reflect("userID", existing.ID))
+		return existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	user, err := newOIDCUser(claims)
+	if err != nil {
+		return nil, err
+	}
+	if err := o.oidcRepo.InsertUserWithIdentity(ctx, user, identity); err != nil {
+		return nil, err
+	}
+	if !user.IsEmailVerified() {
+		if err := o.authService.sendVerification(ctx, user); err != nil {
+			zap.L().Error("auth.oidc.linkedUser: cannot send verification mail", tracing.Field(ctx), zap.Error(err))
+		}
+	}
+	return user, nil
+}
+
+// loginTimeout returns how long a login started at a provider can be completed
+func (o *OIDCService) loginTimeout() time.Duration {
+	minutes := o.cfg.OIDCConfig.LoginTimeoutMinutes
+	if minutes <= 0 {
+		minutes = defaultOIDCLoginTimeoutMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// newOIDCUser returns a new user from the ID token claims. The user gets a
+// random password, which can be set later with a password reset.
+func newOIDCUser(claims *oidcClaims) (*model.User, error) {
+	password, err := common.GenerateToken(32)
+	if err != nil {
+		return nil, err
+	}
+	suffix, err := common.GenerateToken(3)
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		names := strings.Fields(claims.Name)
+		if len(names) > 0 {
+			firstName, lastName = names[0], strings.Join(names[1:], " ")
+		}
+	}
+
+	// the username is unique, the user can change it later
+	username := claims.PreferredUsername
+	if username == "" {
+		username = claims.Email[:strings.Index(claims.Email+"@", "@")]
+	}
+	username = truncate(username, 90) + "-" + suffix
+	if firstName == "" {
+		firstName = username
+	}
+
+	email := claims.Email
+	user := &model.User{
+		FirstName: &firstName,
+		LastName:  &lastName,
+		Username:  &username,
+		Email:     &email,
+		Password:  password,
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return user, nil
+}
+
+// codeChallenge returns the S256 PKCE challenge of the verifier
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+type oidcHandler struct {
+	oidcService OIDCServiceInterface
+}
+
+// NewOIDCHandler serves the OpenID Connect login endpoints
+func NewOIDCHandler(r *gin.RouterGroup, oidcService OIDCServiceInterface, loginRateLimit gin.HandlerFunc) {
+	handler := &oidcHandler{oidcService: oidcService}
+
+	r.GET("/oidc/:provider/authorize", handler.authorize)
+	r.POST("/oidc/:provider/callback", loginRateLimit, handler.callback)
+}
+
+// authorize returns the URL to log in at the provider
+func (h *oidcHandler) authorize(c *gin.Context) {
+	authorizationURL, err := h.oidcService.AuthorizationURL(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, api.OIDCAuthorizationResponse{AuthorizationURL: authorizationURL})
+}
+
+// callback completes the login with the code of the provider
+func (h *oidcHandler) callback(c *gin.Context) {
+	var reqBody api.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	resp, err := h.oidcService.Callback(c.Request.Context(), c.Param("provider"), *reqBody.Code, *reqBody.State, clientFromContext(c))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, resp)
+}
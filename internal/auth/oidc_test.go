@@ -0,0 +1,310 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/config"
+	"patika-ecommerce/pkg/notifier"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestOIDCService_Callback(t *testing.T) {
+	provider := newMockOIDCProvider(t, "client-id")
+	cfg := &config.Config{
+		JWTConfig: config.JWTConfig{SecretKey: "secret", AccessTokenLifeTime: 30, RefreshTokenLifeTime: 24},
+		OIDCConfig: config.OIDCConfig{Providers: []config.OIDCProviderConfig{{
+			Name:         "mock",
+			IssuerURL:    provider.server.URL,
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+			RedirectURL:  "https://shop.example.com/oidc/mock/callback",
+		}}},
+	}
+	existingEmail, existingName := "existing@example.com", "existing"
+	userRepo := &mockUserRepository{items: []model.User{
+		{Base: model.Base{ID: uuid.New()}, Email: &existingEmail, Username: &existingName, FirstName: &existingName, LastName: &existingName},
+	}}
+	oidcRepo := &mockOIDCRepository{users: userRepo}
+	twoFactorRepo := &mockTwoFactorRepository{}
+	authService := NewAuthService(cfg, userRepo, &mockRefreshTokenRepository{}, &mockUserTokenRepository{}, twoFactorRepo, notifier.NewLogNotifier())
+	o := NewOIDCService(cfg, authService, userRepo, oidcRepo)
+
+	login := func(claims jwt.MapClaims) (string, string) {
+		authorizationURL, err := o.AuthorizationURL(context.Background(), "mock")
+		assert.Equal(t, nil, err)
+		return provider.authorize(t, authorizationURL, claims)
+	}
+
+	// a new identity creates a verified user
+	code, state := login(jwt.MapClaims{"sub": "subject-1", "email": "new@example.com", "email_verified": true, "given_name": "New", "family_name": "User"})
+	resp, err := o.Callback(context.Background(), "mock", code, state, &Client{})
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, "", resp.AccessToken)
+	assert.Equal(t, 2, len(userRepo.items))
+	created := userRepo.items[1]
+	assert.Equal(t, "New", *created.FirstName)
+	assert.Equal(t, true, created.IsEmailVerified())
+	assert.Equal(t, created.ID, oidcRepo.identities[0].UserID)
+
+	// the state is used once
+	_, err = o.Callback(context.Background(), "mock", code, state, &Client{})
+	assert.Equal(t, httpErr.InvalidOIDCStateError, err)
+
+	// the same identity logs in the same user
+	code, state = login(jwt.MapClaims{"sub": "subject-1", "email": "changed@example.com"})
+	_, err = o.Callback(context.Background(), "mock", code, state, &Client{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(userRepo.items))
+
+	// a code cannot be redeemed with the verifier of another login
+	code, _ = login(jwt.MapClaims{"sub": "subject-2", "email": "other@example.com"})
+	_, state = login(jwt.MapClaims{"sub": "subject-2", "email": "other@example.com"})
+	_, err = o.Callback(context.Background(), "mock", code, state, &Client{})
+	assert.Equal(t, httpErr.UnauthorizedError, err)
+
+	// an existing account is linked only with a verified email
+	code, state = login(jwt.MapClaims{"sub": "subject-3", "email": existingEmail})
+	_, err = o.Callback(context.Background(), "mock", code, state, &Client{})
+	assert.Equal(t, httpErr.OIDCAccountExistsError, err)
+
+	code, state = login(jwt.MapClaims{"sub": "subject-3", "email": existingEmail, "email_verified": true})
+	_, err = o.Callback(context.Background(), "mock", code, state, &Client{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(userRepo.items))
+	assert.Equal(t, userRepo.items[0].ID, oidcRepo.identities[1].UserID)
+	assert.Equal(t, true, userRepo.items[0].IsEmailVerified())
+
+	// two-factor authentication is still required
+	now := time.Now()
+	twoFactorRepo.twoFactors = append(twoFactorRepo.twoFactors, &model.TwoFactor{UserID: userRepo.items[0].ID, ConfirmedAt: &now})
+	code, state = login(jwt.MapClaims{"sub": "subject-3"})
+	resp, err = o.Callback(context.Background(), "mock", code, state, &Client{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, resp.TwoFactorRequired)
+	assert.Equal(t, "", resp.AccessToken)
+
+	code, state = login(jwt.MapClaims{"sub": "subject-4"})
+	_, err = o.Callback(context.Background(), "mock", code, state, &Client{})
+	assert.Equal(t, httpErr.OIDCEmailRequiredError, err)
+
+	code, state = login(jwt.MapClaims{"sub": "subject-1"})
+	oidcRepo.requests[len(oidcRepo.requests)-1].ExpiresAt = time.Now().Add(-time.Second)
+	_, err = o.Callback(context.Background(), "mock", code, state, &Client{})
+	assert.Equal(t, httpErr.InvalidOIDCStateError, err)
+
+	_, err = o.AuthorizationURL(context.Background(), "unknown")
+	assert.Equal(t, httpErr.UnknownOIDCProviderError, err)
+}
+
+func Test_oidcHandler(t *testing.T) {
+	provider := newMockOIDCProvider(t, "client-id")
+	cfg := &config.Config{
+		JWTConfig: config.JWTConfig{SecretKey: "secret", AccessTokenLifeTime: 30, RefreshTokenLifeTime: 24},
+		OIDCConfig: config.OIDCConfig{Providers: []config.OIDCProviderConfig{{
+			Name: "mock", IssuerURL: provider.server.URL, ClientID: "client-id", RedirectURL: "https://shop.example.com/callback",
+		}}},
+	}
+	userRepo := &mockUserRepository{}
+	authService := NewAuthService(cfg, userRepo, &mockRefreshTokenRepository{}, &mockUserTokenRepository{}, &mockTwoFactorRepository{}, notifier.NewLogNotifier())
+	o := NewOIDCService(cfg, authService, userRepo, &mockOIDCRepository{users: userRepo})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewOIDCHandler(&r.RouterGroup, o, noop)
+	request := func(method string, path string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("GET", "/oidc/mock/authorize", "")
+	assert.Equal(t, 200, w.Code)
+	resp := map[string]string{}
+	assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), &resp))
+	code, state := provider.authorize(t, resp["authorizationUrl"], jwt.MapClaims{"sub": "subject", "email": "user@example.com", "name": "Jane Doe"})
+
+	assert.Equal(t, 404, request("GET", "/oidc/unknown/authorize", "").Code)
+	assert.Equal(t, 400, request("POST", "/oidc/mock/callback", `{"code":"`+code+`"}`).Code)
+	assert.Equal(t, 400, request("POST", "/oidc/mock/callback", `{"code":"`+code+`","state":"wrong"}`).Code)
+
+	w = request("POST", "/oidc/mock/callback", `{"code":"`+code+`","state":"`+state+`"}`)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "Jane", *userRepo.items[0].FirstName)
+	assert.Equal(t, "Doe", *userRepo.items[0].LastName)
+	assert.Equal(t, false, userRepo.items[0].IsEmailVerified())
+}
+
+// mockOIDCProvider is a local OpenID Connect provider, which issues RS256 ID
+// tokens for the codes of authorize and checks the PKCE verifier
+type mockOIDCProvider struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+
+	mu    sync.Mutex
+	codes map[string]mockOIDCCode
+}
+
+type mockOIDCCode struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T, clientID string) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Equal(t, nil, err)
+	p := &mockOIDCProvider{key: key, clientID: clientID, codes: map[string]mockOIDCCode{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, 200, map[string]interface{}{
+			"issuer":                                p.server.URL,
+			"authorization_endpoint":                p.server.URL + "/authorize",
+			"token_endpoint":                        p.server.URL + "/token",
+			"jwks_uri":                              p.server.URL + "/jwks",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, 200, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": "mock",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// authorize logs in at the provider with the given claims and returns the
+// code and the state the provider redirects back with
+func (p *mockOIDCProvider) authorize(t *testing.T, authorizationURL string, claims jwt.MapClaims) (string, string) {
+	u, err := url.Parse(authorizationURL)
+	assert.Equal(t, nil, err)
+	query := u.Query()
+	assert.Equal(t, p.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, p.clientID, query.Get("client_id"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+
+	idClaims := jwt.MapClaims{"nonce": query.Get("nonce")}
+	for name, value := range claims {
+		idClaims[name] = value
+	}
+	code := uuid.New().String()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.codes[code] = mockOIDCCode{challenge: query.Get("code_challenge"), claims: idClaims}
+	return code, query.Get("state")
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	code, ok := p.codes[r.PostFormValue("code")]
+	delete(p.codes, r.PostFormValue("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(sum[:]) != code.challenge {
+		writeJSON(w, 400, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss": p.server.URL,
+		"aud": p.clientID,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}
+	for name, value := range code.claims {
+		claims[name] = value
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "mock"
+	idToken, _ := token.SignedString(p.key)
+
+	writeJSON(w, 200, map[string]interface{}{
+		"access_token": "provider-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     idToken,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+type mockOIDCRepository struct {
+	users      *mockUserRepository
+	requests   []*model.OIDCLoginRequest
+	identities []*model.ExternalIdentity
+}
+
+func (r *mockOIDCRepository) InsertLoginRequest(ctx context.Context, request *model.OIDCLoginRequest) error {
+	request.ID = uuid.New()
+	r.requests = append(r.requests, request)
+	return nil
+}
+
+func (r *mockOIDCRepository) ConsumeLoginRequest(ctx context.Context, provider string, stateHash string) (*model.OIDCLoginRequest, error) {
+	for i, request := range r.requests {
+		if request.Provider == provider && request.StateHash == stateHash {
+			r.requests = append(r.requests[:i], r.requests[i+1:]...)
+			return request, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockOIDCRepository) GetExternalIdentity(ctx context.Context, provider string, subject string) (*model.ExternalIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockOIDCRepository) InsertExternalIdentity(ctx context.Context, identity *model.ExternalIdentity) error {
+	identity.ID = uuid.New()
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *mockOIDCRepository) InsertUserWithIdentity(ctx context.Context, user *model.User, identity *model.ExternalIdentity) error {
+	user.ID = uuid.New()
+	if _, err := r.users.InsertUser(ctx, user); err != nil {
+		return err
+	}
+	identity.UserID = user.ID
+	return r.InsertExternalIdentity(ctx, identity)
+}
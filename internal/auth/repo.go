@@ -0,0 +1,488 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// errRefreshTokenReused is returned when a token is rotated by another request first
+	errRefreshTokenReused = errors.New("refresh token is already rotated")
+	// errUserTokenUsed is returned when a user token is consumed by another request first
+	errUserTokenUsed = errors.New("user token is already used")
+	// errTOTPCodeUsed is returned when a TOTP code is accepted by another request first
+	errTOTPCodeUsed = errors.New("totp code is already used")
+)
+
+type RefreshTokenRepositoryInterface interface {
+	InsertToken(ctx context.Context, token *model.RefreshToken) error
+	GetTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error)
+	RotateToken(ctx context.Context, old *model.RefreshToken, new *model.RefreshToken) error
+	RevokeFamily(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (int64, error)
+	RevokeUserTokens(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetActiveTokensByUser(ctx context.Context, userID uuid.UUID) ([]model.RefreshToken, error)
+}
+
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// InsertToken inserts a new refresh token
+func (r *RefreshTokenRepository) InsertToken(ctx context.Context, token *model.RefreshToken) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.InsertToken")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.InsertToken", tracing.Field(ctx), zap.Reflect("userID", token.UserID), zap.Reflect("familyID", token.FamilyID))
+
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+// GetTokenByHash returns the refresh token with the given hash
+func (r *RefreshTokenRepository) GetTokenByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	ctx, span := tracing.Start(ctx, "auth.repo.GetTokenByHash")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.GetTokenByHash", tracing.Field(ctx))
+
+	token := &model.RefreshToken{}
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// RotateToken marks the old token as rotated and inserts the new one. It
+// returns errRefreshTokenReused when the old token is not active anymore.
+func (r *RefreshTokenRepository) RotateToken(ctx context.Context, old *model.RefreshToken, new *model.RefreshToken) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.RotateToken")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.RotateToken", tracing.Field(ctx), zap.Reflect("familyID", old.FamilyID))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	result := tx.Model(&model.RefreshToken{}).
+		Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", old.ID).
+		UpdateColumn("rotated_at", time.Now())
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return errRefreshTokenReused
+	}
+
+	if err := tx.Create(new).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// RevokeFamily revokes the tokens of a session of the user
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, userID uuid.UUID, familyID uuid.UUID) (int64, error) {
+	ctx, span := tracing.Start(ctx, "auth.repo.RevokeFamily")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.RevokeFamily", tracing.Field(ctx), zap.Reflect("userID", userID), zap.Reflect("familyID", familyID))
+
+	result := r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND family_id = ? AND revoked_at IS NULL", userID, familyID).
+		UpdateColumn("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// RevokeUserTokens revokes the tokens of every session of the user
+func (r *RefreshTokenRepository) RevokeUserTokens(ctx context.Context, userID uuid.UUID) (int64, error) {
+	ctx, span := tracing.Start(ctx, "auth.repo.RevokeUserTokens")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.RevokeUserTokens", tracing.Field(ctx), zap.Reflect("userID", userID))
+
+	result := r.db.WithContext(ctx).Model(&model.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumn("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// GetActiveTokensByUser returns the latest token of every open session of the user
+func (r *RefreshTokenRepository) GetActiveTokensByUser(ctx context.Context, userID uuid.UUID) ([]model.RefreshToken, error) {
+	ctx, span := tracing.Start(ctx, "auth.repo.GetActiveTokensByUser")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.GetActiveTokensByUser", tracing.Field(ctx), zap.Reflect("userID", userID))
+
+	var tokens []model.RefreshToken
+	err := r.db.WithContext(ctx).Where("user_id = ? AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
+type UserTokenRepositoryInterface interface {
+	InsertUserToken(ctx context.Context, token *model.UserToken) error
+	GetUserTokenByHash(ctx context.Context, hash string, purpose model.UserTokenPurpose) (*model.UserToken, error)
+	ConsumeUserToken(ctx context.Context, token *model.UserToken) error
+}
+
+type UserTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+// InsertUserToken inserts a new token and invalidates the unused tokens of the
+// user with the same purpose, so only the latest mailed link works.
+func (r *UserTokenRepository) InsertUserToken(ctx context.Context, token *model.UserToken) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.InsertUserToken")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.InsertUserToken", tracing.Field(ctx), zap.Reflect("userID", token.UserID), zap.Reflect("purpose", token.Purpose))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+		Delete(&model.UserToken{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(token).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetUserTokenByHash returns the token with the given hash and purpose
+func (r *UserTokenRepository) GetUserTokenByHash(ctx context.Context, hash string, purpose model.UserTokenPurpose) (*model.UserToken, error) {
+	ctx, span := tracing.Start(ctx, "auth.repo.GetUserTokenByHash")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.GetUserTokenByHash", tracing.Field(ctx), zap.Reflect("purpose", purpose))
+
+	token := &model.UserToken{}
+	if err := r.db.WithContext(ctx).Where("token_hash = ? AND purpose = ?", hash, purpose).First(token).Error; err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// ConsumeUserToken marks the token as used. It returns errUserTokenUsed when
+// the token is already used.
+func (r *UserTokenRepository) ConsumeUserToken(ctx context.Context, token *model.UserToken) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.ConsumeUserToken")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.ConsumeUserToken", tracing.Field(ctx), zap.Reflect("id", token.ID))
+
+	result := r.db.WithContext(ctx).Model(&model.UserToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errUserTokenUsed
+	}
+	return nil
+}
+
+type TwoFactorRepositoryInterface interface {
+	GetTwoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactor, error)
+	ReplaceTwoFactor(ctx context.Context, twoFactor *model.TwoFactor) error
+	ConfirmTwoFactor(ctx context.Context, twoFactor *model.TwoFactor, step int64, codes []model.RecoveryCode) error
+	UseTOTPStep(ctx context.Context, twoFactor *model.TwoFactor, step int64) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []model.RecoveryCode) error
+	DeleteTwoFactor(ctx context.Context, userID uuid.UUID) error
+}
+
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// GetTwoFactor returns the TOTP secret of the user
+func (r *TwoFactorRepository) GetTwoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactor, error) {
+	ctx, span := tracing.Start(ctx, "auth.repo.GetTwoFactor")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.GetTwoFactor", tracing.Field(ctx), zap.Reflect("userID", userID))
+
+	twoFactor := &model.TwoFactor{}
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(twoFactor).Error; err != nil {
+		return nil, err
+	}
+	return twoFactor, nil
+}
+
+// ReplaceTwoFactor replaces the unconfirmed TOTP secret of the user
+func (r *TwoFactorRepository) ReplaceTwoFactor(ctx context.Context, twoFactor *model.TwoFactor) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.ReplaceTwoFactor")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.ReplaceTwoFactor", tracing.Field(ctx), zap.Reflect("userID", twoFactor.UserID))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	if err := tx.Where("user_id = ? AND confirmed_at IS NULL", twoFactor.UserID).Delete(&model.TwoFactor{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(twoFactor).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ConfirmTwoFactor enables the TOTP secret with the step of the confirming
+// code and stores the recovery codes
+func (r *TwoFactorRepository) ConfirmTwoFactor(ctx context.Context, twoFactor *model.TwoFactor, step int64, codes []model.RecoveryCode) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.ConfirmTwoFactor")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.ConfirmTwoFactor", tracing.Field(ctx), zap.Reflect("userID", twoFactor.UserID))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	result := tx.Model(&model.TwoFactor{}).
+		Where("id = ? AND confirmed_at IS NULL", twoFactor.ID).
+		UpdateColumns(map[string]interface{}{"confirmed_at": time.Now(), "last_used_step": step})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return errTOTPCodeUsed
+	}
+
+	if err := replaceRecoveryCodes(tx, twoFactor.UserID, codes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// UseTOTPStep records the step of an accepted code. It returns
+// errTOTPCodeUsed when the step or a later one is already used.
+func (r *TwoFactorRepository) UseTOTPStep(ctx context.Context, twoFactor *model.TwoFactor, step int64) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.UseTOTPStep")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.UseTOTPStep", tracing.Field(ctx), zap.Reflect("userID", twoFactor.UserID))
+
+	result := r.db.WithContext(ctx).Model(&model.TwoFactor{}).
+		Where("id = ? AND last_used_step < ?", twoFactor.ID, step).
+		UpdateColumn("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errTOTPCodeUsed
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code of the user as used
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash string) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.UseRecoveryCode")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.UseRecoveryCode", tracing.Field(ctx), zap.Reflect("userID", userID))
+
+	result := r.db.WithContext(ctx).Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes replaces the recovery codes of the user
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codes []model.RecoveryCode) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.ReplaceRecoveryCodes")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.ReplaceRecoveryCodes", tracing.Field(ctx), zap.Reflect("userID", userID))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	if err := replaceRecoveryCodes(tx, userID, codes); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// DeleteTwoFactor removes the TOTP secret and the recovery codes of the user
+func (r *TwoFactorRepository) DeleteTwoFactor(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.DeleteTwoFactor")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.DeleteTwoFactor", tracing.Field(ctx), zap.Reflect("userID", userID))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&model.TwoFactor{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID, codes []model.RecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&codes).Error
+}
+
+type OIDCRepositoryInterface interface {
+	InsertLoginRequest(ctx context.Context, request *model.OIDCLoginRequest) error
+	ConsumeLoginRequest(ctx context.Context, provider string, stateHash string) (*model.OIDCLoginRequest, error)
+	GetExternalIdentity(ctx context.Context, provider string, subject string) (*model.ExternalIdentity, error)
+	InsertExternalIdentity(ctx context.Context, identity *model.ExternalIdentity) error
+	InsertUserWithIdentity(ctx context.Context, user *model.User, identity *model.ExternalIdentity) error
+}
+
+type OIDCRepository struct {
+	db *gorm.DB
+}
+
+func NewOIDCRepository(db *gorm.DB) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+// InsertLoginRequest inserts a new login request and removes the expired ones
+func (r *OIDCRepository) InsertLoginRequest(ctx context.Context, request *model.OIDCLoginRequest) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.InsertLoginRequest")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.InsertLoginRequest", tracing.Field(ctx), zap.String("provider", request.Provider))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	if err := tx.Where("expires_at < ?", time.Now()).Delete(&model.OIDCLoginRequest{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(request).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ConsumeLoginRequest removes and returns the login request with the given
+// state, so a login is completed once
+func (r *OIDCRepository) ConsumeLoginRequest(ctx context.Context, provider string, stateHash string) (*model.OIDCLoginRequest, error) {
+	ctx, span := tracing.Start(ctx, "auth.repo.ConsumeLoginRequest")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.ConsumeLoginRequest", tracing.Field(ctx), zap.String("provider", provider))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	request := &model.OIDCLoginRequest{}
+	if err := tx.Where("provider = ? AND state_hash = ?", provider, stateHash).First(request).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	result := tx.Where("id = ?", request.ID).Delete(&model.OIDCLoginRequest{})
+	if result.Error != nil {
+		tx.Rollback()
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return request, tx.Commit().Error
+}
+
+// GetExternalIdentity returns the identity of the subject at the provider
+func (r *OIDCRepository) GetExternalIdentity(ctx context.Context, provider string, subject string) (*model.ExternalIdentity, error) {
+	ctx, span := tracing.Start(ctx, "auth.repo.GetExternalIdentity")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.GetExternalIdentity", tracing.Field(ctx), zap.String("provider", provider))
+
+	identity := &model.ExternalIdentity{}
+	if err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(identity).Error; err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// InsertExternalIdentity links an identity to an existing user
+func (r *OIDCRepository) InsertExternalIdentity(ctx context.Context, identity *model.ExternalIdentity) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.InsertExternalIdentity")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.InsertExternalIdentity", tracing.Field(ctx), zap.String("provider", identity.Provider), zap.Reflect("userID", identity.UserID))
+
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+// InsertUserWithIdentity inserts a new user with its identity
+func (r *OIDCRepository) InsertUserWithIdentity(ctx context.Context, user *model.User, identity *model.ExternalIdentity) error {
+	ctx, span := tracing.Start(ctx, "auth.repo.InsertUserWithIdentity")
+	defer span.End()
+
+	zap.L().Debug("auth.repo.InsertUserWithIdentity", tracing.Field(ctx), zap.String("provider", identity.Provider))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	if err := tx.Create(user).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	identity.UserID = user.ID
+	if err := tx.Create(identity).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
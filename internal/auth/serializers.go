@@ -3,6 +3,9 @@ package auth
 import (
 	"patika-ecommerce/internal/api"
 	"patika-ecommerce/internal/model"
+	common "patika-ecommerce/pkg/utils"
+
+	"github.com/go-openapi/strfmt"
 )
 
 // RegisterToUser converts a RegisterUser to a User
@@ -29,3 +32,33 @@ func LoginToUser(user *api.LoginUser) *model.User {
 		Password: *user.Password,
 	}
 }
+
+// RefreshTokenToSessionResponse converts the latest refresh token of a session to a session response
+func RefreshTokenToSessionResponse(token *model.RefreshToken) *api.SessionResponse {
+	return &api.SessionResponse{
+		ID:         common.UUIDToStrfmt(token.FamilyID),
+		UserAgent:  token.UserAgent,
+		IP:         token.IP,
+		StartedAt:  strfmt.DateTime(token.SessionStartedAt),
+		LastUsedAt: strfmt.DateTime(token.CreatedAt),
+		ExpiresAt:  strfmt.DateTime(token.ExpiresAt),
+	}
+}
+
+// RefreshTokensToSessionResponse converts refresh tokens to session responses
+func RefreshTokensToSessionResponse(tokens []model.RefreshToken) []*api.SessionResponse {
+	response := []*api.SessionResponse{}
+	for _, token := range tokens {
+		response = append(response, RefreshTokenToSessionResponse(&token))
+	}
+	return response
+}
+
+// TwoFactorEnrollmentToResponse converts a TwoFactorEnrollment to a TwoFactorEnrollmentResponse
+func TwoFactorEnrollmentToResponse(enrollment *TwoFactorEnrollment) *api.TwoFactorEnrollmentResponse {
+	return &api.TwoFactorEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.URI,
+		QrCode:          strfmt.Base64(enrollment.QRCode),
+	}
+}
@@ -1,97 +1,821 @@
 package auth
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/url"
 	"patika-ecommerce/internal/api"
 	httpErr "patika-ecommerce/internal/httpErrors"
 	"patika-ecommerce/internal/model"
 	user "patika-ecommerce/internal/user"
 	"patika-ecommerce/pkg/config"
 	jwtHelper "patika-ecommerce/pkg/jwt"
+	"patika-ecommerce/pkg/metrics"
+	"patika-ecommerce/pkg/notifier"
+	"patika-ecommerce/pkg/tracing"
+	common "patika-ecommerce/pkg/utils"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+const (
+	NotificationPasswordReset     = "password_reset"
+	NotificationEmailVerification = "email_verification"
+)
+
+const (
+	defaultPasswordResetTokenMinutes   = 30
+	defaultEmailVerificationTokenHours = 48
+	defaultLockoutThreshold            = 5
+	defaultLockoutMinutes              = 1
+	defaultMaxLockoutMinutes           = 24 * 60
+	defaultTwoFactorIssuer             = "Patika E-commerce"
+	defaultTwoFactorChallengeMinutes   = 5
+)
+
+// Client is the device a session is opened from
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
 type AuthService struct {
-	cfg      *config.Config
-	userRepo user.UserRepositoryInterface
+	cfg              *config.Config
+	userRepo         user.UserRepositoryInterface
+	refreshTokenRepo RefreshTokenRepositoryInterface
+	userTokenRepo    UserTokenRepositoryInterface
+	twoFactorRepo    TwoFactorRepositoryInterface
+	notifier         notifier.Notifier
+}
+
+// TwoFactorEnrollment is a new TOTP secret waiting for confirmation
+type TwoFactorEnrollment struct {
+	Secret string
+	URI    string
+	QRCode []byte
 }
 
 type AuthServiceInterface interface {
-	Register(user *model.User) (api.TokenResponse, error)
-	Login(user *model.User) (api.TokenResponse, error)
-	RefreshToken(refreshToken string) (api.TokenResponse, error)
+	Register(ctx context.Context, user *model.User, client *Client) (api.TokenResponse, error)
+	Login(ctx context.Context, user *model.User, client *Client) (api.TokenResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string, client *Client) (api.TokenResponse, error)
+	Logout(ctx context.Context, refreshToken string) error
+	LogoutAll(ctx context.Context, user *model.User) error
+	GetSessions(ctx context.Context, user *model.User) ([]model.RefreshToken, error)
+	RevokeSession(ctx context.Context, user *model.User, id uuid.UUID) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, user *model.User) error
+	UnlockUser(ctx context.Context, admin *model.User, id uuid.UUID) error
+	LoginTwoFactor(ctx context.Context, challenge string, code string, client *Client) (api.TokenResponse, error)
+	EnrollTwoFactor(ctx context.Context, user *model.User) (*TwoFactorEnrollment, error)
+	ConfirmTwoFactor(ctx context.Context, user *model.User, code string) ([]string, error)
+	RegenerateRecoveryCodes(ctx context.Context, user *model.User, code string) ([]string, error)
+	DisableTwoFactor(ctx context.Context, user *model.User, code string) error
 }
 
 // NewAuthService creates a new AuthService
-func NewAuthService(cfg *config.Config, userRepo user.UserRepositoryInterface) *AuthService {
+func NewAuthService(cfg *config.Config, userRepo user.UserRepositoryInterface, refreshTokenRepo RefreshTokenRepositoryInterface,
+	userTokenRepo UserTokenRepositoryInterface, twoFactorRepo TwoFactorRepositoryInterface, notifier notifier.Notifier) *AuthService {
 	return &AuthService{
-		cfg:      cfg,
-		userRepo: userRepo,
+		cfg:              cfg,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		userTokenRepo:    userTokenRepo,
+		twoFactorRepo:    twoFactorRepo,
+		notifier:         notifier,
 	}
 }
 
 // Register is a service that registers a new user
-func (a *AuthService) Register(user *model.User) (api.TokenResponse, error) {
-	user, err := a.userRepo.InsertUser(user)
+func (a *AuthService) Register(ctx context.Context, user *model.User, client *Client) (api.TokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "auth.service.Register")
+	defer span.End()
+
+	user, err := a.userRepo.InsertUser(ctx, user)
 	if err != nil {
 		return api.TokenResponse{}, err
 	}
-	return jwtHelper.GetAuthToken(user, a.cfg), nil
+
+	// the user can ask for a new link, so a failed mail does not fail the registration
+	if err := a.sendVerification(ctx, user); err != nil {
+		zap.L().Error("auth.service.Register: cannot send verification mail", tracing.Field(ctx), zap.Error(err))
+	}
+	return a.startSession(ctx, user, client)
 }
 
 // Login is a service that logs in a user
-func (a *AuthService) Login(u *model.User) (api.TokenResponse, error) {
-	user, err := a.userRepo.GetUserByEmail(*u.Email)
+func (a *AuthService) Login(ctx context.Context, u *model.User, client *Client) (api.TokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "auth.service.Login")
+	defer span.End()
+
+	user, err := a.userRepo.GetUserByEmail(ctx, *u.Email)
 	if err != nil {
-		if err := gorm.ErrRecordNotFound; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			metrics.LoginFailed(metrics.LoginFailureUnknownUser)
 			return api.TokenResponse{}, httpErr.UnauthorizedError
 		}
 		return api.TokenResponse{}, err
 	}
+	if user.IsLocked(time.Now()) {
+		metrics.LoginFailed(metrics.LoginFailureLocked)
+		return api.TokenResponse{}, httpErr.AccountLockedError
+	}
 	// check if the password is correct
 	if !user.CheckPassword(u.Password) {
+		metrics.LoginFailed(metrics.LoginFailureInvalidPassword)
+		a.recordFailedLogin(ctx, user)
 		return api.TokenResponse{}, httpErr.UnauthorizedError
 	}
 
-	return jwtHelper.GetAuthToken(user, a.cfg), nil
+	return a.completeFirstFactor(ctx, user, client)
+}
+
+// LoginTwoFactor completes the login of a user with two-factor authentication
+// with the challenge token of Login and a TOTP or recovery code
+func (a *AuthService) LoginTwoFactor(ctx context.Context, challenge string, code string, client *Client) (api.TokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "auth.service.LoginTwoFactor")
+	defer span.End()
+
+	claims, err := jwtHelper.ParseToken(challenge, jwtHelper.TokenTypeTwoFactorChallenge, a.cfg)
+	if err != nil {
+		return api.TokenResponse{}, httpErr.UnauthorizedError
+	}
+
+	user, err := a.userRepo.GetUser(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.TokenResponse{}, httpErr.UnauthorizedError
+		}
+		return api.TokenResponse{}, err
+	}
+	if user.IsLocked(time.Now()) {
+		metrics.LoginFailed(metrics.LoginFailureLocked)
+		return api.TokenResponse{}, httpErr.AccountLockedError
+	}
+
+	twoFactor, err := a.enabledTwoFactor(ctx, user.ID)
+	if err != nil {
+		return api.TokenResponse{}, err
+	}
+	if twoFactor == nil || user.IsSuspended() {
+		return api.TokenResponse{}, httpErr.UnauthorizedError
+	}
+	if err := a.verifySecondFactor(ctx, user, twoFactor, code); err != nil {
+		if errors.Is(err, httpErr.InvalidTwoFactorCodeError) {
+			metrics.LoginFailed(metrics.LoginFailureInvalidCode)
+			a.recordFailedLogin(ctx, user)
+			return api.TokenResponse{}, httpErr.UnauthorizedError
+		}
+		return api.TokenResponse{}, err
+	}
+
+	if err := a.resetFailedLogins(ctx, user); err != nil {
+		return api.TokenResponse{}, err
+	}
+	user.TwoFactorVerified = true
+	return a.startSession(ctx, user, client)
 }
 
-// RefreshToken is a service that checks if the refresh token is valid and returns a new JWT token
-func (a *AuthService) RefreshToken(refreshToken string) (api.TokenResponse, error) {
-	u := jwtHelper.VerifyToken(refreshToken, a.cfg.JWTConfig.SecretKey)
+// RefreshToken exchanges the refresh token for a new access and refresh token.
+// Using a token which is already exchanged means it is stolen, so the whole
+// session is revoked.
+func (a *AuthService) RefreshToken(ctx context.Context, refreshToken string, client *Client) (api.TokenResponse, error) {
+	ctx, span := tracing.Start(ctx, "auth.service.RefreshToken")
+	defer span.End()
+
+	token, err := a.refreshTokenRepo.GetTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return api.TokenResponse{}, httpErr.UnauthorizedError
+		}
+		return api.TokenResponse{}, err
+	}
 
-	if u == nil {
+	if token.IsRotated() {
+		a.revokeReusedFamily(ctx, token)
+		return api.TokenResponse{}, httpErr.UnauthorizedError
+	}
+	if !token.IsActive(time.Now()) {
 		return api.TokenResponse{}, httpErr.UnauthorizedError
 	}
 
-	// parse the refresh token
-	token, err := jwtHelper.ParseToken(refreshToken, a.cfg.JWTConfig.SecretKey)
+	user, err := a.userRepo.GetUser(ctx, token.UserID.String())
+	if err != nil {
+		return api.TokenResponse{}, err
+	}
+	if user.IsSuspended() {
+		return api.TokenResponse{}, httpErr.AccountSuspendedError
+	}
+	user.TwoFactorVerified = token.TwoFactorVerified
 
+	next, plain, err := a.newRefreshToken(user, token.FamilyID, token.SessionStartedAt, client)
 	if err != nil {
 		return api.TokenResponse{}, err
 	}
+	// the session reached its maximum lifetime
+	if !next.IsActive(time.Now()) {
+		return api.TokenResponse{}, httpErr.UnauthorizedError
+	}
+	if err := a.refreshTokenRepo.RotateToken(ctx, token, next); err != nil {
+		if errors.Is(err, errRefreshTokenReused) {
+			a.revokeReusedFamily(ctx, token)
+			return api.TokenResponse{}, httpErr.UnauthorizedError
+		}
+		return api.TokenResponse{}, err
+	}
+
+	return a.tokenResponse(user, plain)
+}
+
+// Logout revokes the session of the refresh token
+func (a *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	ctx, span := tracing.Start(ctx, "auth.service.Logout")
+	defer span.End()
+
+	token, err := a.refreshTokenRepo.GetTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return httpErr.UnauthorizedError
+		}
+		return err
+	}
+
+	_, err = a.refreshTokenRepo.RevokeFamily(ctx, token.UserID, token.FamilyID)
+	return err
+}
+
+// LogoutAll revokes every session of the user
+func (a *AuthService) LogoutAll(ctx context.Context, user *model.User) error {
+	ctx, span := tracing.Start(ctx, "auth.service.LogoutAll")
+	defer span.End()
+
+	_, err := a.refreshTokenRepo.RevokeUserTokens(ctx, user.ID)
+	return err
+}
+
+// GetSessions returns the open sessions of the user
+func (a *AuthService) GetSessions(ctx context.Context, user *model.User) ([]model.RefreshToken, error) {
+	ctx, span := tracing.Start(ctx, "auth.service.GetSessions")
+	defer span.End()
+
+	return a.refreshTokenRepo.GetActiveTokensByUser(ctx, user.ID)
+}
+
+// RevokeSession revokes a session of the user
+func (a *AuthService) RevokeSession(ctx context.Context, user *model.User, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "auth.service.RevokeSession")
+	defer span.End()
+
+	revoked, err := a.refreshTokenRepo.RevokeFamily(ctx, user.ID, id)
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UnlockUser unlocks an account locked after failed logins. Admins cannot
+// unlock the accounts they cannot manage.
+func (a *AuthService) UnlockUser(ctx context.Context, admin *model.User, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "auth.service.UnlockUser")
+	defer span.End()
+
+	user, err := a.userRepo.GetUser(ctx, id.String())
+	if err != nil {
+		return err
+	}
+	if !admin.CanManage(user) {
+		return httpErr.ForbiddenError
+	}
+
+	unlocked, err := a.userRepo.UnlockUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if unlocked == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// EnrollTwoFactor creates a new TOTP secret of the user, it replaces an
+// earlier secret which is not confirmed
+func (a *AuthService) EnrollTwoFactor(ctx context.Context, u *model.User) (*TwoFactorEnrollment, error) {
+	ctx, span := tracing.Start(ctx, "auth.service.EnrollTwoFactor")
+	defer span.End()
+
+	user, err := a.userRepo.GetUser(ctx, u.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	twoFactor, err := a.enabledTwoFactor(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor != nil {
+		return nil, httpErr.TwoFactorAlreadyEnabledError
+	}
+
+	issuer := a.cfg.AccountConfig.TwoFactorIssuer
+	if issuer == "" {
+		issuer = defaultTwoFactorIssuer
+	}
+	key, err := newTOTPKey(issuer, *user.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.twoFactorRepo.ReplaceTwoFactor(ctx, &model.TwoFactor{UserID: user.ID, Secret: key.Secret()}); err != nil {
+		return nil, err
+	}
+
+	image, err := qrCode(key)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoFactorEnrollment{Secret: key.Secret(), URI: key.URL(), QRCode: image}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication with a code of the new
+// secret and returns the recovery codes
+func (a *AuthService) ConfirmTwoFactor(ctx context.Context, user *model.User, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "auth.service.ConfirmTwoFactor")
+	defer span.End()
+
+	twoFactor, err := a.twoFactorRepo.GetTwoFactor(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, httpErr.TwoFactorNotEnabledError
+		}
+		return nil, err
+	}
+	if twoFactor.IsEnabled() {
+		return nil, httpErr.TwoFactorAlreadyEnabledError
+	}
+
+	step, ok := matchTOTP(twoFactor.Secret, code, time.Now(), twoFactor.LastUsedStep)
+	if !ok {
+		return nil, httpErr.InvalidTwoFactorCodeError
+	}
+
+	codes, hashed, err := a.newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.twoFactorRepo.ConfirmTwoFactor(ctx, twoFactor, step, hashed); err != nil {
+		if errors.Is(err, errTOTPCodeUsed) {
+			return nil, httpErr.TwoFactorAlreadyEnabledError
+		}
+		return nil, err
+	}
+	return codes, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the user
+func (a *AuthService) RegenerateRecoveryCodes(ctx context.Context, user *model.User, code string) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "auth.service.RegenerateRecoveryCodes")
+	defer span.End()
+
+	twoFactor, err := a.enabledTwoFactor(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, httpErr.TwoFactorNotEnabledError
+	}
+	if err := a.verifySecondFactor(ctx, user, twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashed, err := a.newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.twoFactorRepo.ReplaceRecoveryCodes(ctx, user.ID, hashed); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor disables two-factor authentication of the user, admins
+// must keep it
+func (a *AuthService) DisableTwoFactor(ctx context.Context, u *model.User, code string) error {
+	ctx, span := tracing.Start(ctx, "auth.service.DisableTwoFactor")
+	defer span.End()
+
+	user, err := a.userRepo.GetUser(ctx, u.ID.String())
+	if err != nil {
+		return err
+	}
+	if user.IsStaff() {
+		return httpErr.CannotDisableTwoFactorError
+	}
+
+	twoFactor, err := a.enabledTwoFactor(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if twoFactor == nil {
+		return httpErr.TwoFactorNotEnabledError
+	}
+	if err := a.verifySecondFactor(ctx, user, twoFactor, code); err != nil {
+		return err
+	}
 
-	claims := token.Claims.(jwt.MapClaims)
+	return a.twoFactorRepo.DeleteTwoFactor(ctx, user.ID)
+}
+
+// ForgotPassword mails a password reset link to the user. An unknown address
+// is not reported, so the response does not tell whether an account exists.
+func (a *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	ctx, span := tracing.Start(ctx, "auth.service.ForgotPassword")
+	defer span.End()
+
+	user, err := a.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	lifetime := a.tokenLifetime(model.UserTokenPurposePasswordReset)
+	token, err := a.newUserToken(ctx, user, model.UserTokenPurposePasswordReset, lifetime)
+	if err != nil {
+		return err
+	}
+
+	return a.notifier.Notify(&notifier.Notification{
+		Kind:      NotificationPasswordReset,
+		Recipient: *user.Email,
+		Subject:   "Reset your password",
+		Body: fmt.Sprintf("Use the link below to set a new password. The link expires in %s.\n\n%s",
+			lifetime, a.accountLink("/reset-password", token)),
+	})
+}
+
+// ResetPassword sets the password of the user of the token and closes every
+// session of the user
+func (a *AuthService) ResetPassword(ctx context.Context, token string, password string) error {
+	ctx, span := tracing.Start(ctx, "auth.service.ResetPassword")
+	defer span.End()
+
+	userToken, err := a.consumeUserToken(ctx, token, model.UserTokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	user, err := a.userRepo.GetUser(ctx, userToken.UserID.String())
+	if err != nil {
+		return err
+	}
+	if err := user.SetPassword(password); err != nil {
+		return err
+	}
+	if err := a.userRepo.UpdatePassword(ctx, user); err != nil {
+		return err
+	}
+
+	_, err = a.refreshTokenRepo.RevokeUserTokens(ctx, user.ID)
+	return err
+}
+
+// VerifyEmail marks the email address of the user of the token as verified
+func (a *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	ctx, span := tracing.Start(ctx, "auth.service.VerifyEmail")
+	defer span.End()
+
+	userToken, err := a.consumeUserToken(ctx, token, model.UserTokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	return a.userRepo.MarkEmailVerified(ctx, userToken.UserID)
+}
+
+// ResendVerification mails a new verification link, the earlier links stop working
+func (a *AuthService) ResendVerification(ctx context.Context, u *model.User) error {
+	ctx, span := tracing.Start(ctx, "auth.service.ResendVerification")
+	defer span.End()
+
+	user, err := a.userRepo.GetUser(ctx, u.ID.String())
+	if err != nil {
+		return err
+	}
+	if user.IsEmailVerified() {
+		return httpErr.EmailAlreadyVerifiedError
+	}
+
+	return a.sendVerification(ctx, user)
+}
 
-	if int64(claims["ExpiresAt"].(float64)) < time.Now().Unix() {
-		return api.TokenResponse{}, fmt.Errorf("refresh token expired")
+// sendVerification mails an email verification link to the user
+func (a *AuthService) sendVerification(ctx context.Context, user *model.User) error {
+	lifetime := a.tokenLifetime(model.UserTokenPurposeEmailVerification)
+	token, err := a.newUserToken(ctx, user, model.UserTokenPurposeEmailVerification, lifetime)
+	if err != nil {
+		return err
 	}
 
-	userId := claims["UserId"].(string)
+	return a.notifier.Notify(&notifier.Notification{
+		Kind:      NotificationEmailVerification,
+		Recipient: *user.Email,
+		Subject:   "Verify your email address",
+		Body: fmt.Sprintf("Use the link below to verify your email address. The link expires in %s.\n\n%s",
+			lifetime, a.accountLink("/verify-email", token)),
+	})
+}
+
+// newUserToken stores a new token of the user and returns its plain value
+func (a *AuthService) newUserToken(ctx context.Context, user *model.User, purpose model.UserTokenPurpose, lifetime time.Duration) (string, error) {
+	plain, err := common.GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	token := &model.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(plain),
+		ExpiresAt: time.Now().Add(lifetime),
+	}
+	if err := a.userTokenRepo.InsertUserToken(ctx, token); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
 
-	user, err := a.userRepo.GetUser(userId)
+// consumeUserToken marks the token as used and returns it. Unknown, expired
+// and already used tokens are reported the same way.
+func (a *AuthService) consumeUserToken(ctx context.Context, plain string, purpose model.UserTokenPurpose) (*model.UserToken, error) {
+	token, err := a.userTokenRepo.GetUserTokenByHash(ctx, hashToken(plain), purpose)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, httpErr.InvalidUserTokenError
+		}
+		return nil, err
+	}
+	if !token.IsUsable(time.Now()) {
+		return nil, httpErr.InvalidUserTokenError
+	}
+
+	if err := a.userTokenRepo.ConsumeUserToken(ctx, token); err != nil {
+		if errors.Is(err, errUserTokenUsed) {
+			return nil, httpErr.InvalidUserTokenError
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+// tokenLifetime returns how long a mailed token of the purpose is valid
+func (a *AuthService) tokenLifetime(purpose model.UserTokenPurpose) time.Duration {
+	if purpose == model.UserTokenPurposePasswordReset {
+		minutes := a.cfg.AccountConfig.PasswordResetTokenMinutes
+		if minutes <= 0 {
+			minutes = defaultPasswordResetTokenMinutes
+		}
+		return time.Duration(minutes) * time.Minute
+	}
+
+	hours := a.cfg.AccountConfig.EmailVerificationTokenHours
+	if hours <= 0 {
+		hours = defaultEmailVerificationTokenHours
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// accountLink returns the frontend link with the token
+func (a *AuthService) accountLink(path string, token string) string {
+	return a.cfg.AccountConfig.AppURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// enabledTwoFactor returns the confirmed TOTP secret of the user, or nil when
+// two-factor authentication is not enabled
+func (a *AuthService) enabledTwoFactor(ctx context.Context, userID uuid.UUID) (*model.TwoFactor, error) {
+	twoFactor, err := a.twoFactorRepo.GetTwoFactor(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !twoFactor.IsEnabled() {
+		return nil, nil
+	}
+	return twoFactor, nil
+}
+
+// verifySecondFactor accepts a TOTP code once, or uses up a recovery code
+func (a *AuthService) verifySecondFactor(ctx context.Context, user *model.User, twoFactor *model.TwoFactor, code string) error {
+	if step, ok := matchTOTP(twoFactor.Secret, code, time.Now(), twoFactor.LastUsedStep); ok {
+		if err := a.twoFactorRepo.UseTOTPStep(ctx, twoFactor, step); err != nil {
+			if errors.Is(err, errTOTPCodeUsed) {
+				return httpErr.InvalidTwoFactorCodeError
+			}
+			return err
+		}
+		return nil
+	}
+
+	if err := a.twoFactorRepo.UseRecoveryCode(ctx, user.ID, hashToken(normalizeRecoveryCode(code))); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return httpErr.InvalidTwoFactorCodeError
+		}
+		return err
+	}
+	zap.L().Info("recovery code used", tracing.Field(ctx), zap.Reflect("userID", user.ID))
+	return nil
+}
+
+// newRecoveryCodes returns new recovery codes and their stored form
+func (a *AuthService) newRecoveryCodes(userID uuid.UUID) ([]string, []model.RecoveryCode, error) {
+	codes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashed := make([]model.RecoveryCode, len(codes))
+	for i, code := range codes {
+		hashed[i] = model.RecoveryCode{UserID: userID, CodeHash: hashToken(code)}
+	}
+	return codes, hashed, nil
+}
+
+// challengeLifetime returns how long the second login step can be completed
+func (a *AuthService) challengeLifetime() time.Duration {
+	minutes := a.cfg.AccountConfig.TwoFactorChallengeMinutes
+	if minutes <= 0 {
+		minutes = defaultTwoFactorChallengeMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// resetFailedLogins resets the failed logins of the user after a successful login
+func (a *AuthService) resetFailedLogins(ctx context.Context, user *model.User) error {
+	if user.FailedLoginAttempts == 0 && user.LockedUntil == nil {
+		return nil
+	}
+	_, err := a.userRepo.UnlockUser(ctx, user.ID)
+	return err
+}
+
+// recordFailedLogin counts the failed login and locks the account after every
+// LockoutThreshold failures. Every lock is twice as long as the one before.
+func (a *AuthService) recordFailedLogin(ctx context.Context, user *model.User) {
+	attempts, err := a.userRepo.RecordFailedLogin(ctx, user.ID)
+	if err != nil {
+		zap.L().Error("auth.service.recordFailedLogin", tracing.Field(ctx), zap.Error(err))
+		return
+	}
+
+	threshold := a.cfg.AccountConfig.LockoutThreshold
+	if threshold <= 0 {
+		threshold = defaultLockoutThreshold
+	}
+	if attempts%threshold != 0 {
+		return
+	}
+
+	lockout := a.lockoutDuration(attempts / threshold)
+	zap.L().Warn("account locked after failed logins", tracing.Field(ctx),
+		zap.Reflect("userID", user.ID), zap.Int("attempts", attempts), zap.Duration("lockout", lockout))
+	if err := a.userRepo.LockUser(ctx, user.ID, time.Now().Add(lockout)); err != nil {
+		zap.L().Error("auth.service.recordFailedLogin", tracing.Field(ctx), zap.Error(err))
+	}
+}
+
+// lockoutDuration returns the lock time of the nth lock of an account
+func (a *AuthService) lockoutDuration(lock int) time.Duration {
+	minutes, maxMinutes := a.cfg.AccountConfig.LockoutMinutes, a.cfg.AccountConfig.MaxLockoutMinutes
+	if minutes <= 0 {
+		minutes = defaultLockoutMinutes
+	}
+	if maxMinutes <= 0 {
+		maxMinutes = defaultMaxLockoutMinutes
+	}
+
+	lockout, max := time.Duration(minutes)*time.Minute, time.Duration(maxMinutes)*time.Minute
+	for i := 1; i < lock && lockout < max; i++ {
+		lockout *= 2
+	}
+	if lockout > max {
+		lockout = max
+	}
+	return lockout
+}
+
+// completeFirstFactor opens a session for a user who passed the first login
+// step, or returns a challenge when two-factor authentication is enabled
+func (a *AuthService) completeFirstFactor(ctx context.Context, user *model.User, client *Client) (api.TokenResponse, error) {
+	if user.IsSuspended() {
+		return api.TokenResponse{}, httpErr.AccountSuspendedError
+	}
+
+	// the failed logins are reset only after the second factor, otherwise the
+	// codes could be guessed between two logins with the password
+	twoFactor, err := a.enabledTwoFactor(ctx, user.ID)
 	if err != nil {
 		return api.TokenResponse{}, err
 	}
+	if twoFactor != nil {
+		challenge, err := jwtHelper.GenerateChallengeToken(user, a.challengeLifetime(), a.cfg)
+		if err != nil {
+			return api.TokenResponse{}, err
+		}
+		return api.TokenResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
 
-	jwtClaimsForAccessToken := jwtHelper.NewJwtClaimsForAccessToken(user, a.cfg.JWTConfig.AccessTokenLifeTime)
+	if err := a.resetFailedLogins(ctx, user); err != nil {
+		return api.TokenResponse{}, err
+	}
+	return a.startSession(ctx, user, client)
+}
 
-	accesToken := jwtHelper.GenerateToken(jwtClaimsForAccessToken, a.cfg.JWTConfig.SecretKey)
+// startSession opens a new session and returns its first tokens
+func (a *AuthService) startSession(ctx context.Context, user *model.User, client *Client) (api.TokenResponse, error) {
+	token, plain, err := a.newRefreshToken(user, uuid.New(), time.Now(), client)
+	if err != nil {
+		return api.TokenResponse{}, err
+	}
+	if err := a.refreshTokenRepo.InsertToken(ctx, token); err != nil {
+		return api.TokenResponse{}, err
+	}
+
+	return a.tokenResponse(user, plain)
+}
+
+// tokenResponse returns a new access token with the refresh token
+func (a *AuthService) tokenResponse(user *model.User, refreshToken string) (api.TokenResponse, error) {
+	accessToken, err := jwtHelper.GenerateAccessToken(user, a.cfg)
+	if err != nil {
+		return api.TokenResponse{}, err
+	}
 
 	return api.TokenResponse{
-		AccessToken: accesToken,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
 	}, nil
 }
+
+// newRefreshToken returns a new token of the session and its plain value. The
+// token expires after its lifetime, or when the session reaches its maximum
+// lifetime if that comes first, so rotating does not keep a session forever.
+func (a *AuthService) newRefreshToken(user *model.User, familyID uuid.UUID, startedAt time.Time, client *Client) (*model.RefreshToken, string, error) {
+	plain, err := common.GenerateToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	expiresAt := time.Now().Add(time.Duration(a.cfg.JWTConfig.RefreshTokenLifeTime) * time.Hour)
+	if maxLifeTime := a.cfg.JWTConfig.MaxSessionLifeTime; maxLifeTime > 0 {
+		if sessionEnd := startedAt.Add(time.Duration(maxLifeTime) * time.Hour); sessionEnd.Before(expiresAt) {
+			expiresAt = sessionEnd
+		}
+	}
+
+	token := &model.RefreshToken{
+		UserID:           user.ID,
+		FamilyID:         familyID,
+		TokenHash:        hashToken(plain),
+		SessionStartedAt: startedAt,
+		ExpiresAt:        expiresAt,
+	}
+	token.TwoFactorVerified = user.TwoFactorVerified
+	if client != nil {
+		token.UserAgent = truncate(client.UserAgent, 255)
+		token.IP = truncate(client.IP, 45)
+	}
+	return token, plain, nil
+}
+
+// revokeReusedFamily revokes the session of a refresh token used twice
+func (a *AuthService) revokeReusedFamily(ctx context.Context, token *model.RefreshToken) {
+	zap.L().Warn("refresh token reused, revoking the session", tracing.Field(ctx),
+		zap.Reflect("userID", token.UserID), zap.Reflect("familyID", token.FamilyID))
+
+	if _, err := a.refreshTokenRepo.RevokeFamily(ctx, token.UserID, token.FamilyID); err != nil {
+		zap.L().Error("auth.service.revokeReusedFamily", tracing.Field(ctx), zap.Error(err))
+	}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncate(s string, length int) string {
+	if len(s) > length {
+		return s[:length]
+	}
+	return s
+}
@@ -0,0 +1,171 @@
+package cart
+
+import (
+	"context"
+	"fmt"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/notifier"
+	"patika-ecommerce/pkg/tracing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// NotificationAbandonedCart is sent to users who left items in their cart
+const NotificationAbandonedCart = "abandoned_cart"
+
+// lastActivity is the latest change of a cart or one of its items
+const lastActivity = `GREATEST(carts.updated_at, COALESCE((SELECT MAX(cart_items.updated_at) FROM cart_items WHERE cart_items.cart_id = carts.id AND cart_items.deleted_at IS NULL), carts.updated_at))`
+
+const hasItems = `EXISTS (SELECT 1 FROM cart_items WHERE cart_items.cart_id = carts.id AND cart_items.deleted_at IS NULL)`
+
+type AbandonedCartRepositoryInterface interface {
+	FlagAbandonedCarts(ctx context.Context, idleSince time.Time) (int64, error)
+	UnflagActiveCarts(ctx context.Context, idleSince time.Time) (int64, error)
+	GetCartsToRemind(ctx context.Context) ([]model.Cart, error)
+	MarkReminderSent(ctx context.Context, cart *model.Cart) error
+	ExpireCarts(ctx context.Context, idleSince time.Time) (int64, error)
+}
+
+// FlagAbandonedCarts flags the created, non-empty carts idle since the given time
+func (r *CartRepository) FlagAbandonedCarts(ctx context.Context, idleSince time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.FlagAbandonedCarts")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.FlagAbandonedCarts", tracing.Field(ctx), zap.Time("idleSince", idleSince))
+
+	result := r.db.WithContext(ctx).Model(&model.Cart{}).
+		Where("status = ? AND abandoned_at IS NULL", model.CartStatusCreated).
+		Where(hasItems).
+		Where(lastActivity+" < ?", idleSince).
+		UpdateColumn("abandoned_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// UnflagActiveCarts clears the abandoned flag of carts used again, so they can be reminded again later
+func (r *CartRepository) UnflagActiveCarts(ctx context.Context, idleSince time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.UnflagActiveCarts")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.UnflagActiveCarts", tracing.Field(ctx), zap.Time("idleSince", idleSince))
+
+	result := r.db.WithContext(ctx).Model(&model.Cart{}).
+		Where("status = ? AND abandoned_at IS NOT NULL", model.CartStatusCreated).
+		Where(lastActivity+" >= ?", idleSince).
+		UpdateColumns(map[string]interface{}{"abandoned_at": nil, "reminder_sent_at": nil})
+	return result.RowsAffected, result.Error
+}
+
+// GetCartsToRemind returns the abandoned carts whose active owners are not
+// reminded yet
+func (r *CartRepository) GetCartsToRemind(ctx context.Context) ([]model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.GetCartsToRemind")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.GetCartsToRemind", tracing.Field(ctx))
+
+	var carts []model.Cart
+	err := r.db.WithContext(ctx).Preload("User").Preload("Items.Product").
+		Where("status = ? AND abandoned_at IS NOT NULL AND reminder_sent_at IS NULL", model.CartStatusCreated).
+		Where("user_id IN (" + model.ActiveUserIDs + ")").
+		Find(&carts).Error
+	return carts, err
+}
+
+// MarkReminderSent records that the owner of the cart is reminded
+func (r *CartRepository) MarkReminderSent(ctx context.Context, cart *model.Cart) error {
+	ctx, span := tracing.Start(ctx, "cart.repo.MarkReminderSent")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.MarkReminderSent", tracing.Field(ctx), zap.Reflect("cartID", cart.ID))
+
+	return r.db.WithContext(ctx).Model(cart).UpdateColumn("reminder_sent_at", time.Now()).Error
+}
+
+// ExpireCarts expires the abandoned carts idle since the given time
+func (r *CartRepository) ExpireCarts(ctx context.Context, idleSince time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.ExpireCarts")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.ExpireCarts", tracing.Field(ctx), zap.Time("idleSince", idleSince))
+
+	result := r.db.WithContext(ctx).Model(&model.Cart{}).
+		Where("status = ? AND abandoned_at IS NOT NULL", model.CartStatusCreated).
+		Where(lastActivity+" < ?", idleSince).
+		UpdateColumn("status", model.CartStatusExpired)
+	return result.RowsAffected, result.Error
+}
+
+// AbandonedCartJob flags idle carts, reminds their owners once and expires
+// the carts left alone for too long
+type AbandonedCartJob struct {
+	repo      AbandonedCartRepositoryInterface
+	notifier  notifier.Notifier
+	idleAfter time.Duration
+	expireAt  time.Duration
+	now       func() time.Time
+}
+
+// NewAbandonedCartJob creates a new abandoned cart job
+func NewAbandonedCartJob(repo AbandonedCartRepositoryInterface, notifier notifier.Notifier, idleAfter, expireAfter time.Duration) *AbandonedCartJob {
+	return &AbandonedCartJob{repo: repo, notifier: notifier, idleAfter: idleAfter, expireAt: expireAfter, now: time.Now}
+}
+
+// Name returns the name of the job
+func (j *AbandonedCartJob) Name() string {
+	return "abandoned_carts"
+}
+
+// Run flags, reminds and expires the abandoned carts
+func (j *AbandonedCartJob) Run(ctx context.Context) (string, error) {
+	now := j.now()
+
+	expired, err := j.repo.ExpireCarts(ctx, now.Add(-j.expireAt))
+	if err != nil {
+		return "", err
+	}
+	if _, err := j.repo.UnflagActiveCarts(ctx, now.Add(-j.idleAfter)); err != nil {
+		return "", err
+	}
+	flagged, err := j.repo.FlagAbandonedCarts(ctx, now.Add(-j.idleAfter))
+	if err != nil {
+		return "", err
+	}
+
+	carts, err := j.repo.GetCartsToRemind(ctx)
+	if err != nil {
+		return "", err
+	}
+	reminded := 0
+	for i := range carts {
+		if ctx.Err() != nil {
+			break
+		}
+		cart := &carts[i]
+		if err := j.notifier.Notify(abandonedCartNotification(cart)); err != nil {
+			zap.L().Error("cart.abandoned.Run", tracing.Field(ctx), zap.Reflect("cartID", cart.ID), zap.Error(err))
+			continue
+		}
+		if err := j.repo.MarkReminderSent(ctx, cart); err != nil {
+			return "", err
+		}
+		reminded++
+	}
+
+	return fmt.Sprintf("flagged %d, reminded %d, expired %d", flagged, reminded, expired), nil
+}
+
+func abandonedCartNotification(cart *model.Cart) *notifier.Notification {
+	body := "You left these items in your cart:\n"
+	for _, item := range cart.Items {
+		body += fmt.Sprintf("- %s x%d\n", *item.Product.Name, item.Quantity)
+	}
+
+	return &notifier.Notification{
+		Kind:      NotificationAbandonedCart,
+		Recipient: *cart.User.Email,
+		Subject:   "Your cart is waiting for you",
+		Body:      body,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,105 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/notifier"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-playground/assert/v2"
+)
+
+type mockAbandonedCartRepo struct {
+	carts    []model.Cart
+	reminded int
+	expired  int64
+}
+
+func (r *mockAbandonedCartRepo) FlagAbandonedCarts(ctx context.Context, idleSince time.Time) (int64, error) {
+	return int64(len(r.carts)), nil
+}
+
+func (r *mockAbandonedCartRepo) UnflagActiveCarts(ctx context.Context, idleSince time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *mockAbandonedCartRepo) GetCartsToRemind(ctx context.Context) ([]model.Cart, error) {
+	return r.carts, nil
+}
+
+func (r *mockAbandonedCartRepo) MarkReminderSent(ctx context.Context, cart *model.Cart) error {
+	r.reminded++
+	return nil
+}
+
+func (r *mockAbandonedCartRepo) ExpireCarts(ctx context.Context, idleSince time.Time) (int64, error) {
+	return r.expired, nil
+}
+
+type mockNotifier struct {
+	notifications []*notifier.Notification
+	err           error
+}
+
+func (n *mockNotifier) Notify(notification *notifier.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func TestAbandonedCartJob_Run(t *testing.T) {
+	email := "shopper@example.com"
+	productName := "Keyboard"
+	carts := []model.Cart{{
+		User:  model.User{Email: &email},
+		Items: []model.CartItem{{Quantity: 2, Product: model.Product{Name: &productName}}},
+	}}
+
+	tests := []struct {
+		name         string
+		notifyErr    error
+		wantReminded int
+		wantMessage  string
+	}{
+		{name: "reminded", wantReminded: 1, wantMessage: "flagged 1, reminded 1, expired 3"},
+		{name: "notifierFails", notifyErr: errors.New("smtp down"), wantReminded: 0, wantMessage: "flagged 1, reminded 0, expired 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockAbandonedCartRepo{carts: carts, expired: 3}
+			n := &mockNotifier{err: tt.notifyErr}
+			job := NewAbandonedCartJob(repo, n, 24*time.Hour, 7*24*time.Hour)
+
+			message, err := job.Run(context.Background())
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tt.wantMessage, message)
+			assert.Equal(t, tt.wantReminded, repo.reminded)
+			if tt.wantReminded > 0 {
+				assert.Equal(t, NotificationAbandonedCart, n.notifications[0].Kind)
+				assert.Equal(t, email, n.notifications[0].Recipient)
+			}
+		})
+	}
+}
+
+func TestCartRepository_GetCartsToRemind(t *testing.T) {
+	db, mock := NewMock()
+	repo := NewCartRepository(db)
+
+	// the carts of the deleted and the suspended users are not reminded
+	query := `SELECT * FROM "carts" WHERE (status = $1 AND abandoned_at IS NOT NULL AND reminder_sent_at IS NULL) ` +
+		`AND (user_id IN (SELECT id FROM users WHERE deleted_at IS NULL AND suspended_at IS NULL))`
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(model.CartStatusCreated).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "user_id"}))
+
+	carts, err := repo.GetCartsToRemind(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(carts))
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+}
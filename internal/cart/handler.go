@@ -5,8 +5,6 @@ import (
 	"patika-ecommerce/internal/model"
 
 	httpErr "patika-ecommerce/internal/httpErrors"
-	"patika-ecommerce/pkg/config"
-	mw "patika-ecommerce/pkg/middleware"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-openapi/strfmt"
@@ -18,22 +16,24 @@ type cartHandler struct {
 }
 
 // NewCartHandler creates a new cart handler
-func NewCartHandler(r *gin.RouterGroup, cfg *config.Config, cartService CartServiceInterface) {
+func NewCartHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, cartService CartServiceInterface, idempotencyMiddleware gin.HandlerFunc) {
 	handler := &cartHandler{cartService: cartService}
 
-	r.Use(mw.AuthenticationMiddleware(cfg.JWTConfig.SecretKey))
+	r.Use(authMiddleware)
 	r.POST("", handler.getOrCreateCart)
-	r.POST("/add", handler.addToCart)
+	r.POST("/add", idempotencyMiddleware, handler.addToCart)
 	r.GET("/items", handler.listCartItems)
 	r.PUT("/items/:id", handler.updateCartItem)
 	r.DELETE("/items/:id", handler.deleteCartItem)
+	r.GET("/revalidate", handler.revalidateCart)
+	r.POST("/revalidate", handler.fixCart)
 }
 
 // getOrCreateCart if users cart exists, returns it, otherwise creates a new one
 func (r *cartHandler) getOrCreateCart(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 
-	cart, err := r.cartService.GetOrCreateCart(user)
+	cart, err := r.cartService.GetOrCreateCart(c.Request.Context(), user)
 	if err != nil {
 
 		c.JSON(httpErr.ErrorResponse(err))
@@ -59,7 +59,7 @@ func (r *cartHandler) addToCart(c *gin.Context) {
 
 	user := c.MustGet("user").(*model.User)
 
-	cart, err := r.cartService.AddToCart(user, reqBody)
+	cart, err := r.cartService.AddToCart(c.Request.Context(), user, reqBody)
 
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
@@ -73,7 +73,7 @@ func (r *cartHandler) addToCart(c *gin.Context) {
 func (r *cartHandler) listCartItems(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 
-	cart, err := r.cartService.GetOrCreateCart(user)
+	cart, err := r.cartService.GetOrCreateCart(c.Request.Context(), user)
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
@@ -99,11 +99,11 @@ func (r *cartHandler) updateCartItem(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(httpErr.ErrorResponse(err))
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
 		return
 	}
 
-	cartItem, err := r.cartService.UpdateCartItem(user, id, reqBody)
+	cartItem, err := r.cartService.UpdateCartItem(c.Request.Context(), user, id, reqBody)
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
@@ -117,14 +117,56 @@ func (r *cartHandler) deleteCartItem(c *gin.Context) {
 
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(httpErr.ErrorResponse(err))
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
 		return
 	}
 
-	if err = r.cartService.DeleteCartItem(user, id); err != nil {
+	if err = r.cartService.DeleteCartItem(c.Request.Context(), user, id); err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
 	}
 
 	c.JSON(204, nil)
 }
+
+// revalidateCart reports what changed on the cart items before checkout
+func (r *cartHandler) revalidateCart(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	cart, issues, err := r.cartService.RevalidateCart(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, CartToCartRevalidationResponse(cart, issues))
+}
+
+// fixCart applies the given fixes to the cart and revalidates it
+func (r *cartHandler) fixCart(c *gin.Context) {
+	reqBody := &api.CartFixRequest{}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	fixes := []model.CartFix{}
+	for _, fix := range reqBody.Fixes {
+		fixes = append(fixes, model.CartFix(fix))
+	}
+
+	user := c.MustGet("user").(*model.User)
+	cart, issues, err := r.cartService.FixCart(c.Request.Context(), user, fixes)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, CartToCartRevalidationResponse(cart, issues))
+}
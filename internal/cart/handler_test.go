@@ -2,7 +2,7 @@ package cart
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -15,6 +15,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 func getAddToCartPayload(productID string, quantity int) []byte {
@@ -398,6 +399,7 @@ func Test_cartHandler_deleteCartItem(t *testing.T) {
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
 		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("DELETE", "/cart/item", nil)
 		c.Set("user", &user)
 		c.Params = []gin.Param{{Key: "id", Value: cartItemId.String()}}
 		cartHandler.deleteCartItem(c)
@@ -408,6 +410,7 @@ func Test_cartHandler_deleteCartItem(t *testing.T) {
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
 		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("DELETE", "/cart/item", nil)
 		c.Set("user", &user)
 		c.Params = []gin.Param{{Key: "id", Value: uuid.New().String()}}
 		cartHandler.deleteCartItem(c)
@@ -418,6 +421,7 @@ func Test_cartHandler_deleteCartItem(t *testing.T) {
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
 		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("DELETE", "/cart/item", nil)
 		c.Set("user", &user)
 		c.Params = []gin.Param{{Key: "id", Value: "uuid.New().String()"}}
 		cartHandler.deleteCartItem(c)
@@ -427,9 +431,9 @@ func Test_cartHandler_deleteCartItem(t *testing.T) {
 }
 
 var (
-	UserNotFoundError    = fmt.Errorf("user not found")
-	ProductNotFoundError = fmt.Errorf("product not found")
-	NotEnoughStockError  = fmt.Errorf("not enough stock")
+	UserNotFoundError    = gorm.ErrRecordNotFound
+	ProductNotFoundError = httpErr.ProductNotFoundError
+	NotEnoughStockError  = httpErr.InsufficientStockError
 )
 
 type mockCartService struct {
@@ -439,7 +443,7 @@ type mockCartService struct {
 }
 
 // GetOrCreateCart returns a cart by user id
-func (r *mockCartService) GetOrCreateCart(user *model.User) (*model.Cart, error) {
+func (r *mockCartService) GetOrCreateCart(ctx context.Context, user *model.User) (*model.Cart, error) {
 	newUser := &model.User{}
 
 	for _, item := range r.users {
@@ -468,7 +472,7 @@ func (r *mockCartService) GetOrCreateCart(user *model.User) (*model.Cart, error)
 }
 
 // AddToCart adds a product to cart
-func (r *mockCartService) AddToCart(user *model.User, req *api.AddToCartRequest) (*model.Cart, error) {
+func (r *mockCartService) AddToCart(ctx context.Context, user *model.User, req *api.AddToCartRequest) (*model.Cart, error) {
 	cart := &model.Cart{}
 	product := &model.Product{}
 	reqProductId, _ := uuid.Parse(req.ProductID.String())
@@ -503,7 +507,7 @@ func (r *mockCartService) AddToCart(user *model.User, req *api.AddToCartRequest)
 }
 
 // UpdateCartItem updates a cart item
-func (r *mockCartService) UpdateCartItem(user *model.User, id uuid.UUID, req *api.CartItemUpdateRequest) (*model.CartItem, error) {
+func (r *mockCartService) UpdateCartItem(ctx context.Context, user *model.User, id uuid.UUID, req *api.CartItemUpdateRequest) (*model.CartItem, error) {
 
 	for _, item := range r.carts {
 		if item.UserID == user.ID && item.Status == model.CartStatusCreated {
@@ -511,7 +515,7 @@ func (r *mockCartService) UpdateCartItem(user *model.User, id uuid.UUID, req *ap
 			for index, cartItem := range item.Items {
 				if cartItem.ID == id {
 					if req.Quantity > *cartItem.Product.Stock {
-						return nil, fmt.Errorf("Product stock is not enough")
+						return nil, httpErr.InsufficientStockError
 					}
 					if req.Quantity == 0 {
 						item.Items = append(item.Items[:index], item.Items[index+1:]...)
@@ -528,7 +532,7 @@ func (r *mockCartService) UpdateCartItem(user *model.User, id uuid.UUID, req *ap
 }
 
 // DeleteCartItem deletes a cart item
-func (r *mockCartService) DeleteCartItem(user *model.User, id uuid.UUID) error {
+func (r *mockCartService) DeleteCartItem(ctx context.Context, user *model.User, id uuid.UUID) error {
 
 	for _, item := range r.carts {
 		if item.UserID == user.ID && item.Status == model.CartStatusCreated {
@@ -543,3 +547,94 @@ func (r *mockCartService) DeleteCartItem(user *model.User, id uuid.UUID) error {
 	return CartItemNotFoundError
 
 }
+
+// RevalidateCart reports what changed on the cart items
+func (r *mockCartService) RevalidateCart(ctx context.Context, user *model.User) (*model.Cart, []model.CartIssue, error) {
+	for _, item := range r.carts {
+		if item.UserID == user.ID && item.Status == model.CartStatusCreated {
+			return &item, item.Revalidate(), nil
+		}
+	}
+	return nil, nil, httpErr.CartNotFoundError
+}
+
+// FixCart fixes the cart, the mock accepts the new prices only
+func (r *mockCartService) FixCart(ctx context.Context, user *model.User, fixes []model.CartFix) (*model.Cart, []model.CartIssue, error) {
+	for _, item := range r.carts {
+		if item.UserID == user.ID && item.Status == model.CartStatusCreated {
+			for i := range item.Items {
+				item.Items[i].Price = item.Items[i].Product.Price
+			}
+			return &item, item.Revalidate(), nil
+		}
+	}
+	return nil, nil, httpErr.CartNotFoundError
+}
+
+func Test_cartHandler_revalidateCart(t *testing.T) {
+	user := model.User{Base: model.Base{ID: uuid.New()}}
+	stock := int64(1)
+	product := model.Product{Base: model.Base{ID: uuid.New()}, Price: 12, Stock: &stock}
+
+	tests := []struct {
+		name  string
+		carts []model.Cart
+		want  int
+	}{
+		{
+			name: "revalidateCart_Succeed",
+			carts: []model.Cart{{
+				UserID: user.ID,
+				Status: model.CartStatusCreated,
+				Items:  []model.CartItem{{ProductID: product.ID, Product: product, Quantity: 2, Price: 10}},
+			}},
+			want: http.StatusOK,
+		},
+		{name: "revalidateCart_Failed_cartNotFound", carts: []model.Cart{}, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartHandler := &cartHandler{cartService: &mockCartService{carts: tt.carts}}
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/cart/revalidate", nil)
+			c.Set("user", &user)
+			cartHandler.revalidateCart(c)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func Test_cartHandler_fixCart(t *testing.T) {
+	user := model.User{Base: model.Base{ID: uuid.New()}}
+	carts := []model.Cart{{UserID: user.ID, Status: model.CartStatusCreated}}
+
+	tests := []struct {
+		name    string
+		payload string
+		want    int
+	}{
+		{name: "fixCart_Succeed", payload: `{"fixes": ["accept_prices"]}`, want: http.StatusOK},
+		{name: "fixCart_Failed_unknownFix", payload: `{"fixes": ["free_items"]}`, want: http.StatusBadRequest},
+		{name: "fixCart_Failed_noFixes", payload: `{"fixes": []}`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartHandler := &cartHandler{cartService: &mockCartService{carts: carts}}
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/cart/revalidate", nil)
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(tt.payload)))
+			c.Set("user", &user)
+			cartHandler.fixCart(c)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
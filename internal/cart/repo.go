@@ -1,8 +1,10 @@
 package cart
 
 import (
-	"errors"
+	"context"
+	httpErr "patika-ecommerce/internal/httpErrors"
 	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/google/uuid"
@@ -11,20 +13,20 @@ import (
 )
 
 type CartRepositoryInterface interface {
-	GetOrCreateCart(user *model.User) (*model.Cart, error)
-	GetCreatedCart(user *model.User) (*model.Cart, error)
-	GetCreatedCartWithItemsAndProducts(user *model.User) (*model.Cart, error)
-	GetCreatedCartWithItems(user *model.User) (*model.Cart, error)
-	GetCreatedCartByUserAndCart(user *model.User, cartId strfmt.UUID) (*model.Cart, error)
-	GetCartByID(id uuid.UUID) (*model.Cart, error)
-	UpdateCart(cart *model.Cart) error
+	GetOrCreateCart(ctx context.Context, user *model.User) (*model.Cart, error)
+	GetCreatedCart(ctx context.Context, user *model.User) (*model.Cart, error)
+	GetCreatedCartWithItemsAndProducts(ctx context.Context, user *model.User) (*model.Cart, error)
+	GetCreatedCartWithItems(ctx context.Context, user *model.User) (*model.Cart, error)
+	GetCreatedCartByUserAndCart(ctx context.Context, user *model.User, cartId strfmt.UUID) (*model.Cart, error)
+	GetCartByID(ctx context.Context, id uuid.UUID) (*model.Cart, error)
+	UpdateCart(ctx context.Context, cart *model.Cart) error
 }
 
 type CartItemRepositoryInterface interface {
-	Create(cart *model.Cart, product *model.Product) error
-	UpdateCartItem(cartItem *model.CartItem) error
-	GetCartItemByCartAndIDWithProduct(cart *model.Cart, id uuid.UUID) (*model.CartItem, error)
-	DeleteCartItem(cartItem *model.CartItem) error
+	Create(ctx context.Context, cart *model.Cart, product *model.Product) error
+	UpdateCartItem(ctx context.Context, cartItem *model.CartItem) error
+	GetCartItemByCartAndIDWithProduct(ctx context.Context, cart *model.Cart, id uuid.UUID) (*model.CartItem, error)
+	DeleteCartItem(ctx context.Context, cartItem *model.CartItem) error
 }
 
 type CartRepository struct {
@@ -35,14 +37,6 @@ type CartItemRepository struct {
 	db *gorm.DB
 }
 
-func (r *CartRepository) Migration() {
-	r.db.AutoMigrate(&model.Cart{})
-}
-
-func (r *CartItemRepository) Migration() {
-	r.db.AutoMigrate(&model.CartItem{})
-}
-
 func NewCartRepository(db *gorm.DB) *CartRepository {
 	return &CartRepository{db: db}
 }
@@ -52,19 +46,22 @@ func NewCartItemRepository(db *gorm.DB) *CartItemRepository {
 }
 
 // GetOrCreateCart if cart is exists returns it otherwise create cart and return it
-func (r *CartRepository) GetOrCreateCart(user *model.User) (*model.Cart, error) {
-	zap.L().Debug("cart.repo.GetOrCreateCart", zap.Reflect("user", user))
+func (r *CartRepository) GetOrCreateCart(ctx context.Context, user *model.User) (*model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.GetOrCreateCart")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.GetOrCreateCart", tracing.Field(ctx), zap.Reflect("user", user))
 
 	cart := &model.Cart{}
-	result := r.db.Model(&user).Find(&user)
+	result := r.db.WithContext(ctx).Model(&user).Find(&user)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 
-	if err := r.db.Preload("Items.Product").Where("user_id = ? AND status = ?", user.ID, model.CartStatusCreated).First(cart).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Items.Product").Where("user_id = ? AND status = ?", user.ID, model.CartStatusCreated).First(cart).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			cart.UserID = user.ID
-			if err := r.db.Create(cart).Error; err != nil {
+			if err := r.db.WithContext(ctx).Create(cart).Error; err != nil {
 				return nil, err
 			}
 		} else {
@@ -75,13 +72,16 @@ func (r *CartRepository) GetOrCreateCart(user *model.User) (*model.Cart, error)
 }
 
 // GetCreatedCart returns a created cart by user id
-func (r *CartRepository) GetCreatedCart(user *model.User) (*model.Cart, error) {
-	zap.L().Debug("cart.repo.GetCreatedCart", zap.Reflect("user", user))
+func (r *CartRepository) GetCreatedCart(ctx context.Context, user *model.User) (*model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.GetCreatedCart")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.GetCreatedCart", tracing.Field(ctx), zap.Reflect("user", user))
 
 	cart := &model.Cart{}
-	if err := r.db.Preload("Items").Where("user_id = ? AND status = ?", user.ID, model.CartStatusCreated).First(cart).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Items").Where("user_id = ? AND status = ?", user.ID, model.CartStatusCreated).First(cart).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("Cart not found. Please create a cart")
+			return nil, httpErr.CartNotFoundError
 		}
 		return nil, err
 	}
@@ -89,13 +89,16 @@ func (r *CartRepository) GetCreatedCart(user *model.User) (*model.Cart, error) {
 }
 
 // GetCreatedCartWithItemsAndProducts returns a cart by user id
-func (r *CartRepository) GetCreatedCartWithItemsAndProducts(user *model.User) (*model.Cart, error) {
-	zap.L().Debug("cart.repo.GetCreatedCartWithItemsAndProducts", zap.Reflect("user", user))
+func (r *CartRepository) GetCreatedCartWithItemsAndProducts(ctx context.Context, user *model.User) (*model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.GetCreatedCartWithItemsAndProducts")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.GetCreatedCartWithItemsAndProducts", tracing.Field(ctx), zap.Reflect("user", user))
 
 	cart := &model.Cart{}
-	if err := r.db.Preload("Items.Product").Where("user_id = ? AND status = ?", user.ID, model.CartStatusCreated).First(cart).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Items.Product").Where("user_id = ? AND status = ?", user.ID, model.CartStatusCreated).First(cart).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("Cart not found. Please create a cart")
+			return nil, httpErr.CartNotFoundError
 		}
 		return nil, err
 	}
@@ -103,13 +106,16 @@ func (r *CartRepository) GetCreatedCartWithItemsAndProducts(user *model.User) (*
 }
 
 // GetCreatedCartWithItems returns a cart by user id
-func (r *CartRepository) GetCreatedCartWithItems(user *model.User) (*model.Cart, error) {
-	zap.L().Debug("cart.repo.GetCreatedCartWithItems", zap.Reflect("user", user))
+func (r *CartRepository) GetCreatedCartWithItems(ctx context.Context, user *model.User) (*model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.GetCreatedCartWithItems")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.GetCreatedCartWithItems", tracing.Field(ctx), zap.Reflect("user", user))
 
 	cart := &model.Cart{}
-	if err := r.db.Preload("Items").Where("user_id = ? AND status = ?", user.ID, model.CartStatusCreated).First(cart).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Items").Where("user_id = ? AND status = ?", user.ID, model.CartStatusCreated).First(cart).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, errors.New("Cart not found. Please create a cart")
+			return nil, httpErr.CartNotFoundError
 		}
 		return nil, err
 	}
@@ -117,11 +123,14 @@ func (r *CartRepository) GetCreatedCartWithItems(user *model.User) (*model.Cart,
 }
 
 // GetCreatedCartByUserAndCart returns a cart by user id
-func (r *CartRepository) GetCreatedCartByUserAndCart(user *model.User, cartId strfmt.UUID) (*model.Cart, error) {
-	zap.L().Debug("cart.repo.GetCreatedCartByUserAndCart", zap.Reflect("user", user), zap.Reflect("cartId", cartId))
+func (r *CartRepository) GetCreatedCartByUserAndCart(ctx context.Context, user *model.User, cartId strfmt.UUID) (*model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.GetCreatedCartByUserAndCart")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.GetCreatedCartByUserAndCart", tracing.Field(ctx), zap.Reflect("user", user), zap.Reflect("cartId", cartId))
 
 	cart := model.Cart{}
-	if err := r.db.Preload("Items.Product").
+	if err := r.db.WithContext(ctx).Preload("Items.Product").
 		Where("user_id = ? AND status = ? AND id = ?", user.ID, model.CartStatusCreated, cartId).
 		First(&cart).Error; err != nil {
 		return nil, err
@@ -130,11 +139,14 @@ func (r *CartRepository) GetCreatedCartByUserAndCart(user *model.User, cartId st
 }
 
 // GetCartByID returns a cart by id
-func (r *CartRepository) GetCartByID(id uuid.UUID) (*model.Cart, error) {
-	zap.L().Debug("cart.repo.GetCartByID", zap.Reflect("id", id))
+func (r *CartRepository) GetCartByID(ctx context.Context, id uuid.UUID) (*model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.GetCartByID")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.GetCartByID", tracing.Field(ctx), zap.Reflect("id", id))
 
 	cart := &model.Cart{}
-	if err := r.db.Preload("Items.Product").Where("id = ?", id).First(cart).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Items.Product").Where("id = ?", id).First(cart).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -143,17 +155,37 @@ func (r *CartRepository) GetCartByID(id uuid.UUID) (*model.Cart, error) {
 	return cart, nil
 }
 
+// GetCartsByUser returns every cart of the user with its items, newest first
+func (r *CartRepository) GetCartsByUser(ctx context.Context, user *model.User) ([]model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.GetCartsByUser")
+	defer span.End()
+
+	zap.L().Debug("cart.repo.GetCartsByUser", tracing.Field(ctx), zap.Reflect("userID", user.ID))
+
+	carts := []model.Cart{}
+	if err := r.db.WithContext(ctx).Preload("Items.Product").Where("user_id = ?", user.ID).Order("created_at DESC").Find(&carts).Error; err != nil {
+		return nil, err
+	}
+	return carts, nil
+}
+
 // UpdateCart updates a cart
-func (r *CartRepository) UpdateCart(cart *model.Cart) error {
-	zap.L().Debug("cart.repo.UpdateCart", zap.Reflect("cart", cart))
+func (r *CartRepository) UpdateCart(ctx context.Context, cart *model.Cart) error {
+	ctx, span := tracing.Start(ctx, "cart.repo.UpdateCart")
+	defer span.End()
 
-	return r.db.Model(&cart).Updates(cart).Error
+	zap.L().Debug("cart.repo.UpdateCart", tracing.Field(ctx), zap.Reflect("cart", cart))
+
+	return r.db.WithContext(ctx).Model(&cart).Updates(cart).Error
 }
 
 // ###### CART ITEM REPOSITORY ######
 
-func (r *CartItemRepository) Create(cart *model.Cart, product *model.Product) error {
-	zap.L().Debug("cartItem.repo.Create", zap.Reflect("cart", cart), zap.Reflect("product", product))
+func (r *CartItemRepository) Create(ctx context.Context, cart *model.Cart, product *model.Product) error {
+	ctx, span := tracing.Start(ctx, "cart.repo.Create")
+	defer span.End()
+
+	zap.L().Debug("cartItem.repo.Create", tracing.Field(ctx), zap.Reflect("cart", cart), zap.Reflect("product", product))
 
 	cartItem := &model.CartItem{
 		CartID:    cart.ID,
@@ -163,22 +195,28 @@ func (r *CartItemRepository) Create(cart *model.Cart, product *model.Product) er
 	}
 	cart.Items = append(cart.Items, *cartItem)
 
-	return r.db.Create(cartItem).Error
+	return r.db.WithContext(ctx).Create(cartItem).Error
 }
 
 // UpdateCartItem updates a cart item
-func (r *CartItemRepository) UpdateCartItem(cartItem *model.CartItem) error {
-	zap.L().Debug("cartItem.repo.UpdateCartItem", zap.Reflect("cartItem", cartItem))
+func (r *CartItemRepository) UpdateCartItem(ctx context.Context, cartItem *model.CartItem) error {
+	ctx, span := tracing.Start(ctx, "cart.repo.UpdateCartItem")
+	defer span.End()
+
+	zap.L().Debug("cartItem.repo.UpdateCartItem", tracing.Field(ctx), zap.Reflect("cartItem", cartItem))
 
-	return r.db.Model(&cartItem).Updates(cartItem).Error
+	return r.db.WithContext(ctx).Model(&cartItem).Updates(cartItem).Error
 }
 
 // GetCartItemByID returns a cart item by id
-func (r *CartItemRepository) GetCartItemByCartAndIDWithProduct(cart *model.Cart, id uuid.UUID) (*model.CartItem, error) {
-	zap.L().Debug("cartItem.repo.GetCartItemByCartAndIDWithProduct", zap.Reflect("cart", cart), zap.Reflect("id", id))
+func (r *CartItemRepository) GetCartItemByCartAndIDWithProduct(ctx context.Context, cart *model.Cart, id uuid.UUID) (*model.CartItem, error) {
+	ctx, span := tracing.Start(ctx, "cart.repo.GetCartItemByCartAndIDWithProduct")
+	defer span.End()
+
+	zap.L().Debug("cartItem.repo.GetCartItemByCartAndIDWithProduct", tracing.Field(ctx), zap.Reflect("cart", cart), zap.Reflect("id", id))
 
 	cartItem := &model.CartItem{}
-	if err := r.db.Model(&cartItem).Preload("Product").Where("cart_id = ? AND id = ?", cart.ID, id).First(cartItem).Error; err != nil {
+	if err := r.db.WithContext(ctx).Model(&cartItem).Preload("Product").Where("cart_id = ? AND id = ?", cart.ID, id).First(cartItem).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
 		}
@@ -189,8 +227,11 @@ func (r *CartItemRepository) GetCartItemByCartAndIDWithProduct(cart *model.Cart,
 }
 
 // DeleteCartItem deletes a cart item
-func (r *CartItemRepository) DeleteCartItem(cartItem *model.CartItem) error {
-	zap.L().Debug("cartItem.repo.DeleteCartItem", zap.Reflect("cartItem", cartItem))
+func (r *CartItemRepository) DeleteCartItem(ctx context.Context, cartItem *model.CartItem) error {
+	ctx, span := tracing.Start(ctx, "cart.repo.DeleteCartItem")
+	defer span.End()
+
+	zap.L().Debug("cartItem.repo.DeleteCartItem", tracing.Field(ctx), zap.Reflect("cartItem", cartItem))
 
-	return r.db.Delete(cartItem).Error
+	return r.db.WithContext(ctx).Delete(cartItem).Error
 }
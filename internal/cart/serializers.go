@@ -61,3 +61,31 @@ func CartItemsToCartItemResponse(items []model.CartItem) []*api.CartItemDetailRe
 
 	return cartItemResponse
 }
+
+// CartIssuesToCartIssueResponse converts cart issues to cart issue responses
+func CartIssuesToCartIssueResponse(issues []model.CartIssue) []*api.CartIssueResponse {
+	response := []*api.CartIssueResponse{}
+	for _, issue := range issues {
+		response = append(response, &api.CartIssueResponse{
+			Type:              string(issue.Type),
+			CartItemID:        common.UUIDToStrfmt(issue.CartItemID),
+			ProductID:         common.UUIDToStrfmt(issue.ProductID),
+			Quantity:          issue.Quantity,
+			AvailableQuantity: issue.AvailableQuantity,
+			OldPrice:          issue.OldPrice,
+			NewPrice:          issue.NewPrice,
+			Fix:               string(issue.Fix()),
+		})
+	}
+	return response
+}
+
+// CartToCartRevalidationResponse converts a revalidated cart to a cart revalidation response
+func CartToCartRevalidationResponse(cart *model.Cart, issues []model.CartIssue) *api.CartRevalidationResponse {
+	return &api.CartRevalidationResponse{
+		CartID:     common.UUIDToStrfmt(cart.ID),
+		Valid:      len(issues) == 0,
+		TotalPrice: cart.GetTotalPrice(),
+		Issues:     CartIssuesToCartIssueResponse(issues),
+	}
+}
@@ -1,21 +1,25 @@
 package cart
 
 import (
-	"fmt"
+	"context"
 	"patika-ecommerce/internal/api"
 	httpErr "patika-ecommerce/internal/httpErrors"
 	"patika-ecommerce/internal/model"
 	product "patika-ecommerce/internal/product"
+	"patika-ecommerce/pkg/metrics"
+	"patika-ecommerce/pkg/tracing"
 	common "patika-ecommerce/pkg/utils"
 
 	"github.com/google/uuid"
 )
 
 type CartServiceInterface interface {
-	GetOrCreateCart(user *model.User) (*model.Cart, error)
-	AddToCart(user *model.User, req *api.AddToCartRequest) (*model.Cart, error)
-	UpdateCartItem(user *model.User, id uuid.UUID, req *api.CartItemUpdateRequest) (*model.CartItem, error)
-	DeleteCartItem(user *model.User, id uuid.UUID) error
+	GetOrCreateCart(ctx context.Context, user *model.User) (*model.Cart, error)
+	AddToCart(ctx context.Context, user *model.User, req *api.AddToCartRequest) (*model.Cart, error)
+	UpdateCartItem(ctx context.Context, user *model.User, id uuid.UUID, req *api.CartItemUpdateRequest) (*model.CartItem, error)
+	DeleteCartItem(ctx context.Context, user *model.User, id uuid.UUID) error
+	RevalidateCart(ctx context.Context, user *model.User) (*model.Cart, []model.CartIssue, error)
+	FixCart(ctx context.Context, user *model.User, fixes []model.CartFix) (*model.Cart, []model.CartIssue, error)
 }
 
 type CartService struct {
@@ -34,13 +38,19 @@ func NewCartService(cartRepo *CartRepository, productRepo *product.ProductReposi
 }
 
 // GetOrCreateCart returns a cart by user id
-func (r *CartService) GetOrCreateCart(user *model.User) (*model.Cart, error) {
-	return r.cartRepo.GetOrCreateCart(user)
+func (r *CartService) GetOrCreateCart(ctx context.Context, user *model.User) (*model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.service.GetOrCreateCart")
+	defer span.End()
+
+	return r.cartRepo.GetOrCreateCart(ctx, user)
 }
 
 // AddToCart adds a product to cart
-func (r *CartService) AddToCart(user *model.User, req *api.AddToCartRequest) (*model.Cart, error) {
-	cart, err := r.cartRepo.GetCreatedCart(user)
+func (r *CartService) AddToCart(ctx context.Context, user *model.User, req *api.AddToCartRequest) (*model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "cart.service.AddToCart")
+	defer span.End()
+
+	cart, err := r.cartRepo.GetCreatedCart(ctx, user)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +61,7 @@ func (r *CartService) AddToCart(user *model.User, req *api.AddToCartRequest) (*m
 	}
 
 	// find product by given id
-	product, err := r.productRepo.GetProductWithoutCategories(pId)
+	product, err := r.productRepo.GetProductWithoutCategories(ctx, pId)
 	if err != nil {
 		return nil, httpErr.GivenAssociationNotFound
 	}
@@ -62,12 +72,15 @@ func (r *CartService) AddToCart(user *model.User, req *api.AddToCartRequest) (*m
 		// if product is already in cart then update quantity
 		if item.ProductID == pId {
 			if int64(item.Quantity)+req.Quantity > *product.Stock {
-				return nil, fmt.Errorf("Product stock is not enough")
+				return nil, insufficientStock(pId, *product.Stock)
 			}
 			item.Quantity += req.Quantity
 
-			r.cartItemRepo.UpdateCartItem(&item)
+			if err := r.cartItemRepo.UpdateCartItem(ctx, &item); err != nil {
+				return nil, err
+			}
 			is_exists = true
+			metrics.CartItemAdded(req.Quantity)
 
 			cart.Items[index] = item
 			return cart, nil
@@ -77,19 +90,23 @@ func (r *CartService) AddToCart(user *model.User, req *api.AddToCartRequest) (*m
 	// if product not exists in cart, create new cart item
 	if !is_exists {
 		if *product.Stock < req.Quantity {
-			return nil, fmt.Errorf("Product stock is not enough")
+			return nil, insufficientStock(pId, *product.Stock)
 		}
-		if err := r.cartItemRepo.Create(cart, product); err != nil {
+		if err := r.cartItemRepo.Create(ctx, cart, product); err != nil {
 			return nil, err
 		}
+		// the new item is created with its own quantity, which is counted
+		metrics.CartItemAdded(cart.Items[len(cart.Items)-1].Quantity)
 	}
-	cart.Items = append(cart.Items)
 	return cart, nil
 }
 
 // UpdateCartItem updates a cart item
-func (r *CartService) UpdateCartItem(user *model.User, id uuid.UUID, req *api.CartItemUpdateRequest) (*model.CartItem, error) {
-	cart, err := r.cartRepo.GetCreatedCartWithItemsAndProducts(user)
+func (r *CartService) UpdateCartItem(ctx context.Context, user *model.User, id uuid.UUID, req *api.CartItemUpdateRequest) (*model.CartItem, error) {
+	ctx, span := tracing.Start(ctx, "cart.service.UpdateCartItem")
+	defer span.End()
+
+	cart, err := r.cartRepo.GetCreatedCartWithItemsAndProducts(ctx, user)
 	if err != nil {
 		return nil, err
 	}
@@ -100,17 +117,17 @@ func (r *CartService) UpdateCartItem(user *model.User, id uuid.UUID, req *api.Ca
 	}
 
 	if req.Quantity == 0 {
-		r.cartItemRepo.DeleteCartItem(cartItem)
+		r.cartItemRepo.DeleteCartItem(ctx, cartItem)
 		cartItem.Quantity = 0
 		return cartItem, nil
 	}
 
 	if req.Quantity > *cartItem.Product.Stock {
-		return nil, fmt.Errorf("product stock is not enough")
+		return nil, insufficientStock(cartItem.ProductID, *cartItem.Product.Stock)
 	}
 
 	cartItem.Quantity = req.Quantity
-	if err := r.cartItemRepo.UpdateCartItem(cartItem); err != nil {
+	if err := r.cartItemRepo.UpdateCartItem(ctx, cartItem); err != nil {
 		return nil, err
 	}
 
@@ -118,8 +135,11 @@ func (r *CartService) UpdateCartItem(user *model.User, id uuid.UUID, req *api.Ca
 }
 
 // DeleteCartItem deletes a cart item
-func (r *CartService) DeleteCartItem(user *model.User, id uuid.UUID) error {
-	cart, err := r.cartRepo.GetCreatedCartWithItems(user)
+func (r *CartService) DeleteCartItem(ctx context.Context, user *model.User, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "cart.service.DeleteCartItem")
+	defer span.End()
+
+	cart, err := r.cartRepo.GetCreatedCartWithItems(ctx, user)
 	if err != nil {
 		return err
 	}
@@ -129,9 +149,96 @@ func (r *CartService) DeleteCartItem(user *model.User, id uuid.UUID) error {
 		return err
 	}
 
-	if err := r.cartItemRepo.DeleteCartItem(cartItem); err != nil {
+	if err := r.cartItemRepo.DeleteCartItem(ctx, cartItem); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// RevalidateCart reports what changed on the cart items since they were added
+func (r *CartService) RevalidateCart(ctx context.Context, user *model.User) (*model.Cart, []model.CartIssue, error) {
+	ctx, span := tracing.Start(ctx, "cart.service.RevalidateCart")
+	defer span.End()
+
+	cart, err := r.cartRepo.GetCreatedCartWithItemsAndProducts(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cart, cart.Revalidate(), nil
+}
+
+// FixCart resolves the issues of the cart with the given fixes and revalidates it
+func (r *CartService) FixCart(ctx context.Context, user *model.User, fixes []model.CartFix) (*model.Cart, []model.CartIssue, error) {
+	ctx, span := tracing.Start(ctx, "cart.service.FixCart")
+	defer span.End()
+
+	cart, err := r.cartRepo.GetCreatedCartWithItemsAndProducts(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	enabled := map[model.CartFix]bool{}
+	for _, fix := range fixes {
+		enabled[fix] = true
+	}
+
+	updated := map[uuid.UUID]*model.CartItem{}
+	removed := map[uuid.UUID]bool{}
+	for _, issue := range cart.Revalidate() {
+		if !enabled[issue.Fix()] {
+			continue
+		}
+
+		if issue.Fix() == model.CartFixRemoveUnavailable {
+			removed[issue.CartItemID] = true
+			continue
+		}
+
+		item, ok := updated[issue.CartItemID]
+		if !ok {
+			cartItem, err := cart.GetCartItemByID(issue.CartItemID)
+			if err != nil {
+				return nil, nil, err
+			}
+			// the product is left out so that it is not saved with the item
+			item = &model.CartItem{
+				Base:      cartItem.Base,
+				CartID:    cartItem.CartID,
+				ProductID: cartItem.ProductID,
+				Quantity:  cartItem.Quantity,
+				Price:     cartItem.Price,
+			}
+			updated[issue.CartItemID] = item
+		}
+
+		switch issue.Type {
+		case model.CartIssuePriceChanged:
+			item.Price = issue.NewPrice
+		case model.CartIssueReducedQuantity:
+			item.Quantity = issue.AvailableQuantity
+		}
+	}
+
+	for id := range removed {
+		if err := r.cartItemRepo.DeleteCartItem(ctx, &model.CartItem{Base: model.Base{ID: id}}); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, item := range updated {
+		if err := r.cartItemRepo.UpdateCartItem(ctx, item); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return r.RevalidateCart(ctx, user)
+}
+
+// insufficientStock returns the error of a quantity above the stock of a product
+func insufficientStock(productID uuid.UUID, available int64) error {
+	return httpErr.InsufficientStockError.WithDetails(map[string]interface{}{
+		"productId": productID,
+		"available": available,
+	})
+}
@@ -1,11 +1,13 @@
 package cart
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"patika-ecommerce/internal/api"
+	httpErr "patika-ecommerce/internal/httpErrors"
 	"patika-ecommerce/internal/model"
 	product "patika-ecommerce/internal/product"
+	"patika-ecommerce/pkg/metrics"
 	paginationHelper "patika-ecommerce/pkg/pagination"
 	"reflect"
 	"testing"
@@ -130,7 +132,7 @@ func TestCartService_GetOrCreateCart(t *testing.T) {
 				cartItemRepo: tt.fields.cartItemRepo,
 				// productRepo:  tt.fields.productRepo,
 			}
-			got, err := r.GetOrCreateCart(tt.args.user)
+			got, err := r.GetOrCreateCart(context.Background(), tt.args.user)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CartService.GetOrCreateCart() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -147,6 +149,11 @@ func TestCartService_GetOrCreateCart(t *testing.T) {
 
 func TestCartService_AddToCart(t *testing.T) {
 	userId := uuid.New()
+	existingItem := model.CartItem{
+		Base:      model.Base{ID: uuid.New()},
+		ProductID: productOneID,
+		Quantity:  1,
+	}
 	type fields struct {
 		cartRepo     CartRepositoryInterface
 		cartItemRepo CartItemRepositoryInterface
@@ -247,17 +254,11 @@ func TestCartService_AddToCart(t *testing.T) {
 							Base:   model.Base{ID: uuid.New()},
 							UserID: userId,
 							Status: model.CartStatusCreated,
-							Items: []model.CartItem{
-								{
-									Base:      model.Base{ID: uuid.New()},
-									ProductID: productOneID,
-									Quantity:  1,
-								},
-							},
+							Items:  []model.CartItem{existingItem},
 						},
 					},
 				},
-				cartItemRepo: &mockCartItemRepo{items: []model.CartItem{}},
+				cartItemRepo: &mockCartItemRepo{items: []model.CartItem{existingItem}},
 				productRepo:  &mockProductRepo{items: products},
 			},
 			args: args{
@@ -342,7 +343,7 @@ func TestCartService_AddToCart(t *testing.T) {
 				cartItemRepo: tt.fields.cartItemRepo,
 				productRepo:  tt.fields.productRepo,
 			}
-			_, err := r.AddToCart(tt.args.user, tt.args.req)
+			_, err := r.AddToCart(context.Background(), tt.args.user, tt.args.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CartService.AddToCart() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -351,6 +352,36 @@ func TestCartService_AddToCart(t *testing.T) {
 	}
 }
 
+// addedQuantity returns the quantity counted by the cart metrics so far
+func addedQuantity(t *testing.T) float64 {
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() == "patika_ecommerce_cart_added_quantity_total" {
+			return family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	return 0
+}
+
+func TestCartService_AddToCart_countsPersistedQuantity(t *testing.T) {
+	userId := uuid.New()
+	cartRepo := &mockCartRepo{
+		items: []model.Cart{{Base: model.Base{ID: uuid.New()}, UserID: userId, Status: model.CartStatusCreated, Items: []model.CartItem{}}},
+	}
+	r := &CartService{cartRepo: cartRepo, cartItemRepo: &mockCartItemRepo{items: []model.CartItem{}}, productRepo: &mockProductRepo{items: products}}
+	before := addedQuantity(t)
+
+	// a new item is created with a quantity of one whatever is requested
+	cart, err := r.AddToCart(context.Background(), &model.User{Base: model.Base{ID: userId}},
+		&api.AddToCartRequest{ProductID: strfmt.UUID(productOneID.String()), Quantity: 3})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(1), cart.Items[0].Quantity)
+	assert.Equal(t, before+1, addedQuantity(t))
+}
+
 func TestCartService_UpdateCartItem(t *testing.T) {
 	cartID, cartItemID, userID, productID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
 
@@ -497,7 +528,7 @@ func TestCartService_UpdateCartItem(t *testing.T) {
 				cartItemRepo: tt.fields.cartItemRepo,
 				productRepo:  tt.fields.productRepo,
 			}
-			_, err := r.UpdateCartItem(tt.args.user, tt.args.id, tt.args.req)
+			_, err := r.UpdateCartItem(context.Background(), tt.args.user, tt.args.id, tt.args.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CartService.UpdateCartItem() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -700,13 +731,63 @@ func TestCartService_DeleteCartItem(t *testing.T) {
 				cartItemRepo: tt.fields.cartItemRepo,
 				productRepo:  tt.fields.productRepo,
 			}
-			if err := r.DeleteCartItem(tt.args.user, tt.args.id); (err != nil) != tt.wantErr {
+			if err := r.DeleteCartItem(context.Background(), tt.args.user, tt.args.id); (err != nil) != tt.wantErr {
 				t.Errorf("CartService.DeleteCartItem() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestCartService_FixCart(t *testing.T) {
+	user := model.User{Base: model.Base{ID: uuid.New()}}
+	lowStock, noStock := int64(1), int64(0)
+	cheaper := model.Product{Base: model.Base{ID: uuid.New()}, Price: 8, Stock: &productOneStock}
+	low := model.Product{Base: model.Base{ID: uuid.New()}, Price: 10, Stock: &lowStock}
+	gone := model.Product{Base: model.Base{ID: uuid.New()}, Price: 10, Stock: &noStock}
+
+	items := []model.CartItem{
+		{Base: model.Base{ID: uuid.New()}, ProductID: cheaper.ID, Product: cheaper, Quantity: 1, Price: 10},
+		{Base: model.Base{ID: uuid.New()}, ProductID: low.ID, Product: low, Quantity: 3, Price: 10},
+		{Base: model.Base{ID: uuid.New()}, ProductID: gone.ID, Product: gone, Quantity: 1, Price: 10},
+	}
+
+	tests := []struct {
+		name       string
+		fixes      []model.CartFix
+		wantPrices []float64
+		wantQty    []int64
+		wantItems  int
+	}{
+		{name: "acceptPrices", fixes: []model.CartFix{model.CartFixAcceptPrices}, wantPrices: []float64{8, 10, 10}, wantQty: []int64{1, 3, 1}, wantItems: 3},
+		{name: "adjustQuantities", fixes: []model.CartFix{model.CartFixAdjustQuantities}, wantPrices: []float64{10, 10, 10}, wantQty: []int64{1, 1, 1}, wantItems: 3},
+		{name: "removeUnavailable", fixes: []model.CartFix{model.CartFixRemoveUnavailable}, wantPrices: []float64{10, 10}, wantQty: []int64{1, 3}, wantItems: 2},
+		{
+			name:       "allFixes",
+			fixes:      []model.CartFix{model.CartFixAcceptPrices, model.CartFixAdjustQuantities, model.CartFixRemoveUnavailable},
+			wantPrices: []float64{8, 10},
+			wantQty:    []int64{1, 1},
+			wantItems:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cartRepo := &mockCartRepo{
+				items: []model.Cart{{Base: model.Base{ID: uuid.New()}, UserID: user.ID, Status: model.CartStatusCreated, Items: items}},
+			}
+			cartItemRepo := &mockCartItemRepo{items: append([]model.CartItem{}, items...)}
+			s := &CartService{cartRepo: cartRepo, cartItemRepo: cartItemRepo, productRepo: &mockProductRepo{}}
+
+			_, _, err := s.FixCart(context.Background(), &user, tt.fixes)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tt.wantItems, len(cartItemRepo.items))
+			for i, item := range cartItemRepo.items {
+				assert.Equal(t, tt.wantPrices[i], item.Price)
+				assert.Equal(t, tt.wantQty[i], item.Quantity)
+			}
+		})
+	}
+}
+
 type mockCartRepo struct {
 	items []model.Cart
 	users []model.User
@@ -722,12 +803,12 @@ type mockProductRepo struct {
 }
 
 var (
-	CartNotFoundError     = fmt.Errorf("cart not found")
-	CartItemNotFoundError = fmt.Errorf("cart item not found")
+	CartNotFoundError     = httpErr.CartNotFoundError
+	CartItemNotFoundError = httpErr.CartItemNotFoundError
 )
 
 // GetOrCreateCart if cart is exists returns it otherwise create cart and return it
-func (r *mockCartRepo) GetOrCreateCart(user *model.User) (*model.Cart, error) {
+func (r *mockCartRepo) GetOrCreateCart(ctx context.Context, user *model.User) (*model.Cart, error) {
 	cart := &model.Cart{}
 
 	for _, item := range r.users {
@@ -748,7 +829,7 @@ func (r *mockCartRepo) GetOrCreateCart(user *model.User) (*model.Cart, error) {
 }
 
 // GetCreatedCart returns a created cart by user id
-func (r *mockCartRepo) GetCreatedCart(user *model.User) (*model.Cart, error) {
+func (r *mockCartRepo) GetCreatedCart(ctx context.Context, user *model.User) (*model.Cart, error) {
 	for _, item := range r.items {
 		if user.ID == item.UserID && item.Status == model.CartStatusCreated {
 			return &item, nil
@@ -758,7 +839,7 @@ func (r *mockCartRepo) GetCreatedCart(user *model.User) (*model.Cart, error) {
 }
 
 // GetCreatedCartWithItemsAndProducts returns a cart by user id
-func (r *mockCartRepo) GetCreatedCartWithItemsAndProducts(user *model.User) (*model.Cart, error) {
+func (r *mockCartRepo) GetCreatedCartWithItemsAndProducts(ctx context.Context, user *model.User) (*model.Cart, error) {
 	for _, item := range r.items {
 		if user.ID == item.UserID && item.Status == model.CartStatusCreated {
 			return &item, nil
@@ -768,7 +849,7 @@ func (r *mockCartRepo) GetCreatedCartWithItemsAndProducts(user *model.User) (*mo
 }
 
 // GetCreatedCartWithItems returns a cart by user id
-func (r *mockCartRepo) GetCreatedCartWithItems(user *model.User) (*model.Cart, error) {
+func (r *mockCartRepo) GetCreatedCartWithItems(ctx context.Context, user *model.User) (*model.Cart, error) {
 	for _, item := range r.items {
 		if user.ID == item.UserID && item.Status == model.CartStatusCreated {
 			return &item, nil
@@ -778,13 +859,13 @@ func (r *mockCartRepo) GetCreatedCartWithItems(user *model.User) (*model.Cart, e
 }
 
 // GetCreatedCartByUserAndCart returns a cart by user id
-func (r *mockCartRepo) GetCreatedCartByUserAndCart(user *model.User, cartId strfmt.UUID) (*model.Cart, error) {
+func (r *mockCartRepo) GetCreatedCartByUserAndCart(ctx context.Context, user *model.User, cartId strfmt.UUID) (*model.Cart, error) {
 	cart := model.Cart{}
 	return &cart, nil
 }
 
 // GetCartByID returns a cart by id
-func (r *mockCartRepo) GetCartByID(id uuid.UUID) (*model.Cart, error) {
+func (r *mockCartRepo) GetCartByID(ctx context.Context, id uuid.UUID) (*model.Cart, error) {
 	for _, item := range r.items {
 		if item.ID == id {
 			return &item, nil
@@ -795,13 +876,13 @@ func (r *mockCartRepo) GetCartByID(id uuid.UUID) (*model.Cart, error) {
 }
 
 // UpdateCart updates a cart
-func (r *mockCartRepo) UpdateCart(cart *model.Cart) error {
+func (r *mockCartRepo) UpdateCart(ctx context.Context, cart *model.Cart) error {
 	return nil
 }
 
 // ###### CART ITEM ######
 
-func (r *mockCartItemRepo) Create(cart *model.Cart, product *model.Product) error {
+func (r *mockCartItemRepo) Create(ctx context.Context, cart *model.Cart, product *model.Product) error {
 	cartItem := model.CartItem{
 		CartID:    cart.ID,
 		ProductID: product.ID,
@@ -815,7 +896,7 @@ func (r *mockCartItemRepo) Create(cart *model.Cart, product *model.Product) erro
 }
 
 // UpdateCartItem updates a cart item
-func (r *mockCartItemRepo) UpdateCartItem(cartItem *model.CartItem) error {
+func (r *mockCartItemRepo) UpdateCartItem(ctx context.Context, cartItem *model.CartItem) error {
 	for i, item := range r.items {
 		if item.ID == cartItem.ID {
 			r.items[i] = *cartItem
@@ -827,13 +908,13 @@ func (r *mockCartItemRepo) UpdateCartItem(cartItem *model.CartItem) error {
 }
 
 // GetCartItemByID returns a cart item by id
-func (r *mockCartItemRepo) GetCartItemByCartAndIDWithProduct(cart *model.Cart, id uuid.UUID) (*model.CartItem, error) {
+func (r *mockCartItemRepo) GetCartItemByCartAndIDWithProduct(ctx context.Context, cart *model.Cart, id uuid.UUID) (*model.CartItem, error) {
 	cartItem := &model.CartItem{}
 	return cartItem, nil
 }
 
 // DeleteCartItem deletes a cart item
-func (r *mockCartItemRepo) DeleteCartItem(cartItem *model.CartItem) error {
+func (r *mockCartItemRepo) DeleteCartItem(ctx context.Context, cartItem *model.CartItem) error {
 	for i, item := range r.items {
 		if item.ID == cartItem.ID {
 			r.items = append(r.items[:i], r.items[i+1:]...)
@@ -844,22 +925,22 @@ func (r *mockCartItemRepo) DeleteCartItem(cartItem *model.CartItem) error {
 }
 
 // Product
-func (r *mockProductRepo) Insert(product *model.Product) error {
+func (r *mockProductRepo) Insert(ctx context.Context, product *model.Product) error {
 	return nil
 }
 
 // GetProducts get all products
-func (r *mockProductRepo) GetAll(pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+func (r *mockProductRepo) GetAll(ctx context.Context, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
 	return pagination, nil
 }
 
 // GetProduct get a single product
-func (r *mockProductRepo) Get(id uuid.UUID) (*model.Product, error) {
+func (r *mockProductRepo) Get(ctx context.Context, id uuid.UUID) (*model.Product, error) {
 	return nil, nil
 }
 
 // GetProductWithoutCategories get a single product
-func (r *mockProductRepo) GetProductWithoutCategories(id uuid.UUID) (*model.Product, error) {
+func (r *mockProductRepo) GetProductWithoutCategories(ctx context.Context, id uuid.UUID) (*model.Product, error) {
 	for _, item := range r.items {
 		if item.ID == id {
 			return &item, nil
@@ -869,7 +950,7 @@ func (r *mockProductRepo) GetProductWithoutCategories(id uuid.UUID) (*model.Prod
 }
 
 // DeleteProduct delete a single product
-func (r *mockProductRepo) Delete(product *model.Product) error {
+func (r *mockProductRepo) Delete(ctx context.Context, product *model.Product) error {
 	for i, item := range r.items {
 		if item.ID == product.ID {
 			r.items = append(r.items[:i], r.items[i+1:]...)
@@ -880,7 +961,7 @@ func (r *mockProductRepo) Delete(product *model.Product) error {
 }
 
 // UpdateProduct update a single product
-func (r *mockProductRepo) Update(product *model.Product) error {
+func (r *mockProductRepo) Update(ctx context.Context, product *model.Product) error {
 	for i, item := range r.items {
 		if item.ID == product.ID {
 			r.items[i] = *product
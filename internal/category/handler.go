@@ -5,7 +5,7 @@ import (
 	"io"
 	"patika-ecommerce/internal/api"
 	httpErr "patika-ecommerce/internal/httpErrors"
-	"patika-ecommerce/pkg/config"
+	"patika-ecommerce/internal/model"
 	mw "patika-ecommerce/pkg/middleware"
 	file_helper "patika-ecommerce/pkg/utils"
 
@@ -18,13 +18,13 @@ type categoryHandler struct {
 	categoryService MockCategoryService
 }
 
-func NewCategoryHandler(r *gin.RouterGroup, cfg *config.Config, categoryService MockCategoryService) {
+func NewCategoryHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, categoryService MockCategoryService) {
 	handler := &categoryHandler{
 		categoryService: categoryService,
 	}
 
-	r.Use(mw.AuthenticationMiddleware(cfg.JWTConfig.SecretKey))
-	r.Use(mw.AdminMiddleware())
+	r.Use(authMiddleware)
+	r.Use(mw.RequirePermission(model.PermissionCategoryWrite))
 	r.POST("", handler.createCategory)
 	r.GET("", handler.getCategories)
 	r.GET("/:id", handler.getCategory)
@@ -49,7 +49,7 @@ func (r *categoryHandler) createCategory(c *gin.Context) {
 
 	category := CategoryRequestToCategory(reqBody)
 
-	if err := r.categoryService.CreateCategory(category); err != nil {
+	if err := r.categoryService.CreateCategory(c.Request.Context(), category); err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
 	}
@@ -58,7 +58,7 @@ func (r *categoryHandler) createCategory(c *gin.Context) {
 
 // getCategories returns all categories
 func (r *categoryHandler) getCategories(c *gin.Context) {
-	categories, err := r.categoryService.GetCategories()
+	categories, err := r.categoryService.GetCategories(c.Request.Context())
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
@@ -71,11 +71,11 @@ func (r *categoryHandler) getCategories(c *gin.Context) {
 func (r *categoryHandler) getCategory(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(httpErr.ErrorResponse(err))
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
 		return
 	}
 
-	category, err := r.categoryService.GetCategoryByID(id)
+	category, err := r.categoryService.GetCategoryByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
@@ -88,7 +88,7 @@ func (r *categoryHandler) getCategory(c *gin.Context) {
 func (r *categoryHandler) updateCategory(c *gin.Context) {
 	categoryID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(httpErr.ErrorResponse(err))
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
 		return
 	}
 
@@ -106,7 +106,7 @@ func (r *categoryHandler) updateCategory(c *gin.Context) {
 	category := CategoryRequestToCategory(reqBody)
 	category.ID = categoryID
 
-	if err := r.categoryService.UpdateCategory(category); err != nil {
+	if err := r.categoryService.UpdateCategory(c.Request.Context(), category); err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
 	}
@@ -132,7 +132,7 @@ func (r *categoryHandler) createBulkCategories(c *gin.Context) {
 		c.JSON(httpErr.ErrorResponse(err))
 	}
 
-	categories, err := r.categoryService.CreateBulkCategories(buf)
+	categories, err := r.categoryService.CreateBulkCategories(c.Request.Context(), buf)
 
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
@@ -146,11 +146,11 @@ func (r *categoryHandler) createBulkCategories(c *gin.Context) {
 func (r *categoryHandler) deleteCategory(c *gin.Context) {
 	categoryID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(httpErr.ErrorResponse(err))
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
 		return
 	}
 
-	if err := r.categoryService.DeleteCategoryService(categoryID); err != nil {
+	if err := r.categoryService.DeleteCategoryService(c.Request.Context(), categoryID); err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
 	}
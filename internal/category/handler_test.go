@@ -2,8 +2,7 @@ package category
 
 import (
 	"bytes"
-	"errors"
-	"fmt"
+	"context"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
@@ -15,6 +14,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"gorm.io/gorm"
 )
 
@@ -438,10 +438,10 @@ type mockCategoryService struct {
 }
 
 // CreateCategory creates a new category
-func (c *mockCategoryService) CreateCategory(category *model.Category) error {
+func (c *mockCategoryService) CreateCategory(ctx context.Context, category *model.Category) error {
 	for _, item := range c.items {
 		if *item.Name == *category.Name {
-			return errors.New("23505")
+			return &pgconn.PgError{Code: "23505"}
 		}
 	}
 	c.items = append(c.items, *category)
@@ -450,12 +450,12 @@ func (c *mockCategoryService) CreateCategory(category *model.Category) error {
 }
 
 // GetCategories returns all categories
-func (c *mockCategoryService) GetCategories() (*[]model.Category, error) {
+func (c *mockCategoryService) GetCategories(ctx context.Context) (*[]model.Category, error) {
 	return &c.items, nil
 }
 
 // GetCategoryByID returns a category by id
-func (c *mockCategoryService) GetCategoryByID(id uuid.UUID) (*model.Category, error) {
+func (c *mockCategoryService) GetCategoryByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
 
 	for _, item := range c.items {
 		if item.ID == id {
@@ -466,7 +466,7 @@ func (c *mockCategoryService) GetCategoryByID(id uuid.UUID) (*model.Category, er
 }
 
 // UpdateCategory updates a category
-func (c *mockCategoryService) UpdateCategory(category *model.Category) error {
+func (c *mockCategoryService) UpdateCategory(ctx context.Context, category *model.Category) error {
 	for index, item := range c.items {
 		if item.ID == category.ID {
 			c.items[index] = *category
@@ -477,7 +477,7 @@ func (c *mockCategoryService) UpdateCategory(category *model.Category) error {
 }
 
 // CreateBulkCategories creates multiple categories in bulk operation with the specified file
-func (c *mockCategoryService) CreateBulkCategories(filename *bytes.Buffer) ([]model.Category, error) {
+func (c *mockCategoryService) CreateBulkCategories(ctx context.Context, filename *bytes.Buffer) ([]model.Category, error) {
 	records, err := utils.ReadFile(filename)
 	if err != nil {
 		return nil, err
@@ -491,7 +491,7 @@ func (c *mockCategoryService) CreateBulkCategories(filename *bytes.Buffer) ([]mo
 		}
 		for _, item := range c.items {
 			if *item.Name == *category.Name {
-				return nil, fmt.Errorf("23505")
+				return nil, &pgconn.PgError{Code: "23505"}
 			}
 		}
 		categories = append(categories, category)
@@ -506,7 +506,7 @@ func (c *mockCategoryService) CreateBulkCategories(filename *bytes.Buffer) ([]mo
 }
 
 // DeleteCategory deletes a category by id
-func (c *mockCategoryService) DeleteCategoryService(id uuid.UUID) error {
+func (c *mockCategoryService) DeleteCategoryService(ctx context.Context, id uuid.UUID) error {
 
 	for index, item := range c.items {
 		if item.ID == id {
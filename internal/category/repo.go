@@ -1,8 +1,10 @@
 package category
 
 import (
+	"context"
 	"fmt"
 	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -15,26 +17,26 @@ type CategoryRepository struct {
 }
 
 type CategoryRepositoryInterface interface {
-	InsertCategory(category *model.Category) error
-	GetCategories() (*[]model.Category, error)
-	GetCategoryByID(id uuid.UUID) (*model.Category, error)
-	UpdateCategory(category *model.Category) error
-	InsertBulkCategory(categories *[]model.Category) error
-	Delete(category *model.Category) error
+	InsertCategory(ctx context.Context, category *model.Category) error
+	GetCategories(ctx context.Context) (*[]model.Category, error)
+	GetCategoryByID(ctx context.Context, id uuid.UUID) (*model.Category, error)
+	UpdateCategory(ctx context.Context, category *model.Category) error
+	InsertBulkCategory(ctx context.Context, categories *[]model.Category) error
+	Delete(ctx context.Context, category *model.Category) error
 }
 
-func (r *CategoryRepository) Migration() {
-	r.db.AutoMigrate(&model.Category{})
-}
 func NewCategoryrRepository(db *gorm.DB) *CategoryRepository {
 	return &CategoryRepository{db: db}
 }
 
 // InsertCategory inserts a new category
-func (r *CategoryRepository) InsertCategory(category *model.Category) error {
-	zap.L().Debug("category.repo.InsertCategory", zap.Reflect("category", category))
+func (r *CategoryRepository) InsertCategory(ctx context.Context, category *model.Category) error {
+	ctx, span := tracing.Start(ctx, "category.repo.InsertCategory")
+	defer span.End()
+
+	zap.L().Debug("category.repo.InsertCategory", tracing.Field(ctx), zap.Reflect("category", category))
 
-	result := r.db.Create(category)
+	result := r.db.WithContext(ctx).Create(category)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -42,12 +44,15 @@ func (r *CategoryRepository) InsertCategory(category *model.Category) error {
 }
 
 // GetCategories returns all categories
-func (r *CategoryRepository) GetCategories() (*[]model.Category, error) {
-	zap.L().Debug("category.repo.GetCategories")
+func (r *CategoryRepository) GetCategories(ctx context.Context) (*[]model.Category, error) {
+	ctx, span := tracing.Start(ctx, "category.repo.GetCategories")
+	defer span.End()
+
+	zap.L().Debug("category.repo.GetCategories", tracing.Field(ctx))
 
 	categories := &[]model.Category{}
 
-	if err := r.db.Find(categories).Error; err != nil {
+	if err := r.db.WithContext(ctx).Find(categories).Error; err != nil {
 		return nil, err
 	}
 
@@ -55,21 +60,27 @@ func (r *CategoryRepository) GetCategories() (*[]model.Category, error) {
 }
 
 // GetCategoryByID returns a category by id
-func (r *CategoryRepository) GetCategoryByID(id uuid.UUID) (*model.Category, error) {
-	zap.L().Debug("category.repo.GetCategoryByID", zap.Reflect("id", id))
+func (r *CategoryRepository) GetCategoryByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	ctx, span := tracing.Start(ctx, "category.repo.GetCategoryByID")
+	defer span.End()
+
+	zap.L().Debug("category.repo.GetCategoryByID", tracing.Field(ctx), zap.Reflect("id", id))
 
 	category := &model.Category{}
-	if err := r.db.Where("id = ?", id).First(category).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(category).Error; err != nil {
 		return nil, err
 	}
 	return category, nil
 }
 
 // UpdateCategory updates a category with the given id
-func (r *CategoryRepository) UpdateCategory(category *model.Category) error {
-	zap.L().Debug("category.repo.UpdateCategory", zap.Reflect("category", category))
+func (r *CategoryRepository) UpdateCategory(ctx context.Context, category *model.Category) error {
+	ctx, span := tracing.Start(ctx, "category.repo.UpdateCategory")
+	defer span.End()
 
-	result := r.db.Updates(category)
+	zap.L().Debug("category.repo.UpdateCategory", tracing.Field(ctx), zap.Reflect("category", category))
+
+	result := r.db.WithContext(ctx).Updates(category)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -78,10 +89,13 @@ func (r *CategoryRepository) UpdateCategory(category *model.Category) error {
 }
 
 // InsertBulkCategory inserts bulk categories
-func (r *CategoryRepository) InsertBulkCategory(categories *[]model.Category) error {
-	zap.L().Debug("category.repo.InsertBulkCategory", zap.Reflect("categories", categories))
+func (r *CategoryRepository) InsertBulkCategory(ctx context.Context, categories *[]model.Category) error {
+	ctx, span := tracing.Start(ctx, "category.repo.InsertBulkCategory")
+	defer span.End()
+
+	zap.L().Debug("category.repo.InsertBulkCategory", tracing.Field(ctx), zap.Reflect("categories", categories))
 
-	tx := r.db.Begin()
+	tx := r.db.WithContext(ctx).Begin()
 
 	for _, category := range *categories {
 		if err := tx.Create(&category).Error; err != nil {
@@ -95,10 +109,13 @@ func (r *CategoryRepository) InsertBulkCategory(categories *[]model.Category) er
 }
 
 // DeleteCategory deletes a category by id
-func (r *CategoryRepository) Delete(category *model.Category) error {
-	zap.L().Debug("category.repo.Delete", zap.Reflect("category", category))
+func (r *CategoryRepository) Delete(ctx context.Context, category *model.Category) error {
+	ctx, span := tracing.Start(ctx, "category.repo.Delete")
+	defer span.End()
+
+	zap.L().Debug("category.repo.Delete", tracing.Field(ctx), zap.Reflect("category", category))
 
-	result := r.db.Debug().Select(clause.Associations).Delete(category)
+	result := r.db.WithContext(ctx).Debug().Select(clause.Associations).Delete(category)
 	if result.Error != nil {
 		return result.Error
 	}
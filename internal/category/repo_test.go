@@ -1,6 +1,7 @@
 package category
 
 import (
+	"context"
 	"database/sql"
 	"patika-ecommerce/internal/model"
 	"regexp"
@@ -52,7 +53,7 @@ func TestCategoryRepository_GetCategoryByID(t *testing.T) {
 
 	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(c.ID).WillReturnRows(rows)
 
-	category, _ := repo.GetCategoryByID(c.ID)
+	category, _ := repo.GetCategoryByID(context.Background(), c.ID)
 
 	assert.Equal(t, category.ID, id)
 	assert.Equal(t, category.Name, name)
@@ -70,7 +71,7 @@ func TestCategoryRepository_GetCategories(t *testing.T) {
 
 	mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(rows)
 
-	_, err := repo.GetCategories()
+	_, err := repo.GetCategories(context.Background())
 
 	assert.Equal(t, err, nil)
 
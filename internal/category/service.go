@@ -2,7 +2,10 @@ package category
 
 import (
 	"bytes"
+	"context"
+	httpErr "patika-ecommerce/internal/httpErrors"
 	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
 	"patika-ecommerce/pkg/utils"
 
 	"github.com/google/uuid"
@@ -13,12 +16,12 @@ type CategoryService struct {
 }
 
 type MockCategoryService interface {
-	CreateCategory(category *model.Category) error
-	GetCategories() (*[]model.Category, error)
-	GetCategoryByID(id uuid.UUID) (*model.Category, error)
-	UpdateCategory(category *model.Category) error
-	DeleteCategoryService(id uuid.UUID) error
-	CreateBulkCategories(filename *bytes.Buffer) ([]model.Category, error)
+	CreateCategory(ctx context.Context, category *model.Category) error
+	GetCategories(ctx context.Context) (*[]model.Category, error)
+	GetCategoryByID(ctx context.Context, id uuid.UUID) (*model.Category, error)
+	UpdateCategory(ctx context.Context, category *model.Category) error
+	DeleteCategoryService(ctx context.Context, id uuid.UUID) error
+	CreateBulkCategories(ctx context.Context, filename *bytes.Buffer) ([]model.Category, error)
 }
 
 func NewCategoryService(categoryRepo CategoryRepositoryInterface) *CategoryService {
@@ -26,27 +29,46 @@ func NewCategoryService(categoryRepo CategoryRepositoryInterface) *CategoryServi
 }
 
 // CreateCategory creates a new category
-func (c *CategoryService) CreateCategory(category *model.Category) error {
-	return c.categoryRepo.InsertCategory(category)
+func (c *CategoryService) CreateCategory(ctx context.Context, category *model.Category) error {
+	ctx, span := tracing.Start(ctx, "category.service.CreateCategory")
+	defer span.End()
+
+	return c.categoryRepo.InsertCategory(ctx, category)
 }
 
 // GetCategories returns all categories
-func (c *CategoryService) GetCategories() (*[]model.Category, error) {
-	return c.categoryRepo.GetCategories()
+func (c *CategoryService) GetCategories(ctx context.Context) (*[]model.Category, error) {
+	ctx, span := tracing.Start(ctx, "category.service.GetCategories")
+	defer span.End()
+
+	return c.categoryRepo.GetCategories(ctx)
 }
 
 // GetCategoryByID returns a category by id
-func (c *CategoryService) GetCategoryByID(id uuid.UUID) (*model.Category, error) {
-	return c.categoryRepo.GetCategoryByID(id)
+func (c *CategoryService) GetCategoryByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
+	ctx, span := tracing.Start(ctx, "category.service.GetCategoryByID")
+	defer span.End()
+
+	category, err := c.categoryRepo.GetCategoryByID(ctx, id)
+	if err != nil {
+		return nil, httpErr.NotFoundAs(err, httpErr.CategoryNotFoundError)
+	}
+	return category, nil
 }
 
 // UpdateCategory updates a category
-func (c *CategoryService) UpdateCategory(category *model.Category) error {
-	return c.categoryRepo.UpdateCategory(category)
+func (c *CategoryService) UpdateCategory(ctx context.Context, category *model.Category) error {
+	ctx, span := tracing.Start(ctx, "category.service.UpdateCategory")
+	defer span.End()
+
+	return c.categoryRepo.UpdateCategory(ctx, category)
 }
 
 // CreateBulkCategories creates multiple categories in bulk operation with the specified file
-func (c *CategoryService) CreateBulkCategories(buf *bytes.Buffer) ([]model.Category, error) {
+func (c *CategoryService) CreateBulkCategories(ctx context.Context, buf *bytes.Buffer) ([]model.Category, error) {
+	ctx, span := tracing.Start(ctx, "category.service.CreateBulkCategories")
+	defer span.End()
+
 	records, err := utils.ReadFile(buf)
 	if err != nil {
 		return nil, err
@@ -61,7 +83,7 @@ func (c *CategoryService) CreateBulkCategories(buf *bytes.Buffer) ([]model.Categ
 		categories = append(categories, category)
 	}
 
-	if err := c.categoryRepo.InsertBulkCategory(&categories); err != nil {
+	if err := c.categoryRepo.InsertBulkCategory(ctx, &categories); err != nil {
 		return nil, err
 	}
 	return categories, nil
@@ -69,10 +91,13 @@ func (c *CategoryService) CreateBulkCategories(buf *bytes.Buffer) ([]model.Categ
 }
 
 // DeleteCategory deletes a category by id
-func (c *CategoryService) DeleteCategoryService(id uuid.UUID) error {
-	category, err := c.categoryRepo.GetCategoryByID(id)
+func (c *CategoryService) DeleteCategoryService(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "category.service.DeleteCategoryService")
+	defer span.End()
+
+	category, err := c.categoryRepo.GetCategoryByID(ctx, id)
 	if err != nil {
-		return err
+		return httpErr.NotFoundAs(err, httpErr.CategoryNotFoundError)
 	}
-	return c.categoryRepo.Delete(category)
+	return c.categoryRepo.Delete(ctx, category)
 }
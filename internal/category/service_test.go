@@ -2,6 +2,7 @@ package category
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"patika-ecommerce/internal/model"
 	"reflect"
@@ -72,7 +73,7 @@ func TestCategoryService_CreateCategory(t *testing.T) {
 			c := &CategoryService{
 				categoryRepo: tt.fields.categoryRepo,
 			}
-			if err := c.CreateCategory(tt.args.category); (err != nil) != tt.wantErr {
+			if err := c.CreateCategory(context.Background(), tt.args.category); (err != nil) != tt.wantErr {
 				t.Errorf("CategoryService.CreateCategory() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -118,7 +119,7 @@ func TestCategoryService_GetCategories(t *testing.T) {
 			c := &CategoryService{
 				categoryRepo: tt.fields.categoryRepo,
 			}
-			got, err := c.GetCategories()
+			got, err := c.GetCategories(context.Background())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CategoryService.GetCategories() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -177,7 +178,7 @@ func TestCategoryService_GetCategoryByID(t *testing.T) {
 			c := &CategoryService{
 				categoryRepo: tt.fields.categoryRepo,
 			}
-			got, err := c.GetCategoryByID(tt.args.id)
+			got, err := c.GetCategoryByID(context.Background(), tt.args.id)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CategoryService.GetCategoryByID() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -236,7 +237,7 @@ func TestCategoryService_UpdateCategory(t *testing.T) {
 			c := &CategoryService{
 				categoryRepo: tt.fields.categoryRepo,
 			}
-			if err := c.UpdateCategory(tt.args.category); (err != nil) != tt.wantErr {
+			if err := c.UpdateCategory(context.Background(), tt.args.category); (err != nil) != tt.wantErr {
 				t.Errorf("CategoryService.UpdateCategory() error = %v, wantErr %v", err, tt.wantErr)
 				assert.Equal(t, tt.args.category.Name, newCategoryName)
 			}
@@ -297,7 +298,7 @@ func TestCategoryService_DeleteCategory(t *testing.T) {
 			c := &CategoryService{
 				categoryRepo: tt.fields.categoryRepo,
 			}
-			if err := c.DeleteCategoryService(tt.args.id); (err != nil) != tt.wantErr {
+			if err := c.DeleteCategoryService(context.Background(), tt.args.id); (err != nil) != tt.wantErr {
 				t.Errorf("CategoryService.DeleteCategory() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -346,7 +347,7 @@ func TestCategoryService_CreateBulkCategories(t *testing.T) {
 			c := &CategoryService{
 				categoryRepo: tt.fields.categoryRepo,
 			}
-			_, err := c.CreateBulkCategories(tt.args.filename)
+			_, err := c.CreateBulkCategories(context.Background(), tt.args.filename)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CategoryService.CreateBulkCategories() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -366,7 +367,7 @@ type categoryMockRepository struct {
 	Items []model.Category
 }
 
-func (r *categoryMockRepository) InsertCategory(category *model.Category) error {
+func (r *categoryMockRepository) InsertCategory(ctx context.Context, category *model.Category) error {
 	for _, item := range r.Items {
 		item.ID = category.ID
 		return errors.New("category already exists")
@@ -377,12 +378,12 @@ func (r *categoryMockRepository) InsertCategory(category *model.Category) error
 }
 
 // GetCategories returns all categories
-func (r *categoryMockRepository) GetCategories() (*[]model.Category, error) {
+func (r *categoryMockRepository) GetCategories(ctx context.Context) (*[]model.Category, error) {
 	return &r.Items, nil
 }
 
 // GetCategoryByID returns a category by id
-func (r *categoryMockRepository) GetCategoryByID(id uuid.UUID) (*model.Category, error) {
+func (r *categoryMockRepository) GetCategoryByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
 	category := &model.Category{}
 	for _, item := range r.Items {
 		if item.ID == id {
@@ -394,7 +395,7 @@ func (r *categoryMockRepository) GetCategoryByID(id uuid.UUID) (*model.Category,
 }
 
 // UpdateCategory updates a category with the given id
-func (r *categoryMockRepository) UpdateCategory(category *model.Category) error {
+func (r *categoryMockRepository) UpdateCategory(ctx context.Context, category *model.Category) error {
 	for i, item := range r.Items {
 		if item.ID == category.ID {
 			r.Items[i] = *category
@@ -404,7 +405,7 @@ func (r *categoryMockRepository) UpdateCategory(category *model.Category) error
 }
 
 // InsertBulkCategory inserts bulk categories //TODO
-func (r *categoryMockRepository) InsertBulkCategory(categories *[]model.Category) error {
+func (r *categoryMockRepository) InsertBulkCategory(ctx context.Context, categories *[]model.Category) error {
 	for _, category := range *categories {
 		for _, item := range r.Items {
 			if item.Name == category.Name {
@@ -417,7 +418,7 @@ func (r *categoryMockRepository) InsertBulkCategory(categories *[]model.Category
 }
 
 // DeleteCategory deletes a category by id
-func (r *categoryMockRepository) Delete(category *model.Category) error {
+func (r *categoryMockRepository) Delete(ctx context.Context, category *model.Category) error {
 	for index, item := range r.Items {
 		if item.ID == category.ID {
 			r.Items = append(r.Items[:index], r.Items[index+1:]...)
@@ -3,27 +3,125 @@ package httpErrors
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"patika-ecommerce/internal/api"
-	"strings"
 
+	oaErrors "github.com/go-openapi/errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgconn"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// DomainError is an error returned by the services. Code is a stable
+// machine-readable identifier of the error the clients can rely on, Status
+// is the HTTP status it is answered with.
+type DomainError struct {
+	Code    string
+	Status  int
+	Message string
+	Details interface{}
+}
+
+// NewDomainError creates a new DomainError
+func NewDomainError(code string, status int, message string) *DomainError {
+	return &DomainError{Code: code, Status: status, Message: message}
+}
+
+// Error returns the message of the error
+func (e *DomainError) Error() string {
+	return e.Message
+}
+
+// Is reports whether the target is a domain error with the same code, so an
+// error with details still matches its sentinel
+func (e *DomainError) Is(target error) bool {
+	t, ok := target.(*DomainError)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails returns a copy of the error carrying the details
+func (e *DomainError) WithDetails(details interface{}) *DomainError {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
 var (
-	InternalServerError        = errors.New("Internal Server Error")
-	NotFound                   = errors.New("Not Found")
-	RequestTimeoutError        = errors.New("Request Timeout")
-	CannotBindGivenData        = errors.New("Could not bind given data")
-	ValidationError            = errors.New("Validation failed for given payload")
-	UniqueError                = errors.New("Item should be unique on database")
-	Unauthorized               = errors.New("Unauthorized")
-	MediaTypeNotSupported      = errors.New("Media type not supported")
-	UnauthorizedError          = errors.New("Unauthorized")
-	GivenAssociationNotFound   = errors.New("Given association not found")
-	OrderCannotBeCanceledError = errors.New("Order cannot be canceled")
+	InternalServerError           = NewDomainError("internal_error", http.StatusInternalServerError, "Internal Server Error")
+	NotFound                      = NewDomainError("not_found", http.StatusNotFound, "Not Found")
+	RequestTimeoutError           = NewDomainError("request_timeout", http.StatusRequestTimeout, "Request Timeout")
+	CannotBindGivenData           = NewDomainError("invalid_body", http.StatusBadRequest, "Could not bind given data")
+	ValidationError               = NewDomainError("validation_failed", http.StatusBadRequest, "Validation failed for given payload")
+	UniqueError                   = NewDomainError("already_exists", http.StatusBadRequest, "Item should be unique on database")
+	Unauthorized                  = NewDomainError("unauthorized", http.StatusUnauthorized, "Unauthorized")
+	MediaTypeNotSupported         = NewDomainError("unsupported_media_type", http.StatusBadRequest, "Media type not supported")
+	UnauthorizedError             = Unauthorized
+	GivenAssociationNotFound      = NewDomainError("association_not_found", http.StatusBadRequest, "Given association not found")
+	InvalidIDError                = NewDomainError("invalid_id", http.StatusNotFound, "Given id is not valid")
+	CategoryNotFoundError         = NewDomainError("category_not_found", http.StatusNotFound, "Category not found")
+	ProductNotFoundError          = NewDomainError("product_not_found", http.StatusNotFound, "Product not found")
+	CartNotFoundError             = NewDomainError("cart_not_found", http.StatusBadRequest, "Cart not found. Please create a cart")
+	CartItemNotFoundError         = NewDomainError("cart_item_not_found", http.StatusNotFound, "Cart item not found")
+	WishlistItemNotFoundError     = NewDomainError("wishlist_item_not_found", http.StatusNotFound, "Wishlist item not found")
+	OrderNotFoundError            = NewDomainError("order_not_found", http.StatusNotFound, "Order not found")
+	InsufficientStockError        = NewDomainError("insufficient_stock", http.StatusBadRequest, "Product stock is not enough")
+	InvalidAllocationError        = NewDomainError("invalid_allocation_quantity", http.StatusBadRequest, "Allocated quantity must be positive")
+	OrderCannotBeCanceledError    = NewDomainError("order_not_cancelable", http.StatusBadRequest, "Order cannot be canceled")
+	InvalidStockQuantityError     = NewDomainError("invalid_stock_quantity", http.StatusBadRequest, "Stock quantity must not be zero")
+	InvalidStockTransferError     = NewDomainError("invalid_stock_transfer", http.StatusBadRequest, "Stock cannot be transferred to the same warehouse")
+	StockReasonRequiredError      = NewDomainError("stock_reason_required", http.StatusBadRequest, "A reason is required to change the stock")
+	ProductInStockError           = NewDomainError("product_in_stock", http.StatusBadRequest, "Product is in stock")
+	ProductArchivedOnlyError      = NewDomainError("product_archived_only", http.StatusConflict, "Products are archived and cannot be deleted, their stock history is kept")
+	InvalidImportHeaderError      = NewDomainError("invalid_import_header", http.StatusBadRequest, "Header of the import file must be Name,SKU,Description,Price,Stock,Categories")
+	ImportNameRequiredError       = NewDomainError("import_name_required", http.StatusBadRequest, "Name and SKU of an imported product are required")
+	InvalidImportPriceError       = NewDomainError("invalid_import_price", http.StatusBadRequest, "Price of an imported product must be a non-negative number")
+	InvalidImportStockError       = NewDomainError("invalid_import_stock", http.StatusBadRequest, "Stock of an imported product must be a non-negative integer")
+	CartChangedError              = NewDomainError("cart_changed", http.StatusConflict, "Cart has changed, please review it before checkout")
+	IdempotencyKeyInProgressError = NewDomainError("idempotency_key_in_progress", http.StatusConflict, "A request with this Idempotency-Key is in progress")
+	IdempotencyKeyReusedError     = NewDomainError("idempotency_key_reused", http.StatusUnprocessableEntity, "Idempotency-Key is already used with a different payload")
+	IdempotencyKeyTooLongError    = NewDomainError("idempotency_key_too_long", http.StatusBadRequest, "Idempotency-Key must not be longer than 255 characters")
+	InvalidRoleError              = NewDomainError("invalid_role", http.StatusBadRequest, "Role is not valid")
+	CannotChangeOwnRolesError     = NewDomainError("cannot_change_own_roles", http.StatusForbidden, "You cannot change your own roles")
+	InvalidUserTokenError         = NewDomainError("invalid_user_token", http.StatusBadRequest, "Token is invalid or expired")
+	EmailAlreadyVerifiedError     = NewDomainError("email_already_verified", http.StatusBadRequest, "Email address is already verified")
+	EmailNotVerifiedError         = NewDomainError("email_not_verified", http.StatusForbidden, "Email address must be verified")
+	TooManyRequestsError          = NewDomainError("too_many_requests", http.StatusTooManyRequests, "Too many requests, please try again later")
+	AccountLockedError            = NewDomainError("account_locked", http.StatusLocked, "Account is locked after too many failed logins, please try again later")
+	TwoFactorAlreadyEnabledError  = NewDomainError("two_factor_already_enabled", http.StatusConflict, "Two-factor authentication is already enabled")
+	TwoFactorNotEnabledError      = NewDomainError("two_factor_not_enabled", http.StatusBadRequest, "Two-factor authentication is not enabled")
+	InvalidTwoFactorCodeError     = NewDomainError("invalid_two_factor_code", http.StatusBadRequest, "Two-factor code is not valid")
+	CannotDisableTwoFactorError   = NewDomainError("cannot_disable_two_factor", http.StatusForbidden, "Admins must keep two-factor authentication")
+	UnknownOIDCProviderError      = NewDomainError("unknown_oidc_provider", http.StatusNotFound, "Identity provider is not configured")
+	InvalidOIDCStateError         = NewDomainError("invalid_oidc_state", http.StatusBadRequest, "Login request is invalid or expired")
+	OIDCEmailRequiredError        = NewDomainError("oidc_email_required", http.StatusBadRequest, "Identity provider did not share an email address")
+	OIDCAccountExistsError        = NewDomainError("oidc_account_exists", http.StatusConflict, "An account with this email address exists, please sign in with your password")
+	PasswordTooLongError          = NewDomainError("password_too_long", http.StatusBadRequest, "Password must not be longer than 72 bytes")
+	WrongCurrentPasswordError     = NewDomainError("wrong_current_password", http.StatusBadRequest, "Current password is wrong")
+	AccountSuspendedError         = NewDomainError("account_suspended", http.StatusForbidden, "Account is suspended")
+	ForbiddenError                = NewDomainError("forbidden", http.StatusForbidden, "You are not allowed to manage this account")
+	CannotManageOwnAccountError   = NewDomainError("cannot_manage_own_account", http.StatusForbidden, "You cannot suspend your own account or change your own admin status")
+	DataExportInProgressError     = NewDomainError("data_export_in_progress", http.StatusConflict, "A data export is already in progress")
+	DataExportNotReadyError       = NewDomainError("data_export_not_ready", http.StatusConflict, "Data export is not ready or has expired")
+)
+
+// NotFoundAs replaces a missing record error with the domain error of the
+// missing resource, other errors are returned as they are
+func NotFoundAs(err error, notFound *DomainError) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	}
+	return err
+}
+
+// Postgres error codes of the constraint violations
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
 )
 
 type RestError api.APIErrorResponse
@@ -56,62 +154,61 @@ func NewRestError(status int, err string, causes interface{}) RestErr {
 }
 
 func NewInternalServerError(causes interface{}) RestErr {
-	result := RestError{
-		Code:    http.StatusInternalServerError,
-		Message: InternalServerError.Error(),
-		Details: causes,
+	return newRestError(InternalServerError, causes)
+}
+
+// newRestError creates the response of a domain error with the causes as details
+func newRestError(domainErr *DomainError, causes interface{}) RestErr {
+	return RestError{
+		Code:      int64(domainErr.Status),
+		ErrorCode: domainErr.Code,
+		Message:   domainErr.Message,
+		Details:   causes,
 	}
-	return result
 }
 
-// ParseErrors Parser of error string messages returns RestError
+// ParseErrors maps an error to its response. Domain errors carry their own
+// status and code, the errors of the libraries are matched by their types.
 func ParseErrors(err error) RestErr {
+	if restErr, ok := err.(RestErr); ok {
+		return restErr
+	}
+
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return newRestError(domainErr, domainErr.Details)
+	}
+
+	var (
+		pgErr          *pgconn.PgError
+		syntaxErr      *json.SyntaxError
+		unmarshalErr   *json.UnmarshalTypeError
+		compositeErr   *oaErrors.CompositeError
+		openAPIErr     oaErrors.Error
+		validationErrs validator.ValidationErrors
+	)
 	switch {
-	case errors.Is(err, sql.ErrNoRows):
-		return NewRestError(http.StatusNotFound, NotFound.Error(), err)
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, gorm.ErrRecordNotFound):
+		return newRestError(NotFound, err)
 	case errors.Is(err, context.DeadlineExceeded):
-		return NewRestError(http.StatusRequestTimeout, RequestTimeoutError.Error(), err)
-	case errors.Is(err, CannotBindGivenData):
-		return NewRestError(http.StatusBadRequest, CannotBindGivenData.Error(), err)
-	case errors.Is(err, gorm.ErrRecordNotFound):
-		return NewRestError(http.StatusNotFound, gorm.ErrRecordNotFound.Error(), err)
-	case errors.Is(err, OrderCannotBeCanceledError):
-		return NewRestError(http.StatusBadRequest, OrderCannotBeCanceledError.Error(), err)
-	case strings.Contains(err.Error(), "validation"):
-		return NewRestError(http.StatusBadRequest, ValidationError.Error(), err)
-	case strings.Contains(err.Error(), "extension") || strings.Contains(err.Error(), "Media type"):
-		return NewRestError(http.StatusBadRequest, MediaTypeNotSupported.Error(), err)
-	case strings.Contains(err.Error(), "23505"):
-		return NewRestError(http.StatusBadRequest, UniqueError.Error(), err)
-	case strings.Contains(err.Error(), "23503"):
-		return NewRestError(http.StatusBadRequest, GivenAssociationNotFound.Error(), err)
-	case strings.Contains(err.Error(), "cannot unmarshal"):
-		return NewRestError(http.StatusBadRequest, CannotBindGivenData.Error(), err)
-	case strings.Contains(err.Error(), "Unauthorized"):
-		return NewRestError(http.StatusUnauthorized, err.Error(), err)
-	case strings.Contains(err.Error(), "Cart not found"):
-		return NewRestError(http.StatusBadRequest, err.Error(), err)
-	case strings.Contains(err.Error(), "is not enough"):
-		return NewRestError(http.StatusBadRequest, err.Error(), err)
-	case strings.Contains(err.Error(), "not found"):
-		return NewRestError(http.StatusNotFound, NotFound.Error(), err)
-	case strings.Contains(err.Error(), "password"):
-		return NewRestError(http.StatusBadRequest, UnauthorizedError.Error(), err)
-	case strings.Contains(err.Error(), "token contains an invalid number"):
-		return NewRestError(http.StatusBadRequest, ValidationError.Error(), err)
-	case strings.Contains(err.Error(), "invalid character"):
-		return NewRestError(http.StatusBadRequest, MediaTypeNotSupported.Error(), err)
-	case strings.Contains(err.Error(), "invalid UUID format") || strings.Contains(err.Error(), "invalid UUID"):
-		return NewRestError(http.StatusNotFound, NotFound.Error(), err)
+		return newRestError(RequestTimeoutError, err)
+	case errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation:
+		return newRestError(UniqueError, err)
+	case errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation:
+		return newRestError(GivenAssociationNotFound, err)
+	case errors.As(err, &compositeErr), errors.As(err, &openAPIErr), errors.As(err, &validationErrs):
+		return newRestError(ValidationError, FieldErrors(err))
+	case errors.Is(err, io.EOF), errors.As(err, &syntaxErr), errors.As(err, &unmarshalErr):
+		return newRestError(CannotBindGivenData, err)
 
 	default:
-		if restErr, ok := err.(RestErr); ok {
-			return restErr
-		}
-		return NewInternalServerError(err)
+		// the cause may reveal the internals, it is logged instead of answered
+		zap.L().Error("httpErrors.ParseErrors", zap.Error(err))
+		return NewInternalServerError(nil)
 	}
 }
 
 func ErrorResponse(err error) (int, interface{}) {
-	return ParseErrors(err).Status(), ParseErrors(err)
+	restErr := ParseErrors(err)
+	return restErr.Status(), restErr
 }
@@ -0,0 +1,67 @@
+package httpErrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	oaErrors "github.com/go-openapi/errors"
+	"github.com/go-playground/assert/v2"
+	"github.com/jackc/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestParseErrors(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "domain", err: InsufficientStockError, wantStatus: http.StatusBadRequest, wantCode: "insufficient_stock"},
+		{name: "wrapped domain", err: fmt.Errorf("checkout: %w", CartChangedError), wantStatus: http.StatusConflict, wantCode: "cart_changed"},
+		{name: "record not found", err: gorm.ErrRecordNotFound, wantStatus: http.StatusNotFound, wantCode: "not_found"},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, wantStatus: http.StatusBadRequest, wantCode: "already_exists"},
+		{name: "foreign key violation", err: &pgconn.PgError{Code: "23503"}, wantStatus: http.StatusBadRequest, wantCode: "association_not_found"},
+		{name: "openapi validation", err: oaErrors.CompositeValidationError(oaErrors.Required("name", "body", nil)), wantStatus: http.StatusBadRequest, wantCode: "validation_failed"},
+		{name: "empty body", err: io.EOF, wantStatus: http.StatusBadRequest, wantCode: "invalid_body"},
+		{name: "malformed json", err: syntaxErr, wantStatus: http.StatusBadRequest, wantCode: "invalid_body"},
+		// the wording of an unknown error does not change its status
+		{name: "message mentioning not found", err: errors.New("product password not found"), wantStatus: http.StatusInternalServerError, wantCode: "internal_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restErr := ParseErrors(tt.err).(RestError)
+			assert.Equal(t, tt.wantStatus, restErr.Status())
+			assert.Equal(t, tt.wantCode, restErr.ErrorCode)
+		})
+	}
+}
+
+func TestParseErrors_internalErrorHidesCause(t *testing.T) {
+	restErr := ParseErrors(errors.New(`pq: relation "users" does not exist`)).(RestError)
+
+	assert.Equal(t, http.StatusInternalServerError, restErr.Status())
+	assert.Equal(t, "Internal Server Error", restErr.Message)
+	assert.Equal(t, nil, restErr.Details)
+}
+
+func TestDomainError_WithDetails(t *testing.T) {
+	err := InsufficientStockError.WithDetails(map[string]interface{}{"available": 1})
+
+	assert.Equal(t, true, errors.Is(err, InsufficientStockError))
+	assert.Equal(t, false, errors.Is(err, CartChangedError))
+	assert.Equal(t, nil, InsufficientStockError.Details)
+
+	restErr := ParseErrors(err).(RestError)
+	assert.Equal(t, map[string]interface{}{"available": 1}, restErr.Details)
+}
+
+func TestNotFoundAs(t *testing.T) {
+	assert.Equal(t, error(ProductNotFoundError), NotFoundAs(gorm.ErrRecordNotFound, ProductNotFoundError))
+	assert.Equal(t, io.EOF, NotFoundAs(io.EOF, ProductNotFoundError))
+}
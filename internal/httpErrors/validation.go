@@ -0,0 +1,292 @@
+package httpErrors
+
+import (
+	"errors"
+	"fmt"
+	"patika-ecommerce/internal/api"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	oaErrors "github.com/go-openapi/errors"
+	"github.com/go-playground/validator/v10"
+)
+
+// Supported languages of the validation messages
+const (
+	LanguageEnglish = "en"
+	LanguageTurkish = "tr"
+)
+
+// Rules of the validation field errors
+const (
+	RuleRequired   = "required"
+	RuleType       = "type"
+	RuleFormat     = "format"
+	RuleMaxLength  = "max_length"
+	RuleMinLength  = "min_length"
+	RulePattern    = "pattern"
+	RuleEnum       = "enum"
+	RuleMaximum    = "maximum"
+	RuleMinimum    = "minimum"
+	RuleMultipleOf = "multiple_of"
+	RuleUnique     = "unique"
+	RuleMaxItems   = "max_items"
+	RuleMinItems   = "min_items"
+	RuleReadOnly   = "read_only"
+	RuleForbidden  = "forbidden"
+	RuleInvalid    = "invalid"
+)
+
+// openAPIRules maps the go-openapi validation codes to the rules
+var openAPIRules = map[int32]string{
+	oaErrors.InvalidTypeCode:           RuleType,
+	oaErrors.RequiredFailCode:          RuleRequired,
+	oaErrors.TooLongFailCode:           RuleMaxLength,
+	oaErrors.TooShortFailCode:          RuleMinLength,
+	oaErrors.PatternFailCode:           RulePattern,
+	oaErrors.EnumFailCode:              RuleEnum,
+	oaErrors.MultipleOfFailCode:        RuleMultipleOf,
+	oaErrors.MaxFailCode:               RuleMaximum,
+	oaErrors.MinFailCode:               RuleMinimum,
+	oaErrors.UniqueFailCode:            RuleUnique,
+	oaErrors.MaxItemsFailCode:          RuleMaxItems,
+	oaErrors.MinItemsFailCode:          RuleMinItems,
+	oaErrors.NoAdditionalItemsCode:     RuleForbidden,
+	oaErrors.TooFewPropertiesCode:      RuleMinItems,
+	oaErrors.TooManyPropertiesCode:     RuleMaxItems,
+	oaErrors.UnallowedPropertyCode:     RuleForbidden,
+	oaErrors.FailedAllPatternPropsCode: RulePattern,
+	oaErrors.ReadOnlyFailCode:          RuleReadOnly,
+}
+
+// validatorRules maps the validator tags used in gin bindings to the rules,
+// min and max depend on the kind of the field
+var validatorRules = map[string]string{
+	"required": RuleRequired,
+	"oneof":    RuleEnum,
+	"email":    RuleFormat,
+	"uuid":     RuleFormat,
+	"uuid4":    RuleFormat,
+	"url":      RuleFormat,
+	"datetime": RuleFormat,
+	"gt":       RuleMinimum,
+	"gte":      RuleMinimum,
+	"lt":       RuleMaximum,
+	"lte":      RuleMaximum,
+	"unique":   RuleUnique,
+}
+
+// fieldErrorMessages are the messages of the rules by language
+var fieldErrorMessages = map[string]map[string]string{
+	LanguageEnglish: {
+		RuleRequired:   "This field is required",
+		RuleType:       "Value is not of the expected type or format",
+		RuleFormat:     "Value is not in a valid format",
+		RuleMaxLength:  "Value is too long",
+		RuleMinLength:  "Value is too short",
+		RulePattern:    "Value does not match the expected pattern",
+		RuleEnum:       "Value is not one of the allowed values",
+		RuleMaximum:    "Value is too large",
+		RuleMinimum:    "Value is too small",
+		RuleMultipleOf: "Value is not a multiple of the allowed step",
+		RuleUnique:     "Values must not contain duplicates",
+		RuleMaxItems:   "There are too many items",
+		RuleMinItems:   "There are too few items",
+		RuleReadOnly:   "This field cannot be set",
+		RuleForbidden:  "This field is not allowed",
+		RuleInvalid:    "Value is not valid",
+	},
+	LanguageTurkish: {
+		RuleRequired:   "Bu alan zorunludur",
+		RuleType:       "Değer beklenen tipte veya formatta değil",
+		RuleFormat:     "Değer geçerli bir formatta değil",
+		RuleMaxLength:  "Değer çok uzun",
+		RuleMinLength:  "Değer çok kısa",
+		RulePattern:    "Değer beklenen kalıba uymuyor",
+		RuleEnum:       "Değer izin verilen değerlerden biri değil",
+		RuleMaximum:    "Değer çok büyük",
+		RuleMinimum:    "Değer çok küçük",
+		RuleMultipleOf: "Değer izin verilen adımın katı değil",
+		RuleUnique:     "Değerler tekrar içermemeli",
+		RuleMaxItems:   "Çok fazla öğe var",
+		RuleMinItems:   "Yeterli sayıda öğe yok",
+		RuleReadOnly:   "Bu alan değiştirilemez",
+		RuleForbidden:  "Bu alana izin verilmiyor",
+		RuleInvalid:    "Değer geçerli değil",
+	},
+}
+
+// fieldErrorParamMessages are the messages of the rules whose parameter is
+// known, e.g. for the gin bindings and the enums
+var fieldErrorParamMessages = map[string]map[string]string{
+	LanguageEnglish: {
+		RuleMaxLength: "Value must be at most %s characters long",
+		RuleMinLength: "Value must be at least %s characters long",
+		RuleEnum:      "Value must be one of %s",
+		RuleMaximum:   "Value must be at most %s",
+		RuleMinimum:   "Value must be at least %s",
+		RuleMaxItems:  "There must be at most %s items",
+		RuleMinItems:  "There must be at least %s items",
+	},
+	LanguageTurkish: {
+		RuleMaxLength: "Değer en fazla %s karakter olabilir",
+		RuleMinLength: "Değer en az %s karakter olmalıdır",
+		RuleEnum:      "Değer şunlardan biri olmalıdır: %s",
+		RuleMaximum:   "Değer en fazla %s olabilir",
+		RuleMinimum:   "Değer en az %s olmalıdır",
+		RuleMaxItems:  "En fazla %s öğe olabilir",
+		RuleMinItems:  "En az %s öğe olmalıdır",
+	},
+}
+
+// validationErrorMessages are the messages of ValidationError by language
+var validationErrorMessages = map[string]string{
+	LanguageEnglish: ValidationError.Message,
+	LanguageTurkish: "Gönderilen veri doğrulanamadı",
+}
+
+// SupportedLanguage reports whether the validation messages are available
+// in the language
+func SupportedLanguage(lang string) bool {
+	_, ok := fieldErrorMessages[lang]
+	return ok
+}
+
+// ValidationErrorMessage returns the message of ValidationError in the
+// language, English is used for the unsupported languages
+func ValidationErrorMessage(lang string) string {
+	if message, ok := validationErrorMessages[lang]; ok {
+		return message
+	}
+	return validationErrorMessages[LanguageEnglish]
+}
+
+// FieldErrorMessage returns the message of a failed rule in the language,
+// English is used for the unsupported languages
+func FieldErrorMessage(lang, rule, param string) string {
+	if !SupportedLanguage(lang) {
+		lang = LanguageEnglish
+	}
+	if param != "" {
+		if format, ok := fieldErrorParamMessages[lang][rule]; ok {
+			return fmt.Sprintf(format, param)
+		}
+	}
+	if message, ok := fieldErrorMessages[lang][rule]; ok {
+		return message
+	}
+	return fieldErrorMessages[lang][RuleInvalid]
+}
+
+// FieldErrors unpacks the go-openapi and gin binding validation errors into
+// one entry per invalid field with an English message
+func FieldErrors(err error) []*api.ValidationFieldError {
+	var (
+		compositeErr   *oaErrors.CompositeError
+		openAPIErr     *oaErrors.Validation
+		otherErr       oaErrors.Error
+		validationErrs validator.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &compositeErr):
+		fieldErrs := []*api.ValidationFieldError{}
+		for _, e := range compositeErr.Errors {
+			fieldErrs = append(fieldErrs, FieldErrors(e)...)
+		}
+		return fieldErrs
+	case errors.As(err, &openAPIErr):
+		return []*api.ValidationFieldError{openAPIFieldError(openAPIErr)}
+	case errors.As(err, &validationErrs):
+		fieldErrs := make([]*api.ValidationFieldError, 0, len(validationErrs))
+		for _, e := range validationErrs {
+			fieldErrs = append(fieldErrs, validatorFieldError(e))
+		}
+		return fieldErrs
+	case errors.As(err, &otherErr):
+		return []*api.ValidationFieldError{newFieldError("", RuleInvalid, "")}
+	default:
+		return nil
+	}
+}
+
+func openAPIFieldError(err *oaErrors.Validation) *api.ValidationFieldError {
+	rule, ok := openAPIRules[err.Code()]
+	if !ok {
+		rule = RuleInvalid
+	}
+	param := ""
+	if rule == RuleEnum && len(err.Values) > 0 {
+		values := make([]string, 0, len(err.Values))
+		for _, v := range err.Values {
+			values = append(values, fmt.Sprint(v))
+		}
+		param = strings.Join(values, ", ")
+	}
+	return newFieldError(err.Name, rule, param)
+}
+
+func validatorFieldError(err validator.FieldError) *api.ValidationFieldError {
+	rule, ok := validatorRules[err.Tag()]
+	if !ok {
+		switch err.Tag() {
+		case "min", "max":
+			rule = sizeRule(err.Tag(), err.Kind())
+		default:
+			rule = RuleInvalid
+		}
+	}
+	param := err.Param()
+	if rule == RuleEnum {
+		param = strings.Join(strings.Fields(param), ", ")
+	}
+	return newFieldError(fieldPath(err.Namespace()), rule, param)
+}
+
+// sizeRule returns the rule of a min or max tag, which limits the length of
+// the strings, the items of the collections and the value of the numbers
+func sizeRule(tag string, kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		if tag == "min" {
+			return RuleMinLength
+		}
+		return RuleMaxLength
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if tag == "min" {
+			return RuleMinItems
+		}
+		return RuleMaxItems
+	default:
+		if tag == "min" {
+			return RuleMinimum
+		}
+		return RuleMaximum
+	}
+}
+
+// fieldPath turns the namespace of a binding error, e.g.
+// CreateOrderRequest.Items[0].Quantity, into a payload path like the ones of
+// go-openapi, e.g. items.0.quantity
+func fieldPath(namespace string) string {
+	parts := strings.Split(namespace, ".")
+	if len(parts) > 1 {
+		parts = parts[1:]
+	}
+	for i, part := range parts {
+		part = strings.NewReplacer("[", ".", "]", "").Replace(part)
+		r, size := utf8.DecodeRuneInString(part)
+		parts[i] = string(unicode.ToLower(r)) + part[size:]
+	}
+	return strings.Join(parts, ".")
+}
+
+func newFieldError(field, rule, param string) *api.ValidationFieldError {
+	return &api.ValidationFieldError{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: FieldErrorMessage(LanguageEnglish, rule, param),
+	}
+}
@@ -0,0 +1,62 @@
+package httpErrors
+
+import (
+	"patika-ecommerce/internal/api"
+	"testing"
+
+	oaErrors "github.com/go-openapi/errors"
+	"github.com/go-playground/assert/v2"
+	"github.com/go-playground/validator/v10"
+)
+
+func TestFieldErrors_OpenAPI(t *testing.T) {
+	err := oaErrors.CompositeValidationError(
+		oaErrors.Required("name", "body", nil),
+		oaErrors.CompositeValidationError(
+			oaErrors.TooLong("items.0.note", "body", 10, "a long note"),
+			oaErrors.EnumFail("status", "body", "lost", []interface{}{"pending", "shipped"}),
+		),
+	)
+
+	assert.Equal(t, []*api.ValidationFieldError{
+		{Field: "name", Rule: RuleRequired, Message: "This field is required"},
+		{Field: "items.0.note", Rule: RuleMaxLength, Message: "Value is too long"},
+		{Field: "status", Rule: RuleEnum, Param: "pending, shipped", Message: "Value must be one of pending, shipped"},
+	}, FieldErrors(err))
+}
+
+func TestFieldErrors_Binding(t *testing.T) {
+	type item struct {
+		Quantity int `validate:"gte=1"`
+	}
+	type request struct {
+		Email string `validate:"required,email"`
+		Name  string `validate:"min=3"`
+		Items []item `validate:"dive"`
+	}
+	err := validator.New().Struct(request{Email: "x", Name: "ab", Items: []item{{Quantity: 0}}})
+
+	assert.Equal(t, []*api.ValidationFieldError{
+		{Field: "email", Rule: RuleFormat, Message: "Value is not in a valid format"},
+		{Field: "name", Rule: RuleMinLength, Param: "3", Message: "Value must be at least 3 characters long"},
+		{Field: "items.0.quantity", Rule: RuleMinimum, Param: "1", Message: "Value must be at least 1"},
+	}, FieldErrors(err))
+}
+
+func TestParseErrors_ValidationDetails(t *testing.T) {
+	restErr := ParseErrors(oaErrors.CompositeValidationError(oaErrors.Required("email", "body", nil))).(RestError)
+
+	assert.Equal(t, "validation_failed", restErr.ErrorCode)
+	assert.Equal(t, []*api.ValidationFieldError{
+		{Field: "email", Rule: RuleRequired, Message: "This field is required"},
+	}, restErr.Details)
+}
+
+func TestFieldErrorMessage(t *testing.T) {
+	assert.Equal(t, "Bu alan zorunludur", FieldErrorMessage(LanguageTurkish, RuleRequired, ""))
+	assert.Equal(t, "Değer en fazla 100 karakter olabilir", FieldErrorMessage(LanguageTurkish, RuleMaxLength, "100"))
+	// the parameter is only used by the rules with a message for it
+	assert.Equal(t, "Value does not match the expected pattern", FieldErrorMessage(LanguageEnglish, RulePattern, "^[a-z]+$"))
+	assert.Equal(t, "This field is required", FieldErrorMessage("de", RuleRequired, ""))
+	assert.Equal(t, "Value is not valid", FieldErrorMessage(LanguageEnglish, "unknown", ""))
+}
@@ -0,0 +1,35 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type expiredKeyRepositoryInterface interface {
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// PurgeJob removes the expired idempotency keys
+type PurgeJob struct {
+	repo expiredKeyRepositoryInterface
+}
+
+// NewPurgeJob creates a new purge job
+func NewPurgeJob(repo expiredKeyRepositoryInterface) *PurgeJob {
+	return &PurgeJob{repo: repo}
+}
+
+// Name returns the name of the job
+func (j *PurgeJob) Name() string {
+	return "idempotency_keys_purge"
+}
+
+// Run removes the expired keys
+func (j *PurgeJob) Run(ctx context.Context) (string, error) {
+	deleted, err := j.repo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("deleted %d", deleted), nil
+}
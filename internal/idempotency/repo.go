@@ -0,0 +1,84 @@
+package idempotency
+
+import (
+	"context"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve inserts the key unless it is already used in its scope. It returns
+// nil when the key is reserved for this request, otherwise the stored key.
+// An expired key is removed and reserved again.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, key *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	ctx, span := tracing.Start(ctx, "idempotency.repo.Reserve")
+	defer span.End()
+
+	zap.L().Debug("idempotency.repo.Reserve", tracing.Field(ctx), zap.String("key", key.Key), zap.String("scope", key.Scope))
+
+	for i := 0; i < 2; i++ {
+		result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil, nil
+		}
+
+		existing := &model.IdempotencyKey{}
+		if err := r.db.WithContext(ctx).Where("key = ? AND scope = ?", key.Key, key.Scope).First(existing).Error; err != nil {
+			return nil, err
+		}
+		if !existing.IsExpired(time.Now()) {
+			return existing, nil
+		}
+		if err := r.db.WithContext(ctx).Delete(existing).Error; err != nil {
+			return nil, err
+		}
+	}
+	return nil, httpErr.IdempotencyKeyInProgressError
+}
+
+// Complete stores the response of the request
+func (r *IdempotencyRepository) Complete(ctx context.Context, key *model.IdempotencyKey) error {
+	ctx, span := tracing.Start(ctx, "idempotency.repo.Complete")
+	defer span.End()
+
+	zap.L().Debug("idempotency.repo.Complete", tracing.Field(ctx), zap.String("key", key.Key), zap.Int("status", key.StatusCode))
+
+	return r.db.WithContext(ctx).Model(key).Select("status_code", "content_type", "response_body").Updates(key).Error
+}
+
+// Release removes the key, so the request can be retried with it
+func (r *IdempotencyRepository) Release(ctx context.Context, key *model.IdempotencyKey) error {
+	ctx, span := tracing.Start(ctx, "idempotency.repo.Release")
+	defer span.End()
+
+	zap.L().Debug("idempotency.repo.Release", tracing.Field(ctx), zap.String("key", key.Key))
+
+	return r.db.WithContext(ctx).Delete(key).Error
+}
+
+// DeleteExpired removes the keys expired before the given time
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "idempotency.repo.DeleteExpired")
+	defer span.End()
+
+	zap.L().Debug("idempotency.repo.DeleteExpired", tracing.Field(ctx), zap.Time("now", now))
+
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&model.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
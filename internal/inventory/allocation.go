@@ -0,0 +1,68 @@
+package inventory
+
+import (
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// Allocation is the quantity taken from a single warehouse
+type Allocation struct {
+	WarehouseID uuid.UUID
+	Quantity    int64
+}
+
+// Allocate picks the warehouses the given quantity is shipped from.
+// A single warehouse that can fulfill the whole quantity is preferred,
+// otherwise the quantity is split over the warehouses by priority.
+func Allocate(levels []model.StockLevel, quantity int64) ([]Allocation, error) {
+	if quantity <= 0 {
+		return nil, httpErr.InvalidAllocationError
+	}
+
+	candidates := []model.StockLevel{}
+	var total int64
+	for _, level := range levels {
+		if level.Quantity > 0 && level.Warehouse.IsActive {
+			candidates = append(candidates, level)
+			total += level.Quantity
+		}
+	}
+
+	if total < quantity {
+		return nil, httpErr.InsufficientStockError
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Warehouse.Priority != candidates[j].Warehouse.Priority {
+			return candidates[i].Warehouse.Priority < candidates[j].Warehouse.Priority
+		}
+		return candidates[i].Quantity > candidates[j].Quantity
+	})
+
+	// single warehouse preferred
+	for _, level := range candidates {
+		if level.Available(quantity) {
+			return []Allocation{{WarehouseID: level.WarehouseID, Quantity: quantity}}, nil
+		}
+	}
+
+	// else split
+	allocations := []Allocation{}
+	remaining := quantity
+	for _, level := range candidates {
+		if remaining == 0 {
+			break
+		}
+		taken := level.Quantity
+		if taken > remaining {
+			taken = remaining
+		}
+		allocations = append(allocations, Allocation{WarehouseID: level.WarehouseID, Quantity: taken})
+		remaining -= taken
+	}
+
+	return allocations, nil
+}
@@ -0,0 +1,93 @@
+package inventory
+
+import (
+	"patika-ecommerce/internal/model"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestAllocate(t *testing.T) {
+	mainID, secondID, closedID := uuid.New(), uuid.New(), uuid.New()
+
+	main := model.Warehouse{Base: model.Base{ID: mainID}, Priority: 0, IsActive: true}
+	second := model.Warehouse{Base: model.Base{ID: secondID}, Priority: 1, IsActive: true}
+	closed := model.Warehouse{Base: model.Base{ID: closedID}, Priority: 0, IsActive: false}
+
+	type args struct {
+		levels   []model.StockLevel
+		quantity int64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []Allocation
+		wantErr bool
+	}{
+		{
+			name: "singleWarehouseByPriority",
+			args: args{
+				levels: []model.StockLevel{
+					{WarehouseID: secondID, Warehouse: second, Quantity: 10},
+					{WarehouseID: mainID, Warehouse: main, Quantity: 10},
+				},
+				quantity: 5,
+			},
+			want: []Allocation{{WarehouseID: mainID, Quantity: 5}},
+		},
+		{
+			name: "singleWarehousePreferredOverPriority",
+			args: args{
+				levels: []model.StockLevel{
+					{WarehouseID: mainID, Warehouse: main, Quantity: 2},
+					{WarehouseID: secondID, Warehouse: second, Quantity: 10},
+				},
+				quantity: 5,
+			},
+			want: []Allocation{{WarehouseID: secondID, Quantity: 5}},
+		},
+		{
+			name: "splitOverWarehouses",
+			args: args{
+				levels: []model.StockLevel{
+					{WarehouseID: mainID, Warehouse: main, Quantity: 3},
+					{WarehouseID: secondID, Warehouse: second, Quantity: 4},
+				},
+				quantity: 5,
+			},
+			want: []Allocation{{WarehouseID: mainID, Quantity: 3}, {WarehouseID: secondID, Quantity: 2}},
+		},
+		{
+			name: "inactiveWarehouseSkipped",
+			args: args{
+				levels: []model.StockLevel{
+					{WarehouseID: closedID, Warehouse: closed, Quantity: 100},
+					{WarehouseID: secondID, Warehouse: second, Quantity: 4},
+				},
+				quantity: 5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "zeroQuantity",
+			args: args{
+				levels:   []model.StockLevel{{WarehouseID: mainID, Warehouse: main, Quantity: 3}},
+				quantity: 0,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Allocate(tt.args.levels, tt.args.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Allocate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package inventory
+
+import (
+	"patika-ecommerce/internal/api"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	mw "patika-ecommerce/pkg/middleware"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+	common "patika-ecommerce/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+)
+
+type inventoryHandler struct {
+	inventoryService InventoryServiceInterface
+}
+
+// NewInventoryHandler creates a new inventory handler
+func NewInventoryHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, inventoryService InventoryServiceInterface) {
+	handler := &inventoryHandler{inventoryService: inventoryService}
+
+	read := mw.RequirePermission(model.PermissionInventoryRead)
+	write := mw.RequirePermission(model.PermissionInventoryWrite)
+
+	r.Use(authMiddleware)
+	r.GET("/warehouses", read, handler.getWarehouses)
+	r.POST("/warehouses", write, handler.createWarehouse)
+	r.PUT("/warehouses/:id", write, handler.updateWarehouse)
+	r.GET("/products/:id", read, handler.getProductStock)
+	r.POST("/adjustments", write, handler.adjustStock)
+	r.POST("/transfers", write, handler.transferStock)
+	r.GET("/movements", read, mw.PaginationMiddleware(), handler.listMovements)
+	r.GET("/reconciliation", read, handler.reconcile)
+}
+
+// getWarehouses lists all warehouses
+func (r *inventoryHandler) getWarehouses(c *gin.Context) {
+	warehouses, err := r.inventoryService.GetWarehouses(c.Request.Context())
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, WarehousesToWarehouseResponse(warehouses))
+}
+
+// createWarehouse creates a new warehouse
+func (r *inventoryHandler) createWarehouse(c *gin.Context) {
+	reqBody := &api.WarehouseRequest{}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	warehouse := WarehouseRequestToWarehouse(reqBody)
+	if err := r.inventoryService.CreateWarehouse(c.Request.Context(), warehouse); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(201, WarehouseToWarehouseResponse(warehouse))
+}
+
+// updateWarehouse updates a warehouse
+func (r *inventoryHandler) updateWarehouse(c *gin.Context) {
+	reqBody := &api.WarehouseRequest{}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	warehouse := WarehouseRequestToWarehouse(reqBody)
+	warehouse.ID = id
+
+	if err := r.inventoryService.UpdateWarehouse(c.Request.Context(), warehouse); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, WarehouseToWarehouseResponse(warehouse))
+}
+
+// getProductStock returns stock levels of a product per warehouse
+func (r *inventoryHandler) getProductStock(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	levels, err := r.inventoryService.GetStockLevels(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, StockLevelsToProductStockResponse(id, levels))
+}
+
+// adjustStock applies a manual stock adjustment
+func (r *inventoryHandler) adjustStock(c *gin.Context) {
+	reqBody := &api.StockAdjustmentRequest{}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	movement, err := StockAdjustmentRequestToStockMovement(reqBody)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	if err := r.inventoryService.AdjustStock(c.Request.Context(), user, movement); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(201, StockMovementToStockMovementResponse(movement))
+}
+
+// transferStock moves stock between warehouses
+func (r *inventoryHandler) transferStock(c *gin.Context) {
+	reqBody := &api.StockTransferRequest{}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	productID, err := common.StrfmtToUUID(*reqBody.ProductID)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+	fromID, err := common.StrfmtToUUID(*reqBody.FromWarehouseID)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+	toID, err := common.StrfmtToUUID(*reqBody.ToWarehouseID)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	movements, err := r.inventoryService.TransferStock(c.Request.Context(), user, productID, fromID, toID, *reqBody.Quantity, *reqBody.Reason)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(201, StockMovementsToStockMovementResponse(movements))
+}
+
+// listMovements lists the inventory ledger
+func (r *inventoryHandler) listMovements(c *gin.Context) {
+	pagination := c.MustGet("pagination").(*paginationHelper.Pagination)
+
+	filter := &MovementFilter{Type: model.StockMovementType(c.Query("type"))}
+	if c.Query("productId") != "" {
+		id, err := uuid.Parse(c.Query("productId"))
+		if err != nil {
+			c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+			return
+		}
+		filter.ProductID = &id
+	}
+	if c.Query("warehouseId") != "" {
+		id, err := uuid.Parse(c.Query("warehouseId"))
+		if err != nil {
+			c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+			return
+		}
+		filter.WarehouseID = &id
+	}
+
+	data, err := r.inventoryService.ListMovements(c.Request.Context(), filter, pagination)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, data)
+}
+
+// reconcile recomputes stock from the ledger and reports drift
+func (r *inventoryHandler) reconcile(c *gin.Context) {
+	result, err := r.inventoryService.Reconcile(c.Request.Context())
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, ReconciliationToStockReconciliationResponse(result))
+}
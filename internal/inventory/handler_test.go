@@ -0,0 +1,106 @@
+package inventory
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"patika-ecommerce/internal/model"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+)
+
+func getAdjustmentPayload(productID, warehouseID string, quantity string, reason string) []byte {
+	return []byte(`{"productId": "` + productID + `", "warehouseId": "` + warehouseID + `", "quantity": ` + quantity + `, "reason": "` + reason + `"}`)
+}
+
+func getWarehousePayload() []byte {
+	return []byte(`{"name": "Istanbul Warehouse", "code": "IST", "priority": 1}`)
+}
+
+func Test_inventoryHandler_createWarehouse(t *testing.T) {
+	repo := &mockInventoryRepo{}
+	handler := &inventoryHandler{inventoryService: NewInventoryService(repo, nil)}
+
+	t.Run("createWarehouse_Success", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/inventory/warehouses", nil)
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(getWarehousePayload()))
+
+		handler.createWarehouse(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, 1, len(repo.warehouses))
+		assert.Equal(t, true, repo.warehouses[0].IsActive)
+	})
+
+	t.Run("createWarehouse_Failed_missingCode", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/inventory/warehouses", nil)
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(`{"name": "Ankara Warehouse"}`)))
+
+		handler.createWarehouse(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func Test_inventoryHandler_adjustStock(t *testing.T) {
+	warehouseID, productID := uuid.New(), uuid.New()
+	user := model.User{Base: model.Base{ID: uuid.New()}, IsAdmin: true}
+
+	repo := newMockInventoryRepo(warehouseID, productID, 10)
+	handler := &inventoryHandler{inventoryService: NewInventoryService(repo, nil)}
+
+	t.Run("adjustStock_Success", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user", &user)
+		c.Request, _ = http.NewRequest("POST", "/inventory/adjustments", nil)
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(getAdjustmentPayload(productID.String(), warehouseID.String(), "-3", "damaged")))
+
+		handler.adjustStock(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, int64(7), repo.quantity(warehouseID, productID))
+	})
+
+	t.Run("adjustStock_Failed_missingReason", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user", &user)
+		c.Request, _ = http.NewRequest("POST", "/inventory/adjustments", nil)
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(`{"productId": "` + productID.String() + `", "warehouseId": "` + warehouseID.String() + `", "quantity": 3}`)))
+
+		handler.adjustStock(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("adjustStock_Failed_notEnough", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user", &user)
+		c.Request, _ = http.NewRequest("POST", "/inventory/adjustments", nil)
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(getAdjustmentPayload(productID.String(), warehouseID.String(), "-100", "lost")))
+
+		handler.adjustStock(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
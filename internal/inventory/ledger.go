@@ -0,0 +1,149 @@
+package inventory
+
+import (
+	"errors"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MinStockReasonLength is the shortest reason accepted for a manual stock change
+const MinStockReasonLength = 3
+
+// ValidateStockReason checks the reason of a manual stock change, the spaces
+// around it do not count
+func ValidateStockReason(reason string) error {
+	if len(strings.TrimSpace(reason)) < MinStockReasonLength {
+		return httpErr.StockReasonRequiredError
+	}
+	return nil
+}
+
+// ApplyMovement records the movement in the ledger and applies it to the
+// warehouse stock level and to the product total stock. It must be called
+// within a transaction, every stock change goes through here.
+func ApplyMovement(tx *gorm.DB, movement *model.StockMovement) error {
+	zap.L().Debug("inventory.ledger.ApplyMovement", tracing.Field(tx.Statement.Context), zap.Reflect("movement", movement))
+
+	level := model.StockLevel{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("warehouse_id = ? AND product_id = ?", movement.WarehouseID, movement.ProductID).
+		First(&level).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		level = model.StockLevel{
+			WarehouseID: movement.WarehouseID,
+			ProductID:   movement.ProductID,
+		}
+	}
+
+	if err := level.Apply(movement); err != nil {
+		return err
+	}
+	movement.BalanceAfter = level.Quantity
+
+	if err := tx.Save(&level).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&model.Product{}).
+		Where("id = ?", movement.ProductID).
+		Update("stock", gorm.Expr("COALESCE(stock, 0) + ?", movement.Quantity)).Error; err != nil {
+		return err
+	}
+
+	return tx.Omit(clause.Associations).Create(movement).Error
+}
+
+// PrimaryWarehouse returns the active warehouse with the highest priority
+func PrimaryWarehouse(tx *gorm.DB) (*model.Warehouse, error) {
+	warehouse := &model.Warehouse{}
+	if err := tx.Where("is_active = ?", true).
+		Order("priority ASC, created_at ASC").
+		First(warehouse).Error; err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// DeductForOrder allocates the quantity over the warehouses and deducts it with sale movements
+func DeductForOrder(tx *gorm.DB, productID uuid.UUID, quantity int64, orderID uuid.UUID) ([]Allocation, error) {
+	zap.L().Debug("inventory.ledger.DeductForOrder", tracing.Field(tx.Statement.Context), zap.Reflect("productID", productID), zap.Reflect("quantity", quantity))
+
+	var levels []model.StockLevel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "stock_levels"}}).
+		Joins("Warehouse").
+		Where("stock_levels.product_id = ?", productID).
+		Find(&levels).Error; err != nil {
+		return nil, err
+	}
+
+	allocations, err := Allocate(levels, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, allocation := range allocations {
+		movement := &model.StockMovement{
+			ProductID:   productID,
+			WarehouseID: allocation.WarehouseID,
+			Type:        model.StockMovementSale,
+			Quantity:    -allocation.Quantity,
+			ReferenceID: &orderID,
+		}
+		if err := ApplyMovement(tx, movement); err != nil {
+			return nil, err
+		}
+	}
+
+	return allocations, nil
+}
+
+// RestoreForOrder puts the items of a cancelled order back to the warehouses they were shipped from
+func RestoreForOrder(tx *gorm.DB, order *model.Order) error {
+	zap.L().Debug("inventory.ledger.RestoreForOrder", tracing.Field(tx.Statement.Context), zap.Reflect("orderID", order.ID))
+
+	type key struct {
+		productID   uuid.UUID
+		warehouseID uuid.UUID
+	}
+	quantities := map[key]int64{}
+	keys := []key{}
+
+	for _, item := range order.Items {
+		// orders completed before warehouses existed go back to the primary one
+		if item.WarehouseID == nil {
+			primary, err := PrimaryWarehouse(tx)
+			if err != nil {
+				return err
+			}
+			item.WarehouseID = &primary.ID
+		}
+		k := key{productID: item.ProductID, warehouseID: *item.WarehouseID}
+		if _, ok := quantities[k]; !ok {
+			keys = append(keys, k)
+		}
+		quantities[k]++
+	}
+
+	for _, k := range keys {
+		movement := &model.StockMovement{
+			ProductID:   k.productID,
+			WarehouseID: k.warehouseID,
+			Type:        model.StockMovementCancel,
+			Quantity:    quantities[k],
+			ReferenceID: &order.ID,
+		}
+		if err := ApplyMovement(tx, movement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package inventory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// StockObserver is told about products whose stock changed, after the change is committed
+type StockObserver interface {
+	StockChanged(ctx context.Context, productIDs ...uuid.UUID)
+}
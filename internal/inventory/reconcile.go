@@ -0,0 +1,108 @@
+package inventory
+
+import (
+	"patika-ecommerce/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockBalance is the stock of a product in a warehouse recomputed from the ledger
+type StockBalance struct {
+	ProductID   uuid.UUID
+	WarehouseID uuid.UUID
+	Quantity    int64
+}
+
+// Drift is a difference between the ledger and the recorded stock.
+// WarehouseID is nil when the drift is on the product total stock.
+type Drift struct {
+	ProductID        uuid.UUID
+	WarehouseID      *uuid.UUID
+	LedgerQuantity   int64
+	RecordedQuantity int64
+}
+
+// Difference returns how much the recorded stock is off from the ledger
+func (d Drift) Difference() int64 {
+	return d.RecordedQuantity - d.LedgerQuantity
+}
+
+type Reconciliation struct {
+	CheckedAt          time.Time
+	ProductsChecked    int
+	StockLevelsChecked int
+	Drifts             []Drift
+}
+
+// Reconcile compares the ledger balances with the stock levels of every
+// warehouse and with the total stock of every product
+func Reconcile(balances []StockBalance, levels []model.StockLevel, products []model.Product) *Reconciliation {
+	type key struct {
+		productID   uuid.UUID
+		warehouseID uuid.UUID
+	}
+
+	ledger := map[key]int64{}
+	ledgerKeys := []key{}
+	ledgerTotals := map[uuid.UUID]int64{}
+	for _, balance := range balances {
+		k := key{productID: balance.ProductID, warehouseID: balance.WarehouseID}
+		if _, ok := ledger[k]; !ok {
+			ledgerKeys = append(ledgerKeys, k)
+		}
+		ledger[k] += balance.Quantity
+		ledgerTotals[balance.ProductID] += balance.Quantity
+	}
+
+	result := &Reconciliation{
+		CheckedAt:          time.Now(),
+		ProductsChecked:    len(products),
+		StockLevelsChecked: len(levels),
+		Drifts:             []Drift{},
+	}
+
+	seen := map[key]bool{}
+	for _, level := range levels {
+		k := key{productID: level.ProductID, warehouseID: level.WarehouseID}
+		seen[k] = true
+		if ledger[k] != level.Quantity {
+			warehouseID := level.WarehouseID
+			result.Drifts = append(result.Drifts, Drift{
+				ProductID:        level.ProductID,
+				WarehouseID:      &warehouseID,
+				LedgerQuantity:   ledger[k],
+				RecordedQuantity: level.Quantity,
+			})
+		}
+	}
+
+	// movements of a warehouse which has no stock level row at all
+	for _, k := range ledgerKeys {
+		if !seen[k] && ledger[k] != 0 {
+			warehouseID := k.warehouseID
+			result.Drifts = append(result.Drifts, Drift{
+				ProductID:        k.productID,
+				WarehouseID:      &warehouseID,
+				LedgerQuantity:   ledger[k],
+				RecordedQuantity: 0,
+			})
+		}
+	}
+
+	for _, product := range products {
+		var stock int64
+		if product.Stock != nil {
+			stock = *product.Stock
+		}
+		if ledgerTotals[product.ID] != stock {
+			result.Drifts = append(result.Drifts, Drift{
+				ProductID:        product.ID,
+				LedgerQuantity:   ledgerTotals[product.ID],
+				RecordedQuantity: stock,
+			})
+		}
+	}
+
+	return result
+}
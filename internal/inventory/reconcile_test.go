@@ -0,0 +1,80 @@
+package inventory
+
+import (
+	"patika-ecommerce/internal/model"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+)
+
+func TestReconcile(t *testing.T) {
+	productID, mainID, secondID := uuid.New(), uuid.New(), uuid.New()
+	stock, wrongStock := int64(8), int64(9)
+
+	tests := []struct {
+		name     string
+		balances []StockBalance
+		levels   []model.StockLevel
+		products []model.Product
+		want     []Drift
+	}{
+		{
+			name: "noDrift",
+			balances: []StockBalance{
+				{ProductID: productID, WarehouseID: mainID, Quantity: 5},
+				{ProductID: productID, WarehouseID: secondID, Quantity: 3},
+			},
+			levels: []model.StockLevel{
+				{ProductID: productID, WarehouseID: mainID, Quantity: 5},
+				{ProductID: productID, WarehouseID: secondID, Quantity: 3},
+			},
+			products: []model.Product{{Base: model.Base{ID: productID}, Stock: &stock}},
+			want:     []Drift{},
+		},
+		{
+			name: "stockLevelDrift",
+			balances: []StockBalance{
+				{ProductID: productID, WarehouseID: mainID, Quantity: 5},
+				{ProductID: productID, WarehouseID: secondID, Quantity: 3},
+			},
+			levels: []model.StockLevel{
+				{ProductID: productID, WarehouseID: mainID, Quantity: 6},
+				{ProductID: productID, WarehouseID: secondID, Quantity: 3},
+			},
+			products: []model.Product{{Base: model.Base{ID: productID}, Stock: &wrongStock}},
+			want: []Drift{
+				{ProductID: productID, WarehouseID: &mainID, LedgerQuantity: 5, RecordedQuantity: 6},
+				{ProductID: productID, LedgerQuantity: 8, RecordedQuantity: 9},
+			},
+		},
+		{
+			name: "missingStockLevel",
+			balances: []StockBalance{
+				{ProductID: productID, WarehouseID: mainID, Quantity: 5},
+				{ProductID: productID, WarehouseID: secondID, Quantity: 3},
+			},
+			levels: []model.StockLevel{
+				{ProductID: productID, WarehouseID: mainID, Quantity: 5},
+			},
+			products: []model.Product{{Base: model.Base{ID: productID}, Stock: &stock}},
+			want: []Drift{
+				{ProductID: productID, WarehouseID: &secondID, LedgerQuantity: 3, RecordedQuantity: 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Reconcile(tt.balances, tt.levels, tt.products)
+
+			assert.Equal(t, len(tt.products), got.ProductsChecked)
+			assert.Equal(t, len(tt.levels), got.StockLevelsChecked)
+			assert.Equal(t, len(tt.want), len(got.Drifts))
+			for i := range tt.want {
+				assert.Equal(t, tt.want[i].ProductID, got.Drifts[i].ProductID)
+				assert.Equal(t, tt.want[i].WarehouseID, got.Drifts[i].WarehouseID)
+				assert.Equal(t, tt.want[i].Difference(), got.Drifts[i].Difference())
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package inventory
+
+import (
+	"context"
+	"patika-ecommerce/internal/model"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+	"patika-ecommerce/pkg/tracing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type InventoryRepositoryInterface interface {
+	InsertWarehouse(ctx context.Context, warehouse *model.Warehouse) error
+	GetWarehouses(ctx context.Context) ([]model.Warehouse, error)
+	GetWarehouseByID(ctx context.Context, id uuid.UUID) (*model.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
+	GetStockLevelsByProduct(ctx context.Context, productID uuid.UUID) ([]model.StockLevel, error)
+	Adjust(ctx context.Context, movement *model.StockMovement) error
+	Transfer(ctx context.Context, out *model.StockMovement, in *model.StockMovement) error
+	GetMovements(ctx context.Context, filter *MovementFilter, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
+	GetLedgerBalances(ctx context.Context) ([]StockBalance, error)
+	GetAllStockLevels(ctx context.Context) ([]model.StockLevel, error)
+	GetProductStocks(ctx context.Context) ([]model.Product, error)
+}
+
+// MovementFilter narrows down the ledger listing
+type MovementFilter struct {
+	ProductID   *uuid.UUID
+	WarehouseID *uuid.UUID
+	Type        model.StockMovementType
+}
+
+type InventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) *InventoryRepository {
+	return &InventoryRepository{db: db}
+}
+
+// InsertWarehouse inserts a new warehouse
+func (r *InventoryRepository) InsertWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
+	ctx, span := tracing.Start(ctx, "inventory.repo.InsertWarehouse")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.InsertWarehouse", tracing.Field(ctx), zap.Reflect("warehouse", warehouse))
+
+	return r.db.WithContext(ctx).Create(warehouse).Error
+}
+
+// GetWarehouses returns all warehouses ordered by priority
+func (r *InventoryRepository) GetWarehouses(ctx context.Context) ([]model.Warehouse, error) {
+	ctx, span := tracing.Start(ctx, "inventory.repo.GetWarehouses")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.GetWarehouses", tracing.Field(ctx))
+
+	var warehouses []model.Warehouse
+	if err := r.db.WithContext(ctx).Order("priority ASC, created_at ASC").Find(&warehouses).Error; err != nil {
+		return nil, err
+	}
+	return warehouses, nil
+}
+
+// GetWarehouseByID returns a warehouse by id
+func (r *InventoryRepository) GetWarehouseByID(ctx context.Context, id uuid.UUID) (*model.Warehouse, error) {
+	ctx, span := tracing.Start(ctx, "inventory.repo.GetWarehouseByID")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.GetWarehouseByID", tracing.Field(ctx), zap.Reflect("id", id))
+
+	warehouse := &model.Warehouse{}
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(warehouse).Error; err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+// UpdateWarehouse updates a warehouse
+func (r *InventoryRepository) UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
+	ctx, span := tracing.Start(ctx, "inventory.repo.UpdateWarehouse")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.UpdateWarehouse", tracing.Field(ctx), zap.Reflect("warehouse", warehouse))
+
+	return r.db.WithContext(ctx).Model(warehouse).
+		Select("name", "code", "address", "priority", "is_active").
+		Updates(warehouse).Error
+}
+
+// GetStockLevelsByProduct returns stock levels of a product in every warehouse
+func (r *InventoryRepository) GetStockLevelsByProduct(ctx context.Context, productID uuid.UUID) ([]model.StockLevel, error) {
+	ctx, span := tracing.Start(ctx, "inventory.repo.GetStockLevelsByProduct")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.GetStockLevelsByProduct", tracing.Field(ctx), zap.Reflect("productID", productID))
+
+	var levels []model.StockLevel
+	if err := r.db.WithContext(ctx).Joins("Warehouse").
+		Where("stock_levels.product_id = ?", productID).
+		Order("\"Warehouse\".priority ASC").
+		Find(&levels).Error; err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// Adjust applies a manual stock adjustment
+func (r *InventoryRepository) Adjust(ctx context.Context, movement *model.StockMovement) error {
+	ctx, span := tracing.Start(ctx, "inventory.repo.Adjust")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.Adjust", tracing.Field(ctx), zap.Reflect("movement", movement))
+
+	tx := r.db.WithContext(ctx).Begin()
+	if err := ApplyMovement(tx, movement); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// Transfer moves stock from one warehouse to another in a single transaction
+func (r *InventoryRepository) Transfer(ctx context.Context, out *model.StockMovement, in *model.StockMovement) error {
+	ctx, span := tracing.Start(ctx, "inventory.repo.Transfer")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.Transfer", tracing.Field(ctx), zap.Reflect("out", out), zap.Reflect("in", in))
+
+	tx := r.db.WithContext(ctx).Begin()
+	if err := ApplyMovement(tx, out); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := ApplyMovement(tx, in); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// GetMovements returns the ledger entries newest first
+func (r *InventoryRepository) GetMovements(ctx context.Context, filter *MovementFilter, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	ctx, span := tracing.Start(ctx, "inventory.repo.GetMovements")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.GetMovements", tracing.Field(ctx), zap.Reflect("filter", filter), zap.Reflect("pagination", pagination))
+
+	var (
+		movements []model.StockMovement
+		totalRows int64
+	)
+
+	query := r.db.WithContext(ctx).Model(&model.StockMovement{})
+	if filter.ProductID != nil {
+		query = query.Where("product_id = ?", *filter.ProductID)
+	}
+	if filter.WarehouseID != nil {
+		query = query.Where("warehouse_id = ?", *filter.WarehouseID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+
+	if err := query.Count(&totalRows).Error; err != nil {
+		return nil, err
+	}
+	if err := query.Scopes(paginationHelper.Paginate(totalRows, pagination, r.db.WithContext(ctx))).
+		Order("created_at DESC").
+		Find(&movements).Error; err != nil {
+		return nil, err
+	}
+	pagination.Rows = StockMovementsToStockMovementResponse(movements)
+
+	return pagination, nil
+}
+
+// GetLedgerBalances sums up the ledger per product and warehouse
+func (r *InventoryRepository) GetLedgerBalances(ctx context.Context) ([]StockBalance, error) {
+	ctx, span := tracing.Start(ctx, "inventory.repo.GetLedgerBalances")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.GetLedgerBalances", tracing.Field(ctx))
+
+	var balances []StockBalance
+	if err := r.db.WithContext(ctx).Model(&model.StockMovement{}).
+		Select("product_id, warehouse_id, SUM(quantity) AS quantity").
+		Group("product_id, warehouse_id").
+		Scan(&balances).Error; err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// GetAllStockLevels returns stock levels of all products in all warehouses
+func (r *InventoryRepository) GetAllStockLevels(ctx context.Context) ([]model.StockLevel, error) {
+	ctx, span := tracing.Start(ctx, "inventory.repo.GetAllStockLevels")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.GetAllStockLevels", tracing.Field(ctx))
+
+	var levels []model.StockLevel
+	if err := r.db.WithContext(ctx).Order("product_id, warehouse_id").Find(&levels).Error; err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// GetProductStocks returns ids and total stocks of all products
+func (r *InventoryRepository) GetProductStocks(ctx context.Context) ([]model.Product, error) {
+	ctx, span := tracing.Start(ctx, "inventory.repo.GetProductStocks")
+	defer span.End()
+
+	zap.L().Debug("inventory.repo.GetProductStocks", tracing.Field(ctx))
+
+	var products []model.Product
+	if err := r.db.WithContext(ctx).Select("id", "stock").Order("id").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
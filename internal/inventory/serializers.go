@@ -0,0 +1,150 @@
+package inventory
+
+import (
+	"patika-ecommerce/internal/api"
+	"patika-ecommerce/internal/model"
+	common "patika-ecommerce/pkg/utils"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+)
+
+// WarehouseRequestToWarehouse converts a warehouse request to a warehouse
+func WarehouseRequestToWarehouse(req *api.WarehouseRequest) *model.Warehouse {
+	warehouse := &model.Warehouse{
+		Name:     req.Name,
+		Code:     req.Code,
+		Address:  req.Address,
+		IsActive: true,
+	}
+	if req.Priority != nil {
+		warehouse.Priority = int(*req.Priority)
+	}
+	if req.IsActive != nil {
+		warehouse.IsActive = *req.IsActive
+	}
+	return warehouse
+}
+
+// WarehouseToWarehouseResponse converts a warehouse to a warehouse response
+func WarehouseToWarehouseResponse(warehouse *model.Warehouse) *api.WarehouseResponse {
+	return &api.WarehouseResponse{
+		ID:       common.UUIDToStrfmt(warehouse.ID),
+		Name:     *warehouse.Name,
+		Code:     *warehouse.Code,
+		Address:  warehouse.Address,
+		Priority: int64(warehouse.Priority),
+		IsActive: warehouse.IsActive,
+	}
+}
+
+// WarehousesToWarehouseResponse converts warehouses to warehouse responses
+func WarehousesToWarehouseResponse(warehouses []model.Warehouse) []*api.WarehouseResponse {
+	response := []*api.WarehouseResponse{}
+	for _, warehouse := range warehouses {
+		response = append(response, WarehouseToWarehouseResponse(&warehouse))
+	}
+	return response
+}
+
+// StockLevelsToProductStockResponse converts stock levels of a product to a product stock response
+func StockLevelsToProductStockResponse(productID uuid.UUID, levels []model.StockLevel) *api.ProductStockResponse {
+	warehouses := []*api.StockLevelResponse{}
+	var total int64
+
+	for _, level := range levels {
+		code := ""
+		if level.Warehouse.Code != nil {
+			code = *level.Warehouse.Code
+		}
+		warehouses = append(warehouses, &api.StockLevelResponse{
+			WarehouseID:   common.UUIDToStrfmt(level.WarehouseID),
+			WarehouseCode: code,
+			Quantity:      level.Quantity,
+		})
+		total += level.Quantity
+	}
+
+	return &api.ProductStockResponse{
+		ProductID:  common.UUIDToStrfmt(productID),
+		TotalStock: total,
+		Warehouses: warehouses,
+	}
+}
+
+// StockAdjustmentRequestToStockMovement converts a stock adjustment request to a stock movement
+func StockAdjustmentRequestToStockMovement(req *api.StockAdjustmentRequest) (*model.StockMovement, error) {
+	productID, err := common.StrfmtToUUID(*req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	warehouseID, err := common.StrfmtToUUID(*req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.StockMovement{
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Quantity:    *req.Quantity,
+		Reason:      *req.Reason,
+	}, nil
+}
+
+// StockMovementToStockMovementResponse converts a stock movement to a stock movement response
+func StockMovementToStockMovementResponse(movement *model.StockMovement) *api.StockMovementResponse {
+	var referenceID, userID strfmt.UUID
+	if movement.ReferenceID != nil {
+		referenceID = common.UUIDToStrfmt(*movement.ReferenceID)
+	}
+	if movement.UserID != nil {
+		userID = common.UUIDToStrfmt(*movement.UserID)
+	}
+
+	return &api.StockMovementResponse{
+		ID:           common.UUIDToStrfmt(movement.ID),
+		ProductID:    common.UUIDToStrfmt(movement.ProductID),
+		WarehouseID:  common.UUIDToStrfmt(movement.WarehouseID),
+		Type:         string(movement.Type),
+		Quantity:     movement.Quantity,
+		Reason:       movement.Reason,
+		ReferenceID:  referenceID,
+		BalanceAfter: movement.BalanceAfter,
+		UserID:       userID,
+		CreatedAt:    strfmt.DateTime(movement.CreatedAt),
+	}
+}
+
+// StockMovementsToStockMovementResponse converts stock movements to stock movement responses
+func StockMovementsToStockMovementResponse(movements []model.StockMovement) []*api.StockMovementResponse {
+	response := []*api.StockMovementResponse{}
+	for _, movement := range movements {
+		response = append(response, StockMovementToStockMovementResponse(&movement))
+	}
+	return response
+}
+
+// ReconciliationToStockReconciliationResponse converts a reconciliation result to a response
+func ReconciliationToStockReconciliationResponse(result *Reconciliation) *api.StockReconciliationResponse {
+	drifts := []*api.StockDriftResponse{}
+	for _, drift := range result.Drifts {
+		var warehouseID strfmt.UUID
+		if drift.WarehouseID != nil {
+			warehouseID = common.UUIDToStrfmt(*drift.WarehouseID)
+		}
+		drifts = append(drifts, &api.StockDriftResponse{
+			ProductID:        common.UUIDToStrfmt(drift.ProductID),
+			WarehouseID:      warehouseID,
+			LedgerQuantity:   drift.LedgerQuantity,
+			RecordedQuantity: drift.RecordedQuantity,
+			Drift:            drift.Difference(),
+		})
+	}
+
+	return &api.StockReconciliationResponse{
+		CheckedAt:          strfmt.DateTime(result.CheckedAt),
+		ProductsChecked:    int64(result.ProductsChecked),
+		StockLevelsChecked: int64(result.StockLevelsChecked),
+		Drifts:             drifts,
+	}
+}
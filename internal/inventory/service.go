@@ -0,0 +1,176 @@
+package inventory
+
+import (
+	"context"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+	"patika-ecommerce/pkg/tracing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type InventoryServiceInterface interface {
+	CreateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
+	GetWarehouses(ctx context.Context) ([]model.Warehouse, error)
+	UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error
+	GetStockLevels(ctx context.Context, productID uuid.UUID) ([]model.StockLevel, error)
+	AdjustStock(ctx context.Context, user *model.User, movement *model.StockMovement) error
+	TransferStock(ctx context.Context, user *model.User, productID, fromID, toID uuid.UUID, quantity int64, reason string) ([]model.StockMovement, error)
+	ListMovements(ctx context.Context, filter *MovementFilter, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
+	Reconcile(ctx context.Context) (*Reconciliation, error)
+}
+
+type InventoryService struct {
+	inventoryRepo InventoryRepositoryInterface
+	stockObserver StockObserver
+}
+
+// NewInventoryService creates a new InventoryService
+func NewInventoryService(inventoryRepo InventoryRepositoryInterface, stockObserver StockObserver) *InventoryService {
+	return &InventoryService{inventoryRepo: inventoryRepo, stockObserver: stockObserver}
+}
+
+// CreateWarehouse creates a new warehouse
+func (s *InventoryService) CreateWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
+	ctx, span := tracing.Start(ctx, "inventory.service.CreateWarehouse")
+	defer span.End()
+
+	return s.inventoryRepo.InsertWarehouse(ctx, warehouse)
+}
+
+// GetWarehouses returns all warehouses
+func (s *InventoryService) GetWarehouses(ctx context.Context) ([]model.Warehouse, error) {
+	ctx, span := tracing.Start(ctx, "inventory.service.GetWarehouses")
+	defer span.End()
+
+	return s.inventoryRepo.GetWarehouses(ctx)
+}
+
+// UpdateWarehouse updates an existing warehouse
+func (s *InventoryService) UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
+	ctx, span := tracing.Start(ctx, "inventory.service.UpdateWarehouse")
+	defer span.End()
+
+	if _, err := s.inventoryRepo.GetWarehouseByID(ctx, warehouse.ID); err != nil {
+		return err
+	}
+	return s.inventoryRepo.UpdateWarehouse(ctx, warehouse)
+}
+
+// GetStockLevels returns stock levels of a product per warehouse
+func (s *InventoryService) GetStockLevels(ctx context.Context, productID uuid.UUID) ([]model.StockLevel, error) {
+	ctx, span := tracing.Start(ctx, "inventory.service.GetStockLevels")
+	defer span.End()
+
+	return s.inventoryRepo.GetStockLevelsByProduct(ctx, productID)
+}
+
+// AdjustStock applies a manual adjustment to a warehouse stock
+func (s *InventoryService) AdjustStock(ctx context.Context, user *model.User, movement *model.StockMovement) error {
+	ctx, span := tracing.Start(ctx, "inventory.service.AdjustStock")
+	defer span.End()
+
+	if movement.Quantity == 0 {
+		return httpErr.InvalidStockQuantityError
+	}
+	if err := ValidateStockReason(movement.Reason); err != nil {
+		return err
+	}
+
+	if _, err := s.inventoryRepo.GetWarehouseByID(ctx, movement.WarehouseID); err != nil {
+		return err
+	}
+
+	movement.Type = model.StockMovementAdjustment
+	movement.UserID = &user.ID
+	if err := s.inventoryRepo.Adjust(ctx, movement); err != nil {
+		return err
+	}
+
+	if s.stockObserver != nil {
+		s.stockObserver.StockChanged(ctx, movement.ProductID)
+	}
+	return nil
+}
+
+// TransferStock moves stock of a product between two warehouses
+func (s *InventoryService) TransferStock(ctx context.Context, user *model.User, productID, fromID, toID uuid.UUID, quantity int64, reason string) ([]model.StockMovement, error) {
+	ctx, span := tracing.Start(ctx, "inventory.service.TransferStock")
+	defer span.End()
+
+	if fromID == toID {
+		return nil, httpErr.InvalidStockTransferError
+	}
+	if quantity <= 0 {
+		return nil, httpErr.InvalidStockQuantityError
+	}
+	if err := ValidateStockReason(reason); err != nil {
+		return nil, err
+	}
+
+	for _, id := range []uuid.UUID{fromID, toID} {
+		if _, err := s.inventoryRepo.GetWarehouseByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	transferID := uuid.New()
+	out := model.StockMovement{
+		ProductID:   productID,
+		WarehouseID: fromID,
+		Type:        model.StockMovementTransferOut,
+		Quantity:    -quantity,
+		Reason:      reason,
+		ReferenceID: &transferID,
+		UserID:      &user.ID,
+	}
+	in := model.StockMovement{
+		ProductID:   productID,
+		WarehouseID: toID,
+		Type:        model.StockMovementTransferIn,
+		Quantity:    quantity,
+		Reason:      reason,
+		ReferenceID: &transferID,
+		UserID:      &user.ID,
+	}
+
+	if err := s.inventoryRepo.Transfer(ctx, &out, &in); err != nil {
+		return nil, err
+	}
+	return []model.StockMovement{out, in}, nil
+}
+
+// ListMovements lists the inventory ledger
+func (s *InventoryService) ListMovements(ctx context.Context, filter *MovementFilter, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	ctx, span := tracing.Start(ctx, "inventory.service.ListMovements")
+	defer span.End()
+
+	return s.inventoryRepo.GetMovements(ctx, filter, pagination)
+}
+
+// Reconcile recomputes stock from the ledger and reports drift
+func (s *InventoryService) Reconcile(ctx context.Context) (*Reconciliation, error) {
+	ctx, span := tracing.Start(ctx, "inventory.service.Reconcile")
+	defer span.End()
+
+	balances, err := s.inventoryRepo.GetLedgerBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := s.inventoryRepo.GetAllStockLevels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.inventoryRepo.GetProductStocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Reconcile(balances, levels, products)
+	if len(result.Drifts) > 0 {
+		zap.L().Warn("inventory.service.Reconcile", tracing.Field(ctx), zap.Int("drifts", len(result.Drifts)))
+	}
+	return result, nil
+}
@@ -0,0 +1,316 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func TestInventoryService_AdjustStock(t *testing.T) {
+	warehouseID, productID := uuid.New(), uuid.New()
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+
+	tests := []struct {
+		name     string
+		movement *model.StockMovement
+		want     int64
+		wantErr  bool
+	}{
+		{
+			name:     "adjustStock_Succeed",
+			movement: &model.StockMovement{ProductID: productID, WarehouseID: warehouseID, Quantity: 5, Reason: "recount"},
+			want:     15,
+		},
+		{
+			name:     "adjustStock_Failed_zeroQuantity",
+			movement: &model.StockMovement{ProductID: productID, WarehouseID: warehouseID, Quantity: 0, Reason: "recount"},
+			want:     10,
+			wantErr:  true,
+		},
+		{
+			name:     "adjustStock_Failed_notEnough",
+			movement: &model.StockMovement{ProductID: productID, WarehouseID: warehouseID, Quantity: -11, Reason: "damaged"},
+			want:     10,
+			wantErr:  true,
+		},
+		{
+			name:     "adjustStock_Failed_blankReason",
+			movement: &model.StockMovement{ProductID: productID, WarehouseID: warehouseID, Quantity: 5, Reason: "     "},
+			want:     10,
+			wantErr:  true,
+		},
+		{
+			name:     "adjustStock_Failed_warehouseNotFound",
+			movement: &model.StockMovement{ProductID: productID, WarehouseID: uuid.New(), Quantity: 1, Reason: "found"},
+			want:     10,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockInventoryRepo(warehouseID, productID, 10)
+			s := NewInventoryService(repo, nil)
+
+			if err := s.AdjustStock(context.Background(), user, tt.movement); (err != nil) != tt.wantErr {
+				t.Errorf("InventoryService.AdjustStock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, repo.quantity(warehouseID, productID))
+			if !tt.wantErr {
+				assert.Equal(t, model.StockMovementAdjustment, repo.movements[0].Type)
+				assert.Equal(t, user.ID, *repo.movements[0].UserID)
+			}
+		})
+	}
+}
+
+func TestInventoryService_AdjustStock_notifiesObserver(t *testing.T) {
+	warehouseID, productID := uuid.New(), uuid.New()
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+
+	observer := &mockStockObserver{}
+	s := NewInventoryService(newMockInventoryRepo(warehouseID, productID, 10), observer)
+
+	assert.NotEqual(t, nil, s.AdjustStock(context.Background(), user, &model.StockMovement{ProductID: productID, WarehouseID: warehouseID, Quantity: -11, Reason: "damaged"}))
+	assert.Equal(t, 0, len(observer.productIDs))
+
+	assert.Equal(t, nil, s.AdjustStock(context.Background(), user, &model.StockMovement{ProductID: productID, WarehouseID: warehouseID, Quantity: -4, Reason: "damaged"}))
+	assert.Equal(t, []uuid.UUID{productID}, observer.productIDs)
+}
+
+func TestInventoryService_Reconcile(t *testing.T) {
+	warehouseID, productID := uuid.New(), uuid.New()
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+
+	repo := &mockInventoryRepo{warehouses: []model.Warehouse{{Base: model.Base{ID: warehouseID}, IsActive: true}}}
+	s := NewInventoryService(repo, nil)
+
+	assert.Equal(t, nil, s.AdjustStock(context.Background(), user, &model.StockMovement{ProductID: productID, WarehouseID: warehouseID, Quantity: 10, Reason: "restock"}))
+	assert.Equal(t, nil, s.AdjustStock(context.Background(), user, &model.StockMovement{ProductID: productID, WarehouseID: warehouseID, Quantity: -2, Reason: "damaged"}))
+
+	result, err := s.Reconcile(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(result.Drifts))
+
+	// stock level changed outside of the ledger, the product total follows it
+	repo.levels[0].Quantity = 5
+
+	result, err = s.Reconcile(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(result.Drifts))
+	assert.Equal(t, &warehouseID, result.Drifts[0].WarehouseID)
+	assert.Equal(t, int64(-3), result.Drifts[0].Difference())
+	assert.Equal(t, (*uuid.UUID)(nil), result.Drifts[1].WarehouseID)
+}
+
+func TestInventoryService_TransferStock(t *testing.T) {
+	fromID, productID := uuid.New(), uuid.New()
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+
+	t.Run("transferStock_Succeed", func(t *testing.T) {
+		repo := newMockInventoryRepo(fromID, productID, 10)
+		toID := repo.addWarehouse()
+		s := NewInventoryService(repo, nil)
+
+		movements, err := s.TransferStock(context.Background(), user, productID, fromID, toID, 4, "rebalance")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 2, len(movements))
+		assert.Equal(t, *movements[0].ReferenceID, *movements[1].ReferenceID)
+		assert.Equal(t, int64(6), repo.quantity(fromID, productID))
+		assert.Equal(t, int64(4), repo.quantity(toID, productID))
+	})
+
+	t.Run("transferStock_Failed_sameWarehouse", func(t *testing.T) {
+		repo := newMockInventoryRepo(fromID, productID, 10)
+		s := NewInventoryService(repo, nil)
+
+		_, err := s.TransferStock(context.Background(), user, productID, fromID, fromID, 4, "")
+		assert.NotEqual(t, nil, err)
+	})
+
+	t.Run("transferStock_Failed_notEnough", func(t *testing.T) {
+		repo := newMockInventoryRepo(fromID, productID, 10)
+		toID := repo.addWarehouse()
+		s := NewInventoryService(repo, nil)
+
+		_, err := s.TransferStock(context.Background(), user, productID, fromID, toID, 11, "rebalance")
+		assert.NotEqual(t, nil, err)
+		assert.Equal(t, int64(10), repo.quantity(fromID, productID))
+		assert.Equal(t, int64(0), repo.quantity(toID, productID))
+	})
+
+	t.Run("transferStock_Failed_reasonRequired", func(t *testing.T) {
+		repo := newMockInventoryRepo(fromID, productID, 10)
+		toID := repo.addWarehouse()
+		s := NewInventoryService(repo, nil)
+
+		_, err := s.TransferStock(context.Background(), user, productID, fromID, toID, 4, "ok")
+		assert.Equal(t, httpErr.StockReasonRequiredError, err)
+		assert.Equal(t, int64(10), repo.quantity(fromID, productID))
+
+		_, err = s.TransferStock(context.Background(), user, productID, fromID, toID, 4, " \t  ")
+		assert.Equal(t, httpErr.StockReasonRequiredError, err)
+		assert.Equal(t, int64(10), repo.quantity(fromID, productID))
+	})
+}
+
+type mockStockObserver struct {
+	productIDs []uuid.UUID
+}
+
+func (o *mockStockObserver) StockChanged(ctx context.Context, productIDs ...uuid.UUID) {
+	o.productIDs = append(o.productIDs, productIDs...)
+}
+
+type mockInventoryRepo struct {
+	warehouses []model.Warehouse
+	levels     []model.StockLevel
+	movements  []model.StockMovement
+}
+
+func newMockInventoryRepo(warehouseID, productID uuid.UUID, quantity int64) *mockInventoryRepo {
+	return &mockInventoryRepo{
+		warehouses: []model.Warehouse{{Base: model.Base{ID: warehouseID}, IsActive: true}},
+		levels:     []model.StockLevel{{WarehouseID: warehouseID, ProductID: productID, Quantity: quantity}},
+	}
+}
+
+func (r *mockInventoryRepo) addWarehouse() uuid.UUID {
+	id := uuid.New()
+	r.warehouses = append(r.warehouses, model.Warehouse{Base: model.Base{ID: id}, IsActive: true})
+	return id
+}
+
+func (r *mockInventoryRepo) quantity(warehouseID, productID uuid.UUID) int64 {
+	for _, level := range r.levels {
+		if level.WarehouseID == warehouseID && level.ProductID == productID {
+			return level.Quantity
+		}
+	}
+	return 0
+}
+
+func (r *mockInventoryRepo) apply(movement *model.StockMovement) error {
+	for i, level := range r.levels {
+		if level.WarehouseID == movement.WarehouseID && level.ProductID == movement.ProductID {
+			if err := level.Apply(movement); err != nil {
+				return err
+			}
+			r.levels[i] = level
+			r.movements = append(r.movements, *movement)
+			return nil
+		}
+	}
+	level := model.StockLevel{WarehouseID: movement.WarehouseID, ProductID: movement.ProductID}
+	if err := level.Apply(movement); err != nil {
+		return err
+	}
+	r.levels = append(r.levels, level)
+	r.movements = append(r.movements, *movement)
+	return nil
+}
+
+func (r *mockInventoryRepo) InsertWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
+	warehouse.ID = uuid.New()
+	r.warehouses = append(r.warehouses, *warehouse)
+	return nil
+}
+
+func (r *mockInventoryRepo) GetWarehouses(ctx context.Context) ([]model.Warehouse, error) {
+	return r.warehouses, nil
+}
+
+func (r *mockInventoryRepo) GetWarehouseByID(ctx context.Context, id uuid.UUID) (*model.Warehouse, error) {
+	for _, warehouse := range r.warehouses {
+		if warehouse.ID == id {
+			return &warehouse, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockInventoryRepo) UpdateWarehouse(ctx context.Context, warehouse *model.Warehouse) error {
+	for i, item := range r.warehouses {
+		if item.ID == warehouse.ID {
+			r.warehouses[i] = *warehouse
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *mockInventoryRepo) GetStockLevelsByProduct(ctx context.Context, productID uuid.UUID) ([]model.StockLevel, error) {
+	levels := []model.StockLevel{}
+	for _, level := range r.levels {
+		if level.ProductID == productID {
+			levels = append(levels, level)
+		}
+	}
+	return levels, nil
+}
+
+func (r *mockInventoryRepo) Adjust(ctx context.Context, movement *model.StockMovement) error {
+	return r.apply(movement)
+}
+
+func (r *mockInventoryRepo) Transfer(ctx context.Context, out *model.StockMovement, in *model.StockMovement) error {
+	levels := append([]model.StockLevel{}, r.levels...)
+	if err := r.apply(out); err != nil {
+		return err
+	}
+	if err := r.apply(in); err != nil {
+		r.levels = levels
+		return errors.New("transfer failed")
+	}
+	return nil
+}
+
+func (r *mockInventoryRepo) GetMovements(ctx context.Context, filter *MovementFilter, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	movements := []model.StockMovement{}
+	for _, movement := range r.movements {
+		if filter.ProductID != nil && movement.ProductID != *filter.ProductID {
+			continue
+		}
+		if filter.Type != "" && movement.Type != filter.Type {
+			continue
+		}
+		movements = append(movements, movement)
+	}
+	pagination.TotalRows = int64(len(movements))
+	pagination.Rows = StockMovementsToStockMovementResponse(movements)
+	return pagination, nil
+}
+
+func (r *mockInventoryRepo) GetLedgerBalances(ctx context.Context) ([]StockBalance, error) {
+	balances := []StockBalance{}
+	for _, movement := range r.movements {
+		balances = append(balances, StockBalance{ProductID: movement.ProductID, WarehouseID: movement.WarehouseID, Quantity: movement.Quantity})
+	}
+	return balances, nil
+}
+
+func (r *mockInventoryRepo) GetAllStockLevels(ctx context.Context) ([]model.StockLevel, error) {
+	return r.levels, nil
+}
+
+func (r *mockInventoryRepo) GetProductStocks(ctx context.Context) ([]model.Product, error) {
+	totals := map[uuid.UUID]int64{}
+	products := []model.Product{}
+	for _, level := range r.levels {
+		if _, ok := totals[level.ProductID]; !ok {
+			products = append(products, model.Product{Base: model.Base{ID: level.ProductID}})
+		}
+		totals[level.ProductID] += level.Quantity
+	}
+	for i := range products {
+		stock := totals[products[i].ID]
+		products[i].Stock = &stock
+	}
+	return products, nil
+}
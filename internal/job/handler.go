@@ -0,0 +1,42 @@
+package job
+
+import (
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	mw "patika-ecommerce/pkg/middleware"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+type jobHandler struct {
+	jobRepo JobRepositoryInterface
+	runner  *Runner
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, jobRepo JobRepositoryInterface, runner *Runner) {
+	handler := &jobHandler{jobRepo: jobRepo, runner: runner}
+
+	r.Use(authMiddleware, mw.RequirePermission(model.PermissionJobRead))
+	r.GET("", handler.listJobs)
+	r.GET("/runs", mw.PaginationMiddleware(), handler.listRuns)
+}
+
+// listJobs lists the registered jobs
+func (r *jobHandler) listJobs(c *gin.Context) {
+	c.JSON(200, r.runner.Jobs())
+}
+
+// listRuns lists the job run history
+func (r *jobHandler) listRuns(c *gin.Context) {
+	pagination := c.MustGet("pagination").(*paginationHelper.Pagination)
+
+	data, err := r.jobRepo.GetRuns(c.Request.Context(), c.Query("name"), pagination)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, data)
+}
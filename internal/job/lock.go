@@ -0,0 +1,62 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"patika-ecommerce/pkg/tracing"
+
+	"go.uber.org/zap"
+)
+
+// Locker makes sure a job runs on a single instance at a time
+type Locker interface {
+	// TryLock returns false when the lock is held by someone else
+	TryLock(ctx context.Context, name string) (unlock func(), ok bool, err error)
+}
+
+// PostgresLocker uses session level advisory locks, the lock is held on a
+// dedicated connection and released when the job finishes or the connection drops
+type PostgresLocker struct {
+	db *sql.DB
+}
+
+// NewPostgresLocker creates a new PostgresLocker
+func NewPostgresLocker(db *sql.DB) *PostgresLocker {
+	return &PostgresLocker{db: db}
+}
+
+// TryLock tries to take the advisory lock of the job without waiting
+func (l *PostgresLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	key := LockKey(name)
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var ok bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&ok); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			zap.L().Error("job.lock.unlock", tracing.Field(ctx), zap.String("job", name), zap.Error(err))
+		}
+		conn.Close()
+	}
+	return unlock, true, nil
+}
+
+// LockKey returns the advisory lock key of a job
+func LockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("patika-ecommerce:job:" + name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,47 @@
+package job
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestPostgresLocker_TryLock(t *testing.T) {
+	tests := []struct {
+		name     string
+		acquired bool
+	}{
+		{name: "acquired", acquired: true},
+		{name: "heldByAnotherInstance", acquired: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			key := LockKey("mock")
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT pg_try_advisory_lock($1)")).WithArgs(key).
+				WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(tt.acquired))
+			if tt.acquired {
+				mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WithArgs(key).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+
+			unlock, ok, err := NewPostgresLocker(db).TryLock(context.Background(), "mock")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tt.acquired, ok)
+			if ok {
+				unlock()
+			}
+			assert.Equal(t, nil, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLockKey(t *testing.T) {
+	assert.Equal(t, LockKey("abandoned_carts"), LockKey("abandoned_carts"))
+	assert.NotEqual(t, LockKey("abandoned_carts"), LockKey("other"))
+}
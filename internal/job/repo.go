@@ -0,0 +1,95 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"patika-ecommerce/internal/model"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+	"patika-ecommerce/pkg/tracing"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type JobRepositoryInterface interface {
+	InsertRun(ctx context.Context, run *model.JobRun) error
+	UpdateRun(ctx context.Context, run *model.JobRun) error
+	GetLastRun(ctx context.Context, name string) (*model.JobRun, error)
+	GetRuns(ctx context.Context, name string, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
+}
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// InsertRun records the start of a job run
+func (r *JobRepository) InsertRun(ctx context.Context, run *model.JobRun) error {
+	ctx, span := tracing.Start(ctx, "job.repo.InsertRun")
+	defer span.End()
+
+	zap.L().Debug("job.repo.InsertRun", tracing.Field(ctx), zap.Reflect("run", run))
+
+	return r.db.WithContext(ctx).Create(run).Error
+}
+
+// UpdateRun records the result of a job run
+func (r *JobRepository) UpdateRun(ctx context.Context, run *model.JobRun) error {
+	ctx, span := tracing.Start(ctx, "job.repo.UpdateRun")
+	defer span.End()
+
+	zap.L().Debug("job.repo.UpdateRun", tracing.Field(ctx), zap.Reflect("run", run))
+
+	return r.db.WithContext(ctx).Model(run).Select("status", "finished_at", "message").Updates(run).Error
+}
+
+// GetLastRun returns the latest run of a job, nil when it never ran
+func (r *JobRepository) GetLastRun(ctx context.Context, name string) (*model.JobRun, error) {
+	ctx, span := tracing.Start(ctx, "job.repo.GetLastRun")
+	defer span.End()
+
+	zap.L().Debug("job.repo.GetLastRun", tracing.Field(ctx), zap.String("name", name))
+
+	run := &model.JobRun{}
+	err := r.db.WithContext(ctx).Where("name = ?", name).Order("started_at DESC").First(run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// GetRuns returns the job run history, newest first
+func (r *JobRepository) GetRuns(ctx context.Context, name string, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	ctx, span := tracing.Start(ctx, "job.repo.GetRuns")
+	defer span.End()
+
+	zap.L().Debug("job.repo.GetRuns", tracing.Field(ctx), zap.String("name", name), zap.Reflect("pagination", pagination))
+
+	var (
+		runs      []model.JobRun
+		totalRows int64
+	)
+
+	query := r.db.WithContext(ctx).Model(&model.JobRun{})
+	if name != "" {
+		query = query.Where("name = ?", name)
+	}
+	if err := query.Count(&totalRows).Error; err != nil {
+		return nil, err
+	}
+
+	if err := query.Order("started_at DESC").
+		Scopes(paginationHelper.Paginate(totalRows, pagination, r.db.WithContext(ctx))).
+		Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	pagination.Rows = JobRunsToJobRunResponse(runs)
+
+	return pagination, nil
+}
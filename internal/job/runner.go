@@ -0,0 +1,145 @@
+package job
+
+import (
+	"context"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job is a task which runs periodically in the process
+type Job interface {
+	Name() string
+	// Run does the work and returns a short summary kept in the run history
+	Run(ctx context.Context) (string, error)
+}
+
+type entry struct {
+	job      Job
+	interval time.Duration
+}
+
+// Runner runs the registered jobs on their intervals. Every run takes the
+// advisory lock of the job first, so with several instances running a job
+// runs on one of them at a time and the others skip that tick. Under the lock
+// the last run of the job is checked too, so an instance whose ticker fires
+// right after another one finished does not run the job again.
+type Runner struct {
+	jobRepo JobRepositoryInterface
+	locker  Locker
+	entries []entry
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRunner creates a new Runner
+func NewRunner(jobRepo JobRepositoryInterface, locker Locker) *Runner {
+	return &Runner{jobRepo: jobRepo, locker: locker}
+}
+
+// Register adds a job which runs every interval
+func (r *Runner) Register(job Job, interval time.Duration) {
+	r.entries = append(r.entries, entry{job: job, interval: interval})
+}
+
+// Jobs returns the names of the registered jobs
+func (r *Runner) Jobs() []string {
+	names := []string{}
+	for _, e := range r.entries {
+		names = append(names, e.job.Name())
+	}
+	return names
+}
+
+// Start runs every job once its interval passes until Stop is called
+func (r *Runner) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	for _, e := range r.entries {
+		if e.interval <= 0 {
+			zap.L().Warn("job has no interval, not scheduled", tracing.Field(ctx), zap.String("job", e.job.Name()))
+			continue
+		}
+		r.wg.Add(1)
+		go func(e entry) {
+			defer r.wg.Done()
+
+			ticker := time.NewTicker(e.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if _, err := r.RunOnce(ctx, e.job, e.interval); err != nil {
+						zap.L().Error("job.runner.Start", tracing.Field(ctx), zap.String("job", e.job.Name()), zap.Error(err))
+					}
+				}
+			}
+		}(e)
+	}
+	zap.L().Info("job runner started", tracing.Field(ctx), zap.Strings("jobs", r.Jobs()))
+}
+
+// Stop stops scheduling jobs and waits for the running ones to finish
+func (r *Runner) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.wg.Wait()
+}
+
+// RunOnce runs the job if its lock is free and it did not run within the
+// interval, and records the run. It returns nil without an error when another
+// instance holds the lock or ran the job within the interval.
+func (r *Runner) RunOnce(ctx context.Context, job Job, interval time.Duration) (*model.JobRun, error) {
+	ctx, span := tracing.Start(ctx, "job."+job.Name())
+	defer span.End()
+
+	unlock, ok, err := r.locker.TryLock(ctx, job.Name())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		zap.L().Debug("job.runner.RunOnce locked by another instance", tracing.Field(ctx), zap.String("job", job.Name()))
+		return nil, nil
+	}
+	defer unlock()
+
+	lastRun, err := r.jobRepo.GetLastRun(ctx, job.Name())
+	if err != nil {
+		return nil, err
+	}
+	if lastRun != nil && time.Since(lastRun.StartedAt) < interval {
+		zap.L().Debug("job.runner.RunOnce ran within the interval", tracing.Field(ctx), zap.String("job", job.Name()),
+			zap.Time("lastRun", lastRun.StartedAt))
+		return nil, nil
+	}
+
+	run := &model.JobRun{
+		Name:      job.Name(),
+		Status:    model.JobRunStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if err := r.jobRepo.InsertRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	message, jobErr := job.Run(ctx)
+	run.Finish(message, jobErr)
+	if jobErr != nil {
+		tracing.RecordError(span, jobErr)
+		zap.L().Error("job.runner.RunOnce", tracing.Field(ctx), zap.String("job", job.Name()), zap.Error(jobErr))
+	}
+
+	if err := r.jobRepo.UpdateRun(ctx, run); err != nil {
+		return run, err
+	}
+	return run, nil
+}
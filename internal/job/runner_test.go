@@ -0,0 +1,115 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"patika-ecommerce/internal/model"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+type mockJobRepo struct {
+	runs    []*model.JobRun
+	updated int
+	lastRun *model.JobRun
+}
+
+func (r *mockJobRepo) InsertRun(ctx context.Context, run *model.JobRun) error {
+	r.runs = append(r.runs, run)
+	return nil
+}
+
+func (r *mockJobRepo) UpdateRun(ctx context.Context, run *model.JobRun) error {
+	r.updated++
+	return nil
+}
+
+func (r *mockJobRepo) GetLastRun(ctx context.Context, name string) (*model.JobRun, error) {
+	return r.lastRun, nil
+}
+
+func (r *mockJobRepo) GetRuns(ctx context.Context, name string, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	return pagination, nil
+}
+
+type mockLocker struct {
+	locked   map[string]bool
+	unlocked int
+}
+
+func (l *mockLocker) TryLock(ctx context.Context, name string) (func(), bool, error) {
+	if l.locked[name] {
+		return nil, false, nil
+	}
+	return func() { l.unlocked++ }, true, nil
+}
+
+type mockJob struct {
+	calls   int
+	message string
+	err     error
+}
+
+func (j *mockJob) Name() string { return "mock" }
+
+func (j *mockJob) Run(ctx context.Context) (string, error) {
+	j.calls++
+	return j.message, j.err
+}
+
+func TestRunner_RunOnce(t *testing.T) {
+	tests := []struct {
+		name        string
+		locked      bool
+		lastRun     time.Duration
+		jobErr      error
+		wantCalls   int
+		wantStatus  model.JobRunStatus
+		wantMessage string
+	}{
+		{name: "succeeded", wantCalls: 1, wantStatus: model.JobRunStatusSucceeded, wantMessage: "done"},
+		{name: "failed", jobErr: errors.New("boom"), wantCalls: 1, wantStatus: model.JobRunStatusFailed, wantMessage: "boom"},
+		{name: "lockedByAnotherInstance", locked: true, wantCalls: 0},
+		{name: "ranWithinInterval", lastRun: 30 * time.Second, wantCalls: 0},
+		{name: "ranBeforeInterval", lastRun: 2 * time.Minute, wantCalls: 1, wantStatus: model.JobRunStatusSucceeded, wantMessage: "done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockJobRepo{}
+			if tt.lastRun > 0 {
+				repo.lastRun = &model.JobRun{Name: "mock", StartedAt: time.Now().Add(-tt.lastRun)}
+			}
+			locker := &mockLocker{locked: map[string]bool{"mock": tt.locked}}
+			job := &mockJob{message: "done", err: tt.jobErr}
+			r := NewRunner(repo, locker)
+
+			run, err := r.RunOnce(context.Background(), job, time.Minute)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, tt.wantCalls, job.calls)
+
+			if tt.wantCalls == 0 {
+				assert.Equal(t, (*model.JobRun)(nil), run)
+				assert.Equal(t, 0, len(repo.runs))
+				return
+			}
+			assert.Equal(t, tt.wantStatus, run.Status)
+			assert.Equal(t, tt.wantMessage, run.Message)
+			assert.NotEqual(t, nil, run.FinishedAt)
+			assert.Equal(t, 1, len(repo.runs))
+			assert.Equal(t, 1, repo.updated)
+			assert.Equal(t, 1, locker.unlocked)
+		})
+	}
+}
+
+func TestRunner_StartStop(t *testing.T) {
+	r := NewRunner(&mockJobRepo{}, &mockLocker{})
+	r.Register(&mockJob{}, 0)
+	assert.Equal(t, []string{"mock"}, r.Jobs())
+
+	r.Start()
+	r.Stop()
+}
@@ -0,0 +1,37 @@
+package job
+
+import (
+	"patika-ecommerce/internal/api"
+	"patika-ecommerce/internal/model"
+	common "patika-ecommerce/pkg/utils"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// JobRunToJobRunResponse converts a job run to a job run response
+func JobRunToJobRunResponse(run *model.JobRun) *api.JobRunResponse {
+	var finishedAt *strfmt.DateTime
+	if run.FinishedAt != nil {
+		t := strfmt.DateTime(*run.FinishedAt)
+		finishedAt = &t
+	}
+
+	return &api.JobRunResponse{
+		ID:         common.UUIDToStrfmt(run.ID),
+		Name:       run.Name,
+		Status:     string(run.Status),
+		StartedAt:  strfmt.DateTime(run.StartedAt),
+		FinishedAt: finishedAt,
+		DurationMs: run.Duration().Milliseconds(),
+		Message:    run.Message,
+	}
+}
+
+// JobRunsToJobRunResponse converts job runs to job run responses
+func JobRunsToJobRunResponse(runs []model.JobRun) []*api.JobRunResponse {
+	response := []*api.JobRunResponse{}
+	for _, run := range runs {
+		response = append(response, JobRunToJobRunResponse(&run))
+	}
+	return response
+}
@@ -1,7 +1,8 @@
 package model
 
 import (
-	"fmt"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -13,6 +14,8 @@ const (
 	CartStatusCreated   CartStatus = "created"
 	CartStatusPaid      CartStatus = "paid"
 	CartStatusCancelled CartStatus = "cancelled"
+	// CartStatusExpired is set on carts abandoned for too long
+	CartStatusExpired CartStatus = "expired"
 )
 
 type Cart struct {
@@ -23,6 +26,10 @@ type Cart struct {
 	User   User      `json:"user"`
 
 	Items []CartItem `json:"items"`
+
+	// AbandonedAt is set when the cart is idle for too long and cleared when it is used again
+	AbandonedAt    *time.Time `json:"abandoned_at"`
+	ReminderSentAt *time.Time `json:"reminder_sent_at"`
 }
 type CartItem struct {
 	Base
@@ -49,7 +56,7 @@ func (c *Cart) GetCartItemByID(id uuid.UUID) (*CartItem, error) {
 			return &item, nil
 		}
 	}
-	return nil, fmt.Errorf("Cart item not found")
+	return nil, httpErr.CartItemNotFoundError
 }
 
 // GetTotalPrice returns total price of cart
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"github.com/google/uuid"
+)
+
+type CartIssueType string
+
+const (
+	CartIssuePriceChanged    CartIssueType = "price_changed"
+	CartIssueOutOfStock      CartIssueType = "out_of_stock"
+	CartIssueReducedQuantity CartIssueType = "reduced_quantity"
+	CartIssueProductDeleted  CartIssueType = "product_deleted"
+)
+
+type CartFix string
+
+const (
+	// CartFixAcceptPrices updates the cart items to the current product prices
+	CartFixAcceptPrices CartFix = "accept_prices"
+	// CartFixAdjustQuantities reduces the cart items to the available stock
+	CartFixAdjustQuantities CartFix = "adjust_quantities"
+	// CartFixRemoveUnavailable removes out of stock and deleted products
+	CartFixRemoveUnavailable CartFix = "remove_unavailable"
+)
+
+// CartIssue is a change of a cart item since it was added to the cart
+type CartIssue struct {
+	Type              CartIssueType
+	CartItemID        uuid.UUID
+	ProductID         uuid.UUID
+	Quantity          int64
+	AvailableQuantity int64
+	OldPrice          float64
+	NewPrice          float64
+}
+
+// Fix returns the fix which resolves the issue
+func (i CartIssue) Fix() CartFix {
+	switch i.Type {
+	case CartIssuePriceChanged:
+		return CartFixAcceptPrices
+	case CartIssueReducedQuantity:
+		return CartFixAdjustQuantities
+	default:
+		return CartFixRemoveUnavailable
+	}
+}
+
+// Revalidate compares the cart items with their products, the items must be
+// loaded with their products. A cart item can have a price change together
+// with a stock issue.
+func (c *Cart) Revalidate() []CartIssue {
+	issues := []CartIssue{}
+
+	for _, item := range c.Items {
+		issue := CartIssue{
+			CartItemID: item.ID,
+			ProductID:  item.ProductID,
+			Quantity:   item.Quantity,
+			OldPrice:   item.Price,
+		}
+
+		if item.Product.ID == uuid.Nil || item.Product.DeletedAt != nil {
+			issue.Type = CartIssueProductDeleted
+			issues = append(issues, issue)
+			continue
+		}
+
+		issue.NewPrice = item.Product.Price
+		if item.Product.Stock != nil {
+			issue.AvailableQuantity = *item.Product.Stock
+		}
+
+		if item.Price != item.Product.Price {
+			priceIssue := issue
+			priceIssue.Type = CartIssuePriceChanged
+			issues = append(issues, priceIssue)
+		}
+
+		switch {
+		case issue.AvailableQuantity <= 0:
+			issue.Type = CartIssueOutOfStock
+			issues = append(issues, issue)
+		case issue.AvailableQuantity < item.Quantity:
+			issue.Type = CartIssueReducedQuantity
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}
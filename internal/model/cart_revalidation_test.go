@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+)
+
+func TestCart_Revalidate(t *testing.T) {
+	product := func(price float64, stock int64) Product {
+		return Product{Base: Base{ID: uuid.New()}, Price: price, Stock: &stock}
+	}
+	deletedAt := time.Now()
+	deleted := product(10, 5)
+	deleted.DeletedAt = &deletedAt
+
+	tests := []struct {
+		name string
+		item CartItem
+		want []CartIssueType
+	}{
+		{name: "valid", item: CartItem{Quantity: 2, Price: 10, Product: product(10, 5)}, want: []CartIssueType{}},
+		{name: "priceChanged", item: CartItem{Quantity: 2, Price: 10, Product: product(12, 5)}, want: []CartIssueType{CartIssuePriceChanged}},
+		{name: "outOfStock", item: CartItem{Quantity: 2, Price: 10, Product: product(10, 0)}, want: []CartIssueType{CartIssueOutOfStock}},
+		{name: "reducedQuantity", item: CartItem{Quantity: 4, Price: 10, Product: product(10, 3)}, want: []CartIssueType{CartIssueReducedQuantity}},
+		{name: "priceChangedAndReducedQuantity", item: CartItem{Quantity: 4, Price: 10, Product: product(8, 3)}, want: []CartIssueType{CartIssuePriceChanged, CartIssueReducedQuantity}},
+		{name: "productMissing", item: CartItem{Quantity: 1, Price: 10}, want: []CartIssueType{CartIssueProductDeleted}},
+		{name: "productDeleted", item: CartItem{Quantity: 1, Price: 10, Product: deleted}, want: []CartIssueType{CartIssueProductDeleted}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cart := &Cart{Items: []CartItem{tt.item}}
+
+			got := []CartIssueType{}
+			for _, issue := range cart.Revalidate() {
+				got = append(got, issue.Type)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DataExportStatus string
+
+const (
+	DataExportStatusPending DataExportStatus = "pending"
+	DataExportStatusReady   DataExportStatus = "ready"
+	DataExportStatusFailed  DataExportStatus = "failed"
+)
+
+// DataExport is a request of a user for an archive of their personal data.
+// The archive is built in the background and removed once it expires.
+type DataExport struct {
+	Base
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User   User      `json:"-"`
+
+	Status      DataExportStatus `json:"status" gorm:"type:varchar(20);not null"`
+	FileName    string           `json:"-" gorm:"type:varchar(255)"`
+	Size        int64            `json:"size" gorm:"not null;default:0"`
+	CompletedAt *time.Time       `json:"completed_at"`
+	ExpiresAt   *time.Time       `json:"expires_at"`
+	Error       string           `json:"-"`
+}
+
+// IsPending returns true while the archive is not built yet
+func (e *DataExport) IsPending() bool {
+	return e.Status == DataExportStatusPending
+}
+
+// IsDownloadable returns true when the archive is built and not expired
+func (e *DataExport) IsDownloadable(now time.Time) bool {
+	return e.Status == DataExportStatusReady && e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+}
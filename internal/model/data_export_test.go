@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestDataExport_IsDownloadable(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		export DataExport
+		want   bool
+	}{
+		{name: "pending", export: DataExport{Status: DataExportStatusPending}, want: false},
+		{name: "failed", export: DataExport{Status: DataExportStatusFailed, ExpiresAt: &later}, want: false},
+		{name: "ready", export: DataExport{Status: DataExportStatusReady, ExpiresAt: &later}, want: true},
+		{name: "expired", export: DataExport{Status: DataExportStatusReady, ExpiresAt: &earlier}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.export.IsDownloadable(now))
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalIdentity links a user to an account at an OpenID Connect provider
+type ExternalIdentity struct {
+	Base
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User   User      `json:"-"`
+
+	Provider string `json:"provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_external_identity_subject"`
+	// Subject is the sub claim, the id of the user at the provider
+	Subject string `json:"subject" gorm:"type:varchar(255);not null;uniqueIndex:idx_external_identity_subject"`
+	Email   string `json:"email" gorm:"type:varchar(100)"`
+}
+
+// OIDCLoginRequest is a login started at an OpenID Connect provider. It keeps
+// the PKCE verifier and the nonce until the provider redirects back with the
+// state, only the hash of the state is kept.
+type OIDCLoginRequest struct {
+	Base
+	Provider     string    `json:"provider" gorm:"type:varchar(50);not null"`
+	StateHash    string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Nonce        string    `json:"-" gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `json:"-" gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null"`
+}
+
+// IsExpired returns true when the login cannot be completed anymore
+func (r *OIDCLoginRequest) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+// IdempotencyKey stores the response of an unsafe request, so a retry with
+// the same Idempotency-Key header replays it instead of running it again
+type IdempotencyKey struct {
+	Base
+	Key string `json:"key" gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_key_scope"`
+	// Scope is the method, path and user of the request, a key is unique per scope
+	Scope       string `json:"scope" gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_key_scope"`
+	RequestHash string `json:"request_hash" gorm:"type:varchar(64);not null"`
+	// StatusCode is zero until the first request finishes
+	StatusCode   int       `json:"status_code" gorm:"not null;default:0"`
+	ContentType  string    `json:"content_type" gorm:"type:varchar(100)"`
+	ResponseBody []byte    `json:"-"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+}
+
+// IsCompleted returns true when the response is stored
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.StatusCode != 0
+}
+
+// IsExpired returns true when the key can be used for a new request
+func (k *IdempotencyKey) IsExpired(now time.Time) bool {
+	return !now.Before(k.ExpiresAt)
+}
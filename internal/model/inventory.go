@@ -0,0 +1,86 @@
+package model
+
+import (
+	"errors"
+	httpErr "patika-ecommerce/internal/httpErrors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StockMovementType string
+
+const (
+	StockMovementSale        StockMovementType = "sale"
+	StockMovementCancel      StockMovementType = "cancel"
+	StockMovementAdjustment  StockMovementType = "adjustment"
+	StockMovementTransferIn  StockMovementType = "transfer_in"
+	StockMovementTransferOut StockMovementType = "transfer_out"
+	StockMovementImport      StockMovementType = "import"
+)
+
+var ErrStockMovementAppendOnly = errors.New("stock movements are append-only")
+
+type Warehouse struct {
+	Base
+	Name     *string `json:"name" gorm:"type:varchar(100);not null;unique"`
+	Code     *string `json:"code" gorm:"type:varchar(20);not null;unique"`
+	Address  string  `json:"address" gorm:"type:varchar(255)"`
+	Priority int     `json:"priority" gorm:"not null;default:0"`
+	IsActive bool    `json:"is_active" gorm:"not null;default:true"`
+}
+
+type StockLevel struct {
+	Base
+	WarehouseID uuid.UUID `json:"warehouse_id" gorm:"type:uuid;not null;uniqueIndex:idx_stock_levels_warehouse_product"`
+	Warehouse   Warehouse `json:"warehouse"`
+
+	ProductID uuid.UUID `json:"product_id" gorm:"type:uuid;not null;uniqueIndex:idx_stock_levels_warehouse_product"`
+	Product   Product   `json:"product"`
+
+	Quantity int64 `json:"quantity" gorm:"not null;default:0"`
+}
+
+type StockMovement struct {
+	Base
+	ProductID uuid.UUID `json:"product_id" gorm:"type:uuid;not null;index"`
+	Product   Product   `json:"product"`
+
+	WarehouseID uuid.UUID `json:"warehouse_id" gorm:"type:uuid;not null;index"`
+	Warehouse   Warehouse `json:"warehouse"`
+
+	Type     StockMovementType `json:"type" gorm:"type:varchar(20);not null"`
+	Quantity int64             `json:"quantity" gorm:"not null"`
+	Reason   string            `json:"reason" gorm:"type:varchar(255)"`
+
+	// BalanceAfter is the warehouse stock level right after the movement
+	BalanceAfter int64 `json:"balance_after" gorm:"not null;default:0"`
+
+	// ReferenceID points to the order or transfer that caused the movement
+	ReferenceID *uuid.UUID `json:"reference_id" gorm:"type:uuid;index"`
+	UserID      *uuid.UUID `json:"user_id" gorm:"type:uuid"`
+}
+
+// Available returns true if the warehouse has at least the given quantity
+func (s *StockLevel) Available(quantity int64) bool {
+	return s.Quantity >= quantity
+}
+
+// Apply changes stock level quantity by the given movement
+func (s *StockLevel) Apply(movement *StockMovement) error {
+	if s.Quantity+movement.Quantity < 0 {
+		return httpErr.InsufficientStockError
+	}
+	s.Quantity += movement.Quantity
+	return nil
+}
+
+// BeforeUpdate hook, ledger rows can not be changed once they are written
+func (m *StockMovement) BeforeUpdate(tx *gorm.DB) error {
+	return ErrStockMovementAppendOnly
+}
+
+// BeforeDelete hook, ledger rows can not be removed once they are written
+func (m *StockMovement) BeforeDelete(tx *gorm.DB) error {
+	return ErrStockMovementAppendOnly
+}
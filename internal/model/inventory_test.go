@@ -0,0 +1,62 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+)
+
+func TestStockLevel_Apply(t *testing.T) {
+	type args struct {
+		movement *StockMovement
+	}
+	tests := []struct {
+		name     string
+		quantity int64
+		args     args
+		want     int64
+		wantErr  bool
+	}{
+		{
+			name:     "Apply_Succeed_increase",
+			quantity: 5,
+			args:     args{movement: &StockMovement{Type: StockMovementAdjustment, Quantity: 3}},
+			want:     8,
+		},
+		{
+			name:     "Apply_Succeed_decrease",
+			quantity: 5,
+			args:     args{movement: &StockMovement{Type: StockMovementSale, Quantity: -5}},
+			want:     0,
+		},
+		{
+			name:     "Apply_Failed_notEnough",
+			quantity: 5,
+			args:     args{movement: &StockMovement{Type: StockMovementSale, Quantity: -6}},
+			want:     5,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &StockLevel{
+				Base:        Base{ID: uuid.New()},
+				WarehouseID: uuid.New(),
+				ProductID:   uuid.New(),
+				Quantity:    tt.quantity,
+			}
+			if err := s.Apply(tt.args.movement); (err != nil) != tt.wantErr {
+				t.Errorf("StockLevel.Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			assert.Equal(t, tt.want, s.Quantity)
+		})
+	}
+}
+
+func TestStockMovement_AppendOnly(t *testing.T) {
+	m := &StockMovement{Base: Base{ID: uuid.New()}, Type: StockMovementSale, Quantity: -1}
+
+	assert.Equal(t, ErrStockMovementAppendOnly, m.BeforeUpdate(nil))
+	assert.Equal(t, ErrStockMovementAppendOnly, m.BeforeDelete(nil))
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+)
+
+type JobRunStatus string
+
+const (
+	JobRunStatusRunning   JobRunStatus = "running"
+	JobRunStatusSucceeded JobRunStatus = "succeeded"
+	JobRunStatusFailed    JobRunStatus = "failed"
+)
+
+// JobRun is a single run of a scheduled job
+type JobRun struct {
+	Base
+	Name       string       `json:"name" gorm:"type:varchar(100);not null;index"`
+	Status     JobRunStatus `json:"status" gorm:"type:varchar(20);not null"`
+	StartedAt  time.Time    `json:"started_at" gorm:"not null"`
+	FinishedAt *time.Time   `json:"finished_at"`
+	Message    string       `json:"message"`
+}
+
+// Finish sets the result of the run
+func (r *JobRun) Finish(message string, err error) {
+	now := time.Now()
+	r.FinishedAt = &now
+	r.Message = message
+	r.Status = JobRunStatusSucceeded
+	if err != nil {
+		r.Status = JobRunStatusFailed
+		r.Message = err.Error()
+	}
+}
+
+// Duration returns how long the run took, zero while it is running
+func (r *JobRun) Duration() time.Duration {
+	if r.FinishedAt == nil {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
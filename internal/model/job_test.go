@@ -0,0 +1,32 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestJobRun_Finish(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  JobRunStatus
+		wantMessage string
+	}{
+		{name: "succeeded", wantStatus: JobRunStatusSucceeded, wantMessage: "done"},
+		{name: "failed", err: errors.New("boom"), wantStatus: JobRunStatusFailed, wantMessage: "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &JobRun{Status: JobRunStatusRunning, StartedAt: time.Now().Add(-time.Second)}
+			assert.Equal(t, time.Duration(0), run.Duration())
+
+			run.Finish("done", tt.err)
+			assert.Equal(t, tt.wantStatus, run.Status)
+			assert.Equal(t, tt.wantMessage, run.Message)
+			assert.Equal(t, true, run.Duration() >= time.Second)
+		})
+	}
+}
@@ -37,6 +37,8 @@ type OrderItem struct {
 	ProductID uuid.UUID `json:"product_id"`
 	Product   Product   `json:"product"`
 
+	WarehouseID *uuid.UUID `json:"warehouse_id" gorm:"type:uuid"`
+
 	Price float64 `json:"price" gorm:"type:numeric(10,2)"`
 }
 
@@ -1,7 +1,11 @@
 package model
 
 import (
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"time"
+
 	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
 	"github.com/gosimple/slug"
 	"gorm.io/gorm"
 )
@@ -15,8 +19,29 @@ type Product struct {
 	Stock       *int64  `json:"stock"`
 	SKU         *string `json:"sku" gorm:"unique"`
 
+	// LowStockThreshold alerts admins when stock falls to or below it, LowStockAlerted
+	// is set until stock rises above the threshold again so an alert is sent once
+	LowStockThreshold *int64 `json:"lowStockThreshold"`
+	LowStockAlerted   bool   `json:"-" gorm:"default:false"`
+
+	// StockChangedAt queues the product for the stock alert job, which checks
+	// the alerts and notifications outside of the request changing the stock
+	StockChangedAt *time.Time `json:"-"`
+
 	Categories   []Category    `json:"categories" gorm:"many2many:product_categories; constraint:OnDelete:CASCADE"`
 	CategoriesID []strfmt.UUID `json:"categories_id" gorm:"-"`
+
+	// StockReason and StockChangedBy are recorded in the inventory ledger when stock is updated
+	StockReason    string     `json:"-" gorm:"-"`
+	StockChangedBy *uuid.UUID `json:"-" gorm:"-"`
+}
+
+// IsLowOnStock reports whether stock is at or below the low-stock threshold
+func (p *Product) IsLowOnStock() bool {
+	if p.LowStockThreshold == nil || p.Stock == nil {
+		return false
+	}
+	return *p.Stock <= *p.LowStockThreshold
 }
 
 // BeforeCreate hook
@@ -24,3 +49,9 @@ func (p *Product) BeforeCreate(tx *gorm.DB) error {
 	p.Slug = slug.Make(*p.Name + "-" + *p.SKU)
 	return nil
 }
+
+// BeforeDelete hook, products are archived by setting deleted_at since the
+// stock ledger refers to them and is append-only
+func (p *Product) BeforeDelete(tx *gorm.DB) error {
+	return httpErr.ProductArchivedOnlyError
+}
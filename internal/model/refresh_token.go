@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is a stored refresh token. Only the hash of the token is kept.
+// Every refresh rotates the token; the tokens of a login share a family, which
+// is the session shown to the user.
+type RefreshToken struct {
+	Base
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
+	User   User      `json:"-"`
+
+	FamilyID  uuid.UUID `json:"family_id" gorm:"type:uuid;not null;index"`
+	TokenHash string    `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+
+	SessionStartedAt time.Time  `json:"session_started_at" gorm:"not null"`
+	ExpiresAt        time.Time  `json:"expires_at" gorm:"not null"`
+	RotatedAt        *time.Time `json:"rotated_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+
+	UserAgent string `json:"user_agent" gorm:"type:varchar(255)"`
+	IP        string `json:"ip" gorm:"type:varchar(45)"`
+
+	// TwoFactorVerified is set when the session was opened with a second factor
+	TwoFactorVerified bool `json:"two_factor_verified" gorm:"not null;default:false"`
+}
+
+// IsRotated returns true when the token is already exchanged for a new one
+func (t *RefreshToken) IsRotated() bool {
+	return t.RotatedAt != nil
+}
+
+// IsActive returns true when the token can be used to refresh
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RotatedAt == nil && t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"github.com/google/uuid"
+)
+
+type Role string
+
+type Permission string
+
+const (
+	RoleSuperAdmin     Role = "super_admin"
+	RoleCatalogManager Role = "catalog_manager"
+	RoleOrderSupport   Role = "order_support"
+	RoleInventoryClerk Role = "inventory_clerk"
+)
+
+const (
+	PermissionCategoryWrite  Permission = "category:write"
+	PermissionProductWrite   Permission = "product:write"
+	PermissionInventoryRead  Permission = "inventory:read"
+	PermissionInventoryWrite Permission = "inventory:write"
+	PermissionOrderRead      Permission = "order:read"
+	PermissionUserRead       Permission = "user:read"
+	PermissionUserWrite      Permission = "user:write"
+	PermissionRoleWrite      Permission = "role:write"
+	PermissionJobRead        Permission = "job:read"
+)
+
+// RolePermissions are the permissions granted by each role, super_admin is
+// granted every permission
+var RolePermissions = map[Role][]Permission{
+	RoleSuperAdmin:     {},
+	RoleCatalogManager: {PermissionCategoryWrite, PermissionProductWrite, PermissionInventoryRead},
+	RoleOrderSupport:   {PermissionOrderRead, PermissionUserRead, PermissionUserWrite},
+	RoleInventoryClerk: {PermissionInventoryRead, PermissionInventoryWrite},
+}
+
+// IsValid returns true for a known role
+func (r Role) IsValid() bool {
+	_, ok := RolePermissions[r]
+	return ok
+}
+
+// HasPermission returns true when the role grants the permission
+func (r Role) HasPermission(permission Permission) bool {
+	if r == RoleSuperAdmin {
+		return true
+	}
+	for _, p := range RolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RolesWithPermission returns the roles which grant the permission
+func RolesWithPermission(permission Permission) []Role {
+	roles := []Role{}
+	for role := range RolePermissions {
+		if role.HasPermission(permission) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// UserRole is a role assigned to a user
+type UserRole struct {
+	Base
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_user_role"`
+	Role   Role      `json:"role" gorm:"type:varchar(50);not null;uniqueIndex:idx_user_role"`
+	// GrantedBy is the admin who assigned the role
+	GrantedBy *uuid.UUID `json:"granted_by" gorm:"type:uuid"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestUser_HasPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       User
+		permission Permission
+		want       bool
+	}{
+		{name: "noRoles", user: User{}, permission: PermissionProductWrite, want: false},
+		{name: "legacyAdmin", user: User{IsAdmin: true}, permission: PermissionRoleWrite, want: true},
+		{name: "superAdmin", user: User{Roles: []UserRole{{Role: RoleSuperAdmin}}}, permission: PermissionRoleWrite, want: true},
+		{name: "grantedByRole", user: User{Roles: []UserRole{{Role: RoleCatalogManager}}}, permission: PermissionProductWrite, want: true},
+		{name: "notGrantedByRole", user: User{Roles: []UserRole{{Role: RoleCatalogManager}}}, permission: PermissionInventoryWrite, want: false},
+		{name: "grantedBySecondRole", user: User{Roles: []UserRole{{Role: RoleOrderSupport}, {Role: RoleInventoryClerk}}}, permission: PermissionInventoryWrite, want: true},
+		{name: "unknownRole", user: User{Roles: []UserRole{{Role: "owner"}}}, permission: PermissionProductWrite, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.user.HasPermission(tt.permission))
+		})
+	}
+}
+
+func TestUser_IsStaff(t *testing.T) {
+	assert.Equal(t, false, (&User{}).IsStaff())
+	assert.Equal(t, true, (&User{IsAdmin: true}).IsStaff())
+	assert.Equal(t, true, (&User{Roles: []UserRole{{Role: RoleInventoryClerk}}}).IsStaff())
+}
+
+func TestUser_IsSuperAdmin(t *testing.T) {
+	assert.Equal(t, false, (&User{}).IsSuperAdmin())
+	assert.Equal(t, true, (&User{IsAdmin: true}).IsSuperAdmin())
+	assert.Equal(t, true, (&User{Roles: []UserRole{{Role: RoleSuperAdmin}}}).IsSuperAdmin())
+	assert.Equal(t, false, (&User{Roles: []UserRole{{Role: RoleCatalogManager}, {Role: RoleInventoryClerk}}}).IsSuperAdmin())
+}
+
+func TestUser_CanManage(t *testing.T) {
+	support := &User{Roles: []UserRole{{Role: RoleOrderSupport}}}
+	superAdmin := &User{Roles: []UserRole{{Role: RoleSuperAdmin}}}
+	tests := []struct {
+		name   string
+		actor  *User
+		target *User
+		want   bool
+	}{
+		{name: "customer", actor: support, target: &User{}, want: true},
+		{name: "sameRole", actor: support, target: &User{Roles: []UserRole{{Role: RoleOrderSupport}}}, want: true},
+		{name: "otherRole", actor: support, target: &User{Roles: []UserRole{{Role: RoleCatalogManager}}}, want: false},
+		{name: "superAdmin", actor: support, target: superAdmin, want: false},
+		{name: "legacyAdmin", actor: support, target: &User{IsAdmin: true}, want: false},
+		{name: "bySuperAdmin", actor: superAdmin, target: &User{IsAdmin: true}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.actor.CanManage(tt.target))
+		})
+	}
+}
+
+func TestRolesWithPermission(t *testing.T) {
+	roles := RolesWithPermission(PermissionInventoryWrite)
+	assert.Equal(t, 2, len(roles))
+	assert.Equal(t, false, Role("owner").IsValid())
+}
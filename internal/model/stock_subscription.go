@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StockSubscription is a "notify me when available" request of a user for an out-of-stock product
+type StockSubscription struct {
+	Base
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_stock_subscriptions_user_product"`
+	User       User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	ProductID  uuid.UUID  `json:"product_id" gorm:"type:uuid;not null;uniqueIndex:idx_stock_subscriptions_user_product"`
+	Product    Product    `json:"product" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	NotifiedAt *time.Time `json:"notified_at"`
+}
+
+// IsPending reports whether the user has not been notified yet
+func (s *StockSubscription) IsPending() bool {
+	return s.NotifiedAt == nil
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TwoFactor is the TOTP secret of a user. It is enabled once the user
+// confirms it with a code.
+type TwoFactor struct {
+	Base
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"`
+	User   User      `json:"-"`
+
+	Secret      string     `json:"-" gorm:"type:varchar(64);not null"`
+	ConfirmedAt *time.Time `json:"confirmed_at"`
+	// LastUsedStep is the time step of the last accepted code, a code is accepted once
+	LastUsedStep int64 `json:"-" gorm:"not null;default:0"`
+}
+
+// IsEnabled returns true when the secret is confirmed
+func (t *TwoFactor) IsEnabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// RecoveryCode is a single-use code which replaces a TOTP code. Only the hash
+// of the code is kept.
+type RecoveryCode struct {
+	Base
+	UserID   uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash string     `json:"-" gorm:"type:varchar(64);not null"`
+	UsedAt   *time.Time `json:"used_at"`
+}
@@ -1,11 +1,17 @@
 package model
 
 import (
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"time"
+
 	"gorm.io/gorm"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// maxPasswordLength is the longest password bcrypt hashes
+const maxPasswordLength = 72
+
 type User struct {
 	Base
 	FirstName *string `json:"firstName" gorm:"type:varchar(100); not null"`
@@ -14,11 +20,30 @@ type User struct {
 	Email     *string `json:"email" gorm:"unique" gorm:"type:varchar(100); not null"`
 	Password  string  `json:"password,omitempty" gorm:"type:varchar(100); not null"`
 	IsAdmin   bool    `json:"isAdmin" default:"false" gorm:"type:boolean"`
+
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+
+	// FailedLoginAttempts counts the failed logins since the last successful one
+	FailedLoginAttempts int        `json:"-" gorm:"not null;default:0"`
+	LockedUntil         *time.Time `json:"lockedUntil"`
+
+	// SuspendedAt is set while an admin has suspended the account
+	SuspendedAt      *time.Time `json:"suspendedAt"`
+	SuspensionReason string     `json:"suspensionReason" gorm:"type:varchar(255)"`
+
+	// TwoFactorVerified is set for a request whose token was issued after a
+	// second factor, it is not stored
+	TwoFactorVerified bool `json:"-" gorm:"-"`
+
+	Roles []UserRole `json:"roles,omitempty" gorm:"foreignKey:UserID"`
 }
 
 // BeforeCreate hook
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.Password != "" {
+		if len(u.Password) > maxPasswordLength {
+			return httpErr.PasswordTooLongError
+		}
 		hashed, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
 		if err != nil {
 			return err
@@ -33,3 +58,101 @@ func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 	return err == nil
 }
+
+// SetPassword hashes and sets the password of the user
+func (u *User) SetPassword(password string) error {
+	if len(password) > maxPasswordLength {
+		return httpErr.PasswordTooLongError
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.Password = string(hashed)
+	return nil
+}
+
+// IsEmailVerified returns true when the user confirmed the email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsLocked returns true when the account is locked at the given time
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// IsSuspended returns true while the account is suspended
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+// ActiveUserIDs selects the users who are neither deleted nor suspended, the
+// only ones the notifications are sent to
+const ActiveUserIDs = "SELECT id FROM users WHERE deleted_at IS NULL AND suspended_at IS NULL"
+
+// IsStaff returns true for the admins and the users with a role, who must log
+// in with a second factor to use the admin endpoints
+func (u *User) IsStaff() bool {
+	return u.IsAdmin || len(u.Roles) > 0
+}
+
+// IsSuperAdmin returns true for the IsAdmin users and the super admins
+func (u *User) IsSuperAdmin() bool {
+	if u.IsAdmin {
+		return true
+	}
+	for _, role := range u.Roles {
+		if role.Role == RoleSuperAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManage returns true when the user may suspend, unlock or reset the
+// password of the target. The accounts of the super admins and of the users
+// holding a role the user lacks need the role:write permission.
+func (u *User) CanManage(target *User) bool {
+	if u.IsSuperAdmin() || u.HasPermission(PermissionRoleWrite) {
+		return true
+	}
+	if target.IsSuperAdmin() {
+		return false
+	}
+	for _, targetRole := range target.Roles {
+		held := false
+		for _, role := range u.Roles {
+			if role.Role == targetRole.Role {
+				held = true
+			}
+		}
+		if !held {
+			return false
+		}
+	}
+	return true
+}
+
+// RoleNames returns the names of the roles of the user
+func (u *User) RoleNames() []string {
+	names := []string{}
+	for _, role := range u.Roles {
+		names = append(names, string(role.Role))
+	}
+	return names
+}
+
+// HasPermission returns true when a role of the user grants the permission.
+// IsAdmin users are treated as super admins.
+func (u *User) HasPermission(permission Permission) bool {
+	if u.IsAdmin {
+		return true
+	}
+	for _, role := range u.Roles {
+		if role.Role.HasPermission(permission) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	httpErr "patika-ecommerce/internal/httpErrors"
+
+	"github.com/google/uuid"
+)
+
+// Wishlist is a named list of products a user saved for later
+type Wishlist struct {
+	Base
+	UserID uuid.UUID `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_wishlists_user_name"`
+	User   User      `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	Name   string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_wishlists_user_name"`
+
+	// ShareToken is set while the wishlist is shared publicly
+	ShareToken *string `json:"share_token" gorm:"type:varchar(64);unique"`
+
+	Items []WishlistItem `json:"items" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type WishlistItem struct {
+	Base
+	WishlistID uuid.UUID `json:"wishlist_id" gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_items_wishlist_product"`
+	ProductID  uuid.UUID `json:"product_id" gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_items_wishlist_product"`
+	Product    Product   `json:"product" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+
+	// PriceWhenAdded is the product price at the time it was added
+	PriceWhenAdded float64 `json:"price_when_added" gorm:"type:decimal(20,2);not null"`
+}
+
+// IsShared reports whether the wishlist has a public share link
+func (w *Wishlist) IsShared() bool {
+	return w.ShareToken != nil
+}
+
+// GetItemByID returns wishlist item by id
+func (w *Wishlist) GetItemByID(id uuid.UUID) (*WishlistItem, error) {
+	for _, item := range w.Items {
+		if item.ID == id {
+			return &item, nil
+		}
+	}
+	return nil, httpErr.WishlistItemNotFoundError
+}
+
+// PriceDrop returns how much cheaper the product is than when it was added
+func (i *WishlistItem) PriceDrop() float64 {
+	if i.Product.Price >= i.PriceWhenAdded {
+		return 0
+	}
+	return i.PriceWhenAdded - i.Product.Price
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestWishlistItem_PriceDrop(t *testing.T) {
+	tests := []struct {
+		name           string
+		priceWhenAdded float64
+		price          float64
+		want           float64
+	}{
+		{name: "priceDropped", priceWhenAdded: 100, price: 80, want: 20},
+		{name: "priceUnchanged", priceWhenAdded: 100, price: 100, want: 0},
+		{name: "priceIncreased", priceWhenAdded: 100, price: 120, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &WishlistItem{PriceWhenAdded: tt.priceWhenAdded, Product: Product{Price: tt.price}}
+			assert.Equal(t, tt.want, item.PriceDrop())
+		})
+	}
+}
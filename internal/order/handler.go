@@ -1,10 +1,12 @@
 package order
 
 import (
+	"context"
 	"patika-ecommerce/internal/api"
 	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/inventory"
 	"patika-ecommerce/internal/model"
-	"patika-ecommerce/pkg/config"
+	"patika-ecommerce/pkg/metrics"
 	paginationHelper "patika-ecommerce/pkg/pagination"
 	common "patika-ecommerce/pkg/utils"
 
@@ -16,14 +18,17 @@ import (
 )
 
 type orderHandler struct {
-	orderRepo OrderRepositoryInterface
+	orderRepo     OrderRepositoryInterface
+	stockObserver inventory.StockObserver
 }
 
-func NewOrderHandler(r *gin.RouterGroup, cfg *config.Config, orderRepo *OrderRepository) {
-	handler := &orderHandler{orderRepo: orderRepo}
+// NewOrderHandler creates a new order handler. The checkout middlewares run
+// in the given order before an order is completed.
+func NewOrderHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, orderRepo *OrderRepository, stockObserver inventory.StockObserver, checkoutMiddlewares ...gin.HandlerFunc) {
+	handler := &orderHandler{orderRepo: orderRepo, stockObserver: stockObserver}
 
-	r.Use(mw.AuthenticationMiddleware(cfg.JWTConfig.SecretKey))
-	r.POST("", handler.completeOrder)
+	r.Use(authMiddleware)
+	r.POST("", append(checkoutMiddlewares, handler.completeOrder)...)
 	r.GET("", mw.PaginationMiddleware(), handler.listOrders)
 	r.PUT("/:id", handler.cancelOrder)
 }
@@ -49,11 +54,13 @@ func (r *orderHandler) completeOrder(c *gin.Context) {
 		c.JSON(httpErr.ErrorResponse(err))
 	}
 
-	order, err := r.orderRepo.CompleteOrder(user, cartId)
+	order, err := r.orderRepo.CompleteOrder(c.Request.Context(), user, cartId)
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
 	}
+	r.stockChanged(c.Request.Context(), order)
+	metrics.OrderCompleted(order.TotalPrice)
 
 	c.JSON(200, OrderToOrderResponse(order))
 }
@@ -63,7 +70,7 @@ func (r *orderHandler) listOrders(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 	pagination := c.MustGet("pagination").(*paginationHelper.Pagination)
 
-	data, err := r.orderRepo.GetOrdersByUser(user, pagination)
+	data, err := r.orderRepo.GetOrdersByUser(c.Request.Context(), user, pagination)
 
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
@@ -79,13 +86,34 @@ func (r *orderHandler) cancelOrder(c *gin.Context) {
 
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(httpErr.ErrorResponse(err))
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
 		return
 	}
 
-	if err := r.orderRepo.CancelOrder(id, user); err != nil {
+	order, err := r.orderRepo.CancelOrder(c.Request.Context(), id, user)
+	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
 	}
+	r.stockChanged(c.Request.Context(), order)
+	metrics.OrderCancelled(order.TotalPrice)
+
 	c.JSON(200, map[string]string{"message": "order cancelled"})
 }
+
+// stockChanged tells the stock observer about the products of the order
+func (r *orderHandler) stockChanged(ctx context.Context, order *model.Order) {
+	if r.stockObserver == nil {
+		return
+	}
+
+	productIDs := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for _, item := range order.Items {
+		if !seen[item.ProductID] {
+			seen[item.ProductID] = true
+			productIDs = append(productIDs, item.ProductID)
+		}
+	}
+	r.stockObserver.StockChanged(ctx, productIDs...)
+}
@@ -2,7 +2,7 @@ package order
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -15,6 +15,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 func getOrderCompletePayload(cartID string) []byte {
@@ -309,7 +310,7 @@ func Test_orderHandler_cancelOrder(t *testing.T) {
 }
 
 var (
-	OrderNotFoundError = fmt.Errorf("order not found")
+	OrderNotFoundError = httpErr.OrderNotFoundError
 )
 
 type mockOrderRepo struct {
@@ -320,10 +321,10 @@ type mockOrderRepo struct {
 }
 
 var (
-	CartNotFoundError = fmt.Errorf("cart not found")
+	CartNotFoundError = gorm.ErrRecordNotFound
 )
 
-func (r *mockOrderRepo) CompleteOrder(user *model.User, cartId uuid.UUID) (*model.Order, error) {
+func (r *mockOrderRepo) CompleteOrder(ctx context.Context, user *model.User, cartId uuid.UUID) (*model.Order, error) {
 	for _, item := range r.carts {
 		if item.ID == cartId && item.UserID == user.ID {
 
@@ -359,7 +360,7 @@ func (r *mockOrderRepo) CompleteOrder(user *model.User, cartId uuid.UUID) (*mode
 }
 
 // GetOrdersByUser returns all orders of a user
-func (r *mockOrderRepo) GetOrdersByUser(user *model.User, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+func (r *mockOrderRepo) GetOrdersByUser(ctx context.Context, user *model.User, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
 	isExist := false
 	for _, order := range r.orders {
 		if order.UserID == user.ID {
@@ -368,7 +369,7 @@ func (r *mockOrderRepo) GetOrdersByUser(user *model.User, pagination *pagination
 		}
 	}
 	if !isExist {
-		return nil, fmt.Errorf("user not found")
+		return nil, gorm.ErrRecordNotFound
 	}
 
 	orders := []*model.Order{}
@@ -384,12 +385,12 @@ func (r *mockOrderRepo) GetOrdersByUser(user *model.User, pagination *pagination
 }
 
 // GetOrderByIdAndUser returns an order by id and user
-func (r *mockOrderRepo) GetOrderByIdAndUser(user *model.User, id uuid.UUID) (*model.Order, error) {
+func (r *mockOrderRepo) GetOrderByIdAndUser(ctx context.Context, user *model.User, id uuid.UUID) (*model.Order, error) {
 	return nil, nil
 }
 
 // CancelOrder cancels an order
-func (r *mockOrderRepo) CancelOrder(id uuid.UUID, user *model.User) error {
+func (r *mockOrderRepo) CancelOrder(ctx context.Context, id uuid.UUID, user *model.User) (*model.Order, error) {
 	for _, order := range r.orders {
 		if order.ID == id && order.UserID == user.ID && order.Status == model.OrderStatusCompleted {
 			if order.IsCancelable() {
@@ -399,10 +400,10 @@ func (r *mockOrderRepo) CancelOrder(id uuid.UUID, user *model.User) error {
 					*item.Product.Stock += 1
 				}
 
-				return nil
+				return &order, nil
 			}
-			return httpErr.OrderCannotBeCanceledError
+			return nil, httpErr.OrderCannotBeCanceledError
 		}
 	}
-	return OrderNotFoundError
+	return nil, OrderNotFoundError
 }
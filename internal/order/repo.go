@@ -1,10 +1,13 @@
 package order
 
 import (
-	"fmt"
+	"context"
+	cartHelper "patika-ecommerce/internal/cart"
 	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/inventory"
 	"patika-ecommerce/internal/model"
 	paginationHelper "patika-ecommerce/pkg/pagination"
+	"patika-ecommerce/pkg/tracing"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -13,41 +16,28 @@ import (
 )
 
 type OrderRepositoryInterface interface {
-	CompleteOrder(user *model.User, cartId uuid.UUID) (*model.Order, error)
-	GetOrdersByUser(user *model.User, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
-	GetOrderByIdAndUser(user *model.User, id uuid.UUID) (*model.Order, error)
-	CancelOrder(id uuid.UUID, user *model.User) error
+	CompleteOrder(ctx context.Context, user *model.User, cartId uuid.UUID) (*model.Order, error)
+	GetOrdersByUser(ctx context.Context, user *model.User, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
+	GetOrderByIdAndUser(ctx context.Context, user *model.User, id uuid.UUID) (*model.Order, error)
+	CancelOrder(ctx context.Context, id uuid.UUID, user *model.User) (*model.Order, error)
 }
 
 type OrderRepository struct {
 	db *gorm.DB
 }
 
-func (r *OrderRepository) Migration() {
-	r.db.AutoMigrate(&model.Order{})
-}
-
 func NewOrderRepository(db *gorm.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
-type OrderItemRepository struct {
-	db *gorm.DB
-}
-
-func (r *OrderItemRepository) Migration() {
-	r.db.AutoMigrate(&model.OrderItem{})
-}
-
-func NewOrderItemRepository(db *gorm.DB) *OrderItemRepository {
-	return &OrderItemRepository{db: db}
-}
-
 // CompleteOrder
-func (r *OrderRepository) CompleteOrder(user *model.User, cartId uuid.UUID) (*model.Order, error) {
-	zap.L().Debug("order.repo.CompleteOrder", zap.Reflect("user", user), zap.Reflect("cartId", cartId))
+func (r *OrderRepository) CompleteOrder(ctx context.Context, user *model.User, cartId uuid.UUID) (*model.Order, error) {
+	ctx, span := tracing.Start(ctx, "order.repo.CompleteOrder")
+	defer span.End()
+
+	zap.L().Debug("order.repo.CompleteOrder", tracing.Field(ctx), zap.Reflect("user", user), zap.Reflect("cartId", cartId))
 
-	tx := r.db.Begin()
+	tx := r.db.WithContext(ctx).Begin()
 	cart := model.Cart{}
 
 	// get cart by id and user id
@@ -55,10 +45,16 @@ func (r *OrderRepository) CompleteOrder(user *model.User, cartId uuid.UUID) (*mo
 		Clauses(clause.Locking{Strength: "UPDATE"}).
 		Preload("Items.Product").
 		Where("id = ? AND user_id = ? AND status = ?", cartId, user.ID, model.CartStatusCreated).
-		Find(&cart).Error; err != nil {
+		First(&cart).Error; err != nil {
 		tx.Rollback()
-		return nil, err
+		return nil, httpErr.NotFoundAs(err, httpErr.CartNotFoundError)
 	}
+	// refuse checkout when the cart changed since it was reviewed
+	if issues := cart.Revalidate(); len(issues) > 0 {
+		tx.Rollback()
+		return nil, httpErr.CartChangedError.WithDetails(cartHelper.CartIssuesToCartIssueResponse(issues))
+	}
+
 	// create order from cart
 	order := model.Order{
 		UserID:     cart.UserID,
@@ -74,32 +70,28 @@ func (r *OrderRepository) CompleteOrder(user *model.User, cartId uuid.UUID) (*mo
 
 	// create order items from cart items
 	for _, item := range cart.Items {
-		product := item.Product
-		if *product.Stock < item.Quantity {
-			tx.Rollback()
-			return nil, fmt.Errorf("product %s stock is not enough", *item.Product.Name)
-		}
-		newProd := &model.Product{}
-		// update product stock
-		if err := tx.Model(&model.Product{}).
-			Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("id = ?", item.Product.ID).
-			First(&newProd).
-			Update("stock", gorm.Expr("stock - ?", item.Quantity)).Error; err != nil {
+		// deduct stock from the allocated warehouses
+		allocations, err := inventory.DeductForOrder(tx, item.ProductID, item.Quantity, order.ID)
+		if err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 		// create order item
-		for i := 0; i < int(item.Quantity); i++ {
-			orderItem := &model.OrderItem{
-				OrderID:   order.ID,
-				ProductID: item.ProductID,
-				Price:     item.Price,
-			}
-
-			if err := tx.Create(orderItem).Error; err != nil {
-				tx.Rollback()
-				return nil, err
+		for _, allocation := range allocations {
+			warehouseID := allocation.WarehouseID
+			for i := 0; i < int(allocation.Quantity); i++ {
+				orderItem := &model.OrderItem{
+					OrderID:     order.ID,
+					ProductID:   item.ProductID,
+					WarehouseID: &warehouseID,
+					Price:       item.Price,
+				}
+
+				if err := tx.Create(orderItem).Error; err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+				order.Items = append(order.Items, *orderItem)
 			}
 		}
 	}
@@ -115,38 +107,47 @@ func (r *OrderRepository) CompleteOrder(user *model.User, cartId uuid.UUID) (*mo
 }
 
 // GetOrdersByUser returns all orders of a user
-func (r *OrderRepository) GetOrdersByUser(user *model.User, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
-	zap.L().Debug("order.repo.GetOrdersByUser", zap.Reflect("user", user), zap.Reflect("pagination", pagination))
+func (r *OrderRepository) GetOrdersByUser(ctx context.Context, user *model.User, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	ctx, span := tracing.Start(ctx, "order.repo.GetOrdersByUser")
+	defer span.End()
+
+	zap.L().Debug("order.repo.GetOrdersByUser", tracing.Field(ctx), zap.Reflect("user", user), zap.Reflect("pagination", pagination))
 
 	var (
 		orders    []*model.Order
 		totalRows int64
 	)
 
-	query := r.db.Model(&model.Order{}).Where("user_id = ?", user.ID).Count(&totalRows).Preload("Items.Product")
-	query.Scopes(paginationHelper.Paginate(totalRows, pagination, r.db)).Find(&orders)
+	query := r.db.WithContext(ctx).Model(&model.Order{}).Where("user_id = ?", user.ID).Count(&totalRows).Preload("Items.Product")
+	query.Scopes(paginationHelper.Paginate(totalRows, pagination, r.db.WithContext(ctx))).Find(&orders)
 	pagination.Rows = OrdersToOrderDetailedResponse(orders)
 
 	return pagination, nil
 }
 
 // GetOrderByIdAndUser returns an order by id and user
-func (r *OrderRepository) GetOrderByIdAndUser(user *model.User, id uuid.UUID) (*model.Order, error) {
-	zap.L().Debug("order.repo.GetOrderByIdAndUser", zap.Reflect("user", user), zap.Reflect("id", id))
+func (r *OrderRepository) GetOrderByIdAndUser(ctx context.Context, user *model.User, id uuid.UUID) (*model.Order, error) {
+	ctx, span := tracing.Start(ctx, "order.repo.GetOrderByIdAndUser")
+	defer span.End()
+
+	zap.L().Debug("order.repo.GetOrderByIdAndUser", tracing.Field(ctx), zap.Reflect("user", user), zap.Reflect("id", id))
 
 	var order model.Order
-	if err := r.db.Preload("Items.Product").Where("id = ? AND user_id = ?", id, user.ID).First(&order).Error; err != nil {
-		return nil, err
+	if err := r.db.WithContext(ctx).Preload("Items.Product").Where("id = ? AND user_id = ?", id, user.ID).First(&order).Error; err != nil {
+		return nil, httpErr.NotFoundAs(err, httpErr.OrderNotFoundError)
 	}
 
 	return &order, nil
 }
 
 // CancelOrder cancels an order
-func (r *OrderRepository) CancelOrder(id uuid.UUID, user *model.User) error {
-	zap.L().Debug("order.repo.CancelOrder", zap.Reflect("user", user), zap.Reflect("id", id))
+func (r *OrderRepository) CancelOrder(ctx context.Context, id uuid.UUID, user *model.User) (*model.Order, error) {
+	ctx, span := tracing.Start(ctx, "order.repo.CancelOrder")
+	defer span.End()
+
+	zap.L().Debug("order.repo.CancelOrder", tracing.Field(ctx), zap.Reflect("user", user), zap.Reflect("id", id))
 
-	tx := r.db.Begin()
+	tx := r.db.WithContext(ctx).Begin()
 	var order model.Order
 	// get order by id and user id
 	if err := tx.
@@ -155,32 +156,26 @@ func (r *OrderRepository) CancelOrder(id uuid.UUID, user *model.User) error {
 		Where("id = ? AND user_id = ? AND status = ?", id, user.ID, model.OrderStatusCompleted).
 		First(&order).Error; err != nil {
 		tx.Rollback()
-		return err
+		return nil, httpErr.NotFoundAs(err, httpErr.OrderNotFoundError)
 	}
 	// check if order is cancelable
 	if !order.IsCancelable() {
-		return httpErr.OrderCannotBeCanceledError
+		tx.Rollback()
+		return nil, httpErr.OrderCannotBeCanceledError
 	}
 
-	// update products stock
-	newProd := &model.Product{}
-	for _, item := range order.Items {
-		if err := tx.Model(&model.Product{}).
-			Clauses(clause.Locking{Strength: "UPDATE"}).
-			Where("id = ?", item.Product.ID).
-			First(&newProd).
-			Update("stock", gorm.Expr("stock + ?", 1)).Error; err != nil {
-			tx.Rollback()
-			return err
-		}
+	// put products stock back to the warehouses
+	if err := inventory.RestoreForOrder(tx, &order); err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 	// update order status
 	order.Status = model.OrderStatusCanceled
 	if err := tx.Save(&order).Error; err != nil {
 		tx.Rollback()
-		return err
+		return nil, err
 	}
 
 	tx.Commit()
-	return nil
+	return &order, nil
 }
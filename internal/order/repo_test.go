@@ -0,0 +1,49 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func NewMock() (DB *gorm.DB, mock sqlmock.Sqlmock) {
+	var (
+		db *sql.DB
+	)
+
+	db, mock, _ = sqlmock.New()
+
+	DB, _ = gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+
+	return DB, mock
+}
+
+func TestOrderRepository_CompleteOrder_cartNotFound(t *testing.T) {
+	db, mock := NewMock()
+	repo := NewOrderRepository(db)
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+	cartID := uuid.New()
+
+	// a missing, foreign or paid cart is not checked out as an empty order
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "carts" WHERE id = $1 AND user_id = $2 AND status = $3`)).
+		WithArgs(cartID, user.ID, model.CartStatusCreated).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "status"}))
+	mock.ExpectRollback()
+
+	order, err := repo.CompleteOrder(context.Background(), user, cartID)
+	assert.Equal(t, (*model.Order)(nil), order)
+	assert.Equal(t, httpErr.CartNotFoundError, err)
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,50 @@
+package privacy
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"patika-ecommerce/internal/auth"
+	"patika-ecommerce/internal/cart"
+	"patika-ecommerce/internal/order"
+	"patika-ecommerce/internal/stockalert"
+	user "patika-ecommerce/internal/user"
+	"patika-ecommerce/internal/wishlist"
+	"time"
+)
+
+// writeArchive writes the personal data as a ZIP archive with one JSON
+// document per kind of data
+func writeArchive(w io.Writer, data *PersonalData, now time.Time) error {
+	carts := []interface{}{}
+	for i := range data.Carts {
+		carts = append(carts, cart.CartToCartResponse(&data.Carts[i]))
+	}
+
+	documents := []struct {
+		name    string
+		content interface{}
+	}{
+		{"export.json", map[string]interface{}{"userId": data.User.ID, "createdAt": now}},
+		{"profile.json", user.UserToProfileResponse(data.User)},
+		{"carts.json", carts},
+		{"orders.json", order.OrdersToOrderDetailedResponse(data.Orders)},
+		{"wishlists.json", wishlist.WishlistsToWishlistResponse(data.Wishlists)},
+		{"stock_subscriptions.json", stockalert.StockSubscriptionsToResponse(data.Subscriptions)},
+		{"sessions.json", auth.RefreshTokensToSessionResponse(data.Sessions)},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, document := range documents {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: document.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(document.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,112 @@
+package privacy
+
+import (
+	"patika-ecommerce/internal/api"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+)
+
+type privacyHandler struct {
+	privacyService PrivacyServiceInterface
+}
+
+// NewPrivacyHandler creates the handler of the personal data export and erasure endpoints
+func NewPrivacyHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, privacyService PrivacyServiceInterface) {
+	handler := &privacyHandler{privacyService: privacyService}
+
+	r.Use(authMiddleware)
+	r.GET("/data-exports", handler.getExports)
+	r.POST("/data-exports", handler.requestExport)
+	r.GET("/data-exports/:id", handler.getExport)
+	r.GET("/data-exports/:id/download", handler.downloadExport)
+	r.POST("/erasure", handler.erase)
+}
+
+// getExports lists the data exports of the user
+func (h *privacyHandler) getExports(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	exports, err := h.privacyService.GetExports(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, DataExportsToResponse(exports))
+}
+
+// requestExport queues an export of the personal data of the user
+func (h *privacyHandler) requestExport(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	export, err := h.privacyService.RequestExport(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(202, DataExportToResponse(export))
+}
+
+// getExport returns a data export of the user
+func (h *privacyHandler) getExport(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	export, err := h.privacyService.GetExport(c.Request.Context(), user, id)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, DataExportToResponse(export))
+}
+
+// downloadExport sends the archive of a ready data export of the user
+func (h *privacyHandler) downloadExport(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	path, err := h.privacyService.GetExportFile(c.Request.Context(), user, id)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.FileAttachment(path, "personal-data-"+id.String()+".zip")
+}
+
+// erase anonymizes the account of the user
+func (h *privacyHandler) erase(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	var reqBody api.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := h.privacyService.EraseUser(c.Request.Context(), user, *reqBody.Password); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
@@ -0,0 +1,72 @@
+package privacy
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"patika-ecommerce/internal/model"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func Test_privacyHandler_downloadExport(t *testing.T) {
+	user := newUser(t)
+	s := NewPrivacyService(&mockPrivacyRepository{users: []*model.User{user}}, t.TempDir(), time.Hour)
+	handler := &privacyHandler{privacyService: s}
+	export, _ := s.RequestExport(context.Background(), user)
+
+	download := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("GET", "/me/data-exports/"+id+"/download", nil)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+		c.Set("user", user)
+		handler.downloadExport(c)
+		return w
+	}
+
+	assert.Equal(t, http.StatusConflict, download(export.ID.String()).Code)
+	assert.Equal(t, http.StatusNotFound, download("abc").Code)
+
+	s.BuildPendingExports(context.Background())
+	w := download(export.ID.String())
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, true, strings.Contains(w.Header().Get("Content-Disposition"), "personal-data-"))
+	assert.Equal(t, "PK", w.Body.String()[:2])
+}
+
+func Test_privacyHandler_erase(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    int
+	}{
+		{name: "erase_Failed_missingPassword", payload: `{}`, want: http.StatusBadRequest},
+		{name: "erase_Failed_wrongPassword", payload: `{"password": "wrong"}`, want: http.StatusBadRequest},
+		{name: "erase_Succeed", payload: `{"password": "secret"}`, want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newUser(t)
+			handler := &privacyHandler{privacyService: NewPrivacyService(&mockPrivacyRepository{users: []*model.User{user}}, t.TempDir(), time.Hour)}
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/me/erasure", nil)
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(tt.payload)))
+			c.Set("user", user)
+			handler.erase(c)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
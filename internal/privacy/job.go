@@ -0,0 +1,34 @@
+package privacy
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExportJob builds the pending personal data exports and purges the expired ones
+type ExportJob struct {
+	service PrivacyServiceInterface
+}
+
+// NewExportJob creates a new export job
+func NewExportJob(service PrivacyServiceInterface) *ExportJob {
+	return &ExportJob{service: service}
+}
+
+// Name returns the name of the job
+func (j *ExportJob) Name() string {
+	return "data_exports"
+}
+
+// Run builds the pending exports and purges the expired ones
+func (j *ExportJob) Run(ctx context.Context) (string, error) {
+	purged, err := j.service.PurgeExpiredExports(ctx)
+	if err != nil {
+		return "", err
+	}
+	built, failed, err := j.service.BuildPendingExports(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("built %d, failed %d, purged %d", built, failed, purged), nil
+}
@@ -0,0 +1,249 @@
+package privacy
+
+import (
+	"context"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PrivacyRepositoryInterface interface {
+	GetUser(ctx context.Context, id uuid.UUID) (*model.User, error)
+	GetPersonalData(ctx context.Context, userID uuid.UUID) (*PersonalData, error)
+	InsertExport(ctx context.Context, export *model.DataExport) error
+	UpdateExport(ctx context.Context, export *model.DataExport) error
+	GetExportsByUser(ctx context.Context, userID uuid.UUID) ([]model.DataExport, error)
+	GetExportByIDAndUser(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*model.DataExport, error)
+	GetPendingExports(ctx context.Context) ([]model.DataExport, error)
+	DeleteExpiredExports(ctx context.Context, now time.Time) ([]model.DataExport, error)
+	EraseUser(ctx context.Context, userID uuid.UUID, now time.Time) error
+}
+
+// PersonalData is everything stored about a user
+type PersonalData struct {
+	User          *model.User
+	Carts         []model.Cart
+	Orders        []*model.Order
+	Wishlists     []model.Wishlist
+	Subscriptions []model.StockSubscription
+	Sessions      []model.RefreshToken
+}
+
+type PrivacyRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivacyRepository(db *gorm.DB) *PrivacyRepository {
+	return &PrivacyRepository{db: db}
+}
+
+// GetUser returns a user which is not deleted with the roles
+func (r *PrivacyRepository) GetUser(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "privacy.repo.GetUser")
+	defer span.End()
+
+	zap.L().Debug("privacy.repo.GetUser", tracing.Field(ctx), zap.Reflect("id", id))
+
+	user := &model.User{}
+	if err := r.db.WithContext(ctx).Preload("Roles").Where("id = ? AND deleted_at IS NULL", id).First(user).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetPersonalData collects the stored data of a user
+func (r *PrivacyRepository) GetPersonalData(ctx context.Context, userID uuid.UUID) (*PersonalData, error) {
+	ctx, span := tracing.Start(ctx, "privacy.repo.GetPersonalData")
+	defer span.End()
+
+	zap.L().Debug("privacy.repo.GetPersonalData", tracing.Field(ctx), zap.Reflect("userID", userID))
+
+	user, err := r.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	data := &PersonalData{User: user}
+
+	if err := r.db.WithContext(ctx).Preload("Items.Product").Where("user_id = ?", userID).
+		Order("created_at ASC").Find(&data.Carts).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.WithContext(ctx).Preload("Items.Product").Where("user_id = ?", userID).
+		Order("created_at ASC").Find(&data.Orders).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.WithContext(ctx).Preload("Items.Product").Where("user_id = ?", userID).
+		Order("created_at ASC").Find(&data.Wishlists).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.WithContext(ctx).Preload("Product").Where("user_id = ?", userID).
+		Order("created_at ASC").Find(&data.Subscriptions).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).
+		Order("created_at ASC").Find(&data.Sessions).Error; err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// InsertExport inserts a new data export
+func (r *PrivacyRepository) InsertExport(ctx context.Context, export *model.DataExport) error {
+	ctx, span := tracing.Start(ctx, "privacy.repo.InsertExport")
+	defer span.End()
+
+	zap.L().Debug("privacy.repo.InsertExport", tracing.Field(ctx), zap.Reflect("export", export))
+
+	return r.db.WithContext(ctx).Omit("User").Create(export).Error
+}
+
+// UpdateExport saves the state of a data export
+func (r *PrivacyRepository) UpdateExport(ctx context.Context, export *model.DataExport) error {
+	ctx, span := tracing.Start(ctx, "privacy.repo.UpdateExport")
+	defer span.End()
+
+	zap.L().Debug("privacy.repo.UpdateExport", tracing.Field(ctx), zap.Reflect("export", export))
+
+	return r.db.WithContext(ctx).Omit("User").Save(export).Error
+}
+
+// GetExportsByUser returns the data exports of a user, newest first
+func (r *PrivacyRepository) GetExportsByUser(ctx context.Context, userID uuid.UUID) ([]model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "privacy.repo.GetExportsByUser")
+	defer span.End()
+
+	zap.L().Debug("privacy.repo.GetExportsByUser", tracing.Field(ctx), zap.Reflect("userID", userID))
+
+	var exports []model.DataExport
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+// GetExportByIDAndUser returns a data export of a user
+func (r *PrivacyRepository) GetExportByIDAndUser(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "privacy.repo.GetExportByIDAndUser")
+	defer span.End()
+
+	zap.L().Debug("privacy.repo.GetExportByIDAndUser", tracing.Field(ctx), zap.Reflect("userID", userID), zap.Reflect("id", id))
+
+	export := &model.DataExport{}
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(export).Error; err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// GetPendingExports returns the data exports to build, oldest first
+func (r *PrivacyRepository) GetPendingExports(ctx context.Context) ([]model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "privacy.repo.GetPendingExports")
+	defer span.End()
+
+	zap.L().Debug("privacy.repo.GetPendingExports", tracing.Field(ctx))
+
+	var exports []model.DataExport
+	if err := r.db.WithContext(ctx).Where("status = ?", model.DataExportStatusPending).Order("created_at ASC").Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+// DeleteExpiredExports deletes the data exports expired before now and
+// returns them, so their archives can be removed
+func (r *PrivacyRepository) DeleteExpiredExports(ctx context.Context, now time.Time) ([]model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "privacy.repo.DeleteExpiredExports")
+	defer span.End()
+
+	zap.L().Debug("privacy.repo.DeleteExpiredExports", tracing.Field(ctx), zap.Time("now", now))
+
+	var exports []model.DataExport
+	if err := r.db.WithContext(ctx).Where("expires_at < ?", now).Find(&exports).Error; err != nil {
+		return nil, err
+	}
+	if len(exports) == 0 {
+		return exports, nil
+	}
+
+	ids := []uuid.UUID{}
+	for _, export := range exports {
+		ids = append(ids, export.ID)
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&model.DataExport{}).Error; err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+// EraseUser anonymizes a user. The orders and the carts they were placed
+// from are kept for legal retention, they reference the anonymized user.
+// Everything else personal is deleted and the sessions are revoked.
+func (r *PrivacyRepository) EraseUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	ctx, span := tracing.Start(ctx, "privacy.repo.EraseUser")
+	defer span.End()
+
+	zap.L().Debug("privacy.repo.EraseUser", tracing.Field(ctx), zap.Reflect("userID", userID))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	anonymous := "deleted-" + userID.String()
+	result := tx.Model(&model.User{}).Where("id = ? AND deleted_at IS NULL", userID).UpdateColumns(map[string]interface{}{
+		"first_name":            "Deleted",
+		"last_name":             "User",
+		"username":              anonymous,
+		"email":                 anonymous + "@erased.invalid",
+		"password":              "",
+		"is_admin":              false,
+		"email_verified_at":     nil,
+		"failed_login_attempts": 0,
+		"locked_until":          nil,
+		"suspended_at":          nil,
+		"suspension_reason":     "",
+		"updated_at":            now,
+		"deleted_at":            now,
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := tx.Model(&model.RefreshToken{}).Where("user_id = ?", userID).UpdateColumns(map[string]interface{}{
+		"user_agent": "",
+		"ip":         "",
+		"revoked_at": gorm.Expr("COALESCE(revoked_at, ?)", now),
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("wishlist_id IN (?)", tx.Model(&model.Wishlist{}).Select("id").Where("user_id = ?", userID)).
+		Delete(&model.WishlistItem{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, owned := range []interface{}{
+		&model.Wishlist{},
+		&model.StockSubscription{},
+		&model.ExternalIdentity{},
+		&model.TwoFactor{},
+		&model.RecoveryCode{},
+		&model.UserToken{},
+		&model.UserRole{},
+		&model.DataExport{},
+	} {
+		if err := tx.Where("user_id = ?", userID).Delete(owned).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
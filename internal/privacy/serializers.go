@@ -0,0 +1,39 @@
+package privacy
+
+import (
+	"patika-ecommerce/internal/api"
+	"patika-ecommerce/internal/model"
+	common "patika-ecommerce/pkg/utils"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// DataExportToResponse converts a data export to a data export response
+func DataExportToResponse(export *model.DataExport) *api.DataExportResponse {
+	return &api.DataExportResponse{
+		ID:          common.UUIDToStrfmt(export.ID),
+		Status:      string(export.Status),
+		Size:        export.Size,
+		CreatedAt:   strfmt.DateTime(export.CreatedAt),
+		CompletedAt: dateTimeValue(export.CompletedAt),
+		ExpiresAt:   dateTimeValue(export.ExpiresAt),
+	}
+}
+
+// DataExportsToResponse converts data exports to data export responses
+func DataExportsToResponse(exports []model.DataExport) []*api.DataExportResponse {
+	response := []*api.DataExportResponse{}
+	for i := range exports {
+		response = append(response, DataExportToResponse(&exports[i]))
+	}
+	return response
+}
+
+func dateTimeValue(t *time.Time) *strfmt.DateTime {
+	if t == nil {
+		return nil
+	}
+	value := strfmt.DateTime(*t)
+	return &value
+}
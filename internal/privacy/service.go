@@ -0,0 +1,227 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultExportFolder    = "./exports"
+	defaultExportRetention = 72 * time.Hour
+)
+
+type PrivacyService struct {
+	repo      PrivacyRepositoryInterface
+	folder    string
+	retention time.Duration
+	now       func() time.Time
+}
+
+type PrivacyServiceInterface interface {
+	RequestExport(ctx context.Context, user *model.User) (*model.DataExport, error)
+	GetExports(ctx context.Context, user *model.User) ([]model.DataExport, error)
+	GetExport(ctx context.Context, user *model.User, id uuid.UUID) (*model.DataExport, error)
+	GetExportFile(ctx context.Context, user *model.User, id uuid.UUID) (string, error)
+	BuildPendingExports(ctx context.Context) (int, int, error)
+	PurgeExpiredExports(ctx context.Context) (int, error)
+	EraseUser(ctx context.Context, user *model.User, password string) error
+}
+
+// NewPrivacyService creates a new PrivacyService keeping the archives in the
+// folder for the retention time
+func NewPrivacyService(repo PrivacyRepositoryInterface, folder string, retention time.Duration) *PrivacyService {
+	if folder == "" {
+		folder = defaultExportFolder
+	}
+	if retention <= 0 {
+		retention = defaultExportRetention
+	}
+	return &PrivacyService{repo: repo, folder: folder, retention: retention, now: time.Now}
+}
+
+// RequestExport queues an export of the personal data of the user, a user
+// has one pending export at a time
+func (s *PrivacyService) RequestExport(ctx context.Context, user *model.User) (*model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "privacy.service.RequestExport")
+	defer span.End()
+
+	exports, err := s.repo.GetExportsByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, export := range exports {
+		if export.IsPending() {
+			return nil, httpErr.DataExportInProgressError
+		}
+	}
+
+	export := &model.DataExport{UserID: user.ID, Status: model.DataExportStatusPending}
+	if err := s.repo.InsertExport(ctx, export); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// GetExports returns the exports of the user
+func (s *PrivacyService) GetExports(ctx context.Context, user *model.User) ([]model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "privacy.service.GetExports")
+	defer span.End()
+
+	return s.repo.GetExportsByUser(ctx, user.ID)
+}
+
+// GetExport returns an export of the user
+func (s *PrivacyService) GetExport(ctx context.Context, user *model.User, id uuid.UUID) (*model.DataExport, error) {
+	ctx, span := tracing.Start(ctx, "privacy.service.GetExport")
+	defer span.End()
+
+	return s.repo.GetExportByIDAndUser(ctx, user.ID, id)
+}
+
+// GetExportFile returns the path of the archive of a ready export of the user
+func (s *PrivacyService) GetExportFile(ctx context.Context, user *model.User, id uuid.UUID) (string, error) {
+	ctx, span := tracing.Start(ctx, "privacy.service.GetExportFile")
+	defer span.End()
+
+	export, err := s.repo.GetExportByIDAndUser(ctx, user.ID, id)
+	if err != nil {
+		return "", err
+	}
+	if !export.IsDownloadable(s.now()) {
+		return "", httpErr.DataExportNotReadyError
+	}
+	return filepath.Join(s.folder, export.FileName), nil
+}
+
+// BuildPendingExports builds the archives of the pending exports and returns
+// how many are built and how many failed. A failed export is kept until it
+// expires so the user can see it and request a new one.
+func (s *PrivacyService) BuildPendingExports(ctx context.Context) (int, int, error) {
+	ctx, span := tracing.Start(ctx, "privacy.service.BuildPendingExports")
+	defer span.End()
+
+	exports, err := s.repo.GetPendingExports(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(exports) == 0 {
+		return 0, 0, nil
+	}
+	if err := os.MkdirAll(s.folder, 0700); err != nil {
+		return 0, 0, err
+	}
+
+	built, failed := 0, 0
+	for i := range exports {
+		export := &exports[i]
+		now := s.now()
+		expiresAt := now.Add(s.retention)
+		export.CompletedAt = &now
+		export.ExpiresAt = &expiresAt
+
+		size, err := s.buildArchive(ctx, export, now)
+		if err != nil {
+			zap.L().Error("privacy.service.BuildPendingExports", tracing.Field(ctx), zap.Reflect("exportID", export.ID), zap.Error(err))
+			export.Status = model.DataExportStatusFailed
+			export.Error = err.Error()
+			failed++
+		} else {
+			export.Status = model.DataExportStatusReady
+			export.Size = size
+			built++
+		}
+
+		if err := s.repo.UpdateExport(ctx, export); err != nil {
+			return built, failed, err
+		}
+	}
+	return built, failed, nil
+}
+
+// buildArchive writes the archive of an export and returns its size
+func (s *PrivacyService) buildArchive(ctx context.Context, export *model.DataExport, now time.Time) (int64, error) {
+	data, err := s.repo.GetPersonalData(ctx, export.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	export.FileName = export.ID.String() + ".zip"
+	path := filepath.Join(s.folder, export.FileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	if err := writeArchive(file, data, now); err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// PurgeExpiredExports deletes the expired exports with their archives
+func (s *PrivacyService) PurgeExpiredExports(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "privacy.service.PurgeExpiredExports")
+	defer span.End()
+
+	exports, err := s.repo.DeleteExpiredExports(ctx, s.now())
+	if err != nil {
+		return 0, err
+	}
+	s.removeArchives(ctx, exports)
+	return len(exports), nil
+}
+
+// EraseUser anonymizes the user with the current password. The archives of
+// the user are removed first, so none is left behind once the data is gone.
+func (s *PrivacyService) EraseUser(ctx context.Context, user *model.User, password string) error {
+	ctx, span := tracing.Start(ctx, "privacy.service.EraseUser")
+	defer span.End()
+
+	stored, err := s.repo.GetUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if !stored.CheckPassword(password) {
+		return httpErr.WrongCurrentPasswordError
+	}
+
+	exports, err := s.repo.GetExportsByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	s.removeArchives(ctx, exports)
+
+	if err := s.repo.EraseUser(ctx, user.ID, s.now()); err != nil {
+		return err
+	}
+	zap.L().Info("personal data erased", tracing.Field(ctx), zap.Reflect("userID", user.ID))
+	return nil
+}
+
+// removeArchives removes the archive files of the exports
+func (s *PrivacyService) removeArchives(ctx context.Context, exports []model.DataExport) {
+	for _, export := range exports {
+		if export.FileName == "" {
+			continue
+		}
+		err := os.Remove(filepath.Join(s.folder, export.FileName))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			zap.L().Error("privacy.service.removeArchives", tracing.Field(ctx), zap.Reflect("exportID", export.ID), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func newUser(t *testing.T) *model.User {
+	first, last, username, email := "Jane", "Doe", "jane", "jane@example.com"
+	user := &model.User{Base: model.Base{ID: uuid.New()}, FirstName: &first, LastName: &last, Username: &username, Email: &email}
+	if err := user.SetPassword("secret"); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestPrivacyService_exports(t *testing.T) {
+	user := newUser(t)
+	name, stock := "Keyboard", int64(5)
+	product := model.Product{Base: model.Base{ID: uuid.New()}, Name: &name, Price: 10, Stock: &stock}
+	repo := &mockPrivacyRepository{
+		users: []*model.User{user},
+		orders: []*model.Order{{
+			Base:       model.Base{ID: uuid.New()},
+			UserID:     user.ID,
+			Status:     model.OrderStatusCompleted,
+			TotalPrice: 10,
+			Items:      []model.OrderItem{{ProductID: product.ID, Product: product, Price: 10}},
+		}},
+	}
+	folder := t.TempDir()
+	s := NewPrivacyService(repo, folder, time.Hour)
+	job := NewExportJob(s)
+
+	export, err := s.RequestExport(context.Background(), user)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, model.DataExportStatusPending, export.Status)
+	_, err = s.RequestExport(context.Background(), user)
+	assert.Equal(t, httpErr.DataExportInProgressError, err)
+	_, err = s.GetExportFile(context.Background(), user, export.ID)
+	assert.Equal(t, httpErr.DataExportNotReadyError, err)
+
+	message, err := job.Run(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "built 1, failed 0, purged 0", message)
+	assert.Equal(t, model.DataExportStatusReady, export.Status)
+
+	// the archive holds one JSON document per kind of data
+	path, err := s.GetExportFile(context.Background(), user, export.ID)
+	assert.Equal(t, nil, err)
+	archive, err := zip.OpenReader(path)
+	assert.Equal(t, nil, err)
+	defer archive.Close()
+	documents := map[string][]byte{}
+	for _, file := range archive.File {
+		reader, _ := file.Open()
+		documents[file.Name], _ = ioutil.ReadAll(reader)
+		reader.Close()
+	}
+	assert.Equal(t, 7, len(documents))
+	profile := map[string]interface{}{}
+	assert.Equal(t, nil, json.Unmarshal(documents["profile.json"], &profile))
+	assert.Equal(t, "jane@example.com", profile["email"])
+	orders := []map[string]interface{}{}
+	assert.Equal(t, nil, json.Unmarshal(documents["orders.json"], &orders))
+	assert.Equal(t, 1, len(orders))
+	info, _ := os.Stat(path)
+	assert.Equal(t, info.Size(), export.Size)
+
+	// another user cannot download it
+	_, err = s.GetExportFile(context.Background(), newUser(t), export.ID)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	// expired archives are removed
+	s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	message, err = job.Run(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "built 0, failed 0, purged 1", message)
+	_, err = os.Stat(path)
+	assert.Equal(t, true, os.IsNotExist(err))
+}
+
+func TestPrivacyService_BuildPendingExports_failed(t *testing.T) {
+	user := newUser(t)
+	repo := &mockPrivacyRepository{}
+	s := NewPrivacyService(repo, t.TempDir(), time.Hour)
+
+	// the user is gone before the archive is built
+	export := &model.DataExport{UserID: user.ID, Status: model.DataExportStatusPending}
+	assert.Equal(t, nil, repo.InsertExport(context.Background(), export))
+	built, failed, err := s.BuildPendingExports(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, built)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, model.DataExportStatusFailed, export.Status)
+
+	// a failed export does not block a new one
+	_, err = s.RequestExport(context.Background(), user)
+	assert.Equal(t, nil, err)
+}
+
+func TestPrivacyService_EraseUser(t *testing.T) {
+	user := newUser(t)
+	repo := &mockPrivacyRepository{users: []*model.User{user}}
+	folder := t.TempDir()
+	s := NewPrivacyService(repo, folder, time.Hour)
+
+	export, _ := s.RequestExport(context.Background(), user)
+	s.BuildPendingExports(context.Background())
+	path := filepath.Join(folder, export.FileName)
+	_, err := os.Stat(path)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, httpErr.WrongCurrentPasswordError, s.EraseUser(context.Background(), user, "wrong"))
+	assert.Equal(t, false, repo.erased[user.ID])
+
+	assert.Equal(t, nil, s.EraseUser(context.Background(), user, "secret"))
+	assert.Equal(t, true, repo.erased[user.ID])
+	_, err = os.Stat(path)
+	assert.Equal(t, true, os.IsNotExist(err))
+
+	// an erased user is gone
+	assert.Equal(t, gorm.ErrRecordNotFound, s.EraseUser(context.Background(), user, "secret"))
+}
+
+type mockPrivacyRepository struct {
+	users   []*model.User
+	orders  []*model.Order
+	exports []*model.DataExport
+	erased  map[uuid.UUID]bool
+}
+
+func (r *mockPrivacyRepository) GetUser(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	for _, user := range r.users {
+		if user.ID == id && !r.erased[id] {
+			return user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockPrivacyRepository) GetPersonalData(ctx context.Context, userID uuid.UUID) (*PersonalData, error) {
+	user, err := r.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	data := &PersonalData{User: user}
+	for _, order := range r.orders {
+		if order.UserID == userID {
+			data.Orders = append(data.Orders, order)
+		}
+	}
+	return data, nil
+}
+
+func (r *mockPrivacyRepository) InsertExport(ctx context.Context, export *model.DataExport) error {
+	export.ID = uuid.New()
+	export.CreatedAt = time.Now()
+	r.exports = append(r.exports, export)
+	return nil
+}
+
+func (r *mockPrivacyRepository) UpdateExport(ctx context.Context, export *model.DataExport) error {
+	for i, stored := range r.exports {
+		if stored.ID == export.ID {
+			*r.exports[i] = *export
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *mockPrivacyRepository) GetExportsByUser(ctx context.Context, userID uuid.UUID) ([]model.DataExport, error) {
+	exports := []model.DataExport{}
+	for _, export := range r.exports {
+		if export.UserID == userID {
+			exports = append(exports, *export)
+		}
+	}
+	return exports, nil
+}
+
+func (r *mockPrivacyRepository) GetExportByIDAndUser(ctx context.Context, userID uuid.UUID, id uuid.UUID) (*model.DataExport, error) {
+	for _, export := range r.exports {
+		if export.ID == id && export.UserID == userID {
+			return export, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockPrivacyRepository) GetPendingExports(ctx context.Context) ([]model.DataExport, error) {
+	exports := []model.DataExport{}
+	for _, export := range r.exports {
+		if export.IsPending() {
+			exports = append(exports, *export)
+		}
+	}
+	return exports, nil
+}
+
+func (r *mockPrivacyRepository) DeleteExpiredExports(ctx context.Context, now time.Time) ([]model.DataExport, error) {
+	expired, kept := []model.DataExport{}, []*model.DataExport{}
+	for _, export := range r.exports {
+		if export.ExpiresAt != nil && export.ExpiresAt.Before(now) {
+			expired = append(expired, *export)
+		} else {
+			kept = append(kept, export)
+		}
+	}
+	r.exports = kept
+	return expired, nil
+}
+
+func (r *mockPrivacyRepository) EraseUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	if _, err := r.GetUser(ctx, userID); err != nil {
+		return err
+	}
+	if r.erased == nil {
+		r.erased = map[uuid.UUID]bool{}
+	}
+	r.erased[userID] = true
+	return nil
+}
@@ -1,10 +1,11 @@
 package product
 
 import (
+	"encoding/json"
 	"errors"
 	"patika-ecommerce/internal/api"
+	"patika-ecommerce/internal/inventory"
 	"patika-ecommerce/internal/model"
-	"patika-ecommerce/pkg/config"
 
 	mw "patika-ecommerce/pkg/middleware"
 	paginationHelper "patika-ecommerce/pkg/pagination"
@@ -18,17 +19,18 @@ import (
 )
 
 type productHandler struct {
-	productRepo ProductRepositoryInterface
+	productRepo   ProductRepositoryInterface
+	stockObserver inventory.StockObserver
 }
 
-func NewProductHandler(r *gin.RouterGroup, cfg *config.Config, productRepo *ProductRepository) {
-	handler := &productHandler{productRepo: productRepo}
+func NewProductHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, productRepo *ProductRepository, stockObserver inventory.StockObserver) {
+	handler := &productHandler{productRepo: productRepo, stockObserver: stockObserver}
 	// Public endpoints
 	r.GET("", mw.PaginationMiddleware(), handler.getProducts)
 	r.GET("/:id", handler.getProduct)
 
 	// Private endpoints
-	r.Use(mw.AuthenticationMiddleware(cfg.JWTConfig.SecretKey), mw.AdminMiddleware())
+	r.Use(authMiddleware, mw.RequirePermission(model.PermissionProductWrite))
 	r.POST("", handler.createProduct)
 	r.PUT("/:id", handler.updateProduct)
 	r.DELETE("/:id", handler.deleteProduct)
@@ -50,10 +52,14 @@ func (r *productHandler) createProduct(c *gin.Context) {
 
 	product := ProductRequestToProduct(reqBody)
 
-	if err := r.productRepo.Insert(product); err != nil {
+	if err := r.productRepo.Insert(c.Request.Context(), product); err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
 	}
+	// a new product may already be low on stock
+	if r.stockObserver != nil {
+		r.stockObserver.StockChanged(c.Request.Context(), product.ID)
+	}
 	c.JSON(201, ProductToResponse(product))
 }
 
@@ -61,7 +67,7 @@ func (r *productHandler) createProduct(c *gin.Context) {
 func (r *productHandler) getProducts(c *gin.Context) {
 	pagination := c.MustGet("pagination").(*paginationHelper.Pagination)
 
-	data, err := r.productRepo.GetAll(pagination)
+	data, err := r.productRepo.GetAll(c.Request.Context(), pagination)
 
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
@@ -78,11 +84,11 @@ func (r *productHandler) getProduct(c *gin.Context) {
 
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(httpErr.ErrorResponse(err))
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
 		return
 	}
 
-	product, err = r.productRepo.Get(id)
+	product, err = r.productRepo.Get(c.Request.Context(), id)
 
 	if err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
@@ -98,13 +104,13 @@ func (r *productHandler) deleteProduct(c *gin.Context) {
 
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(httpErr.ErrorResponse(err))
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
 		return
 	}
 
 	product.ID = id
 
-	if err := r.productRepo.Delete(product); err != nil {
+	if err := r.productRepo.Delete(c.Request.Context(), product); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(httpErr.ErrorResponse(err))
 			return
@@ -119,8 +125,12 @@ func (r *productHandler) deleteProduct(c *gin.Context) {
 func (r *productHandler) updateProduct(c *gin.Context) {
 	reqBody := &api.ProductUpdateRequest{}
 
-	if err := c.ShouldBindJSON(reqBody); err != nil {
-		c.JSON(httpErr.ErrorResponse(err))
+	// every field is optional, a misspelled one is rejected instead of being
+	// left unchanged without notice
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.CannotBindGivenData))
 		return
 	}
 
@@ -133,16 +143,23 @@ func (r *productHandler) updateProduct(c *gin.Context) {
 
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(httpErr.ErrorResponse(err))
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
 		return
 	}
 
 	product.ID = id
+	if user, ok := c.Get("user"); ok {
+		product.StockChangedBy = &user.(*model.User).ID
+	}
 
-	if err = r.productRepo.Update(product); err != nil {
+	if err = r.productRepo.Update(c.Request.Context(), product); err != nil {
 		c.JSON(httpErr.ErrorResponse(err))
 		return
 	}
+	// stock or the low-stock threshold may have changed
+	if r.stockObserver != nil {
+		r.stockObserver.StockChanged(c.Request.Context(), product.ID)
+	}
 
 	c.JSON(200, ProductToResponse(product))
 }
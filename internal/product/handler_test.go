@@ -2,8 +2,7 @@ package product
 
 import (
 	"bytes"
-	"errors"
-	"fmt"
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"gorm.io/gorm"
 )
 
@@ -390,6 +390,34 @@ func Test_productHandler_updateProduct(t *testing.T) {
 		assert.Equal(t, "product name update", mockProductRepo.items[0].Name)
 	})
 
+	t.Run("updateProduct_stockNotSent", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("PUT", "/product/:id", nil)
+		c.Params = []gin.Param{{Key: "id", Value: id.String()}}
+		c.Request.Body = ioutil.NopCloser(bytes.NewBufferString(`{"name":"product name update","sku":"PRODUCT-SKU-UPDATE"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		productHandler.updateProduct(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(40), *mockProductRepo.items[0].Stock)
+	})
+
+	t.Run("updateProduct_zeroStock", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("PUT", "/product/:id", nil)
+		c.Params = []gin.Param{{Key: "id", Value: id.String()}}
+		c.Request.Body = ioutil.NopCloser(bytes.NewBufferString(`{"name":"product name update","sku":"PRODUCT-SKU-UPDATE","stock":0,"stockReason":"stock count"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		productHandler.updateProduct(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, int64(0), *mockProductRepo.items[0].Stock)
+	})
+
 	t.Run("updateProduct_Failed_idNotValid", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		gin.SetMode(gin.TestMode)
@@ -448,7 +476,7 @@ type mockProductRepository struct {
 	categories []model.Category
 }
 
-func (r *mockProductRepository) Insert(product *model.Product) error {
+func (r *mockProductRepository) Insert(ctx context.Context, product *model.Product) error {
 	newCategories := []model.Category{}
 	for _, item := range product.Categories {
 		for _, category := range r.categories {
@@ -459,7 +487,7 @@ func (r *mockProductRepository) Insert(product *model.Product) error {
 	}
 
 	if len(newCategories) != len(product.Categories) {
-		return fmt.Errorf("23503")
+		return &pgconn.PgError{Code: "23503"}
 	}
 
 	product.Categories = newCategories
@@ -469,7 +497,7 @@ func (r *mockProductRepository) Insert(product *model.Product) error {
 }
 
 // GetProducts get all products
-func (r *mockProductRepository) GetAll(pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+func (r *mockProductRepository) GetAll(ctx context.Context, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
 	var products []model.Product
 	pagination.TotalRows = int64(len(r.items))
 	pagination.Rows = ProductsToResponse(&products)
@@ -478,7 +506,7 @@ func (r *mockProductRepository) GetAll(pagination *paginationHelper.Pagination)
 }
 
 // GetProduct get a single product
-func (r *mockProductRepository) Get(id uuid.UUID) (*model.Product, error) {
+func (r *mockProductRepository) Get(ctx context.Context, id uuid.UUID) (*model.Product, error) {
 	for _, item := range r.items {
 		if item.ID == id {
 			return &item, nil
@@ -488,12 +516,12 @@ func (r *mockProductRepository) Get(id uuid.UUID) (*model.Product, error) {
 }
 
 // GetProductWithoutCategories get a single product
-func (r *mockProductRepository) GetProductWithoutCategories(id uuid.UUID) (*model.Product, error) {
+func (r *mockProductRepository) GetProductWithoutCategories(ctx context.Context, id uuid.UUID) (*model.Product, error) {
 	return nil, nil
 }
 
 // DeleteProduct delete a single product
-func (r *mockProductRepository) Delete(product *model.Product) error {
+func (r *mockProductRepository) Delete(ctx context.Context, product *model.Product) error {
 	for i, item := range r.items {
 		if item.ID == product.ID {
 			r.items = append(r.items[:i], r.items[i+1:]...)
@@ -504,9 +532,12 @@ func (r *mockProductRepository) Delete(product *model.Product) error {
 }
 
 // UpdateProduct update a single product
-func (r *mockProductRepository) Update(product *model.Product) error {
+func (r *mockProductRepository) Update(ctx context.Context, product *model.Product) error {
 	for i, item := range r.items {
 		if item.ID == product.ID {
+			if product.Stock == nil {
+				product.Stock = item.Stock
+			}
 			r.items[i] = *product
 			return nil
 		}
@@ -519,10 +550,10 @@ type CategoryMockRepository struct {
 	Items []model.Category
 }
 
-func (r *CategoryMockRepository) InsertCategory(category *model.Category) error {
+func (r *CategoryMockRepository) InsertCategory(ctx context.Context, category *model.Category) error {
 	for _, item := range r.Items {
 		item.ID = category.ID
-		return errors.New("category already exists")
+		return &pgconn.PgError{Code: "23505"}
 	}
 	r.Items = append(r.Items, *category)
 
@@ -530,12 +561,12 @@ func (r *CategoryMockRepository) InsertCategory(category *model.Category) error
 }
 
 // GetCategories returns all categories
-func (r *CategoryMockRepository) GetCategories() (*[]model.Category, error) {
+func (r *CategoryMockRepository) GetCategories(ctx context.Context) (*[]model.Category, error) {
 	return &r.Items, nil
 }
 
 // GetCategoryByID returns a category by id
-func (r *CategoryMockRepository) GetCategoryByID(id uuid.UUID) (*model.Category, error) {
+func (r *CategoryMockRepository) GetCategoryByID(ctx context.Context, id uuid.UUID) (*model.Category, error) {
 	category := &model.Category{}
 	for _, item := range r.Items {
 		if item.ID == id {
@@ -546,7 +577,7 @@ func (r *CategoryMockRepository) GetCategoryByID(id uuid.UUID) (*model.Category,
 }
 
 // UpdateCategory updates a category with the given id
-func (r *CategoryMockRepository) UpdateCategory(category *model.Category) error {
+func (r *CategoryMockRepository) UpdateCategory(ctx context.Context, category *model.Category) error {
 	for i, item := range r.Items {
 		if item.ID == category.ID {
 			r.Items[i] = *category
@@ -557,11 +588,11 @@ func (r *CategoryMockRepository) UpdateCategory(category *model.Category) error
 }
 
 // InsertBulkCategory inserts bulk categories //TODO
-func (r *CategoryMockRepository) InsertBulkCategory(categories *[]model.Category) error {
+func (r *CategoryMockRepository) InsertBulkCategory(ctx context.Context, categories *[]model.Category) error {
 	for _, category := range *categories {
 		for _, item := range r.Items {
 			if item.Name == category.Name {
-				return errors.New("category already exists")
+				return &pgconn.PgError{Code: "23505"}
 			}
 		}
 		r.Items = append(r.Items, category)
@@ -571,7 +602,7 @@ func (r *CategoryMockRepository) InsertBulkCategory(categories *[]model.Category
 }
 
 // DeleteCategory deletes a category by id
-func (r *CategoryMockRepository) Delete(category *model.Category) error {
+func (r *CategoryMockRepository) Delete(ctx context.Context, category *model.Category) error {
 	isExist := false
 	for index, item := range r.Items {
 		if item.ID == category.ID {
@@ -581,7 +612,7 @@ func (r *CategoryMockRepository) Delete(category *model.Category) error {
 		}
 	}
 	if !isExist {
-		return errors.New("category not found")
+		return gorm.ErrRecordNotFound
 	}
 	return nil
 }
@@ -8,8 +8,7 @@ import (
 func Search(search string) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
 		if search != "" {
-			db = db.Where("name ILIKE ?", "%"+search+"%")
-			db = db.Or("sku ILIKE ?", "%"+search+"%")
+			db = db.Where("name ILIKE ? OR sku ILIKE ?", "%"+search+"%", "%"+search+"%")
 		}
 		return db
 	}
@@ -0,0 +1,151 @@
+package product
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/inventory"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+	"patika-ecommerce/pkg/utils"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// productImportColumns is the header of a product import file, the categories
+// of a product are referred by name and separated by semicolons
+var productImportColumns = []string{"Name", "SKU", "Description", "Price", "Stock", "Categories"}
+
+// ProductImportRepositoryInterface is the part of the product repository the import uses
+type ProductImportRepositoryInterface interface {
+	Insert(ctx context.Context, product *model.Product) error
+	GetBySKU(ctx context.Context, sku string) (*model.Product, error)
+}
+
+// CategoryListerInterface lists the categories the imported products refer to,
+// the category repository implements it
+type CategoryListerInterface interface {
+	GetCategories(ctx context.Context) (*[]model.Category, error)
+}
+
+// ImportResult counts the products of an import
+type ImportResult struct {
+	Imported int
+	Skipped  int
+}
+
+// ProductImporter inserts the products of a CSV file
+type ProductImporter struct {
+	productRepo   ProductImportRepositoryInterface
+	categoryRepo  CategoryListerInterface
+	stockObserver inventory.StockObserver
+}
+
+// NewProductImporter creates a new ProductImporter, the stock observer is told
+// about the inserted products
+func NewProductImporter(productRepo ProductImportRepositoryInterface, categoryRepo CategoryListerInterface, stockObserver inventory.StockObserver) *ProductImporter {
+	return &ProductImporter{productRepo: productRepo, categoryRepo: categoryRepo, stockObserver: stockObserver}
+}
+
+// Import reads every row of the file before inserting any of them, so a
+// broken file does not leave a half import behind. Products whose SKU exists
+// are skipped, so an import can be repeated.
+func (i *ProductImporter) Import(ctx context.Context, buf *bytes.Buffer) (*ImportResult, error) {
+	ctx, span := tracing.Start(ctx, "product.importer.Import")
+	defer span.End()
+
+	records, err := utils.ReadFile(buf)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || strings.Join(records[0], ",") != strings.Join(productImportColumns, ",") {
+		return nil, httpErr.InvalidImportHeaderError
+	}
+
+	categories, err := i.categoryRepo.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categoriesByName := map[string]model.Category{}
+	for _, category := range *categories {
+		categoriesByName[strings.ToLower(*category.Name)] = category
+	}
+
+	products := make([]*model.Product, 0, len(records)-1)
+	for index, record := range records[1:] {
+		product, err := recordToProduct(record, categoriesByName)
+		if err != nil {
+			// the header is line 1
+			return nil, fmt.Errorf("line %d: %w", index+2, err)
+		}
+		products = append(products, product)
+	}
+
+	result := &ImportResult{}
+	// the products inserted before a failure are checked for stock alerts too
+	inserted := []uuid.UUID{}
+	defer func() {
+		if i.stockObserver != nil {
+			i.stockObserver.StockChanged(ctx, inserted...)
+		}
+	}()
+	for _, product := range products {
+		_, err := i.productRepo.GetBySKU(ctx, *product.SKU)
+		if err == nil {
+			result.Skipped++
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return result, err
+		}
+		if err := i.productRepo.Insert(ctx, product); err != nil {
+			return result, fmt.Errorf("product %s: %w", *product.SKU, err)
+		}
+		inserted = append(inserted, product.ID)
+		result.Imported++
+	}
+	zap.L().Info("products imported", tracing.Field(ctx), zap.Int("imported", result.Imported), zap.Int("skipped", result.Skipped))
+	return result, nil
+}
+
+func recordToProduct(record []string, categoriesByName map[string]model.Category) (*model.Product, error) {
+	name, sku := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+	if name == "" || sku == "" {
+		return nil, httpErr.ImportNameRequiredError
+	}
+	price, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+	if err != nil || price < 0 {
+		return nil, httpErr.InvalidImportPriceError.WithDetails(map[string]string{"price": record[3]})
+	}
+	stock, err := strconv.ParseInt(strings.TrimSpace(record[4]), 10, 64)
+	if err != nil || stock < 0 {
+		return nil, httpErr.InvalidImportStockError.WithDetails(map[string]string{"stock": record[4]})
+	}
+
+	product := &model.Product{
+		Name:        &name,
+		SKU:         &sku,
+		Description: strings.TrimSpace(record[2]),
+		Price:       price,
+		Stock:       &stock,
+		Categories:  []model.Category{},
+	}
+	for _, categoryName := range strings.Split(record[5], ";") {
+		categoryName = strings.TrimSpace(categoryName)
+		if categoryName == "" {
+			continue
+		}
+		category, ok := categoriesByName[strings.ToLower(categoryName)]
+		if !ok {
+			return nil, httpErr.CategoryNotFoundError.WithDetails(map[string]string{"name": categoryName})
+		}
+		product.Categories = append(product.Categories, category)
+	}
+	return product, nil
+}
@@ -0,0 +1,97 @@
+package product
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type mockImportRepository struct {
+	products []*model.Product
+}
+
+func (r *mockImportRepository) Insert(ctx context.Context, product *model.Product) error {
+	product.ID = uuid.New()
+	r.products = append(r.products, product)
+	return nil
+}
+
+func (r *mockImportRepository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	for _, product := range r.products {
+		if *product.SKU == sku {
+			return product, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type mockStockObserver struct {
+	productIDs []uuid.UUID
+}
+
+func (o *mockStockObserver) StockChanged(ctx context.Context, productIDs ...uuid.UUID) {
+	o.productIDs = append(o.productIDs, productIDs...)
+}
+
+type mockCategoryLister struct {
+	categories []model.Category
+}
+
+func (r *mockCategoryLister) GetCategories(ctx context.Context) (*[]model.Category, error) {
+	return &r.categories, nil
+}
+
+func TestProductImporter_Import(t *testing.T) {
+	books, games := "Books", "Games"
+	existingSKU := "BOOK-1"
+	repo := &mockImportRepository{products: []*model.Product{{SKU: &existingSKU}}}
+	observer := &mockStockObserver{}
+	importer := NewProductImporter(repo, &mockCategoryLister{categories: []model.Category{{Name: &books}, {Name: &games}}}, observer)
+
+	result, err := importer.Import(context.Background(), bytes.NewBufferString(
+		"Name,SKU,Description,Price,Stock,Categories\n"+
+			"Go Book,BOOK-1,A book,10.5,3,Books\n"+
+			"Board Game,GAME-1,A game,25,0,games; books\n"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &ImportResult{Imported: 1, Skipped: 1}, result)
+	assert.Equal(t, 2, len(repo.products))
+	imported := repo.products[1]
+	assert.Equal(t, "Board Game", *imported.Name)
+	assert.Equal(t, 25.0, imported.Price)
+	assert.Equal(t, int64(0), *imported.Stock)
+	assert.Equal(t, 2, len(imported.Categories))
+	// only the inserted product is queued for the stock alerts
+	assert.Equal(t, []uuid.UUID{imported.ID}, observer.productIDs)
+}
+
+func TestProductImporter_Import_Invalid(t *testing.T) {
+	books := "Books"
+	tests := []struct {
+		name    string
+		file    string
+		wantErr error
+	}{
+		{name: "wrongHeader", file: "Name,SKU\nGo Book,BOOK-1\n", wantErr: httpErr.InvalidImportHeaderError},
+		{name: "invalidPrice", file: "Name,SKU,Description,Price,Stock,Categories\nGo Book,BOOK-1,,ten,1,Books\n", wantErr: httpErr.InvalidImportPriceError},
+		{name: "negativeStock", file: "Name,SKU,Description,Price,Stock,Categories\nGo Book,BOOK-1,,10,-1,Books\n", wantErr: httpErr.InvalidImportStockError},
+		{name: "missingSKU", file: "Name,SKU,Description,Price,Stock,Categories\nGo Book,,,10,1,Books\n", wantErr: httpErr.ImportNameRequiredError},
+		{name: "unknownCategory", file: "Name,SKU,Description,Price,Stock,Categories\nGo Book,BOOK-1,,10,1,Music\n", wantErr: httpErr.CategoryNotFoundError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockImportRepository{}
+			importer := NewProductImporter(repo, &mockCategoryLister{categories: []model.Category{{Name: &books}}}, &mockStockObserver{})
+
+			_, err := importer.Import(context.Background(), bytes.NewBufferString(tt.file))
+			assert.Equal(t, true, errors.Is(err, tt.wantErr))
+			assert.Equal(t, 0, len(repo.products))
+		})
+	}
+}
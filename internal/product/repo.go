@@ -1,48 +1,76 @@
 package product
 
 import (
+	"context"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/inventory"
 	"patika-ecommerce/internal/model"
 	paginationHelper "patika-ecommerce/pkg/pagination"
+	"patika-ecommerce/pkg/tracing"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 type ProductRepositoryInterface interface {
-	Insert(product *model.Product) error
-	GetAll(pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
-	Get(id uuid.UUID) (*model.Product, error)
-	GetProductWithoutCategories(id uuid.UUID) (*model.Product, error)
-	Delete(product *model.Product) error
-	Update(product *model.Product) error
+	Insert(ctx context.Context, product *model.Product) error
+	GetAll(ctx context.Context, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
+	Get(ctx context.Context, id uuid.UUID) (*model.Product, error)
+	GetProductWithoutCategories(ctx context.Context, id uuid.UUID) (*model.Product, error)
+	Delete(ctx context.Context, product *model.Product) error
+	Update(ctx context.Context, product *model.Product) error
 }
 
 type ProductRepository struct {
 	db *gorm.DB
 }
 
-func (r *ProductRepository) Migration() {
-	r.db.AutoMigrate(&model.Product{})
-
-}
-
 func NewProductRepository(db *gorm.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
 // InsertProduct insert product
-func (r *ProductRepository) Insert(product *model.Product) error {
-	zap.L().Debug("product.repo.Insert", zap.Reflect("product", product))
+func (r *ProductRepository) Insert(ctx context.Context, product *model.Product) error {
+	ctx, span := tracing.Start(ctx, "product.repo.Insert")
+	defer span.End()
+
+	zap.L().Debug("product.repo.Insert", tracing.Field(ctx), zap.Reflect("product", product))
 
-	tx := r.db.Begin()
+	tx := r.db.WithContext(ctx).Begin()
+
+	// initial stock is put into the primary warehouse through the ledger
+	var initialStock, zero int64
+	if product.Stock != nil {
+		initialStock = *product.Stock
+	}
+	product.Stock = &zero
 
 	result := tx.Omit("Categories").Create(product)
 	if err := result.Error; err != nil {
 		tx.Rollback()
 		return err
 	}
+
+	if initialStock > 0 {
+		warehouse, err := inventory.PrimaryWarehouse(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := inventory.ApplyMovement(tx, &model.StockMovement{
+			ProductID:   product.ID,
+			WarehouseID: warehouse.ID,
+			Type:        model.StockMovementImport,
+			Quantity:    initialStock,
+			Reason:      "initial stock",
+		}); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	product.Stock = &initialStock
 	// insert categories
 	for _, category := range product.Categories {
 		if err := tx.Model(&category).Association("Products").Append(product); err != nil {
@@ -56,14 +84,17 @@ func (r *ProductRepository) Insert(product *model.Product) error {
 }
 
 // GetProducts get all products
-func (r *ProductRepository) GetAll(pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
-	zap.L().Debug("product.repo.GetAll", zap.Reflect("pagination", pagination))
+func (r *ProductRepository) GetAll(ctx context.Context, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	ctx, span := tracing.Start(ctx, "product.repo.GetAll")
+	defer span.End()
+
+	zap.L().Debug("product.repo.GetAll", tracing.Field(ctx), zap.Reflect("pagination", pagination))
 
 	var products []model.Product
 	var totalRows int64
 
-	query := r.db.Model(&model.Product{}).Scopes(Search(pagination.Q)).Count(&totalRows).Preload("Categories")
-	query.Scopes(paginationHelper.Paginate(totalRows, pagination, r.db)).Find(&products)
+	query := r.db.WithContext(ctx).Model(&model.Product{}).Where("deleted_at IS NULL").Scopes(Search(pagination.Q)).Count(&totalRows).Preload("Categories")
+	query.Scopes(paginationHelper.Paginate(totalRows, pagination, r.db.WithContext(ctx))).Find(&products)
 
 	pagination.Rows = ProductsToResponse(&products)
 
@@ -71,53 +102,101 @@ func (r *ProductRepository) GetAll(pagination *paginationHelper.Pagination) (*pa
 }
 
 // GetProduct get a single product
-func (r *ProductRepository) Get(id uuid.UUID) (*model.Product, error) {
-	zap.L().Debug("product.repo.Get", zap.Reflect("id", id))
+func (r *ProductRepository) Get(ctx context.Context, id uuid.UUID) (*model.Product, error) {
+	ctx, span := tracing.Start(ctx, "product.repo.Get")
+	defer span.End()
+
+	zap.L().Debug("product.repo.Get", tracing.Field(ctx), zap.Reflect("id", id))
 
 	product := new(model.Product)
-	result := r.db.Preload("Categories").Where("id = ?", id).First(&product)
+	result := r.db.WithContext(ctx).Preload("Categories").Where("id = ? AND deleted_at IS NULL", id).First(&product)
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, httpErr.NotFoundAs(result.Error, httpErr.ProductNotFoundError)
 	}
 
 	return product, nil
 }
 
 // GetProductWithoutCategories get a single product
-func (r *ProductRepository) GetProductWithoutCategories(id uuid.UUID) (*model.Product, error) {
-	zap.L().Debug("product.repo.GetProductWithoutCategories", zap.Reflect("id", id))
+func (r *ProductRepository) GetProductWithoutCategories(ctx context.Context, id uuid.UUID) (*model.Product, error) {
+	ctx, span := tracing.Start(ctx, "product.repo.GetProductWithoutCategories")
+	defer span.End()
+
+	zap.L().Debug("product.repo.GetProductWithoutCategories", tracing.Field(ctx), zap.Reflect("id", id))
 
 	product := new(model.Product)
-	result := r.db.Where("id = ?", id).First(&product)
+	result := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(&product)
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, httpErr.NotFoundAs(result.Error, httpErr.ProductNotFoundError)
 	}
 
 	return product, nil
 }
 
-// DeleteProduct delete a single product
-func (r *ProductRepository) Delete(product *model.Product) error {
-	zap.L().Debug("product.repo.Delete", zap.Reflect("product", product))
+// GetBySKU returns the product with the sku
+func (r *ProductRepository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	ctx, span := tracing.Start(ctx, "product.repo.GetBySKU")
+	defer span.End()
 
-	result := r.db.Select(clause.Associations).Delete(product)
+	zap.L().Debug("product.repo.GetBySKU", tracing.Field(ctx), zap.String("sku", sku))
 
+	product := new(model.Product)
+	if err := r.db.WithContext(ctx).Where("sku = ?", sku).First(product).Error; err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// searchIndexes are the trigram indexes the product search uses
+var searchIndexes = []string{"idx_products_name_trgm", "idx_products_sku_trgm"}
+
+// ReindexSearch rebuilds the search indexes without blocking the writes and
+// refreshes the statistics of the planner
+func (r *ProductRepository) ReindexSearch(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "product.repo.ReindexSearch")
+	defer span.End()
+
+	zap.L().Debug("product.repo.ReindexSearch", tracing.Field(ctx))
+
+	for _, index := range searchIndexes {
+		if err := r.db.WithContext(ctx).Exec("REINDEX INDEX CONCURRENTLY " + index).Error; err != nil {
+			return err
+		}
+	}
+	return r.db.WithContext(ctx).Exec("ANALYZE products").Error
+}
+
+// DeleteProduct archives a single product, its stock levels and movements are kept
+func (r *ProductRepository) Delete(ctx context.Context, product *model.Product) error {
+	ctx, span := tracing.Start(ctx, "product.repo.Delete")
+	defer span.End()
+
+	zap.L().Debug("product.repo.Delete", tracing.Field(ctx), zap.Reflect("product", product))
+
+	result := r.db.WithContext(ctx).Model(&model.Product{}).Where("id = ? AND deleted_at IS NULL", product.ID).
+		UpdateColumn("deleted_at", time.Now())
 	if result.Error != nil {
 		return result.Error
 	}
+	if result.RowsAffected == 0 {
+		return httpErr.ProductNotFoundError
+	}
 	return nil
 }
 
 // UpdateProduct update a single product
-func (r *ProductRepository) Update(product *model.Product) error {
-	zap.L().Debug("product.repo.Update", zap.Reflect("product", product))
+func (r *ProductRepository) Update(ctx context.Context, product *model.Product) error {
+	ctx, span := tracing.Start(ctx, "product.repo.Update")
+	defer span.End()
 
-	tx := r.db.Begin()
+	zap.L().Debug("product.repo.Update", tracing.Field(ctx), zap.Reflect("product", product))
+
+	tx := r.db.WithContext(ctx).Begin()
 	for index, item := range product.Categories {
 		category := new(model.Category)
-		if err := tx.Model(category).Where("id = ?", item.ID).First(&category); err != nil {
+		if err := tx.Model(category).Where("id = ?", item.ID).First(&category).Error; err != nil {
 			tx.Rollback()
-			return err.Error
+			return httpErr.NotFoundAs(err, httpErr.CategoryNotFoundError)
 		}
 		product.Categories[index] = *category
 	}
@@ -125,9 +204,9 @@ func (r *ProductRepository) Update(product *model.Product) error {
 	exProduct := new(model.Product)
 
 	// get ex product
-	if err := tx.Where("id = ?", product.ID).Preload("Categories").First(&exProduct); err.Error != nil {
+	if err := tx.Where("id = ? AND deleted_at IS NULL", product.ID).Preload("Categories").First(&exProduct).Error; err != nil {
 		tx.Rollback()
-		return err.Error
+		return httpErr.NotFoundAs(err, httpErr.ProductNotFoundError)
 	}
 
 	// delete all associated categories
@@ -136,18 +215,54 @@ func (r *ProductRepository) Update(product *model.Product) error {
 		return err
 	}
 
-	if result := tx.Model(&product).Updates(&product); result.Error != nil {
-		tx.Rollback()
-		return result.Error
+	// stock changes are applied to the primary warehouse through the ledger
+	// a product without stock is treated as having none, like the ledger does
+	newStock := product.Stock
+	product.Stock = nil
+	var currentStock int64
+	if exProduct.Stock != nil {
+		currentStock = *exProduct.Stock
 	}
-
-	// update cart items price if product price is changed
-	if exProduct.Price != product.Price {
-		if err := tx.Model(&model.CartItem{}).Where("product_id = ?", product.ID).Update("price", product.Price).Error; err != nil {
+	if newStock != nil && *newStock != currentStock {
+		if err := inventory.ValidateStockReason(product.StockReason); err != nil {
 			tx.Rollback()
 			return err
 		}
+		warehouse, err := inventory.PrimaryWarehouse(tx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := inventory.ApplyMovement(tx, &model.StockMovement{
+			ProductID:   product.ID,
+			WarehouseID: warehouse.ID,
+			Type:        model.StockMovementAdjustment,
+			Quantity:    *newStock - currentStock,
+			Reason:      product.StockReason,
+			UserID:      product.StockChangedBy,
+		}); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if result := tx.Model(&product).Omit("stock").Updates(&product); result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	product.Stock = newStock
+	if product.Stock == nil {
+		product.Stock = exProduct.Stock
 	}
+	if product.Name == nil {
+		product.Name = exProduct.Name
+	}
+	if product.SKU == nil {
+		product.SKU = exProduct.SKU
+	}
+
+	// cart items keep the price they were added with, price changes are
+	// reported by the cart revalidation before checkout
 
 	tx.Commit()
 	return nil
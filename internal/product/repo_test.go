@@ -1,7 +1,9 @@
 package product
 
 import (
+	"context"
 	"database/sql"
+	httpErr "patika-ecommerce/internal/httpErrors"
 	"patika-ecommerce/internal/model"
 	"regexp"
 	"testing"
@@ -54,16 +56,98 @@ func TestCategoryRepository_GetProductWithoutCategories(t *testing.T) {
 
 	// query := "SELECT id, first_name, last_name, username, email, is_admin FROM users WHERE id = \\?"
 
-	query := `SELECT * FROM "products" WHERE id = $1 ORDER BY "products"."id" LIMIT 1`
+	query := `SELECT * FROM "products" WHERE id = $1 AND deleted_at IS NULL ORDER BY "products"."id" LIMIT 1`
 
 	rows := sqlmock.NewRows([]string{"id", "name", "description", "price", "stock", "sku"}).
 		AddRow(c.ID, c.Name, c.Description, c.Price, c.Stock, c.SKU)
 
 	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(c.ID).WillReturnRows(rows)
 
-	category, _ := repo.GetProductWithoutCategories(c.ID)
+	category, _ := repo.GetProductWithoutCategories(context.Background(), c.ID)
 
 	assert.Equal(t, category.ID, id)
 	assert.Equal(t, category.Name, name)
 
 }
+
+func TestProductRepository_DeleteArchivesProductWithStockMovements(t *testing.T) {
+	db, mock := NewMock()
+	repo := &ProductRepository{db}
+
+	// the product has stock levels and movements, which are kept: only deleted_at is set
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "products" SET "deleted_at"=$1 WHERE id = $2 AND deleted_at IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), c.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.Delete(context.Background(), &model.Product{Base: model.Base{ID: c.ID}})
+
+	assert.Equal(t, err, nil)
+	assert.Equal(t, mock.ExpectationsWereMet(), nil)
+}
+
+func TestProductRepository_DeleteArchivedProduct(t *testing.T) {
+	db, mock := NewMock()
+	repo := &ProductRepository{db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "products" SET "deleted_at"=$1 WHERE id = $2 AND deleted_at IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), c.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := repo.Delete(context.Background(), &model.Product{Base: model.Base{ID: c.ID}})
+
+	assert.Equal(t, err, httpErr.ProductNotFoundError)
+}
+
+func TestProductRepository_HardDeleteIsRefused(t *testing.T) {
+	db, mock := NewMock()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err := db.Delete(&model.Product{Base: model.Base{ID: c.ID}}).Error
+
+	assert.Equal(t, err, httpErr.ProductArchivedOnlyError)
+	assert.Equal(t, mock.ExpectationsWereMet(), nil)
+}
+
+func TestProductRepository_UpdateStockOfProductWithoutStock(t *testing.T) {
+	db, mock := NewMock()
+	repo := &ProductRepository{db}
+
+	// the product has no stock yet, setting it is an adjustment that needs a reason
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "sku", "stock"}).AddRow(c.ID, name, sku, nil))
+	mock.ExpectQuery(`SELECT \* FROM "product_categories"`).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "category_id"}))
+	mock.ExpectExec(`DELETE FROM "product_categories"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	newStock := int64(5)
+	err := repo.Update(context.Background(), &model.Product{Base: model.Base{ID: c.ID}, Stock: &newStock})
+
+	assert.Equal(t, err, httpErr.StockReasonRequiredError)
+}
+
+func TestProductRepository_UpdateStockWithBlankReason(t *testing.T) {
+	db, mock := NewMock()
+	repo := &ProductRepository{db}
+
+	// a reason made of spaces is no reason
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "products"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "sku", "stock"}).AddRow(c.ID, name, sku, 3))
+	mock.ExpectQuery(`SELECT \* FROM "product_categories"`).
+		WillReturnRows(sqlmock.NewRows([]string{"product_id", "category_id"}))
+	mock.ExpectExec(`DELETE FROM "product_categories"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	newStock := int64(5)
+	err := repo.Update(context.Background(), &model.Product{Base: model.Base{ID: c.ID}, Stock: &newStock, StockReason: "      "})
+
+	assert.Equal(t, err, httpErr.StockReasonRequiredError)
+	assert.Equal(t, mock.ExpectationsWereMet(), nil)
+}
@@ -27,6 +27,8 @@ func ProductRequestToProduct(productRequest *api.ProductRequest) *model.Product
 		Stock:       &stock,
 		SKU:         productRequest.Sku,
 		Categories:  categories,
+
+		LowStockThreshold: productRequest.LowStockThreshold,
 	}
 }
 
@@ -47,6 +49,8 @@ func ProductToResponse(product *model.Product) *api.ProductResponse {
 		Stock:       stock,
 		Sku:         *product.SKU,
 		Categories:  categories,
+
+		LowStockThreshold: product.LowStockThreshold,
 	}
 }
 
@@ -75,22 +79,33 @@ func ProductToProductBasicResponse(product *model.Product) *api.ProductBasicResp
 
 // ProductUpdateRequestToProduct update a single product
 func ProductUpdateRequestToProduct(productUpdateRequest *api.ProductUpdateRequest) *model.Product {
-	stock := productUpdateRequest.Stock
-	name := productUpdateRequest.Name
-	sku := productUpdateRequest.Sku
-
 	categories := []model.Category{}
 	for _, c := range productUpdateRequest.Categories {
 		id, _ := common.StrfmtToUUID(c.ID)
 		categories = append(categories, model.Category{Base: model.Base{ID: id}})
 	}
 
-	return &model.Product{
-		Name:        &name,
+	product := &model.Product{
 		Description: productUpdateRequest.Description,
 		Price:       productUpdateRequest.Price,
-		Stock:       &stock,
 		Categories:  categories,
-		SKU:         &sku,
+
+		LowStockThreshold: productUpdateRequest.LowStockThreshold,
+	}
+
+	// name, sku and stock are left untouched when they are not given
+	if productUpdateRequest.Name != "" {
+		name := productUpdateRequest.Name
+		product.Name = &name
+	}
+	if productUpdateRequest.Sku != "" {
+		sku := productUpdateRequest.Sku
+		product.SKU = &sku
+	}
+	if productUpdateRequest.Stock != nil {
+		stock := *productUpdateRequest.Stock
+		product.Stock = &stock
+		product.StockReason = productUpdateRequest.StockReason
 	}
+	return product
 }
@@ -0,0 +1,78 @@
+package role
+
+import (
+	"patika-ecommerce/internal/api"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	mw "patika-ecommerce/pkg/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+)
+
+type roleHandler struct {
+	roleService RoleServiceInterface
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, roleService RoleServiceInterface) {
+	handler := &roleHandler{roleService: roleService}
+
+	r.Use(authMiddleware)
+	r.GET("/roles", mw.RequirePermission(model.PermissionUserRead), handler.getRoles)
+	r.GET("/users/:id/roles", mw.RequirePermission(model.PermissionUserRead), handler.getUserRoles)
+	r.PUT("/users/:id/roles", mw.RequirePermission(model.PermissionRoleWrite), handler.setUserRoles)
+}
+
+// getRoles lists the roles and their permissions
+func (r *roleHandler) getRoles(c *gin.Context) {
+	c.JSON(200, RolesToRoleResponse())
+}
+
+// getUserRoles returns the roles of a user
+func (r *roleHandler) getUserRoles(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	user, err := r.roleService.GetUserRoles(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, UserToUserRolesResponse(user))
+}
+
+// setUserRoles replaces the roles of a user
+func (r *roleHandler) setUserRoles(c *gin.Context) {
+	admin := c.MustGet("user").(*model.User)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	reqBody := &api.UserRolesRequest{}
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	user, err := r.roleService.SetUserRoles(c.Request.Context(), admin, id, reqBody.Roles)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, UserToUserRolesResponse(user))
+}
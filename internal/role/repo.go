@@ -0,0 +1,72 @@
+package role
+
+import (
+	"context"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleRepositoryInterface interface {
+	GetUserWithRoles(ctx context.Context, id uuid.UUID) (*model.User, error)
+	SetUserRoles(ctx context.Context, user *model.User, roles []model.Role, grantedBy uuid.UUID) error
+}
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// GetUserWithRoles returns the user with its roles
+func (r *RoleRepository) GetUserWithRoles(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "role.repo.GetUserWithRoles")
+	defer span.End()
+
+	zap.L().Debug("role.repo.GetUserWithRoles", tracing.Field(ctx), zap.Reflect("id", id))
+
+	user := &model.User{}
+	if err := r.db.WithContext(ctx).Preload("Roles").First(user, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetUserRoles replaces the roles of the user, the roles kept are not touched
+func (r *RoleRepository) SetUserRoles(ctx context.Context, user *model.User, roles []model.Role, grantedBy uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "role.repo.SetUserRoles")
+	defer span.End()
+
+	zap.L().Debug("role.repo.SetUserRoles", tracing.Field(ctx), zap.Reflect("userID", user.ID), zap.Reflect("roles", roles))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	query := tx.Where("user_id = ?", user.ID)
+	if len(roles) > 0 {
+		query = query.Where("role NOT IN ?", roles)
+	}
+	if err := query.Delete(&model.UserRole{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, role := range roles {
+		userRole := &model.UserRole{UserID: user.ID, Role: role, GrantedBy: &grantedBy}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(userRole).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Where("user_id = ?", user.ID).Find(&user.Roles).Error
+}
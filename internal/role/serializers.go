@@ -0,0 +1,36 @@
+package role
+
+import (
+	"patika-ecommerce/internal/api"
+	"patika-ecommerce/internal/model"
+	common "patika-ecommerce/pkg/utils"
+	"sort"
+)
+
+// RolesToRoleResponse converts the known roles to role responses
+func RolesToRoleResponse() []*api.RoleResponse {
+	response := []*api.RoleResponse{}
+	for role, permissions := range model.RolePermissions {
+		names := []string{}
+		for _, permission := range permissions {
+			names = append(names, string(permission))
+		}
+		if role == model.RoleSuperAdmin {
+			names = append(names, "*")
+		}
+		response = append(response, &api.RoleResponse{Name: string(role), Permissions: names})
+	}
+	sort.Slice(response, func(i, j int) bool {
+		return response[i].Name < response[j].Name
+	})
+	return response
+}
+
+// UserToUserRolesResponse converts a user to a user roles response
+func UserToUserRolesResponse(user *model.User) *api.UserRolesResponse {
+	return &api.UserRolesResponse{
+		UserID:  common.UUIDToStrfmt(user.ID),
+		IsAdmin: user.IsAdmin,
+		Roles:   user.RoleNames(),
+	}
+}
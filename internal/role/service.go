@@ -0,0 +1,86 @@
+package role
+
+import (
+	"context"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+
+	"github.com/google/uuid"
+)
+
+type RoleServiceInterface interface {
+	GetUserRoles(ctx context.Context, id uuid.UUID) (*model.User, error)
+	SetUserRoles(ctx context.Context, admin *model.User, id uuid.UUID, roles []string) (*model.User, error)
+}
+
+// SessionServiceInterface closes the sessions of a user, the auth service
+// implements it
+type SessionServiceInterface interface {
+	LogoutAll(ctx context.Context, user *model.User) error
+}
+
+type RoleService struct {
+	roleRepo       RoleRepositoryInterface
+	sessionService SessionServiceInterface
+}
+
+// NewRoleService creates a new RoleService
+func NewRoleService(roleRepo RoleRepositoryInterface, sessionService SessionServiceInterface) *RoleService {
+	return &RoleService{roleRepo: roleRepo, sessionService: sessionService}
+}
+
+// GetUserRoles returns the user with its roles
+func (s *RoleService) GetUserRoles(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "role.service.GetUserRoles")
+	defer span.End()
+
+	return s.roleRepo.GetUserWithRoles(ctx, id)
+}
+
+// SetUserRoles replaces the roles of the user. Admins cannot change their own
+// roles, so nobody can lock themselves out or grant themselves more. The roles
+// are carried in the tokens, so the sessions of a user losing a role are
+// closed and the removed role cannot be refreshed.
+func (s *RoleService) SetUserRoles(ctx context.Context, admin *model.User, id uuid.UUID, roles []string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "role.service.SetUserRoles")
+	defer span.End()
+
+	if admin.ID == id {
+		return nil, httpErr.CannotChangeOwnRolesError
+	}
+
+	newRoles := []model.Role{}
+	seen := map[model.Role]bool{}
+	for _, name := range roles {
+		role := model.Role(name)
+		if !role.IsValid() {
+			return nil, httpErr.InvalidRoleError
+		}
+		if !seen[role] {
+			seen[role] = true
+			newRoles = append(newRoles, role)
+		}
+	}
+
+	user, err := s.roleRepo.GetUserWithRoles(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	removed := false
+	for _, role := range user.Roles {
+		if !seen[role.Role] {
+			removed = true
+		}
+	}
+
+	if err := s.roleRepo.SetUserRoles(ctx, user, newRoles, admin.ID); err != nil {
+		return nil, err
+	}
+	if removed {
+		if err := s.sessionService.LogoutAll(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	return user, nil
+}
@@ -0,0 +1,79 @@
+package role
+
+import (
+	"context"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type mockRoleRepo struct {
+	users []model.User
+}
+
+func (r *mockRoleRepo) GetUserWithRoles(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	for i := range r.users {
+		if r.users[i].ID == id {
+			return &r.users[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockRoleRepo) SetUserRoles(ctx context.Context, user *model.User, roles []model.Role, grantedBy uuid.UUID) error {
+	user.Roles = []model.UserRole{}
+	for _, role := range roles {
+		user.Roles = append(user.Roles, model.UserRole{UserID: user.ID, Role: role, GrantedBy: &grantedBy})
+	}
+	return nil
+}
+
+type mockSessionService struct {
+	loggedOut int
+}
+
+func (s *mockSessionService) LogoutAll(ctx context.Context, user *model.User) error {
+	s.loggedOut++
+	return nil
+}
+
+func TestRoleService_SetUserRoles(t *testing.T) {
+	admin := model.User{Base: model.Base{ID: uuid.New()}, Roles: []model.UserRole{{Role: model.RoleSuperAdmin}}}
+	clerk := model.User{Base: model.Base{ID: uuid.New()}, Roles: []model.UserRole{{Role: model.RoleInventoryClerk}}}
+
+	tests := []struct {
+		name          string
+		id            uuid.UUID
+		roles         []string
+		wantErr       error
+		wantRoles     []string
+		wantLoggedOut int
+	}{
+		{name: "replaced", id: clerk.ID, roles: []string{"catalog_manager", "catalog_manager", "order_support"}, wantRoles: []string{"catalog_manager", "order_support"}, wantLoggedOut: 1},
+		{name: "added", id: clerk.ID, roles: []string{"inventory_clerk", "order_support"}, wantRoles: []string{"inventory_clerk", "order_support"}},
+		{name: "removedAll", id: clerk.ID, roles: []string{}, wantRoles: []string{}, wantLoggedOut: 1},
+		{name: "invalidRole", id: clerk.ID, roles: []string{"owner"}, wantErr: httpErr.InvalidRoleError},
+		{name: "ownRoles", id: admin.ID, roles: []string{}, wantErr: httpErr.CannotChangeOwnRolesError},
+		{name: "userNotFound", id: uuid.New(), roles: []string{"order_support"}, wantErr: gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &mockSessionService{}
+			s := NewRoleService(&mockRoleRepo{users: []model.User{admin, clerk}}, sessions)
+
+			user, err := s.SetUserRoles(context.Background(), &admin, tt.id, tt.roles)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantLoggedOut, sessions.loggedOut)
+			if tt.wantErr == nil {
+				assert.Equal(t, tt.wantRoles, user.RoleNames())
+				for _, role := range user.Roles {
+					assert.Equal(t, admin.ID, *role.GrantedBy)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package stockalert
+
+import (
+	"patika-ecommerce/internal/api"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	common "patika-ecommerce/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+)
+
+type stockAlertHandler struct {
+	stockAlertService StockAlertServiceInterface
+}
+
+// NewStockAlertHandler creates a new stock alert handler
+func NewStockAlertHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, stockAlertService StockAlertServiceInterface) {
+	handler := &stockAlertHandler{stockAlertService: stockAlertService}
+
+	r.Use(authMiddleware)
+	r.GET("/subscriptions", handler.getSubscriptions)
+	r.POST("/subscriptions", handler.subscribe)
+	r.DELETE("/subscriptions/:productId", handler.unsubscribe)
+}
+
+// getSubscriptions lists the back-in-stock subscriptions of the user
+func (r *stockAlertHandler) getSubscriptions(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	subscriptions, err := r.stockAlertService.GetSubscriptions(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, StockSubscriptionsToResponse(subscriptions))
+}
+
+// subscribe subscribes the user to a back-in-stock notification
+func (r *stockAlertHandler) subscribe(c *gin.Context) {
+	reqBody := &api.StockSubscriptionRequest{}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	productID, err := common.StrfmtToUUID(*reqBody.ProductID)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	subscription, err := r.stockAlertService.Subscribe(c.Request.Context(), user, productID)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(201, StockSubscriptionToResponse(subscription))
+}
+
+// unsubscribe removes a back-in-stock subscription of the user
+func (r *stockAlertHandler) unsubscribe(c *gin.Context) {
+	productID, err := uuid.Parse(c.Param("productId"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	if err := r.stockAlertService.Unsubscribe(c.Request.Context(), user, productID); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, map[string]string{"message": "unsubscribed"})
+}
@@ -0,0 +1,36 @@
+package stockalert
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultCheckInterval is how often the queued stock changes are checked when
+// it is not configured
+const DefaultCheckInterval = time.Minute
+
+// AlertJob sends the low stock alerts and back in stock notifications of the
+// products whose stock has changed
+type AlertJob struct {
+	service StockAlertServiceInterface
+}
+
+// NewAlertJob creates a new stock alert job
+func NewAlertJob(service StockAlertServiceInterface) *AlertJob {
+	return &AlertJob{service: service}
+}
+
+// Name returns the name of the job
+func (j *AlertJob) Name() string {
+	return "stock_alerts"
+}
+
+// Run checks the queued stock changes
+func (j *AlertJob) Run(ctx context.Context) (string, error) {
+	checked, failed, err := j.service.CheckChangedStock(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("checked %d, failed %d", checked, failed), nil
+}
@@ -0,0 +1,203 @@
+package stockalert
+
+import (
+	"context"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type StockAlertRepositoryInterface interface {
+	GetProductByID(ctx context.Context, id uuid.UUID) (*model.Product, error)
+	MarkStockChanged(ctx context.Context, ids []uuid.UUID) error
+	GetStockChangedProducts(ctx context.Context) ([]model.Product, error)
+	ClearStockChanged(ctx context.Context, product *model.Product) error
+	SetLowStockAlerted(ctx context.Context, productID uuid.UUID, alerted bool) error
+	GetAdmins(ctx context.Context) ([]model.User, error)
+	GetPendingSubscriptions(ctx context.Context, productID uuid.UUID) ([]model.StockSubscription, error)
+	MarkNotified(ctx context.Context, subscription *model.StockSubscription) error
+	SaveSubscription(ctx context.Context, subscription *model.StockSubscription) error
+	GetSubscriptionsByUser(ctx context.Context, userID uuid.UUID) ([]model.StockSubscription, error)
+	DeleteSubscription(ctx context.Context, userID, productID uuid.UUID) error
+}
+
+type StockAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewStockAlertRepository(db *gorm.DB) *StockAlertRepository {
+	return &StockAlertRepository{db: db}
+}
+
+// GetProductByID returns a product by id
+func (r *StockAlertRepository) GetProductByID(ctx context.Context, id uuid.UUID) (*model.Product, error) {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.GetProductByID")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.GetProductByID", tracing.Field(ctx), zap.Reflect("id", id))
+
+	product := &model.Product{}
+	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", id).First(product).Error; err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// MarkStockChanged queues the products for the stock alert job
+func (r *StockAlertRepository) MarkStockChanged(ctx context.Context, ids []uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.MarkStockChanged")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.MarkStockChanged", tracing.Field(ctx), zap.Reflect("ids", ids))
+
+	return r.db.WithContext(ctx).Model(&model.Product{}).Where("id IN ?", ids).UpdateColumn("stock_changed_at", time.Now()).Error
+}
+
+// GetStockChangedProducts returns the products queued for the stock alert job, the oldest change first
+func (r *StockAlertRepository) GetStockChangedProducts(ctx context.Context) ([]model.Product, error) {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.GetStockChangedProducts")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.GetStockChangedProducts", tracing.Field(ctx))
+
+	var products []model.Product
+	if err := r.db.WithContext(ctx).Where("stock_changed_at IS NOT NULL").Order("stock_changed_at ASC").Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// ClearStockChanged removes the product from the queue of the stock alert job,
+// unless its stock changed again since it was read
+func (r *StockAlertRepository) ClearStockChanged(ctx context.Context, product *model.Product) error {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.ClearStockChanged")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.ClearStockChanged", tracing.Field(ctx), zap.Reflect("productID", product.ID))
+
+	return r.db.WithContext(ctx).Model(&model.Product{}).
+		Where("id = ? AND stock_changed_at = ?", product.ID, product.StockChangedAt).
+		UpdateColumn("stock_changed_at", nil).Error
+}
+
+// SetLowStockAlerted sets whether the low-stock alert of a product has been sent
+func (r *StockAlertRepository) SetLowStockAlerted(ctx context.Context, productID uuid.UUID, alerted bool) error {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.SetLowStockAlerted")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.SetLowStockAlerted", tracing.Field(ctx), zap.Reflect("productID", productID), zap.Bool("alerted", alerted))
+
+	return r.db.WithContext(ctx).Model(&model.Product{}).Where("id = ?", productID).Update("low_stock_alerted", alerted).Error
+}
+
+// GetAdmins returns the active admins and users whose role manages the inventory
+func (r *StockAlertRepository) GetAdmins(ctx context.Context) ([]model.User, error) {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.GetAdmins")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.GetAdmins", tracing.Field(ctx))
+
+	var users []model.User
+	if err := r.db.WithContext(ctx).Where("id IN ("+model.ActiveUserIDs+")").
+		Where("is_admin = ? OR id IN (SELECT user_id FROM user_roles WHERE role IN ?)",
+			true, model.RolesWithPermission(model.PermissionInventoryWrite)).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetPendingSubscriptions returns the subscriptions of a product which are not
+// notified yet, of the active users
+func (r *StockAlertRepository) GetPendingSubscriptions(ctx context.Context, productID uuid.UUID) ([]model.StockSubscription, error) {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.GetPendingSubscriptions")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.GetPendingSubscriptions", tracing.Field(ctx), zap.Reflect("productID", productID))
+
+	var subscriptions []model.StockSubscription
+	if err := r.db.WithContext(ctx).Preload("User").
+		Where("product_id = ? AND notified_at IS NULL", productID).
+		Where("user_id IN (" + model.ActiveUserIDs + ")").
+		Order("created_at ASC").
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// MarkNotified sets the notification time of a subscription
+func (r *StockAlertRepository) MarkNotified(ctx context.Context, subscription *model.StockSubscription) error {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.MarkNotified")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.MarkNotified", tracing.Field(ctx), zap.Reflect("subscription", subscription.ID))
+
+	now := time.Now()
+	if err := r.db.WithContext(ctx).Model(subscription).Update("notified_at", now).Error; err != nil {
+		return err
+	}
+	subscription.NotifiedAt = &now
+	return nil
+}
+
+// SaveSubscription creates a subscription, or makes an already notified one pending again
+func (r *StockAlertRepository) SaveSubscription(ctx context.Context, subscription *model.StockSubscription) error {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.SaveSubscription")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.SaveSubscription", tracing.Field(ctx), zap.Reflect("subscription", subscription))
+
+	existing := &model.StockSubscription{}
+	err := r.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", subscription.UserID, subscription.ProductID).First(existing).Error
+	if err == gorm.ErrRecordNotFound {
+		return r.db.WithContext(ctx).Omit("User", "Product").Create(subscription).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := r.db.WithContext(ctx).Model(existing).Update("notified_at", nil).Error; err != nil {
+		return err
+	}
+	subscription.Base = existing.Base
+	subscription.NotifiedAt = nil
+	return nil
+}
+
+// GetSubscriptionsByUser returns the subscriptions of a user
+func (r *StockAlertRepository) GetSubscriptionsByUser(ctx context.Context, userID uuid.UUID) ([]model.StockSubscription, error) {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.GetSubscriptionsByUser")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.GetSubscriptionsByUser", tracing.Field(ctx), zap.Reflect("userID", userID))
+
+	var subscriptions []model.StockSubscription
+	if err := r.db.WithContext(ctx).Preload("Product").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// DeleteSubscription deletes the subscription of a user for a product
+func (r *StockAlertRepository) DeleteSubscription(ctx context.Context, userID, productID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "stockalert.repo.DeleteSubscription")
+	defer span.End()
+
+	zap.L().Debug("stockalert.repo.DeleteSubscription", tracing.Field(ctx), zap.Reflect("userID", userID), zap.Reflect("productID", productID))
+
+	result := r.db.WithContext(ctx).Where("user_id = ? AND product_id = ?", userID, productID).Delete(&model.StockSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
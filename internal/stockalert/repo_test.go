@@ -0,0 +1,65 @@
+package stockalert
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func NewMock() (DB *gorm.DB, mock sqlmock.Sqlmock) {
+	var (
+		db *sql.DB
+	)
+
+	db, mock, _ = sqlmock.New()
+
+	DB, _ = gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+
+	return DB, mock
+}
+
+const activeUsers = `(SELECT id FROM users WHERE deleted_at IS NULL AND suspended_at IS NULL)`
+
+func TestStockAlertRepository_GetAdmins(t *testing.T) {
+	db, mock := NewMock()
+	repo := NewStockAlertRepository(db)
+
+	// the deleted and the suspended admins are not alerted
+	query := `SELECT * FROM "users" WHERE (id IN ` + activeUsers + `) ` +
+		`AND (is_admin = $1 OR id IN (SELECT user_id FROM user_roles WHERE role IN ($2,$3)))`
+	mock.ExpectQuery(regexp.QuoteMeta(query)).
+		// the roles come from a map, their order is not fixed
+		WithArgs(true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "is_admin"}))
+
+	users, err := repo.GetAdmins(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(users))
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+}
+
+func TestStockAlertRepository_GetPendingSubscriptions(t *testing.T) {
+	db, mock := NewMock()
+	repo := NewStockAlertRepository(db)
+	productID := uuid.New()
+
+	// the subscriptions of the deleted and the suspended users are not notified
+	query := `SELECT * FROM "stock_subscriptions" WHERE (product_id = $1 AND notified_at IS NULL) ` +
+		`AND (user_id IN ` + activeUsers + `) ORDER BY created_at ASC`
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(productID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "user_id"}))
+
+	subscriptions, err := repo.GetPendingSubscriptions(context.Background(), productID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(subscriptions))
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,39 @@
+package stockalert
+
+import (
+	"patika-ecommerce/internal/api"
+	"patika-ecommerce/internal/model"
+	common "patika-ecommerce/pkg/utils"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// StockSubscriptionToResponse converts a stock subscription to a stock subscription response
+func StockSubscriptionToResponse(subscription *model.StockSubscription) *api.StockSubscriptionResponse {
+	var notifiedAt *strfmt.DateTime
+	if subscription.NotifiedAt != nil {
+		t := strfmt.DateTime(*subscription.NotifiedAt)
+		notifiedAt = &t
+	}
+
+	name := ""
+	if subscription.Product.Name != nil {
+		name = *subscription.Product.Name
+	}
+
+	return &api.StockSubscriptionResponse{
+		ProductID:   common.UUIDToStrfmt(subscription.ProductID),
+		ProductName: name,
+		NotifiedAt:  notifiedAt,
+		CreatedAt:   strfmt.DateTime(subscription.CreatedAt),
+	}
+}
+
+// StockSubscriptionsToResponse converts stock subscriptions to stock subscription responses
+func StockSubscriptionsToResponse(subscriptions []model.StockSubscription) []*api.StockSubscriptionResponse {
+	response := []*api.StockSubscriptionResponse{}
+	for _, subscription := range subscriptions {
+		response = append(response, StockSubscriptionToResponse(&subscription))
+	}
+	return response
+}
@@ -0,0 +1,187 @@
+package stockalert
+
+import (
+	"context"
+	"fmt"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/notifier"
+	"patika-ecommerce/pkg/tracing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	NotificationLowStock    = "low_stock"
+	NotificationBackInStock = "back_in_stock"
+)
+
+type StockAlertServiceInterface interface {
+	Subscribe(ctx context.Context, user *model.User, productID uuid.UUID) (*model.StockSubscription, error)
+	Unsubscribe(ctx context.Context, user *model.User, productID uuid.UUID) error
+	GetSubscriptions(ctx context.Context, user *model.User) ([]model.StockSubscription, error)
+	StockChanged(ctx context.Context, productIDs ...uuid.UUID)
+	CheckChangedStock(ctx context.Context) (int, int, error)
+}
+
+type StockAlertService struct {
+	stockAlertRepo StockAlertRepositoryInterface
+	notifier       notifier.Notifier
+}
+
+// NewStockAlertService creates a new StockAlertService
+func NewStockAlertService(stockAlertRepo StockAlertRepositoryInterface, notifier notifier.Notifier) *StockAlertService {
+	return &StockAlertService{stockAlertRepo: stockAlertRepo, notifier: notifier}
+}
+
+// Subscribe subscribes the user to the back-in-stock notification of an out-of-stock product
+func (s *StockAlertService) Subscribe(ctx context.Context, user *model.User, productID uuid.UUID) (*model.StockSubscription, error) {
+	ctx, span := tracing.Start(ctx, "stockalert.service.Subscribe")
+	defer span.End()
+
+	product, err := s.stockAlertRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Stock != nil && *product.Stock > 0 {
+		return nil, httpErr.ProductInStockError
+	}
+
+	subscription := &model.StockSubscription{UserID: user.ID, ProductID: productID}
+	if err := s.stockAlertRepo.SaveSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	subscription.Product = *product
+	return subscription, nil
+}
+
+// Unsubscribe removes the back-in-stock subscription of the user
+func (s *StockAlertService) Unsubscribe(ctx context.Context, user *model.User, productID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "stockalert.service.Unsubscribe")
+	defer span.End()
+
+	return s.stockAlertRepo.DeleteSubscription(ctx, user.ID, productID)
+}
+
+// GetSubscriptions returns the back-in-stock subscriptions of the user
+func (s *StockAlertService) GetSubscriptions(ctx context.Context, user *model.User) ([]model.StockSubscription, error) {
+	ctx, span := tracing.Start(ctx, "stockalert.service.GetSubscriptions")
+	defer span.End()
+
+	return s.stockAlertRepo.GetSubscriptionsByUser(ctx, user.ID)
+}
+
+// StockChanged queues the given products for the stock alert job, the alerts
+// and notifications are not sent within the request changing the stock.
+// Failures are logged, they never fail the stock change itself.
+func (s *StockAlertService) StockChanged(ctx context.Context, productIDs ...uuid.UUID) {
+	ctx, span := tracing.Start(ctx, "stockalert.service.StockChanged")
+	defer span.End()
+
+	if len(productIDs) == 0 {
+		return
+	}
+	if err := s.stockAlertRepo.MarkStockChanged(ctx, productIDs); err != nil {
+		zap.L().Error("stockalert.service.StockChanged", tracing.Field(ctx), zap.Error(err))
+	}
+}
+
+// CheckChangedStock checks the queued products for low stock and back in stock
+// notifications and returns how many are checked and how many failed. A failed
+// product stays queued, so it is checked again on the next run.
+func (s *StockAlertService) CheckChangedStock(ctx context.Context) (int, int, error) {
+	ctx, span := tracing.Start(ctx, "stockalert.service.CheckChangedStock")
+	defer span.End()
+
+	products, err := s.stockAlertRepo.GetStockChangedProducts(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	checked, failed := 0, 0
+	for i := range products {
+		product := &products[i]
+		if err := s.check(ctx, product); err != nil {
+			failed++
+			zap.L().Error("stockalert.service.CheckChangedStock", tracing.Field(ctx), zap.Reflect("productID", product.ID), zap.Error(err))
+			continue
+		}
+		if err := s.stockAlertRepo.ClearStockChanged(ctx, product); err != nil {
+			return checked, failed, err
+		}
+		checked++
+	}
+	return checked, failed, nil
+}
+
+func (s *StockAlertService) check(ctx context.Context, product *model.Product) error {
+	if err := s.checkLowStock(ctx, product); err != nil {
+		return err
+	}
+	if product.Stock != nil && *product.Stock > 0 {
+		return s.notifySubscribers(ctx, product)
+	}
+	return nil
+}
+
+// checkLowStock alerts admins once when the stock falls to or below the threshold,
+// and re-arms the alert when the stock rises above it again
+func (s *StockAlertService) checkLowStock(ctx context.Context, product *model.Product) error {
+	low := product.IsLowOnStock()
+	if low == product.LowStockAlerted {
+		return nil
+	}
+	if !low {
+		return s.stockAlertRepo.SetLowStockAlerted(ctx, product.ID, false)
+	}
+
+	admins, err := s.stockAlertRepo.GetAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	for _, admin := range admins {
+		if err := s.notifier.Notify(&notifier.Notification{
+			Kind:      NotificationLowStock,
+			Recipient: *admin.Email,
+			Subject:   fmt.Sprintf("%s is low on stock", *product.Name),
+			Body:      fmt.Sprintf("Stock of %s (%s) is %d, the threshold is %d.", *product.Name, *product.SKU, *product.Stock, *product.LowStockThreshold),
+		}); err != nil {
+			return err
+		}
+	}
+	return s.stockAlertRepo.SetLowStockAlerted(ctx, product.ID, true)
+}
+
+// notifySubscribers notifies users waiting for the product to be available.
+// Every subscription is marked as notified right after its notification is
+// sent, and a failed one does not stop the others; it stays pending and its
+// error keeps the product queued, so only the failed ones are sent again.
+func (s *StockAlertService) notifySubscribers(ctx context.Context, product *model.Product) error {
+	subscriptions, err := s.stockAlertRepo.GetPendingSubscriptions(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if err := s.notifier.Notify(&notifier.Notification{
+			Kind:      NotificationBackInStock,
+			Recipient: *subscription.User.Email,
+			Subject:   fmt.Sprintf("%s is back in stock", *product.Name),
+			Body:      fmt.Sprintf("%s is available again, %d left in stock.", *product.Name, *product.Stock),
+		}); err != nil {
+			zap.L().Error("stockalert.service.notifySubscribers", tracing.Field(ctx),
+				zap.Reflect("subscription", subscription.ID), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if err := s.stockAlertRepo.MarkNotified(ctx, subscription); err != nil {
+			return err
+		}
+	}
+	return firstErr
+}
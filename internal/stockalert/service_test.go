@@ -0,0 +1,325 @@
+package stockalert
+
+import (
+	"context"
+	"errors"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/notifier"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func newProduct(stock int64, threshold *int64) model.Product {
+	name, sku := "Product", "SKU-1"
+	return model.Product{
+		Base:              model.Base{ID: uuid.New()},
+		Name:              &name,
+		SKU:               &sku,
+		Stock:             &stock,
+		LowStockThreshold: threshold,
+	}
+}
+
+func newUser(email string, isAdmin bool) model.User {
+	return model.User{Base: model.Base{ID: uuid.New()}, Email: &email, IsAdmin: isAdmin}
+}
+
+// changeStock queues the product and runs the check of the stock alert job
+func changeStock(t *testing.T, s *StockAlertService, productID uuid.UUID) {
+	s.StockChanged(context.Background(), productID)
+	_, failed, err := s.CheckChangedStock(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, failed)
+}
+
+func TestStockAlertService_StockChanged_lowStock(t *testing.T) {
+	threshold := int64(5)
+	product := newProduct(3, &threshold)
+	repo := &mockStockAlertRepo{
+		products: []model.Product{product},
+		users:    []model.User{newUser("admin@example.com", true), newUser("user@example.com", false)},
+	}
+	n := &mockNotifier{}
+	s := NewStockAlertService(repo, n)
+
+	changeStock(t, s, product.ID)
+	assert.Equal(t, 1, len(n.notifications))
+	assert.Equal(t, NotificationLowStock, n.notifications[0].Kind)
+	assert.Equal(t, "admin@example.com", n.notifications[0].Recipient)
+	assert.Equal(t, true, repo.products[0].LowStockAlerted)
+
+	// still low, the alert is not repeated
+	changeStock(t, s, product.ID)
+	assert.Equal(t, 1, len(n.notifications))
+
+	// back above the threshold re-arms the alert
+	*repo.products[0].Stock = 10
+	changeStock(t, s, product.ID)
+	assert.Equal(t, false, repo.products[0].LowStockAlerted)
+
+	*repo.products[0].Stock = 2
+	changeStock(t, s, product.ID)
+	assert.Equal(t, 2, len(n.notifications))
+}
+
+func TestStockAlertService_StockChanged_backInStock(t *testing.T) {
+	product := newProduct(0, nil)
+	shopper := newUser("user@example.com", false)
+	repo := &mockStockAlertRepo{products: []model.Product{product}, users: []model.User{shopper}}
+	n := &mockNotifier{}
+	s := NewStockAlertService(repo, n)
+
+	_, err := s.Subscribe(context.Background(), &shopper, product.ID)
+	assert.Equal(t, nil, err)
+
+	// still out of stock
+	changeStock(t, s, product.ID)
+	assert.Equal(t, 0, len(n.notifications))
+
+	*repo.products[0].Stock = 4
+	changeStock(t, s, product.ID)
+	assert.Equal(t, 1, len(n.notifications))
+	assert.Equal(t, NotificationBackInStock, n.notifications[0].Kind)
+	assert.Equal(t, "user@example.com", n.notifications[0].Recipient)
+	assert.Equal(t, false, repo.subscriptions[0].IsPending())
+
+	// subscribers are notified once
+	changeStock(t, s, product.ID)
+	assert.Equal(t, 1, len(n.notifications))
+}
+
+func TestStockAlertService_StockChanged_backInStockPartialFailure(t *testing.T) {
+	product := newProduct(0, nil)
+	failing, shopper := newUser("failing@example.com", false), newUser("user@example.com", false)
+	repo := &mockStockAlertRepo{products: []model.Product{product}, users: []model.User{failing, shopper}}
+	n := &mockNotifier{failing: map[string]error{"failing@example.com": errors.New("mailbox is full")}}
+	s := NewStockAlertService(repo, n)
+
+	for _, user := range []model.User{failing, shopper} {
+		_, err := s.Subscribe(context.Background(), &user, product.ID)
+		assert.Equal(t, nil, err)
+	}
+
+	// the failed notification does not stop the others
+	*repo.products[0].Stock = 4
+	s.StockChanged(context.Background(), product.ID)
+	checked, failed, err := s.CheckChangedStock(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, checked)
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, 1, len(n.notifications))
+	assert.Equal(t, "user@example.com", n.notifications[0].Recipient)
+	assert.Equal(t, true, repo.subscriptions[0].IsPending())
+	assert.Equal(t, false, repo.subscriptions[1].IsPending())
+
+	// only the failed subscriber is notified on the next run
+	n.failing = nil
+	checked, failed, err = s.CheckChangedStock(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, checked)
+	assert.Equal(t, 0, failed)
+	assert.Equal(t, 2, len(n.notifications))
+	assert.Equal(t, "failing@example.com", n.notifications[1].Recipient)
+	assert.Equal(t, false, repo.subscriptions[0].IsPending())
+}
+
+func TestStockAlertService_StockChanged_queued(t *testing.T) {
+	threshold := int64(5)
+	product := newProduct(3, &threshold)
+	repo := &mockStockAlertRepo{
+		products: []model.Product{product},
+		users:    []model.User{newUser("admin@example.com", true)},
+	}
+	n := &mockNotifier{err: errors.New("mail server is down")}
+	s := NewStockAlertService(repo, n)
+
+	// the request only queues the product
+	s.StockChanged(context.Background(), product.ID)
+	assert.Equal(t, 0, len(n.notifications))
+	assert.NotEqual(t, nil, repo.products[0].StockChangedAt)
+
+	// a failed notification keeps the product queued
+	message, err := NewAlertJob(s).Run(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "checked 0, failed 1", message)
+	assert.NotEqual(t, nil, repo.products[0].StockChangedAt)
+
+	n.err = nil
+	message, err = NewAlertJob(s).Run(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "checked 1, failed 0", message)
+	assert.Equal(t, 1, len(n.notifications))
+	assert.Equal(t, (*time.Time)(nil), repo.products[0].StockChangedAt)
+}
+
+func TestStockAlertService_Subscribe(t *testing.T) {
+	outOfStock := newProduct(0, nil)
+	inStock := newProduct(3, nil)
+	shopper := newUser("user@example.com", false)
+
+	tests := []struct {
+		name      string
+		productID uuid.UUID
+		wantErr   error
+	}{
+		{name: "subscribe_Succeed", productID: outOfStock.ID},
+		{name: "subscribe_Failed_inStock", productID: inStock.ID, wantErr: httpErr.ProductInStockError},
+		{name: "subscribe_Failed_notFound", productID: uuid.New(), wantErr: gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockStockAlertRepo{products: []model.Product{outOfStock, inStock}, users: []model.User{shopper}}
+			s := NewStockAlertService(repo, &mockNotifier{})
+
+			_, err := s.Subscribe(context.Background(), &shopper, tt.productID)
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+type mockNotifier struct {
+	notifications []notifier.Notification
+	err           error
+	// failing recipients get err even when err is nil
+	failing map[string]error
+}
+
+func (n *mockNotifier) Notify(notification *notifier.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+	if err := n.failing[notification.Recipient]; err != nil {
+		return err
+	}
+	n.notifications = append(n.notifications, *notification)
+	return nil
+}
+
+type mockStockAlertRepo struct {
+	products      []model.Product
+	users         []model.User
+	subscriptions []model.StockSubscription
+}
+
+func (r *mockStockAlertRepo) GetProductByID(ctx context.Context, id uuid.UUID) (*model.Product, error) {
+	for _, product := range r.products {
+		if product.ID == id {
+			return &product, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockStockAlertRepo) MarkStockChanged(ctx context.Context, ids []uuid.UUID) error {
+	now := time.Now()
+	for i := range r.products {
+		for _, id := range ids {
+			if r.products[i].ID == id {
+				r.products[i].StockChangedAt = &now
+			}
+		}
+	}
+	return nil
+}
+
+func (r *mockStockAlertRepo) GetStockChangedProducts(ctx context.Context) ([]model.Product, error) {
+	products := []model.Product{}
+	for _, product := range r.products {
+		if product.StockChangedAt != nil {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+func (r *mockStockAlertRepo) ClearStockChanged(ctx context.Context, product *model.Product) error {
+	for i := range r.products {
+		if r.products[i].ID == product.ID && r.products[i].StockChangedAt == product.StockChangedAt {
+			r.products[i].StockChangedAt = nil
+		}
+	}
+	return nil
+}
+
+func (r *mockStockAlertRepo) SetLowStockAlerted(ctx context.Context, productID uuid.UUID, alerted bool) error {
+	for i := range r.products {
+		if r.products[i].ID == productID {
+			r.products[i].LowStockAlerted = alerted
+		}
+	}
+	return nil
+}
+
+func (r *mockStockAlertRepo) GetAdmins(ctx context.Context) ([]model.User, error) {
+	admins := []model.User{}
+	for _, user := range r.users {
+		if user.IsAdmin {
+			admins = append(admins, user)
+		}
+	}
+	return admins, nil
+}
+
+func (r *mockStockAlertRepo) GetPendingSubscriptions(ctx context.Context, productID uuid.UUID) ([]model.StockSubscription, error) {
+	subscriptions := []model.StockSubscription{}
+	for _, subscription := range r.subscriptions {
+		if subscription.ProductID == productID && subscription.IsPending() {
+			for _, user := range r.users {
+				if user.ID == subscription.UserID {
+					subscription.User = user
+				}
+			}
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (r *mockStockAlertRepo) MarkNotified(ctx context.Context, subscription *model.StockSubscription) error {
+	now := time.Now()
+	for i := range r.subscriptions {
+		if r.subscriptions[i].ID == subscription.ID {
+			r.subscriptions[i].NotifiedAt = &now
+		}
+	}
+	subscription.NotifiedAt = &now
+	return nil
+}
+
+func (r *mockStockAlertRepo) SaveSubscription(ctx context.Context, subscription *model.StockSubscription) error {
+	for i := range r.subscriptions {
+		if r.subscriptions[i].UserID == subscription.UserID && r.subscriptions[i].ProductID == subscription.ProductID {
+			r.subscriptions[i].NotifiedAt = nil
+			subscription.Base = r.subscriptions[i].Base
+			return nil
+		}
+	}
+	subscription.ID = uuid.New()
+	r.subscriptions = append(r.subscriptions, *subscription)
+	return nil
+}
+
+func (r *mockStockAlertRepo) GetSubscriptionsByUser(ctx context.Context, userID uuid.UUID) ([]model.StockSubscription, error) {
+	subscriptions := []model.StockSubscription{}
+	for _, subscription := range r.subscriptions {
+		if subscription.UserID == userID {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (r *mockStockAlertRepo) DeleteSubscription(ctx context.Context, userID, productID uuid.UUID) error {
+	for i, subscription := range r.subscriptions {
+		if subscription.UserID == userID && subscription.ProductID == productID {
+			r.subscriptions = append(r.subscriptions[:i], r.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
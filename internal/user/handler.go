@@ -0,0 +1,311 @@
+package auth
+
+import (
+	"patika-ecommerce/internal/api"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	mw "patika-ecommerce/pkg/middleware"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+)
+
+type userHandler struct {
+	userService UserServiceInterface
+}
+
+// NewUserHandler creates the handler of the profile endpoints of the current user
+func NewUserHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, userService UserServiceInterface) {
+	handler := &userHandler{userService: userService}
+
+	r.Use(authMiddleware)
+	r.GET("", handler.getProfile)
+	r.PATCH("", handler.updateProfile)
+	r.DELETE("", handler.deleteAccount)
+	r.PUT("/email", handler.changeEmail)
+	r.PUT("/password", handler.changePassword)
+}
+
+type userAdminHandler struct {
+	userAdminService UserAdminServiceInterface
+}
+
+// NewUserAdminHandler creates the handler of the admin user management endpoints
+func NewUserAdminHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, userAdminService UserAdminServiceInterface) {
+	handler := &userAdminHandler{userAdminService: userAdminService}
+
+	r.Use(authMiddleware)
+	r.GET("", mw.RequirePermission(model.PermissionUserRead), mw.PaginationMiddleware(), handler.searchUsers)
+	r.GET("/:id", mw.RequirePermission(model.PermissionUserRead), handler.getUser)
+	r.GET("/:id/orders", mw.RequirePermission(model.PermissionOrderRead), mw.PaginationMiddleware(), handler.getUserOrders)
+	r.GET("/:id/carts", mw.RequirePermission(model.PermissionOrderRead), handler.getUserCarts)
+	r.POST("/:id/suspend", mw.RequirePermission(model.PermissionUserWrite), handler.suspendUser)
+	r.POST("/:id/reactivate", mw.RequirePermission(model.PermissionUserWrite), handler.reactivateUser)
+	r.PUT("/:id/admin", mw.RequirePermission(model.PermissionRoleWrite), handler.grantAdmin)
+	r.DELETE("/:id/admin", mw.RequirePermission(model.PermissionRoleWrite), handler.revokeAdmin)
+	r.POST("/:id/password-reset", mw.RequirePermission(model.PermissionUserWrite), handler.forcePasswordReset)
+}
+
+// getProfile returns the profile of the user
+func (h *userHandler) getProfile(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	profile, err := h.userService.GetProfile(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, UserToProfileResponse(profile))
+}
+
+// updateProfile changes the name and the username of the user
+func (h *userHandler) updateProfile(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	var reqBody api.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	profile, err := h.userService.UpdateProfile(c.Request.Context(), user, UpdateProfileRequestToProfileUpdate(&reqBody))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, UserToProfileResponse(profile))
+}
+
+// changeEmail changes the email address of the user
+func (h *userHandler) changeEmail(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	var reqBody api.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	profile, err := h.userService.ChangeEmail(c.Request.Context(), user, reqBody.Email.String(), *reqBody.Password)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, UserToProfileResponse(profile))
+}
+
+// changePassword changes the password of the user
+func (h *userHandler) changePassword(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	var reqBody api.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := h.userService.ChangePassword(c.Request.Context(), user, *reqBody.CurrentPassword, *reqBody.NewPassword); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// deleteAccount deletes the account of the user
+func (h *userHandler) deleteAccount(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	var reqBody api.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := h.userService.DeleteAccount(c.Request.Context(), user, *reqBody.Password); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// searchUsers returns a page of the users
+func (h *userAdminHandler) searchUsers(c *gin.Context) {
+	pagination := c.MustGet("pagination").(*paginationHelper.Pagination)
+
+	data, err := h.userAdminService.SearchUsers(c.Request.Context(), pagination)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, data)
+}
+
+// getUser returns a user
+func (h *userAdminHandler) getUser(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	user, err := h.userAdminService.GetUser(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, UserToAdminUserResponse(user))
+}
+
+// getUserOrders returns a page of the orders of a user
+func (h *userAdminHandler) getUserOrders(c *gin.Context) {
+	pagination := c.MustGet("pagination").(*paginationHelper.Pagination)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	data, err := h.userAdminService.GetUserOrders(c.Request.Context(), id, pagination)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, data)
+}
+
+// getUserCarts returns the carts of a user
+func (h *userAdminHandler) getUserCarts(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	carts, err := h.userAdminService.GetUserCarts(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, CartsToCartResponse(carts))
+}
+
+// suspendUser suspends a user
+func (h *userAdminHandler) suspendUser(c *gin.Context) {
+	admin := c.MustGet("user").(*model.User)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	var reqBody api.SuspendUserRequest
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := h.userAdminService.SuspendUser(c.Request.Context(), admin, id, *reqBody.Reason); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// reactivateUser lifts the suspension of a user
+func (h *userAdminHandler) reactivateUser(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	if err := h.userAdminService.ReactivateUser(c.Request.Context(), id); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// grantAdmin grants the admin status to a user
+func (h *userAdminHandler) grantAdmin(c *gin.Context) {
+	h.setAdmin(c, true)
+}
+
+// revokeAdmin revokes the admin status of a user
+func (h *userAdminHandler) revokeAdmin(c *gin.Context) {
+	h.setAdmin(c, false)
+}
+
+func (h *userAdminHandler) setAdmin(c *gin.Context, isAdmin bool) {
+	admin := c.MustGet("user").(*model.User)
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	if err := h.userAdminService.SetAdmin(c.Request.Context(), admin, id, isAdmin); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
+
+// forcePasswordReset makes a user set a new password
+func (h *userAdminHandler) forcePasswordReset(c *gin.Context) {
+	admin := c.MustGet("user").(*model.User)
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	if err := h.userAdminService.ForcePasswordReset(c.Request.Context(), admin, id); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(204, nil)
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"patika-ecommerce/internal/api"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/config"
+	jwtHelper "patika-ecommerce/pkg/jwt"
+	mw "patika-ecommerce/pkg/middleware"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_userHandler(t *testing.T) {
+	cfg := &config.Config{
+		JWTConfig: config.JWTConfig{SecretKey: "secret", AccessTokenLifeTime: 30},
+	}
+	user := newProfileUser(t)
+	repo := &mockUserRepository{users: []*model.User{user}}
+	token, err := jwtHelper.GenerateAccessToken(user, cfg)
+	assert.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewUserHandler(r.Group("/me"), mw.AuthenticationMiddleware(cfg, repo), NewUserService(repo, &mockSessionService{}))
+	request := func(method string, path string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("GET", "/me", "")
+	assert.Equal(t, 200, w.Code)
+	profile := api.UserProfileResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &profile))
+	assert.Equal(t, "user@example.com", profile.Email)
+	assert.True(t, profile.EmailVerified)
+
+	assert.Equal(t, 400, request("PATCH", "/me", `{"firstName":"`+strings.Repeat("a", 101)+`"}`).Code)
+	w = request("PATCH", "/me", `{"lastName":"Changed"}`)
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"lastName":"Changed"`)
+
+	assert.Equal(t, 400, request("PUT", "/me/email", `{"email":"not-an-email","password":"current"}`).Code)
+	assert.Equal(t, 400, request("PUT", "/me/email", `{"email":"new@example.com","password":"wrong"}`).Code)
+	assert.Equal(t, 200, request("PUT", "/me/email", `{"email":"new@example.com","password":"current"}`).Code)
+
+	assert.Equal(t, 400, request("PUT", "/me/password", `{"currentPassword":"current"}`).Code)
+	assert.Equal(t, 204, request("PUT", "/me/password", `{"currentPassword":"current","newPassword":"newPassword"}`).Code)
+
+	assert.Equal(t, 400, request("DELETE", "/me", `{"password":"current"}`).Code)
+	assert.Equal(t, 204, request("DELETE", "/me", `{"password":"newPassword"}`).Code)
+	assert.Equal(t, 401, request("GET", "/me", "").Code)
+}
+
+func Test_userAdminHandler(t *testing.T) {
+	cfg := &config.Config{
+		JWTConfig: config.JWTConfig{SecretKey: "secret", AccessTokenLifeTime: 30},
+	}
+	admin, user, superAdmin := newProfileUser(t), newProfileUser(t), newProfileUser(t)
+	admin.Roles = []model.UserRole{{Role: model.RoleOrderSupport}}
+	admin.TwoFactorVerified = true
+	superAdmin.Roles = []model.UserRole{{Role: model.RoleSuperAdmin}}
+	repo := &mockUserRepository{users: []*model.User{admin, user, superAdmin}}
+	adminToken, _ := jwtHelper.GenerateAccessToken(admin, cfg)
+	userToken, _ := jwtHelper.GenerateAccessToken(user, cfg)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	authMiddleware := mw.AuthenticationMiddleware(cfg, repo)
+	NewUserAdminHandler(r.Group("/admin/users"), authMiddleware, NewUserAdminService(repo, &mockSessionService{}, &mockUserOrderRepository{}, &mockUserCartRepository{}))
+	NewUserHandler(r.Group("/me"), authMiddleware, NewUserService(repo, &mockSessionService{}))
+	request := func(token string, method string, path string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(w, req)
+		return w
+	}
+	userPath := "/admin/users/" + user.ID.String()
+
+	assert.Equal(t, 403, request(userToken, "GET", "/admin/users", "").Code)
+	assert.Equal(t, 200, request(adminToken, "GET", "/admin/users?q=example", "").Code)
+	assert.Equal(t, 200, request(adminToken, "GET", userPath, "").Code)
+	assert.Equal(t, 200, request(adminToken, "GET", userPath+"/orders", "").Code)
+	assert.Equal(t, 200, request(adminToken, "GET", userPath+"/carts", "").Code)
+	assert.Equal(t, 404, request(adminToken, "GET", "/admin/users/"+uuid.New().String(), "").Code)
+
+	// the token of a suspended user stops working at once
+	assert.Equal(t, 200, request(userToken, "GET", "/me", "").Code)
+	assert.Equal(t, 400, request(adminToken, "POST", userPath+"/suspend", `{}`).Code)
+	assert.Equal(t, 204, request(adminToken, "POST", userPath+"/suspend", `{"reason":"fraud"}`).Code)
+	assert.Equal(t, 403, request(userToken, "GET", "/me", "").Code)
+	assert.Equal(t, 204, request(adminToken, "POST", userPath+"/reactivate", "").Code)
+	assert.Equal(t, 200, request(userToken, "GET", "/me", "").Code)
+
+	// order_support cannot grant admin
+	assert.Equal(t, 403, request(adminToken, "PUT", userPath+"/admin", "").Code)
+	assert.Equal(t, 204, request(adminToken, "POST", userPath+"/password-reset", "").Code)
+
+	// order_support cannot take over the account of a super admin
+	superAdminPath := "/admin/users/" + superAdmin.ID.String()
+	assert.Equal(t, 403, request(adminToken, "POST", superAdminPath+"/suspend", `{"reason":"fraud"}`).Code)
+	assert.Equal(t, 403, request(adminToken, "POST", superAdminPath+"/password-reset", "").Code)
+	assert.False(t, superAdmin.IsSuspended())
+}
@@ -1,33 +1,55 @@
 package auth
 
 import (
+	"context"
+	"patika-ecommerce/pkg/tracing"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"patika-ecommerce/internal/model"
+	paginationHelper "patika-ecommerce/pkg/pagination"
 )
 
 type UserRepositoryInterface interface {
-	InsertUser(user *model.User) (*model.User, error)
-	GetUser(id string) (*model.User, error)
-	GetUserByEmail(email string) (*model.User, error)
+	InsertUser(ctx context.Context, user *model.User) (*model.User, error)
+	GetUser(ctx context.Context, id string) (*model.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
+	UpdatePassword(ctx context.Context, user *model.User) error
+	MarkEmailVerified(ctx context.Context, id uuid.UUID) error
+	IsEmailVerified(ctx context.Context, id uuid.UUID) (bool, error)
+	RecordFailedLogin(ctx context.Context, id uuid.UUID) (int, error)
+	LockUser(ctx context.Context, id uuid.UUID, until time.Time) error
+	UnlockUser(ctx context.Context, id uuid.UUID) (int64, error)
+	UpdateProfile(ctx context.Context, user *model.User) error
+	UpdateEmail(ctx context.Context, id uuid.UUID, email string) error
+	DeleteUser(ctx context.Context, id uuid.UUID) error
+	IsSuspended(ctx context.Context, id uuid.UUID) (bool, error)
+	SearchUsers(ctx context.Context, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
+	SuspendUser(ctx context.Context, id uuid.UUID, reason string) error
+	ReactivateUser(ctx context.Context, id uuid.UUID) error
+	SetAdmin(ctx context.Context, id uuid.UUID, isAdmin bool) error
 }
 type UserRepository struct {
 	db *gorm.DB
 }
 
-func (r *UserRepository) Migration() {
-	r.db.AutoMigrate(&model.User{})
-}
 func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
 // InsertUser insert user to database
-func (u *UserRepository) InsertUser(user *model.User) (*model.User, error) {
-	zap.L().Debug("user.repo.insertUser", zap.Reflect("user", user))
+func (u *UserRepository) InsertUser(ctx context.Context, user *model.User) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "user.repo.InsertUser")
+	defer span.End()
+
+	zap.L().Debug("user.repo.insertUser", tracing.Field(ctx), zap.Reflect("user", user))
 
-	result := u.db.Create(user)
+	result := u.db.WithContext(ctx).Create(user)
 
 	if result.Error != nil {
 		return nil, result.Error
@@ -37,12 +59,15 @@ func (u *UserRepository) InsertUser(user *model.User) (*model.User, error) {
 }
 
 // GetUser returns a user by id
-func (u *UserRepository) GetUser(id string) (*model.User, error) {
-	zap.L().Debug("user.repo.GetUser", zap.Reflect("id", id))
+func (u *UserRepository) GetUser(ctx context.Context, id string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "user.repo.GetUser")
+	defer span.End()
+
+	zap.L().Debug("user.repo.GetUser", tracing.Field(ctx), zap.Reflect("id", id))
 
 	var user model.User
 
-	result := u.db.First(&user, "id = ?", id)
+	result := u.db.WithContext(ctx).Preload("Roles").First(&user, "id = ? AND deleted_at IS NULL", id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -50,14 +75,266 @@ func (u *UserRepository) GetUser(id string) (*model.User, error) {
 }
 
 // GetUserByEmail returns a user by email
-func (u *UserRepository) GetUserByEmail(email string) (*model.User, error) {
-	zap.L().Debug("user.repo.GetUserByEmail", zap.Reflect("email", email))
+func (u *UserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "user.repo.GetUserByEmail")
+	defer span.End()
+
+	zap.L().Debug("user.repo.GetUserByEmail", tracing.Field(ctx), zap.Reflect("email", email))
 
 	var user model.User
 
-	result := u.db.First(&user, "email = ?", email)
+	result := u.db.WithContext(ctx).Preload("Roles").First(&user, "email = ? AND deleted_at IS NULL", email)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &user, nil
 }
+
+// UpdatePassword saves the password of the user
+func (u *UserRepository) UpdatePassword(ctx context.Context, user *model.User) error {
+	ctx, span := tracing.Start(ctx, "user.repo.UpdatePassword")
+	defer span.End()
+
+	zap.L().Debug("user.repo.UpdatePassword", tracing.Field(ctx), zap.Reflect("id", user.ID))
+
+	return u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", user.ID).
+		UpdateColumn("password", user.Password).Error
+}
+
+// MarkEmailVerified marks the email address of the user as verified
+func (u *UserRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "user.repo.MarkEmailVerified")
+	defer span.End()
+
+	zap.L().Debug("user.repo.MarkEmailVerified", tracing.Field(ctx), zap.Reflect("id", id))
+
+	return u.db.WithContext(ctx).Model(&model.User{}).Where("id = ? AND email_verified_at IS NULL", id).
+		UpdateColumn("email_verified_at", time.Now()).Error
+}
+
+// IsEmailVerified returns true when the user verified the email address
+func (u *UserRepository) IsEmailVerified(ctx context.Context, id uuid.UUID) (bool, error) {
+	ctx, span := tracing.Start(ctx, "user.repo.IsEmailVerified")
+	defer span.End()
+
+	zap.L().Debug("user.repo.IsEmailVerified", tracing.Field(ctx), zap.Reflect("id", id))
+
+	var count int64
+	err := u.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND email_verified_at IS NOT NULL", id).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RecordFailedLogin counts a failed login of the user and returns the failed
+// logins since the last successful one
+func (u *UserRepository) RecordFailedLogin(ctx context.Context, id uuid.UUID) (int, error) {
+	ctx, span := tracing.Start(ctx, "user.repo.RecordFailedLogin")
+	defer span.End()
+
+	zap.L().Debug("user.repo.RecordFailedLogin", tracing.Field(ctx), zap.Reflect("id", id))
+
+	var user model.User
+	result := u.db.WithContext(ctx).Model(&user).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_login_attempts"}}}).
+		Where("id = ?", id).
+		UpdateColumn("failed_login_attempts", gorm.Expr("failed_login_attempts + 1"))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return user.FailedLoginAttempts, nil
+}
+
+// LockUser locks the user until the given time
+func (u *UserRepository) LockUser(ctx context.Context, id uuid.UUID, until time.Time) error {
+	ctx, span := tracing.Start(ctx, "user.repo.LockUser")
+	defer span.End()
+
+	zap.L().Debug("user.repo.LockUser", tracing.Field(ctx), zap.Reflect("id", id), zap.Time("until", until))
+
+	return u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		UpdateColumn("locked_until", until).Error
+}
+
+// UnlockUser unlocks the user and resets the failed logins
+func (u *UserRepository) UnlockUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	ctx, span := tracing.Start(ctx, "user.repo.UnlockUser")
+	defer span.End()
+
+	zap.L().Debug("user.repo.UnlockUser", tracing.Field(ctx), zap.Reflect("id", id))
+
+	result := u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"failed_login_attempts": 0, "locked_until": nil})
+	return result.RowsAffected, result.Error
+}
+
+// UpdateProfile saves the name and the username of the user
+func (u *UserRepository) UpdateProfile(ctx context.Context, user *model.User) error {
+	ctx, span := tracing.Start(ctx, "user.repo.UpdateProfile")
+	defer span.End()
+
+	zap.L().Debug("user.repo.UpdateProfile", tracing.Field(ctx), zap.Reflect("id", user.ID))
+
+	return u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", user.ID).
+		UpdateColumns(map[string]interface{}{
+			"first_name": user.FirstName,
+			"last_name":  user.LastName,
+			"username":   user.Username,
+		}).Error
+}
+
+// UpdateEmail changes the email address of the user, which has to be verified again
+func (u *UserRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	ctx, span := tracing.Start(ctx, "user.repo.UpdateEmail")
+	defer span.End()
+
+	zap.L().Debug("user.repo.UpdateEmail", tracing.Field(ctx), zap.Reflect("id", id))
+
+	return u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"email": email, "email_verified_at": nil}).Error
+}
+
+// DeleteUser marks the user as deleted, the orders of the user are kept
+func (u *UserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "user.repo.DeleteUser")
+	defer span.End()
+
+	zap.L().Debug("user.repo.DeleteUser", tracing.Field(ctx), zap.Reflect("id", id))
+
+	tx := u.db.WithContext(ctx).Begin()
+
+	// the email address and the username are freed, so they can sign up again
+	deleted := "deleted-" + id.String()
+	result := tx.Model(&model.User{}).Where("id = ? AND deleted_at IS NULL", id).UpdateColumns(map[string]interface{}{
+		"email":      deleted + "@deleted.invalid",
+		"username":   deleted,
+		"deleted_at": time.Now(),
+	})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return gorm.ErrRecordNotFound
+	}
+
+	// the orders and the carts they were placed from are kept, the open
+	// carts are cancelled so they are not reminded
+	if err := tx.Model(&model.Cart{}).Where("user_id = ? AND status = ?", id, model.CartStatusCreated).
+		UpdateColumn("status", model.CartStatusCancelled).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("wishlist_id IN (?)", tx.Model(&model.Wishlist{}).Select("id").Where("user_id = ?", id)).
+		Delete(&model.WishlistItem{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, owned := range []interface{}{
+		&model.Wishlist{},
+		&model.StockSubscription{},
+		&model.UserToken{},
+	} {
+		if err := tx.Where("user_id = ?", id).Delete(owned).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// IsSuspended returns true when the user is suspended, and
+// gorm.ErrRecordNotFound when the user is deleted
+func (u *UserRepository) IsSuspended(ctx context.Context, id uuid.UUID) (bool, error) {
+	ctx, span := tracing.Start(ctx, "user.repo.IsSuspended")
+	defer span.End()
+
+	zap.L().Debug("user.repo.IsSuspended", tracing.Field(ctx), zap.Reflect("id", id))
+
+	var user model.User
+	result := u.db.WithContext(ctx).Select("id", "suspended_at").First(&user, "id = ? AND deleted_at IS NULL", id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return user.IsSuspended(), nil
+}
+
+// SearchUsers returns a page of the users, newest first. The users are
+// filtered by the name, the username or the email when Q is set.
+func (u *UserRepository) SearchUsers(ctx context.Context, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	ctx, span := tracing.Start(ctx, "user.repo.SearchUsers")
+	defer span.End()
+
+	zap.L().Debug("user.repo.SearchUsers", tracing.Field(ctx), zap.Reflect("pagination", pagination))
+
+	var (
+		users     []*model.User
+		totalRows int64
+	)
+
+	query := u.db.WithContext(ctx).Model(&model.User{}).Where("deleted_at IS NULL")
+	if pagination.Q != "" {
+		like := "%" + strings.ToLower(pagination.Q) + "%"
+		query = query.Where("LOWER(email) LIKE ? OR LOWER(username) LIKE ? OR LOWER(first_name || ' ' || last_name) LIKE ?", like, like, like)
+	}
+	if err := query.Count(&totalRows).Error; err != nil {
+		return nil, err
+	}
+
+	err := query.Preload("Roles").Order("created_at DESC").
+		Scopes(paginationHelper.Paginate(totalRows, pagination, u.db.WithContext(ctx))).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	pagination.Rows = UsersToAdminUserResponse(users)
+
+	return pagination, nil
+}
+
+// SuspendUser suspends the user with the given reason
+func (u *UserRepository) SuspendUser(ctx context.Context, id uuid.UUID, reason string) error {
+	ctx, span := tracing.Start(ctx, "user.repo.SuspendUser")
+	defer span.End()
+
+	zap.L().Debug("user.repo.SuspendUser", tracing.Field(ctx), zap.Reflect("id", id))
+
+	return u.updateActiveUser(ctx, id, map[string]interface{}{"suspended_at": time.Now(), "suspension_reason": reason})
+}
+
+// ReactivateUser lifts the suspension of the user
+func (u *UserRepository) ReactivateUser(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "user.repo.ReactivateUser")
+	defer span.End()
+
+	zap.L().Debug("user.repo.ReactivateUser", tracing.Field(ctx), zap.Reflect("id", id))
+
+	return u.updateActiveUser(ctx, id, map[string]interface{}{"suspended_at": nil, "suspension_reason": ""})
+}
+
+// SetAdmin grants or revokes the admin status of the user
+func (u *UserRepository) SetAdmin(ctx context.Context, id uuid.UUID, isAdmin bool) error {
+	ctx, span := tracing.Start(ctx, "user.repo.SetAdmin")
+	defer span.End()
+
+	zap.L().Debug("user.repo.SetAdmin", tracing.Field(ctx), zap.Reflect("id", id), zap.Bool("isAdmin", isAdmin))
+
+	return u.updateActiveUser(ctx, id, map[string]interface{}{"is_admin": isAdmin})
+}
+
+// updateActiveUser updates the columns of a user which is not deleted
+func (u *UserRepository) updateActiveUser(ctx context.Context, id uuid.UUID, columns map[string]interface{}) error {
+	result := u.db.WithContext(ctx).Model(&model.User{}).Where("id = ? AND deleted_at IS NULL", id).UpdateColumns(columns)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
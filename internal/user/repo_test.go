@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
 	"patika-ecommerce/internal/model"
 	"regexp"
@@ -36,6 +37,8 @@ var (
 	id        = uuid.New()
 )
 
+const rolesQuery = `SELECT * FROM "user_roles" WHERE "user_roles"."user_id" = $1`
+
 var u = model.User{
 	Base:      model.Base{ID: id},
 	FirstName: &firstName,
@@ -52,17 +55,20 @@ func TestUserRepository_GetUser(t *testing.T) {
 
 	// query := "SELECT id, first_name, last_name, username, email, is_admin FROM users WHERE id = \\?"
 
-	query := `SELECT * FROM "users" WHERE id = $1 ORDER BY "users"."id" LIMIT 1`
+	query := `SELECT * FROM "users" WHERE id = $1 AND deleted_at IS NULL ORDER BY "users"."id" LIMIT 1`
 
 	rows := sqlmock.NewRows([]string{"id", "first_name", "last_email", "username", "email", "is_admin"}).
 		AddRow(u.ID, u.FirstName, u.LastName, u.Username, u.Email, u.IsAdmin)
 
 	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(u.ID).WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta(rolesQuery)).WithArgs(u.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "role"}).AddRow(uuid.New(), u.ID, model.RoleCatalogManager))
 
-	user, _ := repo.GetUser(u.ID.String())
+	user, _ := repo.GetUser(context.Background(), u.ID.String())
 
 	assert.Equal(t, user.ID, id)
 	assert.Equal(t, *user.Username, username)
+	assert.Equal(t, []string{"catalog_manager"}, user.RoleNames())
 
 }
 
@@ -70,17 +76,20 @@ func TestUserRepository_GetUserByEmail(t *testing.T) {
 	db, mock := NewMock()
 	repo := &UserRepository{db}
 
-	query := `SELECT * FROM "users" WHERE email = $1 ORDER BY "users"."id" LIMIT 1`
+	query := `SELECT * FROM "users" WHERE email = $1 AND deleted_at IS NULL ORDER BY "users"."id" LIMIT 1`
 
 	rows := sqlmock.NewRows([]string{"id", "first_name", "last_email", "username", "email", "is_admin"}).
 		AddRow(u.ID, u.FirstName, u.LastName, u.Username, u.Email, u.IsAdmin)
 
 	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(*u.Email).WillReturnRows(rows)
+	mock.ExpectQuery(regexp.QuoteMeta(rolesQuery)).WithArgs(u.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "role"}).AddRow(uuid.New(), u.ID, model.RoleCatalogManager))
 
-	user, _ := repo.GetUserByEmail(*u.Email)
+	user, _ := repo.GetUserByEmail(context.Background(), *u.Email)
 
 	assert.Equal(t, user.ID, id)
 	assert.Equal(t, *user.Username, username)
+	assert.Equal(t, []string{"catalog_manager"}, user.RoleNames())
 }
 
 // func TestUserRepository_InserUser(t *testing.T) {
@@ -109,3 +118,40 @@ func TestUserRepository_GetUserByEmail(t *testing.T) {
 // 	assert.NoError(t, err)
 
 // }
+
+func TestUserRepository_DeleteUser(t *testing.T) {
+	db, mock := NewMock()
+	repo := &UserRepository{db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "deleted_at"=$1,"email"=$2,"username"=$3 WHERE id = $4 AND deleted_at IS NULL`)).
+		WithArgs(sqlmock.AnyArg(), "deleted-"+id.String()+"@deleted.invalid", "deleted-"+id.String(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "carts" SET "status"=$1 WHERE user_id = $2 AND status = $3`)).
+		WithArgs(model.CartStatusCancelled, id, model.CartStatusCreated).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "wishlist_items" WHERE wishlist_id IN (SELECT "id" FROM "wishlists" WHERE user_id = $1)`)).
+		WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "wishlists" WHERE user_id = $1`)).
+		WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "stock_subscriptions" WHERE user_id = $1`)).
+		WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "user_tokens" WHERE user_id = $1`)).
+		WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.DeleteUser(context.Background(), id))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_DeleteUser_notFound(t *testing.T) {
+	db, mock := NewMock()
+	repo := &UserRepository{db}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users"`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	assert.ErrorIs(t, repo.DeleteUser(context.Background(), id), gorm.ErrRecordNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
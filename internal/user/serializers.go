@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"patika-ecommerce/internal/api"
+	"patika-ecommerce/internal/cart"
+	"patika-ecommerce/internal/model"
+	common "patika-ecommerce/pkg/utils"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// UserToProfileResponse converts a user to a profile response
+func UserToProfileResponse(user *model.User) *api.UserProfileResponse {
+	return &api.UserProfileResponse{
+		ID:            common.UUIDToStrfmt(user.ID),
+		FirstName:     stringValue(user.FirstName),
+		LastName:      stringValue(user.LastName),
+		Username:      stringValue(user.Username),
+		Email:         stringValue(user.Email),
+		EmailVerified: user.IsEmailVerified(),
+		IsAdmin:       user.IsAdmin,
+		Roles:         user.RoleNames(),
+		CreatedAt:     strfmt.DateTime(user.CreatedAt),
+	}
+}
+
+// UserToAdminUserResponse converts a user to an admin user response
+func UserToAdminUserResponse(user *model.User) *api.AdminUserResponse {
+	return &api.AdminUserResponse{
+		ID:               common.UUIDToStrfmt(user.ID),
+		FirstName:        stringValue(user.FirstName),
+		LastName:         stringValue(user.LastName),
+		Username:         stringValue(user.Username),
+		Email:            stringValue(user.Email),
+		EmailVerified:    user.IsEmailVerified(),
+		IsAdmin:          user.IsAdmin,
+		Roles:            user.RoleNames(),
+		LockedUntil:      dateTimeValue(user.LockedUntil),
+		SuspendedAt:      dateTimeValue(user.SuspendedAt),
+		SuspensionReason: user.SuspensionReason,
+		CreatedAt:        strfmt.DateTime(user.CreatedAt),
+	}
+}
+
+// UsersToAdminUserResponse converts users to admin user responses
+func UsersToAdminUserResponse(users []*model.User) []*api.AdminUserResponse {
+	response := []*api.AdminUserResponse{}
+	for _, user := range users {
+		response = append(response, UserToAdminUserResponse(user))
+	}
+	return response
+}
+
+// CartsToCartResponse converts the carts of a user to cart responses
+func CartsToCartResponse(carts []model.Cart) []*api.CartResponse {
+	response := []*api.CartResponse{}
+	for i := range carts {
+		response = append(response, cart.CartToCartResponse(&carts[i]))
+	}
+	return response
+}
+
+// UpdateProfileRequestToProfileUpdate converts an update profile request to a profile update
+func UpdateProfileRequestToProfileUpdate(req *api.UpdateProfileRequest) *ProfileUpdate {
+	return &ProfileUpdate{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Username:  req.Username,
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func dateTimeValue(t *time.Time) *strfmt.DateTime {
+	if t == nil {
+		return nil
+	}
+	dateTime := strfmt.DateTime(*t)
+	return &dateTime
+}
@@ -0,0 +1,358 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+	"patika-ecommerce/pkg/tracing"
+	common "patika-ecommerce/pkg/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SessionServiceInterface is the part of the auth service the account changes
+// use, the auth service implements it
+type SessionServiceInterface interface {
+	LogoutAll(ctx context.Context, user *model.User) error
+	ResendVerification(ctx context.Context, user *model.User) error
+	ForgotPassword(ctx context.Context, email string) error
+}
+
+// UserOrderRepositoryInterface lists the orders of a user, the order
+// repository implements it
+type UserOrderRepositoryInterface interface {
+	GetOrdersByUser(ctx context.Context, user *model.User, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
+}
+
+// UserCartRepositoryInterface lists the carts of a user, the cart repository
+// implements it
+type UserCartRepositoryInterface interface {
+	GetCartsByUser(ctx context.Context, user *model.User) ([]model.Cart, error)
+}
+
+// ProfileUpdate is a change of the profile, the empty fields are kept
+type ProfileUpdate struct {
+	FirstName string
+	LastName  string
+	Username  string
+}
+
+type UserService struct {
+	userRepo       UserRepositoryInterface
+	sessionService SessionServiceInterface
+}
+
+type UserServiceInterface interface {
+	GetProfile(ctx context.Context, user *model.User) (*model.User, error)
+	UpdateProfile(ctx context.Context, user *model.User, update *ProfileUpdate) (*model.User, error)
+	ChangeEmail(ctx context.Context, user *model.User, email string, password string) (*model.User, error)
+	ChangePassword(ctx context.Context, user *model.User, currentPassword string, newPassword string) error
+	DeleteAccount(ctx context.Context, user *model.User, password string) error
+}
+
+// NewUserService creates a new UserService
+func NewUserService(userRepo UserRepositoryInterface, sessionService SessionServiceInterface) *UserService {
+	return &UserService{userRepo: userRepo, sessionService: sessionService}
+}
+
+// GetProfile returns the stored user, the token only carries a part of it
+func (s *UserService) GetProfile(ctx context.Context, user *model.User) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "user.service.GetProfile")
+	defer span.End()
+
+	return s.userRepo.GetUser(ctx, user.ID.String())
+}
+
+// UpdateProfile changes the name and the username of the user
+func (s *UserService) UpdateProfile(ctx context.Context, user *model.User, update *ProfileUpdate) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "user.service.UpdateProfile")
+	defer span.End()
+
+	stored, err := s.userRepo.GetUser(ctx, user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if update.FirstName != "" {
+		stored.FirstName = &update.FirstName
+	}
+	if update.LastName != "" {
+		stored.LastName = &update.LastName
+	}
+	if update.Username != "" {
+		stored.Username = &update.Username
+	}
+	if err := s.userRepo.UpdateProfile(ctx, stored); err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+// ChangeEmail changes the email address of the user with the current password
+// and mails a verification link to the new address
+func (s *UserService) ChangeEmail(ctx context.Context, user *model.User, email string, password string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "user.service.ChangeEmail")
+	defer span.End()
+
+	stored, err := s.checkedUser(ctx, user, password)
+	if err != nil {
+		return nil, err
+	}
+	if *stored.Email == email {
+		return stored, nil
+	}
+
+	if err := s.userRepo.UpdateEmail(ctx, stored.ID, email); err != nil {
+		return nil, err
+	}
+	stored.Email = &email
+	stored.EmailVerifiedAt = nil
+
+	// the user can ask for a new link, so a failed mail does not fail the change
+	if err := s.sessionService.ResendVerification(ctx, stored); err != nil {
+		zap.L().Error("user.service.ChangeEmail: cannot send verification mail", tracing.Field(ctx), zap.Error(err))
+	}
+	return stored, nil
+}
+
+// ChangePassword changes the password of the user with the current one and
+// revokes all sessions
+func (s *UserService) ChangePassword(ctx context.Context, user *model.User, currentPassword string, newPassword string) error {
+	ctx, span := tracing.Start(ctx, "user.service.ChangePassword")
+	defer span.End()
+
+	stored, err := s.checkedUser(ctx, user, currentPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := stored.SetPassword(newPassword); err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, stored); err != nil {
+		return err
+	}
+	return s.sessionService.LogoutAll(ctx, stored)
+}
+
+// DeleteAccount deletes the account of the user with the current password and
+// revokes all sessions
+func (s *UserService) DeleteAccount(ctx context.Context, user *model.User, password string) error {
+	ctx, span := tracing.Start(ctx, "user.service.DeleteAccount")
+	defer span.End()
+
+	stored, err := s.checkedUser(ctx, user, password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DeleteUser(ctx, stored.ID); err != nil {
+		return err
+	}
+	zap.L().Info("account deleted", tracing.Field(ctx), zap.Reflect("userID", stored.ID))
+	return s.sessionService.LogoutAll(ctx, stored)
+}
+
+// checkedUser returns the stored user when the password is right
+func (s *UserService) checkedUser(ctx context.Context, user *model.User, password string) (*model.User, error) {
+	stored, err := s.userRepo.GetUser(ctx, user.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	if !stored.CheckPassword(password) {
+		return nil, httpErr.WrongCurrentPasswordError
+	}
+	return stored, nil
+}
+
+type UserAdminService struct {
+	userRepo       UserRepositoryInterface
+	sessionService SessionServiceInterface
+	orderRepo      UserOrderRepositoryInterface
+	cartRepo       UserCartRepositoryInterface
+}
+
+type UserAdminServiceInterface interface {
+	SearchUsers(ctx context.Context, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*model.User, error)
+	GetUserOrders(ctx context.Context, id uuid.UUID, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error)
+	GetUserCarts(ctx context.Context, id uuid.UUID) ([]model.Cart, error)
+	SuspendUser(ctx context.Context, admin *model.User, id uuid.UUID, reason string) error
+	ReactivateUser(ctx context.Context, id uuid.UUID) error
+	SetAdmin(ctx context.Context, admin *model.User, id uuid.UUID, isAdmin bool) error
+	ForcePasswordReset(ctx context.Context, admin *model.User, id uuid.UUID) error
+}
+
+// NewUserAdminService creates a new UserAdminService
+func NewUserAdminService(userRepo UserRepositoryInterface, sessionService SessionServiceInterface,
+	orderRepo UserOrderRepositoryInterface, cartRepo UserCartRepositoryInterface) *UserAdminService {
+	return &UserAdminService{
+		userRepo:       userRepo,
+		sessionService: sessionService,
+		orderRepo:      orderRepo,
+		cartRepo:       cartRepo,
+	}
+}
+
+// SearchUsers returns a page of the users matching the query
+func (s *UserAdminService) SearchUsers(ctx context.Context, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	ctx, span := tracing.Start(ctx, "user.service.SearchUsers")
+	defer span.End()
+
+	return s.userRepo.SearchUsers(ctx, pagination)
+}
+
+// GetUser returns the user
+func (s *UserAdminService) GetUser(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "user.service.GetUser")
+	defer span.End()
+
+	return s.userRepo.GetUser(ctx, id.String())
+}
+
+// GetUserOrders returns a page of the orders of the user
+func (s *UserAdminService) GetUserOrders(ctx context.Context, id uuid.UUID, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	ctx, span := tracing.Start(ctx, "user.service.GetUserOrders")
+	defer span.End()
+
+	user, err := s.userRepo.GetUser(ctx, id.String())
+	if err != nil {
+		return nil, err
+	}
+	return s.orderRepo.GetOrdersByUser(ctx, user, pagination)
+}
+
+// GetUserCarts returns the carts of the user
+func (s *UserAdminService) GetUserCarts(ctx context.Context, id uuid.UUID) ([]model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "user.service.GetUserCarts")
+	defer span.End()
+
+	user, err := s.userRepo.GetUser(ctx, id.String())
+	if err != nil {
+		return nil, err
+	}
+	return s.cartRepo.GetCartsByUser(ctx, user)
+}
+
+// SuspendUser suspends the user and revokes all sessions. Admins cannot
+// suspend themselves nor the accounts they cannot manage.
+func (s *UserAdminService) SuspendUser(ctx context.Context, admin *model.User, id uuid.UUID, reason string) error {
+	ctx, span := tracing.Start(ctx, "user.service.SuspendUser")
+	defer span.End()
+
+	if admin.ID == id {
+		return httpErr.CannotManageOwnAccountError
+	}
+
+	user, err := s.userRepo.GetUser(ctx, id.String())
+	if err != nil {
+		return err
+	}
+	if !admin.CanManage(user) {
+		return httpErr.ForbiddenError
+	}
+	if err := s.userRepo.SuspendUser(ctx, id, reason); err != nil {
+		return err
+	}
+	zap.L().Info("account suspended", tracing.Field(ctx), zap.Reflect("userID", id), zap.Reflect("adminID", admin.ID))
+	return s.sessionService.LogoutAll(ctx, user)
+}
+
+// ReactivateUser lifts the suspension of the user
+func (s *UserAdminService) ReactivateUser(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "user.service.ReactivateUser")
+	defer span.End()
+
+	return s.userRepo.ReactivateUser(ctx, id)
+}
+
+// SetAdmin grants or revokes the admin status of the user. The sessions of a
+// revoked admin are closed, so the admin claim of the tokens cannot be
+// refreshed. Admins cannot change their own status.
+func (s *UserAdminService) SetAdmin(ctx context.Context, admin *model.User, id uuid.UUID, isAdmin bool) error {
+	ctx, span := tracing.Start(ctx, "user.service.SetAdmin")
+	defer span.End()
+
+	if admin.ID == id {
+		return httpErr.CannotManageOwnAccountError
+	}
+
+	user, err := s.userRepo.GetUser(ctx, id.String())
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.SetAdmin(ctx, id, isAdmin); err != nil {
+		return err
+	}
+	zap.L().Info("admin status changed", tracing.Field(ctx), zap.Reflect("userID", id), zap.Bool("isAdmin", isAdmin), zap.Reflect("adminID", admin.ID))
+	if isAdmin {
+		return nil
+	}
+	return s.sessionService.LogoutAll(ctx, user)
+}
+
+// ForcePasswordReset replaces the password of the user with a random one,
+// revokes all sessions and mails a password reset link. Admins cannot reset
+// the accounts they cannot manage.
+func (s *UserAdminService) ForcePasswordReset(ctx context.Context, admin *model.User, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "user.service.ForcePasswordReset")
+	defer span.End()
+
+	user, err := s.userRepo.GetUser(ctx, id.String())
+	if err != nil {
+		return err
+	}
+	if !admin.CanManage(user) {
+		return httpErr.ForbiddenError
+	}
+
+	password, err := common.GenerateToken(32)
+	if err != nil {
+		return err
+	}
+	if err := user.SetPassword(password); err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdatePassword(ctx, user); err != nil {
+		return err
+	}
+	if err := s.sessionService.LogoutAll(ctx, user); err != nil {
+		return err
+	}
+	return s.sessionService.ForgotPassword(ctx, *user.Email)
+}
+
+// CreateAdmin creates an admin account with a verified email address. An
+// existing user with the email address is made an admin instead and keeps its
+// password, created reports which one happened.
+func (s *UserAdminService) CreateAdmin(ctx context.Context, user *model.User) (admin *model.User, created bool, err error) {
+	ctx, span := tracing.Start(ctx, "user.service.CreateAdmin")
+	defer span.End()
+
+	existing, err := s.userRepo.GetUserByEmail(ctx, *user.Email)
+	if err == nil {
+		if err := s.userRepo.SetAdmin(ctx, existing.ID, true); err != nil {
+			return nil, false, err
+		}
+		existing.IsAdmin = true
+		zap.L().Info("existing user is made an admin", tracing.Field(ctx), zap.Reflect("userID", existing.ID))
+		return existing, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	user.IsAdmin, user.EmailVerifiedAt = true, &now
+	admin, err = s.userRepo.InsertUser(ctx, user)
+	if err != nil {
+		return nil, false, err
+	}
+	zap.L().Info("admin created", tracing.Field(ctx), zap.Reflect("userID", admin.ID))
+	return admin, true, nil
+}
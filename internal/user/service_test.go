@@ -0,0 +1,340 @@
+package auth
+
+import (
+	"context"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newProfileUser(t *testing.T) *model.User {
+	firstName, lastName, username, email := "first", "last", "user", "user@example.com"
+	now := time.Now()
+	user := &model.User{
+		Base:            model.Base{ID: uuid.New()},
+		FirstName:       &firstName,
+		LastName:        &lastName,
+		Username:        &username,
+		Email:           &email,
+		EmailVerifiedAt: &now,
+	}
+	assert.NoError(t, user.SetPassword("current"))
+	return user
+}
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	user := newProfileUser(t)
+	repo := &mockUserRepository{users: []*model.User{user}}
+	s := NewUserService(repo, &mockSessionService{})
+
+	updated, err := s.UpdateProfile(context.Background(), &model.User{Base: user.Base}, &ProfileUpdate{FirstName: "New", Username: "new_user"})
+	assert.NoError(t, err)
+	assert.Equal(t, "New", *updated.FirstName)
+	assert.Equal(t, "last", *updated.LastName)
+	assert.Equal(t, "new_user", *repo.users[0].Username)
+
+	profile, err := s.GetProfile(context.Background(), &model.User{Base: user.Base})
+	assert.NoError(t, err)
+	assert.Equal(t, "New", *profile.FirstName)
+}
+
+func TestUserService_ChangeEmail(t *testing.T) {
+	user := newProfileUser(t)
+	repo := &mockUserRepository{users: []*model.User{user}}
+	sessions := &mockSessionService{}
+	s := NewUserService(repo, sessions)
+
+	_, err := s.ChangeEmail(context.Background(), user, "new@example.com", "wrong")
+	assert.Equal(t, httpErr.WrongCurrentPasswordError, err)
+	assert.Equal(t, "user@example.com", *repo.users[0].Email)
+
+	updated, err := s.ChangeEmail(context.Background(), user, "new@example.com", "current")
+	assert.NoError(t, err)
+	assert.Equal(t, "new@example.com", *updated.Email)
+	assert.False(t, repo.users[0].IsEmailVerified())
+	assert.Equal(t, []string{"new@example.com"}, sessions.verificationsSent)
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	user := newProfileUser(t)
+	repo := &mockUserRepository{users: []*model.User{user}}
+	sessions := &mockSessionService{}
+	s := NewUserService(repo, sessions)
+
+	assert.Equal(t, httpErr.WrongCurrentPasswordError, s.ChangePassword(context.Background(), user, "wrong", "newPassword"))
+	assert.Equal(t, 0, sessions.loggedOut)
+
+	assert.NoError(t, s.ChangePassword(context.Background(), user, "current", "newPassword"))
+	assert.True(t, repo.users[0].CheckPassword("newPassword"))
+	assert.Equal(t, 1, sessions.loggedOut)
+}
+
+func TestUserService_DeleteAccount(t *testing.T) {
+	user := newProfileUser(t)
+	repo := &mockUserRepository{users: []*model.User{user}}
+	sessions := &mockSessionService{}
+	s := NewUserService(repo, sessions)
+
+	assert.Equal(t, httpErr.WrongCurrentPasswordError, s.DeleteAccount(context.Background(), user, "wrong"))
+	assert.NoError(t, s.DeleteAccount(context.Background(), user, "current"))
+	assert.Equal(t, 1, sessions.loggedOut)
+
+	_, err := s.GetProfile(context.Background(), user)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestUserAdminService(t *testing.T) {
+	admin, user := newProfileUser(t), newProfileUser(t)
+	otherEmail := "other@example.com"
+	admin.Email = &otherEmail
+	repo := &mockUserRepository{users: []*model.User{admin, user}}
+	sessions := &mockSessionService{}
+	orders := &mockUserOrderRepository{}
+	carts := &mockUserCartRepository{carts: []model.Cart{{UserID: user.ID}, {UserID: admin.ID}}}
+	s := NewUserAdminService(repo, sessions, orders, carts)
+
+	page, err := s.SearchUsers(context.Background(), &paginationHelper.Pagination{Q: "other"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.TotalRows)
+
+	userCarts, err := s.GetUserCarts(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(userCarts))
+	_, err = s.GetUserOrders(context.Background(), user.ID, &paginationHelper.Pagination{})
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, orders.userID)
+	_, err = s.GetUserOrders(context.Background(), uuid.New(), &paginationHelper.Pagination{})
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	// suspending closes the sessions, admins cannot suspend themselves
+	assert.Equal(t, httpErr.CannotManageOwnAccountError, s.SuspendUser(context.Background(), admin, admin.ID, "abuse"))
+	assert.NoError(t, s.SuspendUser(context.Background(), admin, user.ID, "abuse"))
+	assert.True(t, user.IsSuspended())
+	assert.Equal(t, "abuse", user.SuspensionReason)
+	assert.Equal(t, 1, sessions.loggedOut)
+	suspended, err := repo.IsSuspended(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.True(t, suspended)
+
+	assert.NoError(t, s.ReactivateUser(context.Background(), user.ID))
+	assert.False(t, user.IsSuspended())
+
+	// revoking admin closes the sessions, granting it does not
+	assert.Equal(t, httpErr.CannotManageOwnAccountError, s.SetAdmin(context.Background(), admin, admin.ID, false))
+	assert.NoError(t, s.SetAdmin(context.Background(), admin, user.ID, true))
+	assert.True(t, user.IsAdmin)
+	assert.Equal(t, 1, sessions.loggedOut)
+	assert.NoError(t, s.SetAdmin(context.Background(), admin, user.ID, false))
+	assert.False(t, user.IsAdmin)
+	assert.Equal(t, 2, sessions.loggedOut)
+
+	// a forced reset replaces the password and mails a link
+	assert.NoError(t, s.ForcePasswordReset(context.Background(), admin, user.ID))
+	assert.False(t, user.CheckPassword("current"))
+	assert.Equal(t, 3, sessions.loggedOut)
+	assert.Equal(t, []string{"user@example.com"}, sessions.resetsSent)
+	assert.Equal(t, gorm.ErrRecordNotFound, s.ForcePasswordReset(context.Background(), admin, uuid.New()))
+
+	// an admin is created verified, an existing user is promoted
+	firstName, lastName, adminEmail := "Ops", "Admin", "ops@example.com"
+	created, isNew, err := s.CreateAdmin(context.Background(), &model.User{FirstName: &firstName, LastName: &lastName, Email: &adminEmail, Password: "secret"})
+	assert.NoError(t, err)
+	assert.True(t, isNew)
+	assert.True(t, created.IsAdmin)
+	assert.True(t, created.IsEmailVerified())
+	promoted, isNew, err := s.CreateAdmin(context.Background(), &model.User{Email: user.Email, Password: "other"})
+	assert.NoError(t, err)
+	assert.False(t, isNew)
+	assert.Equal(t, user.ID, promoted.ID)
+	assert.True(t, user.IsAdmin)
+}
+
+type mockUserOrderRepository struct {
+	userID uuid.UUID
+}
+
+func (r *mockUserOrderRepository) GetOrdersByUser(ctx context.Context, user *model.User, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	r.userID = user.ID
+	pagination.Rows = []interface{}{}
+	return pagination, nil
+}
+
+type mockUserCartRepository struct {
+	carts []model.Cart
+}
+
+func (r *mockUserCartRepository) GetCartsByUser(ctx context.Context, user *model.User) ([]model.Cart, error) {
+	carts := []model.Cart{}
+	for _, cart := range r.carts {
+		if cart.UserID == user.ID {
+			carts = append(carts, cart)
+		}
+	}
+	return carts, nil
+}
+
+type mockSessionService struct {
+	loggedOut         int
+	verificationsSent []string
+	resetsSent        []string
+}
+
+func (s *mockSessionService) LogoutAll(ctx context.Context, user *model.User) error {
+	s.loggedOut++
+	return nil
+}
+
+func (s *mockSessionService) ResendVerification(ctx context.Context, user *model.User) error {
+	s.verificationsSent = append(s.verificationsSent, *user.Email)
+	return nil
+}
+
+func (s *mockSessionService) ForgotPassword(ctx context.Context, email string) error {
+	s.resetsSent = append(s.resetsSent, email)
+	return nil
+}
+
+type mockUserRepository struct {
+	users []*model.User
+}
+
+func (r *mockUserRepository) find(id uuid.UUID) *model.User {
+	for _, user := range r.users {
+		if user.ID == id && user.DeletedAt == nil {
+			return user
+		}
+	}
+	return nil
+}
+
+func (r *mockUserRepository) InsertUser(ctx context.Context, user *model.User) (*model.User, error) {
+	r.users = append(r.users, user)
+	return user, nil
+}
+
+func (r *mockUserRepository) GetUser(ctx context.Context, id string) (*model.User, error) {
+	parsed, _ := uuid.Parse(id)
+	if user := r.find(parsed); user != nil {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockUserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	for _, user := range r.users {
+		if *user.Email == email && user.DeletedAt == nil {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockUserRepository) UpdatePassword(ctx context.Context, user *model.User) error {
+	r.find(user.ID).Password = user.Password
+	return nil
+}
+
+func (r *mockUserRepository) MarkEmailVerified(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	r.find(id).EmailVerifiedAt = &now
+	return nil
+}
+
+func (r *mockUserRepository) IsEmailVerified(ctx context.Context, id uuid.UUID) (bool, error) {
+	return r.find(id).IsEmailVerified(), nil
+}
+
+func (r *mockUserRepository) RecordFailedLogin(ctx context.Context, id uuid.UUID) (int, error) {
+	user := r.find(id)
+	user.FailedLoginAttempts++
+	return user.FailedLoginAttempts, nil
+}
+
+func (r *mockUserRepository) LockUser(ctx context.Context, id uuid.UUID, until time.Time) error {
+	r.find(id).LockedUntil = &until
+	return nil
+}
+
+func (r *mockUserRepository) UnlockUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	user := r.find(id)
+	user.FailedLoginAttempts, user.LockedUntil = 0, nil
+	return 1, nil
+}
+
+func (r *mockUserRepository) UpdateProfile(ctx context.Context, user *model.User) error {
+	stored := r.find(user.ID)
+	stored.FirstName, stored.LastName, stored.Username = user.FirstName, user.LastName, user.Username
+	return nil
+}
+
+func (r *mockUserRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string) error {
+	stored := r.find(id)
+	stored.Email, stored.EmailVerifiedAt = &email, nil
+	return nil
+}
+
+func (r *mockUserRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	stored := r.find(id)
+	if stored == nil {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	stored.DeletedAt = &now
+	return nil
+}
+
+func (r *mockUserRepository) IsSuspended(ctx context.Context, id uuid.UUID) (bool, error) {
+	if user := r.find(id); user != nil {
+		return user.IsSuspended(), nil
+	}
+	return false, gorm.ErrRecordNotFound
+}
+
+func (r *mockUserRepository) SearchUsers(ctx context.Context, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	users := []*model.User{}
+	for _, user := range r.users {
+		if user.DeletedAt == nil && strings.Contains(*user.Email, pagination.Q) {
+			users = append(users, user)
+		}
+	}
+	pagination.TotalRows = int64(len(users))
+	pagination.Rows = UsersToAdminUserResponse(users)
+	return pagination, nil
+}
+
+func (r *mockUserRepository) SuspendUser(ctx context.Context, id uuid.UUID, reason string) error {
+	user := r.find(id)
+	if user == nil {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	user.SuspendedAt, user.SuspensionReason = &now, reason
+	return nil
+}
+
+func (r *mockUserRepository) ReactivateUser(ctx context.Context, id uuid.UUID) error {
+	user := r.find(id)
+	if user == nil {
+		return gorm.ErrRecordNotFound
+	}
+	user.SuspendedAt, user.SuspensionReason = nil, ""
+	return nil
+}
+
+func (r *mockUserRepository) SetAdmin(ctx context.Context, id uuid.UUID, isAdmin bool) error {
+	user := r.find(id)
+	if user == nil {
+		return gorm.ErrRecordNotFound
+	}
+	user.IsAdmin = isAdmin
+	return nil
+}
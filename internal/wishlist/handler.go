@@ -0,0 +1,325 @@
+package wishlist
+
+import (
+	"patika-ecommerce/internal/api"
+	"patika-ecommerce/internal/cart"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	common "patika-ecommerce/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+)
+
+type wishlistHandler struct {
+	wishlistService WishlistServiceInterface
+}
+
+// NewWishlistHandler creates a new wishlist handler
+func NewWishlistHandler(r *gin.RouterGroup, authMiddleware gin.HandlerFunc, wishlistService WishlistServiceInterface) {
+	handler := &wishlistHandler{wishlistService: wishlistService}
+
+	r.Use(authMiddleware)
+	r.GET("", handler.getWishlists)
+	r.POST("", handler.createWishlist)
+	r.GET("/:id", handler.getWishlist)
+	r.PUT("/:id", handler.updateWishlist)
+	r.DELETE("/:id", handler.deleteWishlist)
+	r.POST("/:id/share", handler.shareWishlist)
+	r.DELETE("/:id/share", handler.unshareWishlist)
+	r.POST("/:id/items", handler.addItem)
+	r.DELETE("/:id/items/:itemId", handler.removeItem)
+	r.POST("/:id/items/:itemId/move-to-cart", handler.moveToCart)
+	r.POST("/:id/move-from-cart", handler.moveFromCart)
+}
+
+// NewSharedWishlistHandler creates the public handler of shared wishlists
+func NewSharedWishlistHandler(r *gin.RouterGroup, wishlistService WishlistServiceInterface) {
+	handler := &wishlistHandler{wishlistService: wishlistService}
+
+	r.GET("/:token", handler.getSharedWishlist)
+}
+
+// getWishlists lists the wishlists of the user
+func (r *wishlistHandler) getWishlists(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+
+	wishlists, err := r.wishlistService.GetWishlists(c.Request.Context(), user)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, WishlistsToWishlistResponse(wishlists))
+}
+
+// createWishlist creates a new wishlist
+func (r *wishlistHandler) createWishlist(c *gin.Context) {
+	reqBody := &api.WishlistRequest{}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	wishlist, err := r.wishlistService.CreateWishlist(c.Request.Context(), user, *reqBody.Name)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(201, WishlistToWishlistResponse(wishlist))
+}
+
+// getWishlist gets a wishlist of the user
+func (r *wishlistHandler) getWishlist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	wishlist, err := r.wishlistService.GetWishlist(c.Request.Context(), user, id)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, WishlistToWishlistResponse(wishlist))
+}
+
+// getSharedWishlist gets a wishlist by its share token
+func (r *wishlistHandler) getSharedWishlist(c *gin.Context) {
+	wishlist, err := r.wishlistService.GetSharedWishlist(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, WishlistToWishlistResponse(wishlist))
+}
+
+// updateWishlist renames a wishlist
+func (r *wishlistHandler) updateWishlist(c *gin.Context) {
+	reqBody := &api.WishlistRequest{}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	wishlist, err := r.wishlistService.RenameWishlist(c.Request.Context(), user, id, *reqBody.Name)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, WishlistToWishlistResponse(wishlist))
+}
+
+// deleteWishlist deletes a wishlist
+func (r *wishlistHandler) deleteWishlist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	if err := r.wishlistService.DeleteWishlist(c.Request.Context(), user, id); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, map[string]string{"message": "wishlist deleted"})
+}
+
+// shareWishlist creates a public share link of a wishlist
+func (r *wishlistHandler) shareWishlist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	wishlist, err := r.wishlistService.ShareWishlist(c.Request.Context(), user, id)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, WishlistToWishlistResponse(wishlist))
+}
+
+// unshareWishlist revokes the public share link of a wishlist
+func (r *wishlistHandler) unshareWishlist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	wishlist, err := r.wishlistService.UnshareWishlist(c.Request.Context(), user, id)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, WishlistToWishlistResponse(wishlist))
+}
+
+// addItem adds a product to a wishlist
+func (r *wishlistHandler) addItem(c *gin.Context) {
+	reqBody := &api.WishlistItemRequest{}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	productID, err := common.StrfmtToUUID(*reqBody.ProductID)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	item, err := r.wishlistService.AddItem(c.Request.Context(), user, id, productID)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(201, WishlistItemToWishlistItemResponse(item))
+}
+
+// removeItem removes an item from a wishlist
+func (r *wishlistHandler) removeItem(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	if err := r.wishlistService.RemoveItem(c.Request.Context(), user, id, itemID); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, map[string]string{"message": "item removed"})
+}
+
+// moveToCart moves a wishlist item to the cart
+func (r *wishlistHandler) moveToCart(c *gin.Context) {
+	reqBody := &api.MoveToCartRequest{}
+
+	// the body is optional, one item is moved by default
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&reqBody); err != nil {
+			c.JSON(httpErr.ErrorResponse(err))
+			return
+		}
+
+		if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+			c.JSON(httpErr.ErrorResponse(err))
+			return
+		}
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	itemID, err := uuid.Parse(c.Param("itemId"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	userCart, err := r.wishlistService.MoveToCart(c.Request.Context(), user, id, itemID, reqBody.Quantity)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, cart.CartToCartResponse(userCart))
+}
+
+// moveFromCart saves a cart item for later in a wishlist
+func (r *wishlistHandler) moveFromCart(c *gin.Context) {
+	reqBody := &api.MoveFromCartRequest{}
+
+	if err := c.ShouldBindJSON(&reqBody); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	if err := reqBody.Validate(strfmt.NewFormats()); err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(httpErr.InvalidIDError))
+		return
+	}
+
+	cartItemID, err := common.StrfmtToUUID(*reqBody.CartItemID)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	user := c.MustGet("user").(*model.User)
+	item, err := r.wishlistService.MoveFromCart(c.Request.Context(), user, id, cartItemID)
+	if err != nil {
+		c.JSON(httpErr.ErrorResponse(err))
+		return
+	}
+
+	c.JSON(200, WishlistItemToWishlistItemResponse(item))
+}
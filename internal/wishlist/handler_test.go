@@ -0,0 +1,96 @@
+package wishlist
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"patika-ecommerce/internal/model"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+)
+
+func TestNewWishlistHandler_routes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	s := NewWishlistService(&mockWishlistRepo{}, &mockProductRepo{})
+
+	NewWishlistHandler(r.Group("/wishlists"), func(c *gin.Context) {}, s)
+	NewSharedWishlistHandler(r.Group("/shared-wishlists"), s)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/shared-wishlists/unknown", nil)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_wishlistHandler_createWishlist(t *testing.T) {
+	user := model.User{Base: model.Base{ID: uuid.New()}}
+
+	tests := []struct {
+		name    string
+		payload string
+		want    int
+	}{
+		{name: "createWishlist_Succeed", payload: `{"name": "Birthday"}`, want: http.StatusCreated},
+		{name: "createWishlist_Failed_emptyName", payload: `{"name": ""}`, want: http.StatusBadRequest},
+		{name: "createWishlist_Failed_missingName", payload: `{}`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &wishlistHandler{wishlistService: NewWishlistService(&mockWishlistRepo{}, &mockProductRepo{})}
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/wishlists", nil)
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(tt.payload)))
+			c.Set("user", &user)
+			handler.createWishlist(c)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func Test_wishlistHandler_addItem(t *testing.T) {
+	user := model.User{Base: model.Base{ID: uuid.New()}}
+	product := newProduct(50, 0)
+
+	repo := &mockWishlistRepo{}
+	s := NewWishlistService(repo, &mockProductRepo{items: []model.Product{product}})
+	wishlist, _ := s.CreateWishlist(context.Background(), &user, "Later")
+
+	tests := []struct {
+		name       string
+		wishlistID string
+		payload    string
+		want       int
+	}{
+		{name: "addItem_Succeed", wishlistID: wishlist.ID.String(), payload: `{"productId": "` + product.ID.String() + `"}`, want: http.StatusCreated},
+		{name: "addItem_Failed_invalidProductID", wishlistID: wishlist.ID.String(), payload: `{"productId": "abc"}`, want: http.StatusBadRequest},
+		{name: "addItem_Failed_wishlistNotFound", wishlistID: uuid.NewString(), payload: `{"productId": "` + product.ID.String() + `"}`, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &wishlistHandler{wishlistService: s}
+
+			w := httptest.NewRecorder()
+			gin.SetMode(gin.TestMode)
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/wishlists/"+tt.wishlistID+"/items", nil)
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Request.Body = ioutil.NopCloser(bytes.NewBuffer([]byte(tt.payload)))
+			c.Params = gin.Params{{Key: "id", Value: tt.wishlistID}}
+			c.Set("user", &user)
+			handler.addItem(c)
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
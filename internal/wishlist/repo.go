@@ -0,0 +1,269 @@
+package wishlist
+
+import (
+	"context"
+	"errors"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WishlistRepositoryInterface interface {
+	InsertWishlist(ctx context.Context, wishlist *model.Wishlist) error
+	GetWishlistsByUser(ctx context.Context, user *model.User) ([]model.Wishlist, error)
+	GetWishlistByIDAndUser(ctx context.Context, user *model.User, id uuid.UUID) (*model.Wishlist, error)
+	GetWishlistByShareToken(ctx context.Context, token string) (*model.Wishlist, error)
+	UpdateWishlist(ctx context.Context, wishlist *model.Wishlist) error
+	DeleteWishlist(ctx context.Context, wishlist *model.Wishlist) error
+	InsertItem(ctx context.Context, item *model.WishlistItem) error
+	DeleteItem(ctx context.Context, item *model.WishlistItem) error
+	MoveToCart(ctx context.Context, user *model.User, item *model.WishlistItem, quantity int64) (*model.Cart, error)
+	MoveFromCart(ctx context.Context, user *model.User, cartItemID uuid.UUID, wishlist *model.Wishlist) (*model.WishlistItem, error)
+}
+
+type WishlistRepository struct {
+	db *gorm.DB
+}
+
+func NewWishlistRepository(db *gorm.DB) *WishlistRepository {
+	return &WishlistRepository{db: db}
+}
+
+// InsertWishlist creates a new wishlist
+func (r *WishlistRepository) InsertWishlist(ctx context.Context, wishlist *model.Wishlist) error {
+	ctx, span := tracing.Start(ctx, "wishlist.repo.InsertWishlist")
+	defer span.End()
+
+	zap.L().Debug("wishlist.repo.InsertWishlist", tracing.Field(ctx), zap.Reflect("wishlist", wishlist))
+
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(wishlist).Error
+}
+
+// GetWishlistsByUser returns all wishlists of a user with their items
+func (r *WishlistRepository) GetWishlistsByUser(ctx context.Context, user *model.User) ([]model.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.repo.GetWishlistsByUser")
+	defer span.End()
+
+	zap.L().Debug("wishlist.repo.GetWishlistsByUser", tracing.Field(ctx), zap.Reflect("user", user.ID))
+
+	var wishlists []model.Wishlist
+	if err := r.db.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	}).Preload("Items.Product").
+		Where("user_id = ?", user.ID).
+		Order("created_at ASC").
+		Find(&wishlists).Error; err != nil {
+		return nil, err
+	}
+	return wishlists, nil
+}
+
+// GetWishlistByIDAndUser returns a wishlist of a user with its items
+func (r *WishlistRepository) GetWishlistByIDAndUser(ctx context.Context, user *model.User, id uuid.UUID) (*model.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.repo.GetWishlistByIDAndUser")
+	defer span.End()
+
+	zap.L().Debug("wishlist.repo.GetWishlistByIDAndUser", tracing.Field(ctx), zap.Reflect("user", user.ID), zap.Reflect("id", id))
+
+	wishlist := &model.Wishlist{}
+	if err := r.db.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	}).Preload("Items.Product").
+		Where("id = ? AND user_id = ?", id, user.ID).
+		First(wishlist).Error; err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// GetWishlistByShareToken returns a shared wishlist with its items
+func (r *WishlistRepository) GetWishlistByShareToken(ctx context.Context, token string) (*model.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.repo.GetWishlistByShareToken")
+	defer span.End()
+
+	zap.L().Debug("wishlist.repo.GetWishlistByShareToken", tracing.Field(ctx))
+
+	wishlist := &model.Wishlist{}
+	if err := r.db.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at DESC")
+	}).Preload("Items.Product").
+		Where("share_token = ?", token).
+		First(wishlist).Error; err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// UpdateWishlist updates name and share token of a wishlist
+func (r *WishlistRepository) UpdateWishlist(ctx context.Context, wishlist *model.Wishlist) error {
+	ctx, span := tracing.Start(ctx, "wishlist.repo.UpdateWishlist")
+	defer span.End()
+
+	zap.L().Debug("wishlist.repo.UpdateWishlist", tracing.Field(ctx), zap.Reflect("wishlist", wishlist.ID))
+
+	return r.db.WithContext(ctx).Model(wishlist).Select("name", "share_token").Updates(wishlist).Error
+}
+
+// DeleteWishlist deletes a wishlist with its items
+func (r *WishlistRepository) DeleteWishlist(ctx context.Context, wishlist *model.Wishlist) error {
+	ctx, span := tracing.Start(ctx, "wishlist.repo.DeleteWishlist")
+	defer span.End()
+
+	zap.L().Debug("wishlist.repo.DeleteWishlist", tracing.Field(ctx), zap.Reflect("wishlist", wishlist.ID))
+
+	tx := r.db.WithContext(ctx).Begin()
+	if err := tx.Where("wishlist_id = ?", wishlist.ID).Delete(&model.WishlistItem{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Delete(wishlist).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// InsertItem adds a product to a wishlist
+func (r *WishlistRepository) InsertItem(ctx context.Context, item *model.WishlistItem) error {
+	ctx, span := tracing.Start(ctx, "wishlist.repo.InsertItem")
+	defer span.End()
+
+	zap.L().Debug("wishlist.repo.InsertItem", tracing.Field(ctx), zap.Reflect("item", item))
+
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(item).Error
+}
+
+// DeleteItem removes an item from a wishlist
+func (r *WishlistRepository) DeleteItem(ctx context.Context, item *model.WishlistItem) error {
+	ctx, span := tracing.Start(ctx, "wishlist.repo.DeleteItem")
+	defer span.End()
+
+	zap.L().Debug("wishlist.repo.DeleteItem", tracing.Field(ctx), zap.Reflect("item", item.ID))
+
+	return r.db.WithContext(ctx).Delete(item).Error
+}
+
+// MoveToCart adds the product of the wishlist item to the created cart of the
+// user, creating the cart when there is none, and removes the wishlist item
+func (r *WishlistRepository) MoveToCart(ctx context.Context, user *model.User, item *model.WishlistItem, quantity int64) (*model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.repo.MoveToCart")
+	defer span.End()
+
+	zap.L().Debug("wishlist.repo.MoveToCart", tracing.Field(ctx), zap.Reflect("user", user.ID), zap.Reflect("item", item.ID), zap.Int64("quantity", quantity))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	cart := &model.Cart{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND status = ?", user.ID, model.CartStatusCreated).
+		First(cart).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Rollback()
+			return nil, err
+		}
+		cart = &model.Cart{UserID: user.ID}
+		if err := tx.Omit(clause.Associations).Create(cart).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	product := &model.Product{}
+	if err := tx.Where("id = ? AND deleted_at IS NULL", item.ProductID).First(product).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	cartItem := &model.CartItem{}
+	err := tx.Where("cart_id = ? AND product_id = ?", cart.ID, item.ProductID).First(cartItem).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return nil, err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		cartItem = &model.CartItem{CartID: cart.ID, ProductID: item.ProductID, Price: product.Price}
+	}
+
+	cartItem.Quantity += quantity
+	if product.Stock == nil || cartItem.Quantity > *product.Stock {
+		tx.Rollback()
+		return nil, httpErr.InsufficientStockError
+	}
+
+	if err := tx.Omit(clause.Associations).Save(cartItem).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Delete(item).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.db.WithContext(ctx).Preload("Items.Product").Where("id = ?", cart.ID).First(cart).Error; err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// MoveFromCart saves a cart item of the user to the wishlist and removes it from the cart
+func (r *WishlistRepository) MoveFromCart(ctx context.Context, user *model.User, cartItemID uuid.UUID, wishlist *model.Wishlist) (*model.WishlistItem, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.repo.MoveFromCart")
+	defer span.End()
+
+	zap.L().Debug("wishlist.repo.MoveFromCart", tracing.Field(ctx), zap.Reflect("user", user.ID), zap.Reflect("cartItemID", cartItemID), zap.Reflect("wishlist", wishlist.ID))
+
+	tx := r.db.WithContext(ctx).Begin()
+
+	cartItem := &model.CartItem{}
+	if err := tx.Joins("JOIN carts ON carts.id = cart_items.cart_id").
+		Preload("Product").
+		Where("cart_items.id = ? AND carts.user_id = ? AND carts.status = ?", cartItemID, user.ID, model.CartStatusCreated).
+		First(cartItem).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, httpErr.CartItemNotFoundError
+		}
+		return nil, err
+	}
+
+	item := &model.WishlistItem{}
+	err := tx.Where("wishlist_id = ? AND product_id = ?", wishlist.ID, cartItem.ProductID).First(item).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return nil, err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		item = &model.WishlistItem{
+			WishlistID:     wishlist.ID,
+			ProductID:      cartItem.ProductID,
+			PriceWhenAdded: cartItem.Product.Price,
+		}
+		if err := tx.Omit(clause.Associations).Create(item).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Delete(&model.CartItem{}, "id = ?", cartItem.ID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	item.Product = cartItem.Product
+	return item, nil
+}
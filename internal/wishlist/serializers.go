@@ -0,0 +1,58 @@
+package wishlist
+
+import (
+	"patika-ecommerce/internal/api"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/internal/product"
+	common "patika-ecommerce/pkg/utils"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// WishlistToWishlistResponse converts a wishlist to a wishlist response
+func WishlistToWishlistResponse(wishlist *model.Wishlist) *api.WishlistResponse {
+	shareToken := ""
+	if wishlist.ShareToken != nil {
+		shareToken = *wishlist.ShareToken
+	}
+
+	return &api.WishlistResponse{
+		ID:         common.UUIDToStrfmt(wishlist.ID),
+		Name:       wishlist.Name,
+		ShareToken: shareToken,
+		Items:      WishlistItemsToWishlistItemResponse(wishlist.Items),
+		CreatedAt:  strfmt.DateTime(wishlist.CreatedAt),
+	}
+}
+
+// WishlistsToWishlistResponse converts wishlists to wishlist responses
+func WishlistsToWishlistResponse(wishlists []model.Wishlist) []*api.WishlistResponse {
+	response := []*api.WishlistResponse{}
+	for _, wishlist := range wishlists {
+		response = append(response, WishlistToWishlistResponse(&wishlist))
+	}
+	return response
+}
+
+// WishlistItemToWishlistItemResponse converts a wishlist item to a wishlist item response
+func WishlistItemToWishlistItemResponse(item *model.WishlistItem) *api.WishlistItemResponse {
+	return &api.WishlistItemResponse{
+		ID:             common.UUIDToStrfmt(item.ID),
+		Product:        product.ProductToProductBasicResponse(&item.Product),
+		PriceWhenAdded: item.PriceWhenAdded,
+		CurrentPrice:   item.Product.Price,
+		PriceDropped:   item.PriceDrop() > 0,
+		PriceDrop:      item.PriceDrop(),
+		InStock:        item.Product.Stock != nil && *item.Product.Stock > 0,
+		AddedAt:        strfmt.DateTime(item.CreatedAt),
+	}
+}
+
+// WishlistItemsToWishlistItemResponse converts wishlist items to wishlist item responses
+func WishlistItemsToWishlistItemResponse(items []model.WishlistItem) []*api.WishlistItemResponse {
+	response := []*api.WishlistItemResponse{}
+	for _, item := range items {
+		response = append(response, WishlistItemToWishlistItemResponse(&item))
+	}
+	return response
+}
@@ -0,0 +1,224 @@
+package wishlist
+
+import (
+	"context"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/internal/product"
+	"patika-ecommerce/pkg/tracing"
+	common "patika-ecommerce/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+type WishlistServiceInterface interface {
+	GetWishlists(ctx context.Context, user *model.User) ([]model.Wishlist, error)
+	GetWishlist(ctx context.Context, user *model.User, id uuid.UUID) (*model.Wishlist, error)
+	GetSharedWishlist(ctx context.Context, token string) (*model.Wishlist, error)
+	CreateWishlist(ctx context.Context, user *model.User, name string) (*model.Wishlist, error)
+	RenameWishlist(ctx context.Context, user *model.User, id uuid.UUID, name string) (*model.Wishlist, error)
+	DeleteWishlist(ctx context.Context, user *model.User, id uuid.UUID) error
+	ShareWishlist(ctx context.Context, user *model.User, id uuid.UUID) (*model.Wishlist, error)
+	UnshareWishlist(ctx context.Context, user *model.User, id uuid.UUID) (*model.Wishlist, error)
+	AddItem(ctx context.Context, user *model.User, id uuid.UUID, productID uuid.UUID) (*model.WishlistItem, error)
+	RemoveItem(ctx context.Context, user *model.User, id uuid.UUID, itemID uuid.UUID) error
+	MoveToCart(ctx context.Context, user *model.User, id uuid.UUID, itemID uuid.UUID, quantity int64) (*model.Cart, error)
+	MoveFromCart(ctx context.Context, user *model.User, id uuid.UUID, cartItemID uuid.UUID) (*model.WishlistItem, error)
+}
+
+type WishlistService struct {
+	wishlistRepo WishlistRepositoryInterface
+	productRepo  product.ProductRepositoryInterface
+}
+
+// NewWishlistService creates a new WishlistService
+func NewWishlistService(wishlistRepo WishlistRepositoryInterface, productRepo product.ProductRepositoryInterface) *WishlistService {
+	return &WishlistService{wishlistRepo: wishlistRepo, productRepo: productRepo}
+}
+
+// GetWishlists returns all wishlists of the user
+func (s *WishlistService) GetWishlists(ctx context.Context, user *model.User) ([]model.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.service.GetWishlists")
+	defer span.End()
+
+	return s.wishlistRepo.GetWishlistsByUser(ctx, user)
+}
+
+// GetWishlist returns a wishlist of the user
+func (s *WishlistService) GetWishlist(ctx context.Context, user *model.User, id uuid.UUID) (*model.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.service.GetWishlist")
+	defer span.End()
+
+	return s.wishlistRepo.GetWishlistByIDAndUser(ctx, user, id)
+}
+
+// GetSharedWishlist returns a wishlist by its share token
+func (s *WishlistService) GetSharedWishlist(ctx context.Context, token string) (*model.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.service.GetSharedWishlist")
+	defer span.End()
+
+	return s.wishlistRepo.GetWishlistByShareToken(ctx, token)
+}
+
+// CreateWishlist creates a new named wishlist for the user
+func (s *WishlistService) CreateWishlist(ctx context.Context, user *model.User, name string) (*model.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.service.CreateWishlist")
+	defer span.End()
+
+	wishlist := &model.Wishlist{UserID: user.ID, Name: name, Items: []model.WishlistItem{}}
+	if err := s.wishlistRepo.InsertWishlist(ctx, wishlist); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// RenameWishlist renames a wishlist of the user
+func (s *WishlistService) RenameWishlist(ctx context.Context, user *model.User, id uuid.UUID, name string) (*model.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.service.RenameWishlist")
+	defer span.End()
+
+	wishlist, err := s.wishlistRepo.GetWishlistByIDAndUser(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	wishlist.Name = name
+	if err := s.wishlistRepo.UpdateWishlist(ctx, wishlist); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// DeleteWishlist deletes a wishlist of the user
+func (s *WishlistService) DeleteWishlist(ctx context.Context, user *model.User, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "wishlist.service.DeleteWishlist")
+	defer span.End()
+
+	wishlist, err := s.wishlistRepo.GetWishlistByIDAndUser(ctx, user, id)
+	if err != nil {
+		return err
+	}
+	return s.wishlistRepo.DeleteWishlist(ctx, wishlist)
+}
+
+// ShareWishlist creates a new public share token, the previous one stops working
+func (s *WishlistService) ShareWishlist(ctx context.Context, user *model.User, id uuid.UUID) (*model.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.service.ShareWishlist")
+	defer span.End()
+
+	wishlist, err := s.wishlistRepo.GetWishlistByIDAndUser(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := common.GenerateToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	wishlist.ShareToken = &token
+	if err := s.wishlistRepo.UpdateWishlist(ctx, wishlist); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// UnshareWishlist revokes the public share token of a wishlist
+func (s *WishlistService) UnshareWishlist(ctx context.Context, user *model.User, id uuid.UUID) (*model.Wishlist, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.service.UnshareWishlist")
+	defer span.End()
+
+	wishlist, err := s.wishlistRepo.GetWishlistByIDAndUser(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	wishlist.ShareToken = nil
+	if err := s.wishlistRepo.UpdateWishlist(ctx, wishlist); err != nil {
+		return nil, err
+	}
+	return wishlist, nil
+}
+
+// AddItem adds a product to a wishlist, keeping its current price.
+// Adding a product which is already in the wishlist returns the existing item.
+func (s *WishlistService) AddItem(ctx context.Context, user *model.User, id uuid.UUID, productID uuid.UUID) (*model.WishlistItem, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.service.AddItem")
+	defer span.End()
+
+	wishlist, err := s.wishlistRepo.GetWishlistByIDAndUser(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range wishlist.Items {
+		if item.ProductID == productID {
+			return &item, nil
+		}
+	}
+
+	product, err := s.productRepo.GetProductWithoutCategories(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &model.WishlistItem{
+		WishlistID:     wishlist.ID,
+		ProductID:      product.ID,
+		Product:        *product,
+		PriceWhenAdded: product.Price,
+	}
+	if err := s.wishlistRepo.InsertItem(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// RemoveItem removes an item from a wishlist
+func (s *WishlistService) RemoveItem(ctx context.Context, user *model.User, id uuid.UUID, itemID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "wishlist.service.RemoveItem")
+	defer span.End()
+
+	wishlist, err := s.wishlistRepo.GetWishlistByIDAndUser(ctx, user, id)
+	if err != nil {
+		return err
+	}
+
+	item, err := wishlist.GetItemByID(itemID)
+	if err != nil {
+		return err
+	}
+	return s.wishlistRepo.DeleteItem(ctx, item)
+}
+
+// MoveToCart moves a wishlist item to the cart of the user
+func (s *WishlistService) MoveToCart(ctx context.Context, user *model.User, id uuid.UUID, itemID uuid.UUID, quantity int64) (*model.Cart, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.service.MoveToCart")
+	defer span.End()
+
+	wishlist, err := s.wishlistRepo.GetWishlistByIDAndUser(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := wishlist.GetItemByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quantity < 1 {
+		quantity = 1
+	}
+	return s.wishlistRepo.MoveToCart(ctx, user, item, quantity)
+}
+
+// MoveFromCart saves a cart item of the user for later in a wishlist
+func (s *WishlistService) MoveFromCart(ctx context.Context, user *model.User, id uuid.UUID, cartItemID uuid.UUID) (*model.WishlistItem, error) {
+	ctx, span := tracing.Start(ctx, "wishlist.service.MoveFromCart")
+	defer span.End()
+
+	wishlist, err := s.wishlistRepo.GetWishlistByIDAndUser(ctx, user, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.wishlistRepo.MoveFromCart(ctx, user, cartItemID, wishlist)
+}
@@ -0,0 +1,276 @@
+package wishlist
+
+import (
+	"context"
+	"fmt"
+	"patika-ecommerce/internal/model"
+	paginationHelper "patika-ecommerce/pkg/pagination"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+func newProduct(price float64, stock int64) model.Product {
+	name, sku := "Product", uuid.NewString()
+	return model.Product{Base: model.Base{ID: uuid.New()}, Name: &name, SKU: &sku, Price: price, Stock: &stock}
+}
+
+func TestWishlistService_AddItem(t *testing.T) {
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+	product := newProduct(100, 5)
+
+	repo := &mockWishlistRepo{}
+	s := NewWishlistService(repo, &mockProductRepo{items: []model.Product{product}})
+
+	wishlist, err := s.CreateWishlist(context.Background(), user, "Birthday")
+	assert.Equal(t, nil, err)
+
+	t.Run("addItem_Succeed", func(t *testing.T) {
+		item, err := s.AddItem(context.Background(), user, wishlist.ID, product.ID)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, float64(100), item.PriceWhenAdded)
+		assert.Equal(t, 1, len(repo.items))
+	})
+
+	t.Run("addItem_alreadyInWishlist", func(t *testing.T) {
+		_, err := s.AddItem(context.Background(), user, wishlist.ID, product.ID)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 1, len(repo.items))
+	})
+
+	t.Run("addItem_Failed_productNotFound", func(t *testing.T) {
+		_, err := s.AddItem(context.Background(), user, wishlist.ID, uuid.New())
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+
+	t.Run("addItem_Failed_otherUsersWishlist", func(t *testing.T) {
+		other := &model.User{Base: model.Base{ID: uuid.New()}}
+		_, err := s.AddItem(context.Background(), other, wishlist.ID, product.ID)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+	})
+}
+
+func TestWishlistService_ShareWishlist(t *testing.T) {
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+	repo := &mockWishlistRepo{}
+	s := NewWishlistService(repo, &mockProductRepo{})
+
+	wishlist, _ := s.CreateWishlist(context.Background(), user, "Home")
+
+	shared, err := s.ShareWishlist(context.Background(), user, wishlist.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, shared.IsShared())
+	token := *shared.ShareToken
+
+	found, err := s.GetSharedWishlist(context.Background(), token)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, wishlist.ID, found.ID)
+
+	// sharing again rotates the link
+	shared, _ = s.ShareWishlist(context.Background(), user, wishlist.ID)
+	assert.NotEqual(t, token, *shared.ShareToken)
+	_, err = s.GetSharedWishlist(context.Background(), token)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	unshared, err := s.UnshareWishlist(context.Background(), user, wishlist.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, unshared.IsShared())
+}
+
+func TestWishlistService_MoveToCart(t *testing.T) {
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+	product := newProduct(100, 2)
+
+	tests := []struct {
+		name     string
+		quantity int64
+		wantErr  bool
+		want     int64
+	}{
+		{name: "moveToCart_Succeed_defaultQuantity", quantity: 0, want: 1},
+		{name: "moveToCart_Succeed", quantity: 2, want: 2},
+		{name: "moveToCart_Failed_notEnough", quantity: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockWishlistRepo{products: []model.Product{product}}
+			s := NewWishlistService(repo, &mockProductRepo{items: []model.Product{product}})
+
+			wishlist, _ := s.CreateWishlist(context.Background(), user, "Later")
+			item, _ := s.AddItem(context.Background(), user, wishlist.ID, product.ID)
+
+			cart, err := s.MoveToCart(context.Background(), user, wishlist.ID, item.ID, tt.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WishlistService.MoveToCart() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				assert.Equal(t, 1, len(repo.items))
+				return
+			}
+			assert.Equal(t, 0, len(repo.items))
+			assert.Equal(t, tt.want, cart.Items[0].Quantity)
+		})
+	}
+}
+
+func TestWishlistService_RemoveItem(t *testing.T) {
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+	product := newProduct(100, 2)
+
+	repo := &mockWishlistRepo{}
+	s := NewWishlistService(repo, &mockProductRepo{items: []model.Product{product}})
+
+	wishlist, _ := s.CreateWishlist(context.Background(), user, "Later")
+	item, _ := s.AddItem(context.Background(), user, wishlist.ID, product.ID)
+
+	assert.NotEqual(t, nil, s.RemoveItem(context.Background(), user, wishlist.ID, uuid.New()))
+	assert.Equal(t, nil, s.RemoveItem(context.Background(), user, wishlist.ID, item.ID))
+	assert.Equal(t, 0, len(repo.items))
+}
+
+type mockWishlistRepo struct {
+	wishlists []model.Wishlist
+	items     []model.WishlistItem
+	products  []model.Product
+	carts     []model.Cart
+}
+
+func (r *mockWishlistRepo) withItems(wishlist model.Wishlist) *model.Wishlist {
+	wishlist.Items = []model.WishlistItem{}
+	for _, item := range r.items {
+		if item.WishlistID == wishlist.ID {
+			wishlist.Items = append(wishlist.Items, item)
+		}
+	}
+	return &wishlist
+}
+
+func (r *mockWishlistRepo) InsertWishlist(ctx context.Context, wishlist *model.Wishlist) error {
+	wishlist.ID = uuid.New()
+	r.wishlists = append(r.wishlists, *wishlist)
+	return nil
+}
+
+func (r *mockWishlistRepo) GetWishlistsByUser(ctx context.Context, user *model.User) ([]model.Wishlist, error) {
+	wishlists := []model.Wishlist{}
+	for _, wishlist := range r.wishlists {
+		if wishlist.UserID == user.ID {
+			wishlists = append(wishlists, *r.withItems(wishlist))
+		}
+	}
+	return wishlists, nil
+}
+
+func (r *mockWishlistRepo) GetWishlistByIDAndUser(ctx context.Context, user *model.User, id uuid.UUID) (*model.Wishlist, error) {
+	for _, wishlist := range r.wishlists {
+		if wishlist.ID == id && wishlist.UserID == user.ID {
+			return r.withItems(wishlist), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockWishlistRepo) GetWishlistByShareToken(ctx context.Context, token string) (*model.Wishlist, error) {
+	for _, wishlist := range r.wishlists {
+		if wishlist.ShareToken != nil && *wishlist.ShareToken == token {
+			return r.withItems(wishlist), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockWishlistRepo) UpdateWishlist(ctx context.Context, wishlist *model.Wishlist) error {
+	for i := range r.wishlists {
+		if r.wishlists[i].ID == wishlist.ID {
+			r.wishlists[i].Name = wishlist.Name
+			r.wishlists[i].ShareToken = wishlist.ShareToken
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *mockWishlistRepo) DeleteWishlist(ctx context.Context, wishlist *model.Wishlist) error {
+	for i := range r.wishlists {
+		if r.wishlists[i].ID == wishlist.ID {
+			r.wishlists = append(r.wishlists[:i], r.wishlists[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *mockWishlistRepo) InsertItem(ctx context.Context, item *model.WishlistItem) error {
+	item.ID = uuid.New()
+	r.items = append(r.items, *item)
+	return nil
+}
+
+func (r *mockWishlistRepo) DeleteItem(ctx context.Context, item *model.WishlistItem) error {
+	for i := range r.items {
+		if r.items[i].ID == item.ID {
+			r.items = append(r.items[:i], r.items[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *mockWishlistRepo) MoveToCart(ctx context.Context, user *model.User, item *model.WishlistItem, quantity int64) (*model.Cart, error) {
+	for _, product := range r.products {
+		if product.ID == item.ProductID {
+			if quantity > *product.Stock {
+				return nil, fmt.Errorf("Product stock is not enough")
+			}
+			cart := model.Cart{
+				UserID: user.ID,
+				Status: model.CartStatusCreated,
+				Items:  []model.CartItem{{ProductID: product.ID, Quantity: quantity, Price: product.Price}},
+			}
+			r.carts = append(r.carts, cart)
+			return &cart, r.DeleteItem(ctx, item)
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockWishlistRepo) MoveFromCart(ctx context.Context, user *model.User, cartItemID uuid.UUID, wishlist *model.Wishlist) (*model.WishlistItem, error) {
+	return nil, fmt.Errorf("Cart item not found")
+}
+
+type mockProductRepo struct {
+	items []model.Product
+}
+
+func (r *mockProductRepo) Insert(ctx context.Context, product *model.Product) error {
+	r.items = append(r.items, *product)
+	return nil
+}
+
+func (r *mockProductRepo) GetAll(ctx context.Context, pagination *paginationHelper.Pagination) (*paginationHelper.Pagination, error) {
+	return pagination, nil
+}
+
+func (r *mockProductRepo) Get(ctx context.Context, id uuid.UUID) (*model.Product, error) {
+	return r.GetProductWithoutCategories(ctx, id)
+}
+
+func (r *mockProductRepo) GetProductWithoutCategories(ctx context.Context, id uuid.UUID) (*model.Product, error) {
+	for _, product := range r.items {
+		if product.ID == id {
+			return &product, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *mockProductRepo) Delete(ctx context.Context, product *model.Product) error {
+	return nil
+}
+
+func (r *mockProductRepo) Update(ctx context.Context, product *model.Product) error {
+	return nil
+}
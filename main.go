@@ -1,74 +1,124 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
-	"net/http"
+	"os"
 	"patika-ecommerce/pkg/config"
 	db "patika-ecommerce/pkg/database"
-	"patika-ecommerce/pkg/graceful"
 	logger "patika-ecommerce/pkg/logging"
-	"patika-ecommerce/pkg/router"
-	"time"
+	"patika-ecommerce/pkg/tracing"
 
-	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+// app is what the commands share: the configuration and the database
+type app struct {
+	cfg *config.Config
+	db  *gorm.DB
+}
+
+// command is a subcommand of the CLI
+type command struct {
+	name  string
+	args  string
+	usage string
+	run   func(a *app, args []string) error
+}
+
+var commands = []command{
+	{name: "serve", usage: "start the API server, the default command", run: runServe},
+	{name: "migrate", args: "up | down [steps] | status", usage: "apply, roll back or list the database migrations", run: runMigrate},
+	{name: "create-admin", args: "--email <email> [--password <password>]", usage: "create an admin or make an existing user an admin", run: runCreateAdmin},
+	{name: "seed", usage: "insert the demo catalog into an empty database", run: runSeed},
+	{name: "import-categories", args: "<csv>", usage: "import categories from a Name,Description file", run: runImportCategories},
+	{name: "import-products", args: "<csv>", usage: "import products from a Name,SKU,Description,Price,Stock,Categories file", run: runImportProducts},
+	{name: "reindex-search", usage: "rebuild the product search indexes", run: runReindexSearch},
+}
+
+// defaultConfigFile is loaded without the --config flag
+const defaultConfigFile = "./pkg/config/config-local"
+
 func main() {
-	log.Println("Starting server...")
+	configFile := flag.String("config", defaultConfigFile,
+		"configuration file, empty to configure from the environment only")
+	flag.Usage = printUsage
+	flag.Parse()
 
-	// Load the configuration file.
+	name, args := "serve", flag.Args()
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		printUsage()
+		if name == "help" || name == "-h" || name == "--help" {
+			return
+		}
+		os.Exit(2)
+	}
 
-	cfg, err := config.LoadConfig("./pkg/config/config-local")
+	// Load the configuration file.
+	cfg, err := config.LoadConfig(*configFile)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
 	// Set logger
 	logger.NewLogger(cfg)
-	defer logger.Close()
 
-	// Connect to database
-	DB := db.NewPsqlDB(cfg)
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.TracingConfig)
 	if err != nil {
-		log.Fatal(err)
+		log.Fatalf("Failed to start tracing: %v", err)
 	}
 
-	r := gin.Default()
-
-	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%s", cfg.ServerConfig.Port),
-		Handler:      r,
-		ReadTimeout:  time.Duration(cfg.ServerConfig.ReadTimeoutSecs) * time.Second,
-		WriteTimeout: time.Duration(cfg.ServerConfig.WriteTimeoutSecs) * time.Second,
+	// Connect to database
+	a := &app{cfg: cfg, db: db.NewPsqlDB(cfg)}
+	if err := a.db.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatalf("Failed to trace the queries: %v", err)
 	}
 
-	rootRouter := r.Group(cfg.ServerConfig.RoutePrefix)
-	router.InitializeRoutes(rootRouter, DB, cfg)
-
-	rootRouter.GET("/healthz", func(c *gin.Context) {
-		c.JSON(http.StatusOK, nil)
-	})
-
-	rootRouter.GET("/readyz", func(c *gin.Context) {
-		db, err := DB.DB()
-		if err != nil {
-			zap.L().Fatal("cannot get sql database instance", zap.Error(err))
-		}
-		if err := db.Ping(); err != nil {
-			zap.L().Fatal("cannot ping database", zap.Error(err))
-		}
-		c.JSON(http.StatusOK, nil)
-	})
+	err = cmd.run(a, args)
+	if err := shutdownTracing(context.Background()); err != nil {
+		zap.L().Error("cannot flush the spans", zap.Error(err))
+	}
+	logger.Close()
+	if err != nil {
+		log.Fatalf("%s: %v", cmd.name, err)
+	}
+}
 
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Listen error: %v", err)
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: patika-ecommerce [--config <file>] <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\nflags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-18s %s\n", cmd.name, cmd.usage)
+		if cmd.args != "" {
+			fmt.Fprintf(os.Stderr, "  %-18s   %s %s\n", "", cmd.name, cmd.args)
 		}
-	}()
-	log.Println("Patika ecommerce service started")
+	}
+}
 
-	// Wait for interrupt signal to gracefully shutdown the server with
-	graceful.ShutdownGin(srv, time.Duration(cfg.ServerConfig.TimeoutSecs*int64(time.Second)))
+// verifySchema stops the commands using the database before it is migrated,
+// the schema is only changed by the migrate command
+func (a *app) verifySchema() error {
+	migrator, err := a.migrator()
+	if err != nil {
+		return err
+	}
+	if err := migrator.Verify(context.Background()); err != nil {
+		return fmt.Errorf("%w, run the migrate up command first", err)
+	}
+	return nil
 }
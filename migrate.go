@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"patika-ecommerce/pkg/migration"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = "usage: patika-ecommerce migrate up | down [steps] | status"
+
+// migrator loads the migrations of the configured folder
+func (a *app) migrator() (*migration.Migrator, error) {
+	folder := a.cfg.DBConfig.MigrationFolder
+	if folder == "" {
+		folder = migration.DefaultFolder
+	}
+	migrations, err := migration.Load(folder)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load migrations from %s: %w", folder, err)
+	}
+	sqlDB, err := a.db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migration.NewMigrator(sqlDB, migrations), nil
+}
+
+// runMigrate applies, rolls back or lists the migrations
+func runMigrate(a *app, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	migrator, err := a.migrator()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("database schema is up to date")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return errors.New(migrateUsage)
+			}
+			steps = n
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			fmt.Printf("rolled back %d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,27 @@
+DROP TRIGGER IF EXISTS stock_movements_append_only ON stock_movements;
+DROP FUNCTION IF EXISTS stock_movements_append_only();
+
+DROP TABLE IF EXISTS "job_runs";
+DROP TABLE IF EXISTS "data_exports";
+DROP TABLE IF EXISTS "order_items";
+DROP TABLE IF EXISTS "orders";
+DROP TABLE IF EXISTS "wishlist_items";
+DROP TABLE IF EXISTS "wishlists";
+DROP TABLE IF EXISTS "cart_items";
+DROP TABLE IF EXISTS "carts";
+DROP TABLE IF EXISTS "stock_movements";
+DROP TABLE IF EXISTS "stock_levels";
+DROP TABLE IF EXISTS "warehouses";
+DROP TABLE IF EXISTS "stock_subscriptions";
+DROP TABLE IF EXISTS "product_categories";
+DROP TABLE IF EXISTS "products";
+DROP TABLE IF EXISTS "categories";
+DROP TABLE IF EXISTS "user_roles";
+DROP TABLE IF EXISTS "o_id_c_login_requests";
+DROP TABLE IF EXISTS "external_identities";
+DROP TABLE IF EXISTS "recovery_codes";
+DROP TABLE IF EXISTS "two_factors";
+DROP TABLE IF EXISTS "user_tokens";
+DROP TABLE IF EXISTS "refresh_tokens";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "idempotency_keys";
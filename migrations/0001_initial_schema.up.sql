@@ -0,0 +1,382 @@
+-- Schema of the service as it was created by the GORM auto migrations.
+-- A database created by an earlier version is adopted: the missing tables are
+-- created and the columns added to a table after its first version are added
+-- when they are missing, since CREATE TABLE IF NOT EXISTS leaves the existing
+-- tables as they are.
+
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS "idempotency_keys" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "key" varchar(255) NOT NULL,
+    "scope" varchar(255) NOT NULL,
+    "request_hash" varchar(64) NOT NULL,
+    "status_code" bigint NOT NULL DEFAULT 0,
+    "content_type" varchar(100),
+    "response_body" bytea,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_key_scope" ON "idempotency_keys" ("key","scope");
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "first_name" varchar(100) NOT NULL,
+    "last_name" varchar(100) NOT NULL,
+    "username" text UNIQUE,
+    "email" text UNIQUE,
+    "password" varchar(100) NOT NULL,
+    "is_admin" boolean,
+    "email_verified_at" timestamptz,
+    "failed_login_attempts" bigint NOT NULL DEFAULT 0,
+    "locked_until" timestamptz,
+    "suspended_at" timestamptz,
+    "suspension_reason" varchar(255),
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "email_verified_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "failed_login_attempts" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "locked_until" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "suspended_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "suspension_reason" varchar(255);
+
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "family_id" uuid NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "session_started_at" timestamptz NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "rotated_at" timestamptz,
+    "revoked_at" timestamptz,
+    "user_agent" varchar(255),
+    "ip" varchar(45),
+    "two_factor_verified" boolean NOT NULL DEFAULT false,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_refresh_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_family_id" ON "refresh_tokens" ("family_id");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_id" ON "refresh_tokens" ("user_id");
+ALTER TABLE "refresh_tokens" ADD COLUMN IF NOT EXISTS "two_factor_verified" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS "user_tokens" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "purpose" varchar(30) NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_tokens_token_hash" ON "user_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_user_tokens_user_id" ON "user_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "two_factors" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "secret" varchar(64) NOT NULL,
+    "confirmed_at" timestamptz,
+    "last_used_step" bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_two_factors_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_two_factors_user_id" ON "two_factors" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "recovery_codes" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "code_hash" varchar(64) NOT NULL,
+    "used_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_recovery_codes_user_id" ON "recovery_codes" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "external_identities" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "provider" varchar(50) NOT NULL,
+    "subject" varchar(255) NOT NULL,
+    "email" varchar(100),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_external_identities_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_external_identity_subject" ON "external_identities" ("provider","subject");
+CREATE INDEX IF NOT EXISTS "idx_external_identities_user_id" ON "external_identities" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "o_id_c_login_requests" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "provider" varchar(50) NOT NULL,
+    "state_hash" varchar(64) NOT NULL,
+    "nonce" varchar(64) NOT NULL,
+    "code_verifier" varchar(128) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_o_id_c_login_requests_state_hash" ON "o_id_c_login_requests" ("state_hash");
+
+CREATE TABLE IF NOT EXISTS "user_roles" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "role" varchar(50) NOT NULL,
+    "granted_by" uuid,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_roles" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_role" ON "user_roles" ("user_id","role");
+
+CREATE TABLE IF NOT EXISTS "categories" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "name" varchar(100) NOT NULL UNIQUE,
+    "slug" varchar(100) NOT NULL UNIQUE,
+    "description" varchar(255),
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "products" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "name" text,
+    "slug" text UNIQUE,
+    "description" text,
+    "price" decimal(20,2),
+    "stock" bigint,
+    "sku" text UNIQUE,
+    "low_stock_threshold" bigint,
+    "low_stock_alerted" boolean DEFAULT false,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "low_stock_threshold" bigint;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "low_stock_alerted" boolean DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS "product_categories" (
+    "product_id" uuid DEFAULT uuid_generate_v4(),
+    "category_id" uuid DEFAULT uuid_generate_v4(),
+    PRIMARY KEY ("product_id","category_id"),
+    CONSTRAINT "fk_product_categories_product" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_product_categories_category" FOREIGN KEY ("category_id") REFERENCES "categories"("id") ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "stock_subscriptions" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "notified_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_stock_subscriptions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_stock_subscriptions_product" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_stock_subscriptions_user_product" ON "stock_subscriptions" ("user_id","product_id");
+
+CREATE TABLE IF NOT EXISTS "warehouses" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "name" varchar(100) NOT NULL UNIQUE,
+    "code" varchar(20) NOT NULL UNIQUE,
+    "address" varchar(255),
+    "priority" bigint NOT NULL DEFAULT 0,
+    "is_active" boolean NOT NULL DEFAULT true,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "stock_levels" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "warehouse_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "quantity" bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_stock_levels_warehouse" FOREIGN KEY ("warehouse_id") REFERENCES "warehouses"("id"),
+    CONSTRAINT "fk_stock_levels_product" FOREIGN KEY ("product_id") REFERENCES "products"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_stock_levels_warehouse_product" ON "stock_levels" ("warehouse_id","product_id");
+
+CREATE TABLE IF NOT EXISTS "stock_movements" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "product_id" uuid NOT NULL,
+    "warehouse_id" uuid NOT NULL,
+    "type" varchar(20) NOT NULL,
+    "quantity" bigint NOT NULL,
+    "reason" varchar(255),
+    "balance_after" bigint NOT NULL DEFAULT 0,
+    "reference_id" uuid,
+    "user_id" uuid,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_stock_movements_product" FOREIGN KEY ("product_id") REFERENCES "products"("id"),
+    CONSTRAINT "fk_stock_movements_warehouse" FOREIGN KEY ("warehouse_id") REFERENCES "warehouses"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_reference_id" ON "stock_movements" ("reference_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_warehouse_id" ON "stock_movements" ("warehouse_id");
+CREATE INDEX IF NOT EXISTS "idx_stock_movements_product_id" ON "stock_movements" ("product_id");
+ALTER TABLE "stock_movements" ADD COLUMN IF NOT EXISTS "balance_after" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "carts" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "status" varchar(10) NOT NULL,
+    "user_id" uuid,
+    "abandoned_at" timestamptz,
+    "reminder_sent_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_carts_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+ALTER TABLE "carts" ADD COLUMN IF NOT EXISTS "abandoned_at" timestamptz;
+ALTER TABLE "carts" ADD COLUMN IF NOT EXISTS "reminder_sent_at" timestamptz;
+
+CREATE TABLE IF NOT EXISTS "cart_items" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "cart_id" uuid,
+    "product_id" uuid,
+    "quantity" bigint NOT NULL,
+    "price" decimal NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_carts_items" FOREIGN KEY ("cart_id") REFERENCES "carts"("id"),
+    CONSTRAINT "fk_cart_items_product" FOREIGN KEY ("product_id") REFERENCES "products"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "wishlists" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "share_token" varchar(64) UNIQUE,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_wishlists_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_wishlists_user_name" ON "wishlists" ("user_id","name");
+
+CREATE TABLE IF NOT EXISTS "wishlist_items" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "wishlist_id" uuid NOT NULL,
+    "product_id" uuid NOT NULL,
+    "price_when_added" decimal(20,2) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_wishlist_items_product" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_wishlists_items" FOREIGN KEY ("wishlist_id") REFERENCES "wishlists"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_wishlist_items_wishlist_product" ON "wishlist_items" ("wishlist_id","product_id");
+
+CREATE TABLE IF NOT EXISTS "orders" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid,
+    "status" text,
+    "cart_id" uuid,
+    "total_price" numeric(10,2),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_orders_cart" FOREIGN KEY ("cart_id") REFERENCES "carts"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "order_items" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "order_id" uuid,
+    "product_id" uuid,
+    "warehouse_id" uuid,
+    "price" numeric(10,2),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_order_items_product" FOREIGN KEY ("product_id") REFERENCES "products"("id"),
+    CONSTRAINT "fk_orders_items" FOREIGN KEY ("order_id") REFERENCES "orders"("id")
+);
+ALTER TABLE "order_items" ADD COLUMN IF NOT EXISTS "warehouse_id" uuid;
+
+CREATE TABLE IF NOT EXISTS "data_exports" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "user_id" uuid NOT NULL,
+    "status" varchar(20) NOT NULL,
+    "file_name" varchar(255),
+    "size" bigint NOT NULL DEFAULT 0,
+    "completed_at" timestamptz,
+    "expires_at" timestamptz,
+    "error" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_data_exports_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_data_exports_user_id" ON "data_exports" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "job_runs" (
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "id" uuid DEFAULT uuid_generate_v4(),
+    "name" varchar(100) NOT NULL,
+    "status" varchar(20) NOT NULL,
+    "started_at" timestamptz NOT NULL,
+    "finished_at" timestamptz,
+    "message" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_job_runs_name" ON "job_runs" ("name");
+
+-- the stock ledger is append-only on the database side as well
+CREATE OR REPLACE FUNCTION stock_movements_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock movements are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stock_movements_append_only ON stock_movements;
+CREATE TRIGGER stock_movements_append_only BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_append_only();
@@ -0,0 +1,6 @@
+-- the product search matches substrings of the name and the sku with ILIKE,
+-- which only trigram indexes can serve
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS "idx_products_name_trgm" ON "products" USING gin ("name" gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS "idx_products_sku_trgm" ON "products" USING gin ("sku" gin_trgm_ops);
@@ -0,0 +1,2 @@
+-- the opening balances are kept, the stock ledger is append-only
+SELECT 1;
@@ -0,0 +1,21 @@
+-- the stock of the products created before the warehouses is put into the
+-- primary warehouse with an opening balance in the ledger, a database without
+-- an active warehouse gets the default one
+
+INSERT INTO "warehouses" ("created_at", "updated_at", "name", "code", "priority", "is_active")
+SELECT now(), now(), 'Default Warehouse', 'DEFAULT', 0, true
+WHERE NOT EXISTS (SELECT 1 FROM "warehouses" WHERE "is_active");
+
+WITH "primary_warehouse" AS (
+    SELECT "id" FROM "warehouses" WHERE "is_active" ORDER BY "priority" ASC, "created_at" ASC LIMIT 1
+), "levels" AS (
+    INSERT INTO "stock_levels" ("created_at", "updated_at", "warehouse_id", "product_id", "quantity")
+    SELECT now(), now(), "primary_warehouse"."id", "products"."id", "products"."stock"
+    FROM "products" CROSS JOIN "primary_warehouse"
+    WHERE "products"."stock" > 0
+      AND NOT EXISTS (SELECT 1 FROM "stock_levels" WHERE "stock_levels"."product_id" = "products"."id")
+    RETURNING "warehouse_id", "product_id", "quantity"
+)
+INSERT INTO "stock_movements" ("created_at", "updated_at", "product_id", "warehouse_id", "type", "quantity", "reason", "balance_after")
+SELECT now(), now(), "product_id", "warehouse_id", 'import', "quantity", 'opening balance', "quantity"
+FROM "levels";
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_products_stock_changed_at";
+ALTER TABLE "products" DROP COLUMN IF EXISTS "stock_changed_at";
@@ -0,0 +1,3 @@
+-- products whose stock changed are queued for the stock alert job
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "stock_changed_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_products_stock_changed_at" ON "products" ("stock_changed_at") WHERE "stock_changed_at" IS NOT NULL;
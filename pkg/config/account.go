@@ -0,0 +1,26 @@
+package config
+
+// Account config
+type AccountConfig struct {
+	// AppURL is the frontend URL the mailed links point to
+	AppURL string
+	// PasswordResetTokenMinutes is how long a password reset link is valid
+	PasswordResetTokenMinutes int
+	// EmailVerificationTokenHours is how long an email verification link is valid
+	EmailVerificationTokenHours int
+	// RequireVerifiedEmailForCheckout blocks checkout for unverified users
+	RequireVerifiedEmailForCheckout bool
+
+	// LockoutThreshold is the number of failed logins which lock the account,
+	// the account is locked again after every further LockoutThreshold failures
+	LockoutThreshold int
+	// LockoutMinutes is the first lock time, it doubles with every lock
+	LockoutMinutes int
+	// MaxLockoutMinutes caps the lock time
+	MaxLockoutMinutes int
+
+	// TwoFactorIssuer is the name shown by the authenticator apps
+	TwoFactorIssuer string
+	// TwoFactorChallengeMinutes is how long the second login step can be completed
+	TwoFactorChallengeMinutes int
+}
@@ -4,38 +4,108 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
+// EnvPrefix is the prefix of the environment variables overriding the
+// configuration, e.g. APP_DBCONFIG_DATASOURCENAME for DBConfig.DataSourceName
+const EnvPrefix = "APP"
+
+// secretFileSuffix marks an environment variable holding the path of a file
+// with the value, e.g. APP_JWTCONFIG_SECRETKEY_FILE=/run/secrets/jwt
+const secretFileSuffix = "_FILE"
+
 type Config struct {
-	ServerConfig ServerConfig
-	JWTConfig    JWTConfig
-	DBConfig     DatabaseConfig
-	LoggerConfig LoggerConfig
+	ServerConfig      ServerConfig
+	JWTConfig         JWTConfig
+	DBConfig          DatabaseConfig
+	LoggerConfig      LoggerConfig
+	NotifierConfig    NotifierConfig
+	JobConfig         JobConfig
+	IdempotencyConfig IdempotencyConfig
+	AccountConfig     AccountConfig
+	RateLimitConfig   RateLimitConfig
+	OIDCConfig        OIDCConfig
+	PrivacyConfig     PrivacyConfig
+	MetricsConfig     MetricsConfig
+	TracingConfig     TracingConfig
 }
 
-// LoadConfig loads the configuration from the given file.
+// LoadConfig loads the configuration from the given file, a name without an
+// extension is looked up as yaml, json or toml. Every field can be overridden
+// by its environment variable or read from a secret file, and the result is
+// validated. An empty file name loads the configuration from the environment only.
 func LoadConfig(file string) (*Config, error) {
 	v := viper.New()
 
-	v.SetConfigName(file)
-	v.AddConfigPath(".")
-	v.AutomaticEnv()
-	fmt.Fprintf(os.Stderr, "Loading configuration from %s\n", file)
-	if err := v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			return nil, errors.New("config file not found")
+	if file != "" {
+		if filepath.Ext(file) == "" {
+			v.SetConfigName(filepath.Base(file))
+			v.AddConfigPath(filepath.Dir(file))
+		} else {
+			v.SetConfigFile(file)
 		}
+		fmt.Fprintf(os.Stderr, "Loading configuration from %s\n", file)
+		if err := v.ReadInConfig(); err != nil {
+			if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+				return nil, errors.New("config file not found")
+			}
 
-		return nil, err
+			return nil, err
+		}
+	}
+
+	// nested keys are only read from the environment once they are bound
+	for _, key := range configKeys(reflect.TypeOf(Config{}), "") {
+		env := EnvName(key)
+		if err := v.BindEnv(key, env); err != nil {
+			return nil, err
+		}
+		if path := os.Getenv(env + secretFileSuffix); path != "" {
+			secret, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read %s: %w", env+secretFileSuffix, err)
+			}
+			v.Set(key, strings.TrimRight(string(secret), "\r\n"))
+		}
 	}
 
 	var c Config
-	err := v.Unmarshal(&c)
-	if err != nil {
+	// unknown keys are mostly typos, which would silently fall back to defaults
+	if err := v.UnmarshalExact(&c); err != nil {
 		return nil, err
 	}
 
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
 	return &c, nil
 }
+
+// EnvName returns the environment variable of a configuration key
+func EnvName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// configKeys returns the keys of the fields which can be set from the
+// environment. Lists of structs, like the JWT keys, can only be set in the file.
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + field.Name
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			keys = append(keys, configKeys(field.Type, key+".")...)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			continue
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
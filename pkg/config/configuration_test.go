@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+const testConfig = `
+ServerConfig:
+  Mode: Development
+  RoutePrefix: /api/v1
+  Port: 8080
+  TimeoutSecs: 60
+  ReadTimeoutSecs: 60
+  WriteTimeoutSecs: 12
+JWTConfig:
+  SecretKey: dummySecretKey
+  AccessTokenLifeTime: 43200
+  RefreshTokenLifeTime: 86400
+DBConfig:
+  DataSourceName: postgres://localhost:5432/file
+  MaxOpen: 50
+  MaxIdle: 50
+LoggerConfig:
+  Level: debug
+`
+
+func writeConfig(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadConfig(t *testing.T) {
+	file := writeConfig(t, testConfig)
+
+	cfg, err := LoadConfig(file)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "postgres://localhost:5432/file", cfg.DBConfig.DataSourceName)
+	assert.Equal(t, "8080", cfg.ServerConfig.Port)
+
+	// a name without an extension is looked up
+	cfg, err = LoadConfig(strings.TrimSuffix(file, ".yaml"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(12), cfg.ServerConfig.WriteTimeoutSecs)
+}
+
+func TestLoadConfig_Environment(t *testing.T) {
+	file := writeConfig(t, testConfig)
+	secret := filepath.Join(t.TempDir(), "jwt")
+	os.WriteFile(secret, []byte("a-secret-key-of-at-least-32-characters\n"), 0o600)
+
+	t.Setenv("APP_DBCONFIG_DATASOURCENAME", "postgres://db:5432/env")
+	t.Setenv("APP_SERVERCONFIG_MODE", "Production")
+	t.Setenv("APP_JWTCONFIG_SECRETKEY_FILE", secret)
+	t.Setenv("APP_ACCOUNTCONFIG_LOCKOUTTHRESHOLD", "7")
+
+	cfg, err := LoadConfig(file)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "postgres://db:5432/env", cfg.DBConfig.DataSourceName)
+	assert.Equal(t, "a-secret-key-of-at-least-32-characters", cfg.JWTConfig.SecretKey)
+	assert.Equal(t, 7, cfg.AccountConfig.LockoutThreshold)
+	assert.Equal(t, false, cfg.IsDevelopment())
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	_, err := LoadConfig(writeConfig(t, testConfig+"  MaxIdel: 10\n"))
+	assert.NotEqual(t, nil, err)
+
+	t.Setenv("APP_JWTCONFIG_SECRETKEY_FILE", filepath.Join(t.TempDir(), "missing"))
+	_, err = LoadConfig(writeConfig(t, testConfig))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "APP_DBCONFIG_DATASOURCENAME", EnvName("DBConfig.DataSourceName"))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	file := writeConfig(t, testConfig)
+	cfg, err := LoadConfig(file)
+	assert.Equal(t, nil, err)
+
+	cfg.ServerConfig.Mode = "Production"
+	cfg.ServerConfig.ReadTimeoutSecs = 0
+	cfg.DBConfig.DataSourceName = ""
+	cfg.DBConfig.MaxIdle = 100
+	cfg.LoggerConfig.Level = "verbose"
+
+	errs, ok := cfg.Validate().(ValidationErrors)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, ValidationErrors{
+		"ServerConfig.ReadTimeoutSecs must be between 1 and 3600 seconds, got 0",
+		"JWTConfig.SecretKey must be replaced outside development",
+		"JWTConfig.SecretKey must be at least 32 characters long outside development",
+		"DBConfig.DataSourceName is required",
+		"DBConfig.MaxIdle must not be greater than DBConfig.MaxOpen",
+		`LoggerConfig.Level "verbose" is not a log level`,
+	}, errs)
+
+	// asymmetric keys replace the secret key
+	cfg = &Config{
+		ServerConfig: ServerConfig{Mode: "Production", Port: "8080", TimeoutSecs: 1, ReadTimeoutSecs: 1, WriteTimeoutSecs: 1},
+		JWTConfig:    JWTConfig{Keys: []JWTKeyConfig{{ID: "2026-01", PrivateKeyFile: "key.pem"}}, AccessTokenLifeTime: 60, RefreshTokenLifeTime: 60},
+		DBConfig:     DatabaseConfig{DataSourceName: "postgres://localhost"},
+	}
+	assert.Equal(t, nil, cfg.Validate())
+}
@@ -0,0 +1,9 @@
+package config
+
+// Idempotency config
+type IdempotencyConfig struct {
+	// TTLHours is how long a stored response is replayed for its key
+	TTLHours int
+	// PurgeIntervalMinutes is how often the expired keys are removed
+	PurgeIntervalMinutes int
+}
@@ -0,0 +1,15 @@
+package config
+
+// Job config
+type JobConfig struct {
+	Enabled bool
+	// AbandonedCartIntervalMinutes is how often the abandoned cart job runs
+	AbandonedCartIntervalMinutes int
+	// AbandonedCartIdleHours flags a cart as abandoned and sends a reminder
+	AbandonedCartIdleHours int
+	// AbandonedCartExpireHours expires an abandoned cart
+	AbandonedCartExpireHours int
+	// StockAlertIntervalSeconds is how often the stock changes are checked for
+	// low stock alerts and back in stock notifications
+	StockAlertIntervalSeconds int
+}
@@ -1,7 +1,33 @@
 package config
 
 type JWTConfig struct {
+	// SecretKey signs the tokens with HS256 when no Keys are configured
 	SecretKey            string
 	AccessTokenLifeTime  int
 	RefreshTokenLifeTime int
+	// MaxSessionLifeTime caps the hours a session can be refreshed for since
+	// its login, zero does not cap it
+	MaxSessionLifeTime int
+	// Issuer is set as the iss claim and checked when it is not empty
+	Issuer string
+	// Audience is set as the aud claim and required on every token
+	Audience string
+	// Keys are the asymmetric signing keys, see JWTKeyConfig
+	Keys []JWTKeyConfig
+}
+
+// JWTKeyConfig is an RSA (RS256) or Ed25519 (EdDSA) signing key. Tokens are
+// signed with the newest key whose ActiveFrom has passed and verified with
+// any key until its RetireAt, so a key is rotated by adding the next one with
+// a later ActiveFrom and retiring the old one after the tokens expire.
+type JWTKeyConfig struct {
+	// ID is sent as the kid header
+	ID string
+	// Algorithm is optional, it is checked against the key when set
+	Algorithm string
+	// PrivateKeyFile is the PEM encoded PKCS#8 (or PKCS#1 RSA) private key
+	PrivateKeyFile string
+	// ActiveFrom and RetireAt are RFC 3339 times, both optional
+	ActiveFrom string
+	RetireAt   string
 }
@@ -0,0 +1,9 @@
+package config
+
+// Metrics config
+type MetricsConfig struct {
+	// Path of the Prometheus endpoint, outside of the route prefix
+	Path string
+	// Token is the bearer token the scraper must send, the endpoint is open without it
+	Token string
+}
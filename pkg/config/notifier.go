@@ -0,0 +1,15 @@
+package config
+
+// Notifier config
+type NotifierConfig struct {
+	// Type is either "log", "file" or "smtp"
+	Type     string
+	FilePath string
+
+	// SMTP server used by the "smtp" notifier
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+}
@@ -0,0 +1,24 @@
+package config
+
+// OIDC config
+type OIDCConfig struct {
+	// LoginTimeoutMinutes is how long a login started at a provider can be completed
+	LoginTimeoutMinutes int
+	Providers           []OIDCProviderConfig
+}
+
+// OIDCProviderConfig is an OpenID Connect provider users can sign in with. The
+// endpoints are read from the discovery document of the issuer.
+type OIDCProviderConfig struct {
+	// Name identifies the provider in the URLs, e.g. google
+	Name string
+	// IssuerURL serves /.well-known/openid-configuration
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the page of the app which receives the code and the state
+	// and posts them to the callback endpoint
+	RedirectURL string
+	// Scopes are requested besides openid, they default to email and profile
+	Scopes []string
+}
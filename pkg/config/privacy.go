@@ -0,0 +1,12 @@
+package config
+
+// Privacy config
+type PrivacyConfig struct {
+	// ExportFolder keeps the personal data archives, it must be shared by
+	// the instances since any of them can build or serve an archive
+	ExportFolder string
+	// ExportIntervalMinutes is how often the pending exports are built
+	ExportIntervalMinutes int
+	// ExportRetentionHours is how long an archive can be downloaded
+	ExportRetentionHours int
+}
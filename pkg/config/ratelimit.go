@@ -0,0 +1,15 @@
+package config
+
+// Rate limit config. A limit allows the given number of requests at once,
+// refilled evenly over the period; a zero limit disables it.
+type RateLimitConfig struct {
+	// Store is "memory", the only store shipped. Several instances need a
+	// shared store implementing ratelimit.Store.
+	Store string
+
+	LoginPerIP            int
+	LoginPerAccount       int
+	LoginPeriodSeconds    int
+	RegisterPerIP         int
+	RegisterPeriodSeconds int
+}
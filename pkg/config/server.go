@@ -9,4 +9,8 @@ type ServerConfig struct {
 	TimeoutSecs      int64
 	ReadTimeoutSecs  int64
 	WriteTimeoutSecs int64
+	// TrustedProxies are the addresses or CIDRs of the proxies whose
+	// X-Forwarded-For header gives the client IP. By default no proxy is
+	// trusted and the client IP is the address of the peer.
+	TrustedProxies []string
 }
@@ -0,0 +1,18 @@
+package config
+
+// Tracing config
+type TracingConfig struct {
+	// Exporter is "otlp" for an OpenTelemetry collector, "stdout" to print the
+	// spans, or empty to only propagate the incoming trace context
+	Exporter string
+	// Endpoint is the host:port of the OTLP/HTTP collector, the
+	// OTEL_EXPORTER_OTLP_ENDPOINT variable or localhost:4318 when empty
+	Endpoint string
+	// Insecure sends the spans to the collector without TLS
+	Insecure bool
+	// ServiceName names the service in the traces
+	ServiceName string
+	// SampleRatio is the share of the new traces recorded, between 0 and 1.
+	// The incoming requests follow the decision of their caller.
+	SampleRatio float64
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxTimeoutSecs caps the server timeouts, a longer request is a mistake
+const maxTimeoutSecs = 3600
+
+// minSecretKeyLength is the shortest HS256 secret accepted outside development
+const minSecretKeyLength = 32
+
+// defaultSecretKeys are the secrets of the examples, they must be replaced
+var defaultSecretKeys = []string{"dummySecretKey", "secret", "changeme"}
+
+// ValidationErrors lists every problem of a configuration, so they can all be
+// fixed at once
+type ValidationErrors []string
+
+func (e ValidationErrors) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e, "\n  - ")
+}
+
+// IsDevelopment reports whether the service runs in development mode
+func (c *Config) IsDevelopment() bool {
+	return strings.EqualFold(c.ServerConfig.Mode, "development")
+}
+
+// Validate checks the required fields, the secrets and the timeouts
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Sprintf(format, args...))
+		}
+	}
+
+	// server
+	port, err := strconv.Atoi(c.ServerConfig.Port)
+	check(err == nil && port > 0 && port < 65536, "ServerConfig.Port must be a port number, got %q", c.ServerConfig.Port)
+	check(c.ServerConfig.RoutePrefix == "" || strings.HasPrefix(c.ServerConfig.RoutePrefix, "/"),
+		"ServerConfig.RoutePrefix must start with /")
+	for _, timeout := range []struct {
+		name string
+		secs int64
+	}{
+		{"TimeoutSecs", c.ServerConfig.TimeoutSecs},
+		{"ReadTimeoutSecs", c.ServerConfig.ReadTimeoutSecs},
+		{"WriteTimeoutSecs", c.ServerConfig.WriteTimeoutSecs},
+	} {
+		check(timeout.secs > 0 && timeout.secs <= maxTimeoutSecs,
+			"ServerConfig.%s must be between 1 and %d seconds, got %d", timeout.name, maxTimeoutSecs, timeout.secs)
+	}
+
+	// jwt
+	if len(c.JWTConfig.Keys) == 0 {
+		check(c.JWTConfig.SecretKey != "", "JWTConfig.SecretKey is required when no JWTConfig.Keys are set")
+		if !c.IsDevelopment() && c.JWTConfig.SecretKey != "" {
+			check(!isDefaultSecretKey(c.JWTConfig.SecretKey), "JWTConfig.SecretKey must be replaced outside development")
+			check(len(c.JWTConfig.SecretKey) >= minSecretKeyLength,
+				"JWTConfig.SecretKey must be at least %d characters long outside development", minSecretKeyLength)
+		}
+	}
+	for i, key := range c.JWTConfig.Keys {
+		check(key.ID != "" && key.PrivateKeyFile != "", "JWTConfig.Keys[%d] needs an ID and a PrivateKeyFile", i)
+	}
+	check(c.JWTConfig.AccessTokenLifeTime > 0, "JWTConfig.AccessTokenLifeTime must be positive")
+	check(c.JWTConfig.RefreshTokenLifeTime >= c.JWTConfig.AccessTokenLifeTime,
+		"JWTConfig.RefreshTokenLifeTime must not be shorter than JWTConfig.AccessTokenLifeTime")
+	check(c.JWTConfig.MaxSessionLifeTime >= 0, "JWTConfig.MaxSessionLifeTime must not be negative")
+
+	// database
+	check(c.DBConfig.DataSourceName != "", "DBConfig.DataSourceName is required")
+	check(c.DBConfig.MaxOpen >= 0 && c.DBConfig.MaxIdle >= 0 && c.DBConfig.MaxLifetime >= 0,
+		"DBConfig.MaxOpen, MaxIdle and MaxLifetime must not be negative")
+	check(c.DBConfig.MaxOpen == 0 || c.DBConfig.MaxIdle <= c.DBConfig.MaxOpen,
+		"DBConfig.MaxIdle must not be greater than DBConfig.MaxOpen")
+
+	// logger
+	_, err = zapcore.ParseLevel(c.LoggerConfig.Level)
+	check(err == nil, "LoggerConfig.Level %q is not a log level", c.LoggerConfig.Level)
+
+	// notifier
+	switch c.NotifierConfig.Type {
+	case "", "log":
+	case "file":
+		check(c.NotifierConfig.FilePath != "", "NotifierConfig.FilePath is required for the file notifier")
+	case "smtp":
+		check(c.NotifierConfig.SMTPHost != "" && c.NotifierConfig.SMTPFrom != "",
+			"NotifierConfig.SMTPHost and SMTPFrom are required for the smtp notifier")
+	default:
+		check(false, "NotifierConfig.Type must be log, file or smtp, got %q", c.NotifierConfig.Type)
+	}
+
+	// metrics
+	check(c.MetricsConfig.Path == "" || strings.HasPrefix(c.MetricsConfig.Path, "/"),
+		"MetricsConfig.Path must start with /")
+
+	// tracing
+	switch c.TracingConfig.Exporter {
+	case "", "otlp", "stdout":
+	default:
+		check(false, "TracingConfig.Exporter must be otlp, stdout or empty, got %q", c.TracingConfig.Exporter)
+	}
+	check(c.TracingConfig.SampleRatio >= 0 && c.TracingConfig.SampleRatio <= 1,
+		"TracingConfig.SampleRatio must be between 0 and 1")
+
+	// oidc
+	for i, provider := range c.OIDCConfig.Providers {
+		check(provider.Name != "" && provider.IssuerURL != "" && provider.ClientID != "" && provider.RedirectURL != "",
+			"OIDCConfig.Providers[%d] needs a Name, IssuerURL, ClientID and RedirectURL", i)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func isDefaultSecretKey(secret string) bool {
+	for _, key := range defaultSecretKeys {
+		if strings.EqualFold(secret, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,8 +1,7 @@
 package jwt_helper
 
 import (
-	"encoding/json"
-	"patika-ecommerce/internal/api"
+	"errors"
 	"patika-ecommerce/internal/model"
 	"patika-ecommerce/pkg/config"
 	"time"
@@ -11,64 +10,72 @@ import (
 	"github.com/google/uuid"
 )
 
-type JWTToken struct {
-	CreatedAt int      `json:"created_at"`
-	ExpiresAt int      `json:"expires_at"`
-	Roles     []string `json:"roles"`
-	UserId    string   `json:"userId"`
-	Email     string   `json:"email"`
-	IsAdmin   bool     `json:"isAdmin"`
-}
-
-// GetAuthToken is a service that generates a new JWT token
-func GetAuthToken(user *model.User, cfg *config.Config) api.TokenResponse {
-	jwtClaimsForAccessToken := NewJwtClaimsForAccessToken(user, cfg.JWTConfig.AccessTokenLifeTime)
+// TokenType is the kind of a token, kept in the typ claim so a token of one
+// kind is never accepted as another
+type TokenType string
 
-	jwtClaimsForRefreshToken := NewJwtClaimsForRefreshToken(user, cfg.JWTConfig.RefreshTokenLifeTime)
+const (
+	TokenTypeAccess             TokenType = "access"
+	TokenTypeTwoFactorChallenge TokenType = "2fa_challenge"
 
-	accesToken := GenerateToken(jwtClaimsForAccessToken, cfg.JWTConfig.SecretKey)
-	refreshToken := GenerateToken(jwtClaimsForRefreshToken, cfg.JWTConfig.SecretKey)
+	// DefaultAudience is used when JWTConfig.Audience is not set
+	DefaultAudience = "patika-ecommerce"
 
-	return api.TokenResponse{
-		AccessToken:  accesToken,
-		RefreshToken: refreshToken,
-	}
-}
+	// amrOTP is the authentication method reference of a one-time password (RFC 8176)
+	amrOTP = "otp"
+)
 
-// GenerateAccessToken generates an access token
-func GenerateToken(claims *jwt.Token, secret string) string {
-	hmacSecretString := secret
-	hmacSecret := []byte(hmacSecretString)
-	token, _ := claims.SignedString(hmacSecret)
+var (
+	ErrInvalidTokenType = errors.New("token type is not valid")
+	ErrMissingClaim     = errors.New("token is missing a required claim")
+)
 
-	return token
+type JWTToken struct {
+	jwt.RegisteredClaims
+	Type    TokenType `json:"typ"`
+	Roles   []string  `json:"roles,omitempty"`
+	Email   string    `json:"email,omitempty"`
+	IsAdmin bool      `json:"isAdmin"`
+	// AMR are the authentication methods used for the token
+	AMR []string `json:"amr,omitempty"`
 }
 
-//VerifyToken verifies a token
-func VerifyToken(token string, secret string) *model.User {
-	hmacSecretString := secret
-	hmacSecret := []byte(hmacSecretString)
+// Valid validates the registered claims, exp, iat, sub, jti and aud are required
+func (t *JWTToken) Valid() error {
+	if t.ExpiresAt == nil || t.IssuedAt == nil || t.Subject == "" || t.ID == "" || len(t.Audience) == 0 {
+		return ErrMissingClaim
+	}
+	return t.RegisteredClaims.Valid()
+}
 
-	decoded, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return hmacSecret, nil
-	})
+// GenerateAccessToken generates a new access token for the user
+func GenerateAccessToken(user *model.User, cfg *config.Config) (string, error) {
+	ring, err := KeyRingFor(cfg)
+	if err != nil {
+		return "", err
+	}
+	return ring.Sign(NewJwtClaimsForAccessToken(user, cfg))
+}
 
+// GenerateChallengeToken generates a short-lived token which proves the
+// password of the user was right, it is exchanged with a second factor
+func GenerateChallengeToken(user *model.User, lifetime time.Duration, cfg *config.Config) (string, error) {
+	ring, err := KeyRingFor(cfg)
 	if err != nil {
-		return nil
+		return "", err
 	}
+	return ring.Sign(newClaims(user, TokenTypeTwoFactorChallenge, lifetime, cfg))
+}
 
-	if !decoded.Valid {
+// VerifyToken verifies an access token and returns its user
+func VerifyToken(token string, cfg *config.Config) *model.User {
+	claims, err := ParseToken(token, TokenTypeAccess, cfg)
+	if err != nil {
 		return nil
 	}
 
-	decodedClaims := decoded.Claims.(jwt.MapClaims)
-
-	var decodedToken JWTToken
-	jsonString, _ := json.Marshal(decodedClaims)
-	json.Unmarshal(jsonString, &decodedToken)
-
 	// decode the claims into a model.user struct and return it
-	id, err := uuid.Parse(decodedToken.UserId)
+	id, err := uuid.Parse(claims.Subject)
 	if err != nil {
 		return nil
 	}
@@ -76,39 +83,82 @@ func VerifyToken(token string, secret string) *model.User {
 		Base: model.Base{
 			ID: id,
 		},
-		Email:   &decodedToken.Email,
-		IsAdmin: decodedToken.IsAdmin,
+		Email:   &claims.Email,
+		IsAdmin: claims.IsAdmin,
+	}
+	for _, method := range claims.AMR {
+		if method == amrOTP {
+			user.TwoFactorVerified = true
+		}
+	}
+	for _, role := range claims.Roles {
+		if model.Role(role).IsValid() {
+			user.Roles = append(user.Roles, model.UserRole{UserID: id, Role: model.Role(role)})
+		}
 	}
 
 	return &user
 }
 
-// ParseToken parses a token string into a jwt.Token
-func ParseToken(token string, secret string) (*jwt.Token, error) {
-	hmacSecretString := secret
-	hmacSecret := []byte(hmacSecretString)
+// ParseToken parses and validates a token of the given type. Only tokens
+// signed with the algorithm of their key for the configured audience are
+// accepted.
+func ParseToken(token string, tokenType TokenType, cfg *config.Config) (*JWTToken, error) {
+	ring, err := KeyRingFor(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	return jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return hmacSecret, nil
-	})
+	claims := &JWTToken{}
+	if _, err := jwt.ParseWithClaims(token, claims, ring.Keyfunc, jwt.WithValidMethods(ring.ValidMethods())); err != nil {
+		return nil, err
+	}
+
+	if claims.Type != tokenType {
+		return nil, ErrInvalidTokenType
+	}
+	if !claims.VerifyAudience(audience(cfg), true) {
+		return nil, jwt.ErrTokenInvalidAudience
+	}
+	if cfg.JWTConfig.Issuer != "" && !claims.VerifyIssuer(cfg.JWTConfig.Issuer, true) {
+		return nil, jwt.ErrTokenInvalidIssuer
+	}
+
+	return claims, nil
+}
+
+// NewJwtClaimsForAccessToken returns the claims of a new access token
+func NewJwtClaimsForAccessToken(user *model.User, cfg *config.Config) *JWTToken {
+	claims := newClaims(user, TokenTypeAccess, time.Duration(cfg.JWTConfig.AccessTokenLifeTime)*time.Hour, cfg)
+	claims.Roles = user.RoleNames()
+	claims.IsAdmin = user.IsAdmin
+	if user.TwoFactorVerified {
+		claims.AMR = []string{"pwd", amrOTP}
+	}
+	return claims
 }
 
-// NewJwtClaimsForAccessToken returns a new jwt.Claims for an access token
-func NewJwtClaimsForAccessToken(user *model.User, accessTokenLifeTime int) *jwt.Token {
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"CreatedAt": time.Now().Unix(),
-		"ExpiresAt": time.Now().Add(time.Duration(accessTokenLifeTime) * time.Hour).Unix(),
-		"UserId":    user.ID,
-		// "Email":     user.Email,
-		"IsAdmin": user.IsAdmin,
-	})
+// newClaims returns the registered claims of a new token of the user
+func newClaims(user *model.User, tokenType TokenType, lifetime time.Duration, cfg *config.Config) *JWTToken {
+	now := time.Now()
+
+	return &JWTToken{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   user.ID.String(),
+			Issuer:    cfg.JWTConfig.Issuer,
+			Audience:  jwt.ClaimStrings{audience(cfg)},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(lifetime)),
+		},
+		Type: tokenType,
+	}
 }
 
-// NewJwtClaimsForRefreshToken returns a new jwt.Claims for a refresh token
-func NewJwtClaimsForRefreshToken(user *model.User, refreshTokenLifeTime int) *jwt.Token {
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"CreatedAt": time.Now().Unix(),
-		"ExpiresAt": time.Now().Add(time.Duration(refreshTokenLifeTime) * time.Hour).Unix(),
-		"UserId":    user.ID,
-	})
+func audience(cfg *config.Config) string {
+	if cfg.JWTConfig.Audience != "" {
+		return cfg.JWTConfig.Audience
+	}
+	return DefaultAudience
 }
@@ -0,0 +1,89 @@
+package jwt_helper
+
+import (
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+func TestVerifyToken(t *testing.T) {
+	cfg := &config.Config{
+		JWTConfig: config.JWTConfig{SecretKey: "secret", AccessTokenLifeTime: 1, Issuer: "patika", Audience: "api"},
+	}
+	user := &model.User{Base: model.Base{ID: uuid.New()}, IsAdmin: true, Roles: []model.UserRole{{Role: model.RoleCatalogManager}}}
+
+	claims := func(modify func(c *JWTToken)) *JWTToken {
+		c := NewJwtClaimsForAccessToken(user, cfg)
+		modify(c)
+		return c
+	}
+	sign := func(method jwt.SigningMethod, c *JWTToken, secret string) string {
+		token, _ := jwt.NewWithClaims(method, c).SignedString([]byte(secret))
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{name: "valid", token: func() string { token, _ := GenerateAccessToken(user, cfg); return token }(), valid: true},
+		{name: "wrongSecret", token: sign(jwt.SigningMethodHS256, claims(func(c *JWTToken) {}), "other"), valid: false},
+		{name: "otherAlgorithm", token: sign(jwt.SigningMethodHS512, claims(func(c *JWTToken) {}), "secret"), valid: false},
+		{name: "noneAlgorithm", token: func() string {
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims(func(c *JWTToken) {})).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return token
+		}(), valid: false},
+		{name: "expired", token: sign(jwt.SigningMethodHS256, claims(func(c *JWTToken) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		}), "secret"), valid: false},
+		{name: "missingExpiry", token: sign(jwt.SigningMethodHS256, claims(func(c *JWTToken) { c.ExpiresAt = nil }), "secret"), valid: false},
+		{name: "missingID", token: sign(jwt.SigningMethodHS256, claims(func(c *JWTToken) { c.ID = "" }), "secret"), valid: false},
+		{name: "challengeToken", token: func() string { token, _ := GenerateChallengeToken(user, time.Minute, cfg); return token }(), valid: false},
+		{name: "wrongType", token: sign(jwt.SigningMethodHS256, claims(func(c *JWTToken) { c.Type = "refresh" }), "secret"), valid: false},
+		{name: "wrongAudience", token: sign(jwt.SigningMethodHS256, claims(func(c *JWTToken) { c.Audience = jwt.ClaimStrings{"other"} }), "secret"), valid: false},
+		{name: "wrongIssuer", token: sign(jwt.SigningMethodHS256, claims(func(c *JWTToken) { c.Issuer = "other" }), "secret"), valid: false},
+		{name: "legacyClaims", token: func() string {
+			token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"CreatedAt": time.Now().Unix(),
+				"ExpiresAt": time.Now().Add(time.Hour).Unix(),
+				"UserId":    user.ID,
+				"IsAdmin":   true,
+			}).SignedString([]byte("secret"))
+			return token
+		}(), valid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VerifyToken(tt.token, cfg)
+			assert.Equal(t, tt.valid, got != nil)
+			if tt.valid {
+				assert.Equal(t, user.ID, got.ID)
+				assert.Equal(t, true, got.IsAdmin)
+				assert.Equal(t, []string{"catalog_manager"}, got.RoleNames())
+			}
+		})
+	}
+}
+
+func TestVerifyToken_twoFactor(t *testing.T) {
+	cfg := &config.Config{JWTConfig: config.JWTConfig{SecretKey: "secret", AccessTokenLifeTime: 1}}
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+
+	token, _ := GenerateAccessToken(user, cfg)
+	assert.Equal(t, false, VerifyToken(token, cfg).TwoFactorVerified)
+
+	user.TwoFactorVerified = true
+	token, _ = GenerateAccessToken(user, cfg)
+	assert.Equal(t, true, VerifyToken(token, cfg).TwoFactorVerified)
+
+	challenge, _ := GenerateChallengeToken(user, time.Minute, cfg)
+	claims, err := ParseToken(challenge, TokenTypeTwoFactorChallenge, cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, user.ID.String(), claims.Subject)
+}
@@ -0,0 +1,252 @@
+package jwt_helper
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"patika-ecommerce/pkg/config"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrUnknownKey     = errors.New("token is signed with an unknown key")
+	ErrNoSigningKey   = errors.New("no signing key is active")
+	ErrKeyAlgMismatch = errors.New("token algorithm does not match its key")
+)
+
+// signingKey is a key of the key ring
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	private    crypto.Signer
+	public     crypto.PublicKey
+	activeFrom time.Time
+	retireAt   *time.Time
+}
+
+// KeyRing signs tokens with the newest active key and verifies them with any
+// key not retired yet, found by the kid header. Without configured keys the
+// HS256 secret is used, and it is never accepted once keys are configured.
+type KeyRing struct {
+	keys   []*signingKey
+	secret []byte
+	now    func() time.Time
+}
+
+var keyRings sync.Map
+
+// KeyRingFor returns the key ring of the config, the keys are loaded once
+func KeyRingFor(cfg *config.Config) (*KeyRing, error) {
+	if ring, ok := keyRings.Load(&cfg.JWTConfig); ok {
+		return ring.(*KeyRing), nil
+	}
+
+	ring, err := NewKeyRing(&cfg.JWTConfig)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := keyRings.LoadOrStore(&cfg.JWTConfig, ring)
+	return actual.(*KeyRing), nil
+}
+
+// NewKeyRing loads the keys of the config
+func NewKeyRing(cfg *config.JWTConfig) (*KeyRing, error) {
+	ring := &KeyRing{secret: []byte(cfg.SecretKey), now: time.Now}
+
+	ids := map[string]bool{}
+	for _, keyCfg := range cfg.Keys {
+		if keyCfg.ID == "" {
+			return nil, errors.New("jwt key id is required")
+		}
+		if ids[keyCfg.ID] {
+			return nil, fmt.Errorf("jwt key id %s is duplicated", keyCfg.ID)
+		}
+		ids[keyCfg.ID] = true
+
+		key, err := loadKey(keyCfg)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", keyCfg.ID, err)
+		}
+		ring.keys = append(ring.keys, key)
+	}
+	sort.SliceStable(ring.keys, func(i, j int) bool {
+		return ring.keys[i].activeFrom.Before(ring.keys[j].activeFrom)
+	})
+
+	return ring, nil
+}
+
+// loadKey reads the PEM encoded private key of the config
+func loadKey(keyCfg config.JWTKeyConfig) (*signingKey, error) {
+	data, err := ioutil.ReadFile(keyCfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+
+	var parsed interface{}
+	if parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+		if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+			return nil, errors.New("private key must be a PKCS#8 or PKCS#1 key")
+		}
+	}
+
+	key := &signingKey{id: keyCfg.ID}
+	switch private := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, private, &private.PublicKey
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, private, private.Public()
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+	if keyCfg.Algorithm != "" && keyCfg.Algorithm != key.method.Alg() {
+		return nil, fmt.Errorf("algorithm %s does not match the %s key", keyCfg.Algorithm, key.method.Alg())
+	}
+
+	if keyCfg.ActiveFrom != "" {
+		if key.activeFrom, err = time.Parse(time.RFC3339, keyCfg.ActiveFrom); err != nil {
+			return nil, fmt.Errorf("active from: %w", err)
+		}
+	}
+	if keyCfg.RetireAt != "" {
+		retireAt, err := time.Parse(time.RFC3339, keyCfg.RetireAt)
+		if err != nil {
+			return nil, fmt.Errorf("retire at: %w", err)
+		}
+		key.retireAt = &retireAt
+	}
+
+	return key, nil
+}
+
+// isRetired returns true when the key is not accepted anymore
+func (k *signingKey) isRetired(now time.Time) bool {
+	return k.retireAt != nil && !now.Before(*k.retireAt)
+}
+
+// Sign signs the claims with the newest active key
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	if len(r.keys) == 0 {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(r.secret)
+	}
+
+	key := r.signingKey()
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// signingKey returns the newest key which is active and not retired
+func (r *KeyRing) signingKey() *signingKey {
+	now := r.now()
+
+	var active *signingKey
+	for _, key := range r.keys {
+		if key.activeFrom.After(now) {
+			break
+		}
+		if !key.isRetired(now) {
+			active = key
+		}
+	}
+	return active
+}
+
+// Keyfunc returns the key to verify the token with
+func (r *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if len(r.keys) == 0 {
+		return r.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	for _, key := range r.keys {
+		if key.id != kid {
+			continue
+		}
+		if key.isRetired(r.now()) {
+			return nil, ErrUnknownKey
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, ErrKeyAlgMismatch
+		}
+		return key.public, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// ValidMethods returns the algorithms tokens are accepted with
+func (r *KeyRing) ValidMethods() []string {
+	if len(r.keys) == 0 {
+		return []string{jwt.SigningMethodHS256.Alg()}
+	}
+
+	methods := []string{}
+	seen := map[string]bool{}
+	for _, key := range r.keys {
+		if !seen[key.method.Alg()] {
+			seen[key.method.Alg()] = true
+			methods = append(methods, key.method.Alg())
+		}
+	}
+	return methods
+}
+
+// JWK is a public key in the JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys which are not retired, including the keys
+// scheduled for later, so verifiers know a key before it signs any token
+func (r *KeyRing) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range r.keys {
+		if key.isRetired(r.now()) {
+			continue
+		}
+
+		jwk := JWK{Kid: key.id, Use: "sig", Alg: key.method.Alg()}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
@@ -0,0 +1,139 @@
+package jwt_helper
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"path/filepath"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/config"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// writeKey writes the private key as a PKCS#8 PEM file and returns its path
+func writeKey(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestKeyRing(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaFile, edFile := writeKey(t, rsaKey), writeKey(t, edKey)
+
+	now := time.Now()
+	cfg := &config.Config{JWTConfig: config.JWTConfig{
+		SecretKey:           "secret",
+		AccessTokenLifeTime: 1,
+		Keys: []config.JWTKeyConfig{
+			{ID: "old", PrivateKeyFile: rsaFile, RetireAt: now.Add(time.Hour).Format(time.RFC3339)},
+			{ID: "current", PrivateKeyFile: edFile, ActiveFrom: now.Add(-time.Minute).Format(time.RFC3339)},
+		},
+	}}
+	user := &model.User{Base: model.Base{ID: uuid.New()}}
+
+	ring, err := KeyRingFor(cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"RS256", "EdDSA"}, ring.ValidMethods())
+
+	t.Run("signsWithNewestActiveKey", func(t *testing.T) {
+		token, err := GenerateAccessToken(user, cfg)
+		assert.Equal(t, nil, err)
+
+		parsed, _, _ := new(jwt.Parser).ParseUnverified(token, &JWTToken{})
+		assert.Equal(t, "current", parsed.Header["kid"])
+		assert.Equal(t, "EdDSA", parsed.Method.Alg())
+		assert.NotEqual(t, (*model.User)(nil), VerifyToken(token, cfg))
+	})
+
+	t.Run("verifiesWithOldKey", func(t *testing.T) {
+		old := jwt.NewWithClaims(jwt.SigningMethodRS256, NewJwtClaimsForAccessToken(user, cfg))
+		old.Header["kid"] = "old"
+		token, _ := old.SignedString(rsaKey)
+		assert.NotEqual(t, (*model.User)(nil), VerifyToken(token, cfg))
+	})
+
+	t.Run("rejectsRetiredKey", func(t *testing.T) {
+		ring.now = func() time.Time { return now.Add(2 * time.Hour) }
+		defer func() { ring.now = time.Now }()
+
+		old := jwt.NewWithClaims(jwt.SigningMethodRS256, NewJwtClaimsForAccessToken(user, cfg))
+		old.Header["kid"] = "old"
+		token, _ := old.SignedString(rsaKey)
+		assert.Equal(t, (*model.User)(nil), VerifyToken(token, cfg))
+		assert.Equal(t, 1, len(ring.JWKS().Keys))
+	})
+
+	t.Run("rejectsAlgorithmConfusion", func(t *testing.T) {
+		// an HS256 token signed with the public key of the RSA key
+		public, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		confused := jwt.NewWithClaims(jwt.SigningMethodHS256, NewJwtClaimsForAccessToken(user, cfg))
+		confused.Header["kid"] = "old"
+		token, _ := confused.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}))
+		assert.Equal(t, (*model.User)(nil), VerifyToken(token, cfg))
+
+		// the shared secret is not accepted once keys are configured
+		hs := jwt.NewWithClaims(jwt.SigningMethodHS256, NewJwtClaimsForAccessToken(user, cfg))
+		token, _ = hs.SignedString([]byte("secret"))
+		assert.Equal(t, (*model.User)(nil), VerifyToken(token, cfg))
+
+		// an EdDSA token claiming the RSA key
+		mismatched := jwt.NewWithClaims(jwt.SigningMethodEdDSA, NewJwtClaimsForAccessToken(user, cfg))
+		mismatched.Header["kid"] = "old"
+		token, _ = mismatched.SignedString(edKey)
+		assert.Equal(t, (*model.User)(nil), VerifyToken(token, cfg))
+	})
+
+	t.Run("unknownKey", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, NewJwtClaimsForAccessToken(user, cfg))
+		token.Header["kid"] = "unknown"
+		signed, _ := token.SignedString(edKey)
+		assert.Equal(t, (*model.User)(nil), VerifyToken(signed, cfg))
+	})
+
+	t.Run("jwks", func(t *testing.T) {
+		set := ring.JWKS()
+		assert.Equal(t, 2, len(set.Keys))
+		assert.Equal(t, "RSA", set.Keys[0].Kty)
+		assert.Equal(t, "AQAB", set.Keys[0].E)
+		assert.Equal(t, "OKP", set.Keys[1].Kty)
+		assert.Equal(t, "Ed25519", set.Keys[1].Crv)
+	})
+}
+
+func TestNewKeyRing_invalidConfig(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	edFile := writeKey(t, edKey)
+
+	tests := []struct {
+		name string
+		keys []config.JWTKeyConfig
+	}{
+		{name: "missingID", keys: []config.JWTKeyConfig{{PrivateKeyFile: edFile}}},
+		{name: "duplicatedID", keys: []config.JWTKeyConfig{{ID: "a", PrivateKeyFile: edFile}, {ID: "a", PrivateKeyFile: edFile}}},
+		{name: "missingFile", keys: []config.JWTKeyConfig{{ID: "a", PrivateKeyFile: "missing.pem"}}},
+		{name: "algorithmMismatch", keys: []config.JWTKeyConfig{{ID: "a", PrivateKeyFile: edFile, Algorithm: "RS256"}}},
+		{name: "invalidActiveFrom", keys: []config.JWTKeyConfig{{ID: "a", PrivateKeyFile: edFile, ActiveFrom: "tomorrow"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewKeyRing(&config.JWTConfig{Keys: tt.keys})
+			assert.NotEqual(t, nil, err)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the metrics of the service
+const namespace = "patika_ecommerce"
+
+// Registry holds the metrics of the service. It is not the default registry of
+// the client library, so the packages of the dependencies cannot add to it.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Duration of the HTTP requests by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of the GORM queries by operation and table.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	ordersCompleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_completed_total",
+		Help:      "Number of completed orders.",
+	})
+
+	ordersCancelled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_cancelled_total",
+		Help:      "Number of cancelled orders.",
+	})
+
+	revenue = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "revenue_total",
+		Help:      "Total price of the completed orders, less the cancelled ones is the net revenue.",
+	})
+
+	revenueCancelled = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "revenue_cancelled_total",
+		Help:      "Total price of the cancelled orders.",
+	})
+
+	cartAdds = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cart_adds_total",
+		Help:      "Number of products added to the carts.",
+	})
+
+	cartAddedQuantity = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cart_added_quantity_total",
+		Help:      "Quantity of the products added to the carts.",
+	})
+
+	loginFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Number of failed logins by reason.",
+	}, []string{"reason"})
+)
+
+// Reasons of a failed login
+const (
+	LoginFailureUnknownUser     = "unknown_user"
+	LoginFailureInvalidPassword = "invalid_password"
+	LoginFailureInvalidCode     = "invalid_two_factor_code"
+	LoginFailureLocked          = "locked"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration,
+		dbQueryDuration,
+		ordersCompleted,
+		ordersCancelled,
+		revenue,
+		revenueCancelled,
+		cartAdds,
+		cartAddedQuantity,
+		loginFailures,
+	)
+}
+
+// Handler serves the metrics of the registry in the Prometheus format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDBStats exposes the connection pool statistics of the database
+func RegisterDBStats(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveHTTPRequest records the duration of a request, route is the pattern
+// of the route and not the path, so the ids do not make a series each
+func ObserveHTTPRequest(method string, route string, status int, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// OrderCompleted counts a completed order and its total price
+func OrderCompleted(totalPrice float64) {
+	ordersCompleted.Inc()
+	if totalPrice > 0 {
+		revenue.Add(totalPrice)
+	}
+}
+
+// OrderCancelled counts a cancelled order and its total price
+func OrderCancelled(totalPrice float64) {
+	ordersCancelled.Inc()
+	if totalPrice > 0 {
+		revenueCancelled.Add(totalPrice)
+	}
+}
+
+// CartItemAdded counts a product added to a cart with its quantity
+func CartItemAdded(quantity int64) {
+	cartAdds.Inc()
+	if quantity > 0 {
+		cartAddedQuantity.Add(float64(quantity))
+	}
+}
+
+// LoginFailed counts a failed login, reason is one of the LoginFailure constants
+func LoginFailed(reason string) {
+	loginFailures.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-playground/assert/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestOrderCounters(t *testing.T) {
+	completed, cancelled := testutil.ToFloat64(ordersCompleted), testutil.ToFloat64(ordersCancelled)
+	earned, refunded := testutil.ToFloat64(revenue), testutil.ToFloat64(revenueCancelled)
+
+	OrderCompleted(150)
+	OrderCompleted(50)
+	OrderCancelled(50)
+
+	assert.Equal(t, completed+2, testutil.ToFloat64(ordersCompleted))
+	assert.Equal(t, cancelled+1, testutil.ToFloat64(ordersCancelled))
+	assert.Equal(t, earned+200, testutil.ToFloat64(revenue))
+	assert.Equal(t, refunded+50, testutil.ToFloat64(revenueCancelled))
+}
+
+func TestCartItemAdded(t *testing.T) {
+	adds, quantity := testutil.ToFloat64(cartAdds), testutil.ToFloat64(cartAddedQuantity)
+
+	CartItemAdded(3)
+
+	assert.Equal(t, adds+1, testutil.ToFloat64(cartAdds))
+	assert.Equal(t, quantity+3, testutil.ToFloat64(cartAddedQuantity))
+}
+
+func TestLoginFailed(t *testing.T) {
+	failures := testutil.ToFloat64(loginFailures.WithLabelValues(LoginFailureInvalidPassword))
+
+	LoginFailed(LoginFailureInvalidPassword)
+
+	assert.Equal(t, failures+1, testutil.ToFloat64(loginFailures.WithLabelValues(LoginFailureInvalidPassword)))
+}
+
+func TestGormPlugin(t *testing.T) {
+	var db *sql.DB
+	db, mock, _ := sqlmock.New()
+	DB, _ := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	assert.Equal(t, nil, DB.Use(GormPlugin{}))
+
+	type Product struct {
+		ID   int
+		Name string
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "products"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "phone"))
+
+	var products []Product
+	assert.Equal(t, nil, DB.Find(&products).Error)
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+
+	assert.Equal(t, 1, testutil.CollectAndCount(dbQueryDuration))
+	assert.Equal(t, true, strings.Contains(scrape(), `operation="query",table="products"`))
+}
+
+func TestHandler(t *testing.T) {
+	ObserveHTTPRequest(http.MethodGet, "/products/:id", http.StatusOK, 0)
+
+	body := scrape()
+	assert.Equal(t, true, strings.Contains(body,
+		`patika_ecommerce_http_request_duration_seconds_count{method="GET",route="/products/:id",status="200"} 1`))
+	assert.Equal(t, true, strings.Contains(body, "go_goroutines"))
+}
+
+func scrape() string {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	Handler().ServeHTTP(w, req)
+	return w.Body.String()
+}
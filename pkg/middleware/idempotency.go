@@ -0,0 +1,160 @@
+package mw
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/tracing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// IdempotencyKeyHeader is the header the client sends a unique key for the request in
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyStoreTimeout bounds storing the response once the handler is done
+const idempotencyStoreTimeout = 5 * time.Second
+
+// IdempotencyStore keeps the idempotency keys and the stored responses
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, key *model.IdempotencyKey) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, key *model.IdempotencyKey) error
+	Release(ctx context.Context, key *model.IdempotencyKey) error
+}
+
+// responseRecorder keeps a copy of the response body written by the handler
+type responseRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware replays the stored response of a request sent again
+// with the same Idempotency-Key header. A key is scoped to the method, path
+// and user of the request; using it with a different payload is rejected.
+// Requests without the header are not affected. Server errors are not
+// stored, so the request can be retried with the same key.
+func IdempotencyMiddleware(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			c.AbortWithStatusJSON(httpErr.ErrorResponse(httpErr.IdempotencyKeyTooLongError))
+			return
+		}
+
+		body, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(httpErr.ErrorResponse(httpErr.CannotBindGivenData))
+			return
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		record := &model.IdempotencyKey{
+			Key:         key,
+			Scope:       idempotencyScope(c),
+			RequestHash: requestHash(body),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+		existing, err := store.Reserve(c.Request.Context(), record)
+		if err != nil {
+			c.AbortWithStatusJSON(httpErr.ErrorResponse(err))
+			return
+		}
+		if existing != nil {
+			replay(c, existing, record.RequestHash)
+			return
+		}
+
+		// a panicking handler does not keep the key reserved until it expires
+		defer func() {
+			if p := recover(); p != nil {
+				releaseKey(c, store, record)
+				panic(p)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = recorder
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			releaseKey(c, store, record)
+			return
+		}
+
+		record.StatusCode = c.Writer.Status()
+		record.ContentType = c.Writer.Header().Get("Content-Type")
+		record.ResponseBody = recorder.body.Bytes()
+		ctx, cancel := storeContext(c)
+		defer cancel()
+		if err := store.Complete(ctx, record); err != nil {
+			zap.L().Error("mw.IdempotencyMiddleware.Complete", tracing.Field(ctx), zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// releaseKey removes the reserved key, so the request can be retried with it
+func releaseKey(c *gin.Context, store IdempotencyStore, record *model.IdempotencyKey) {
+	ctx, cancel := storeContext(c)
+	defer cancel()
+	if err := store.Release(ctx, record); err != nil {
+		zap.L().Error("mw.IdempotencyMiddleware.Release", tracing.Field(ctx), zap.String("key", record.Key), zap.Error(err))
+	}
+}
+
+// storeContext returns the context the key is completed or released with. It
+// is not cancelled when the client disconnects, which would otherwise keep the
+// key in progress until it expires.
+func storeContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(tracing.Detach(c.Request.Context()), idempotencyStoreTimeout)
+}
+
+// replay writes the stored response of the key
+func replay(c *gin.Context, existing *model.IdempotencyKey, hash string) {
+	if existing.RequestHash != hash {
+		c.AbortWithStatusJSON(httpErr.ErrorResponse(httpErr.IdempotencyKeyReusedError))
+		return
+	}
+	if !existing.IsCompleted() {
+		c.AbortWithStatusJSON(httpErr.ErrorResponse(httpErr.IdempotencyKeyInProgressError))
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(existing.StatusCode, existing.ContentType, existing.ResponseBody)
+	c.Abort()
+}
+
+// idempotencyScope returns the method, route and user of the request
+func idempotencyScope(c *gin.Context) string {
+	scope := c.Request.Method + " " + c.FullPath()
+	if user, ok := c.Get("user"); ok {
+		scope += " " + user.(*model.User).ID.String()
+	}
+	return scope
+}
+
+func requestHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
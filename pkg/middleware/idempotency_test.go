@@ -0,0 +1,165 @@
+package mw
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"patika-ecommerce/internal/model"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+type mockIdempotencyStore struct {
+	keys      map[string]*model.IdempotencyKey
+	completed int
+}
+
+func (s *mockIdempotencyStore) Reserve(ctx context.Context, key *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	if existing, ok := s.keys[key.Scope+key.Key]; ok && !existing.IsExpired(time.Now()) {
+		return existing, nil
+	}
+	s.keys[key.Scope+key.Key] = key
+	return nil, nil
+}
+
+func (s *mockIdempotencyStore) Complete(ctx context.Context, key *model.IdempotencyKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.completed++
+	return nil
+}
+
+func (s *mockIdempotencyStore) Release(ctx context.Context, key *model.IdempotencyKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delete(s.keys, key.Scope+key.Key)
+	return nil
+}
+
+func newIdempotencyRouter(store IdempotencyStore, status *int, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/orders", IdempotencyMiddleware(store, time.Hour), func(c *gin.Context) {
+		*calls++
+		c.JSON(*status, gin.H{"call": *calls})
+	})
+	return r
+}
+
+func sendWithKey(r *gin.Engine, key string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		firstKey   string
+		secondKey  string
+		secondBody string
+		wantCalls  int
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "replayed", status: 201, firstKey: "k1", secondKey: "k1", secondBody: "{}", wantCalls: 1, wantStatus: 201, wantBody: `{"call":1}`},
+		{name: "differentPayload", status: 201, firstKey: "k1", secondKey: "k1", secondBody: `{"a":1}`, wantCalls: 1, wantStatus: 422},
+		{name: "differentKey", status: 201, firstKey: "k1", secondKey: "k2", secondBody: "{}", wantCalls: 2, wantStatus: 201, wantBody: `{"call":2}`},
+		{name: "withoutKey", status: 201, secondBody: "{}", wantCalls: 2, wantStatus: 201, wantBody: `{"call":2}`},
+		{name: "serverErrorNotStored", status: 500, firstKey: "k1", secondKey: "k1", secondBody: "{}", wantCalls: 2, wantStatus: 500, wantBody: `{"call":2}`},
+		{name: "clientErrorReplayed", status: 400, firstKey: "k1", secondKey: "k1", secondBody: "{}", wantCalls: 1, wantStatus: 400, wantBody: `{"call":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockIdempotencyStore{keys: map[string]*model.IdempotencyKey{}}
+			status, calls := tt.status, 0
+			r := newIdempotencyRouter(store, &status, &calls)
+
+			sendWithKey(r, tt.firstKey, "{}")
+			w := sendWithKey(r, tt.secondKey, tt.secondBody)
+
+			assert.Equal(t, tt.wantCalls, calls)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddleware_inProgress(t *testing.T) {
+	store := &mockIdempotencyStore{keys: map[string]*model.IdempotencyKey{
+		"POST /ordersk1": {Key: "k1", Scope: "POST /orders", RequestHash: requestHash([]byte("{}")), ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	status, calls := 201, 0
+	r := newIdempotencyRouter(store, &status, &calls)
+
+	w := sendWithKey(r, "k1", "{}")
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, 409, w.Code)
+}
+
+func TestIdempotencyMiddleware_panicReleasesKey(t *testing.T) {
+	store := &mockIdempotencyStore{keys: map[string]*model.IdempotencyKey{}}
+	calls := 0
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.POST("/orders", IdempotencyMiddleware(store, time.Hour), func(c *gin.Context) {
+		calls++
+		if calls == 1 {
+			panic("handler failed")
+		}
+		c.JSON(201, gin.H{"call": calls})
+	})
+
+	w := sendWithKey(r, "k1", "{}")
+	assert.Equal(t, 500, w.Code)
+	assert.Equal(t, 0, len(store.keys))
+
+	w = sendWithKey(r, "k1", "{}")
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 201, w.Code)
+}
+
+func TestIdempotencyMiddleware_clientGone(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		wantKeys      int
+		wantCompleted int
+	}{
+		{name: "completed", status: 201, wantKeys: 1, wantCompleted: 1},
+		{name: "released", status: 500, wantKeys: 0, wantCompleted: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockIdempotencyStore{keys: map[string]*model.IdempotencyKey{}}
+			ctx, cancel := context.WithCancel(context.Background())
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/orders", IdempotencyMiddleware(store, time.Hour), func(c *gin.Context) {
+				// the client disconnects before the response is stored
+				cancel()
+				c.JSON(tt.status, gin.H{})
+			})
+
+			req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/orders", bytes.NewBufferString("{}"))
+			req.Header.Set(IdempotencyKeyHeader, "k1")
+			r.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.wantKeys, len(store.keys))
+			assert.Equal(t, tt.wantCompleted, store.completed)
+		})
+	}
+}
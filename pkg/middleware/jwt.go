@@ -1,16 +1,30 @@
 package mw
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	httpErr "patika-ecommerce/internal/httpErrors"
 	"patika-ecommerce/internal/model"
+	"patika-ecommerce/pkg/config"
 	jwtHelper "patika-ecommerce/pkg/jwt"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// SuspensionChecker tells whether the account of a user is suspended. It
+// returns gorm.ErrRecordNotFound for a deleted account.
+type SuspensionChecker interface {
+	IsSuspended(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
 // AuthenticationMiddleware is a middleware that checks for valid JWT tokens
-func AuthenticationMiddleware(secretKey string) gin.HandlerFunc {
+// and blocks the suspended and deleted accounts. The account state is read
+// from the checker, so a suspension applies to the tokens already issued.
+func AuthenticationMiddleware(cfg *config.Config, checker SuspensionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if c.GetHeader("Authorization") != "" {
 			authHeader := c.GetHeader("Authorization")
@@ -18,9 +32,22 @@ func AuthenticationMiddleware(secretKey string) gin.HandlerFunc {
 
 			if parsedAuthHeader[0] == "Bearer" && len(parsedAuthHeader) == 2 {
 				token := parsedAuthHeader[1]
-				decodedClaims := jwtHelper.VerifyToken(token, secretKey)
+				decodedClaims := jwtHelper.VerifyToken(token, cfg)
 
 				if decodedClaims != nil {
+					suspended, err := checker.IsSuspended(c.Request.Context(), decodedClaims.ID)
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized!"})
+						return
+					}
+					if err != nil {
+						c.AbortWithStatusJSON(httpErr.ErrorResponse(err))
+						return
+					}
+					if suspended {
+						c.AbortWithStatusJSON(httpErr.ErrorResponse(httpErr.AccountSuspendedError))
+						return
+					}
 					c.Set("user", decodedClaims)
 					c.Next()
 					return
@@ -39,7 +66,7 @@ func AuthenticationMiddleware(secretKey string) gin.HandlerFunc {
 	}
 }
 
-// AdminMiddleware is a middleware that checks for request user is admin
+// AdminMiddleware is a middleware that checks for request user is admin or super admin
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, exists := c.Get("user")
@@ -49,12 +76,53 @@ func AdminMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		isAdmin := user.(*model.User).IsAdmin
-		if !isAdmin {
+		if !user.(*model.User).IsSuperAdmin() {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized!"})
 			c.Abort()
 			return
 		}
+		if !user.(*model.User).TwoFactorVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": twoFactorRequiredMessage})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// twoFactorRequiredMessage is returned to the admins who logged in without a second factor
+const twoFactorRequiredMessage = "Two-factor authentication is required for admin access, please enroll and log in again"
+
+// RequirePermission is a middleware that checks for a role of the request user
+// grants the permission. Staff users must have logged in with a second factor.
+func RequirePermission(permission model.Permission) gin.HandlerFunc {
+	return requireUser(func(user *model.User) bool {
+		return user.HasPermission(permission)
+	})
+}
+
+// requireUser aborts the request unless the authenticated user is allowed, and
+// for a staff user logged in with a second factor
+func requireUser(allowed func(user *model.User) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := c.Get("user")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+			c.Abort()
+			return
+		}
+
+		if !allowed(user.(*model.User)) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to use this endpoint!"})
+			c.Abort()
+			return
+		}
+		if user.(*model.User).IsStaff() && !user.(*model.User).TwoFactorVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": twoFactorRequiredMessage})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -0,0 +1,45 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"patika-ecommerce/internal/model"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *model.User
+		wantCode int
+	}{
+		{name: "anonymous", wantCode: http.StatusUnauthorized},
+		{name: "withoutPermission", user: &model.User{Roles: []model.UserRole{{Role: model.RoleOrderSupport}}}, wantCode: http.StatusForbidden},
+		{name: "withPermission", user: &model.User{Roles: []model.UserRole{{Role: model.RoleCatalogManager}}, TwoFactorVerified: true}, wantCode: http.StatusOK},
+		{name: "withoutTwoFactor", user: &model.User{Roles: []model.UserRole{{Role: model.RoleCatalogManager}}}, wantCode: http.StatusForbidden},
+		{name: "superAdminWithoutTwoFactor", user: &model.User{Roles: []model.UserRole{{Role: model.RoleSuperAdmin}}}, wantCode: http.StatusForbidden},
+		{name: "legacyAdmin", user: &model.User{IsAdmin: true, TwoFactorVerified: true}, wantCode: http.StatusOK},
+		{name: "legacyAdminWithoutTwoFactor", user: &model.User{IsAdmin: true}, wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/products", func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set("user", tt.user)
+				}
+			}, RequirePermission(model.PermissionProductWrite), func(c *gin.Context) {
+				c.JSON(http.StatusOK, nil)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/products", nil)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package mw
+
+import (
+	"bytes"
+	"encoding/json"
+	"patika-ecommerce/internal/api"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreferredLanguage returns the supported language the Accept-Language
+// header prefers the most, English if it prefers none of them
+func PreferredLanguage(acceptLanguage string) string {
+	lang, best := httpErr.LanguageEnglish, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, quality := strings.TrimSpace(part), 1.0
+		if i := strings.Index(tag, ";"); i >= 0 {
+			if q := strings.TrimSpace(tag[i+1:]); strings.HasPrefix(q, "q=") {
+				if parsed, err := strconv.ParseFloat(q[2:], 64); err == nil {
+					quality = parsed
+				}
+			}
+			tag = tag[:i]
+		}
+		primary := strings.ToLower(strings.SplitN(strings.TrimSpace(tag), "-", 2)[0])
+		if httpErr.SupportedLanguage(primary) && quality > best {
+			lang, best = primary, quality
+		}
+	}
+	return lang
+}
+
+// LocalizationMiddleware translates the validation error messages to the
+// language of the Accept-Language header. The responses are already in
+// English, so they are only rewritten for the other supported languages.
+func LocalizationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := PreferredLanguage(c.GetHeader("Accept-Language"))
+		if lang == httpErr.LanguageEnglish {
+			c.Next()
+			return
+		}
+
+		writer := &errorWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.body.Len() == 0 {
+			return
+		}
+		body := writer.body.Bytes()
+		if localized, ok := localizeValidationError(body, lang); ok {
+			body = localized
+			c.Header("Content-Language", lang)
+		}
+		c.Writer.Write(body)
+	}
+}
+
+// localizeValidationError rewrites the messages of a validation error
+// response, the other responses are not changed
+func localizeValidationError(body []byte, lang string) ([]byte, bool) {
+	var errResponse api.APIErrorResponse
+	if err := json.Unmarshal(body, &errResponse); err != nil || errResponse.ErrorCode != httpErr.ValidationError.Code {
+		return nil, false
+	}
+
+	errResponse.Message = httpErr.ValidationErrorMessage(lang)
+	if details, err := json.Marshal(errResponse.Details); err == nil {
+		var fieldErrs []*api.ValidationFieldError
+		if err := json.Unmarshal(details, &fieldErrs); err == nil {
+			for _, fieldErr := range fieldErrs {
+				fieldErr.Message = httpErr.FieldErrorMessage(lang, fieldErr.Rule, fieldErr.Param)
+			}
+			errResponse.Details = fieldErrs
+		}
+	}
+
+	localized, err := json.Marshal(&errResponse)
+	if err != nil {
+		return nil, false
+	}
+	return localized, true
+}
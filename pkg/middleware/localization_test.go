@@ -0,0 +1,81 @@
+package mw
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"patika-ecommerce/internal/api"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	oaErrors "github.com/go-openapi/errors"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestPreferredLanguage(t *testing.T) {
+	assert.Equal(t, "en", PreferredLanguage(""))
+	assert.Equal(t, "tr", PreferredLanguage("tr-TR,tr;q=0.9,en;q=0.8"))
+	assert.Equal(t, "en", PreferredLanguage("de-DE,en;q=0.8,tr;q=0.5"))
+	assert.Equal(t, "tr", PreferredLanguage("en;q=0.4, TR;q=0.7"))
+	assert.Equal(t, "en", PreferredLanguage("fr"))
+}
+
+func TestLocalizationMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ProblemDetailsMiddleware())
+	r.Use(LocalizationMiddleware())
+	r.POST("/products", func(c *gin.Context) {
+		c.JSON(httpErr.ErrorResponse(oaErrors.CompositeValidationError(
+			oaErrors.Required("name", "body", nil),
+			oaErrors.EnumFail("status", "body", "lost", []interface{}{"active", "passive"}),
+		)))
+	})
+	r.GET("/products/:id", func(c *gin.Context) {
+		c.JSON(httpErr.ErrorResponse(httpErr.ProductNotFoundError))
+	})
+	request := func(method, path, accept, lang string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("Accept", accept)
+		req.Header.Set("Accept-Language", lang)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("POST", "/products", "application/json", "en-US")
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, "", w.Header().Get("Content-Language"))
+	body := struct {
+		Message string                      `json:"message"`
+		Details []*api.ValidationFieldError `json:"details"`
+	}{}
+	assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Validation failed for given payload", body.Message)
+	assert.Equal(t, "This field is required", body.Details[0].Message)
+
+	w = request("POST", "/products", "application/json", "tr-TR,tr;q=0.9")
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, "tr", w.Header().Get("Content-Language"))
+	assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Gönderilen veri doğrulanamadı", body.Message)
+	assert.Equal(t, []*api.ValidationFieldError{
+		{Field: "name", Rule: "required", Message: "Bu alan zorunludur"},
+		{Field: "status", Rule: "enum", Param: "active, passive", Message: "Değer şunlardan biri olmalıdır: active, passive"},
+	}, body.Details)
+
+	// the translated errors are still answered as problem details
+	w = request("POST", "/products", ProblemJSONMediaType, "tr")
+	assert.Equal(t, ProblemJSONMediaType, w.Header().Get("Content-Type"))
+	problem := ProblemDetails{}
+	assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "Gönderilen veri doğrulanamadı", problem.Title)
+	assert.Equal(t, "validation_failed", problem.Code)
+
+	// the other errors are left as they are
+	w = request("GET", "/products/1", "application/json", "tr")
+	assert.Equal(t, 404, w.Code)
+	assert.Equal(t, "", w.Header().Get("Content-Language"))
+	assert.Equal(t, `{"code":404,"errorCode":"product_not_found","message":"Product not found"}`, w.Body.String())
+}
@@ -0,0 +1,43 @@
+package mw
+
+import (
+	"crypto/subtle"
+	"patika-ecommerce/pkg/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute labels the requests without a route, like the 404s of scanners
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware observes the duration of the requests by route and status
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsAuthMiddleware requires the bearer token on the metrics endpoint, an
+// empty token leaves the endpoint open
+func MetricsAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte("Bearer "+token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="metrics"`)
+			c.AbortWithStatus(401)
+			return
+		}
+		c.Next()
+	}
+}
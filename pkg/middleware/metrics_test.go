@@ -0,0 +1,67 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"patika-ecommerce/pkg/metrics"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(MetricsMiddleware())
+	r.GET("/metrics-test/:id", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, nil)
+	})
+
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2", "/metrics-test-missing"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		r.ServeHTTP(w, req)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	metrics.Handler().ServeHTTP(w, req)
+	body := w.Body.String()
+	assert.Equal(t, true, strings.Contains(body,
+		`http_request_duration_seconds_count{method="GET",route="/metrics-test/:id",status="404"} 2`))
+	assert.Equal(t, true, strings.Contains(body,
+		`http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`))
+}
+
+func TestMetricsAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantCode      int
+	}{
+		{name: "no token", token: "", authorization: "", wantCode: http.StatusOK},
+		{name: "valid token", token: "scrape", authorization: "Bearer scrape", wantCode: http.StatusOK},
+		{name: "missing token", token: "scrape", authorization: "", wantCode: http.StatusUnauthorized},
+		{name: "wrong token", token: "scrape", authorization: "Bearer other", wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.GET("/metrics", MetricsAuthMiddleware(tt.token), func(c *gin.Context) {
+				c.String(http.StatusOK, "")
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
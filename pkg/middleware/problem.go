@@ -0,0 +1,87 @@
+package mw
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"patika-ecommerce/internal/api"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemJSONMediaType is the media type of RFC 7807 problem details
+const ProblemJSONMediaType = "application/problem+json"
+
+// problemTypePrefix makes the error code a problem type URI
+const problemTypePrefix = "urn:patika-ecommerce:error:"
+
+// ProblemDetails is an error response in the RFC 7807 format
+type ProblemDetails struct {
+	Type     string      `json:"type"`
+	Title    string      `json:"title"`
+	Status   int64       `json:"status"`
+	Instance string      `json:"instance,omitempty"`
+	Code     string      `json:"code"`
+	Details  interface{} `json:"details,omitempty"`
+}
+
+// errorWriter holds back the body of an error response, so it can be
+// rewritten once the handler is done
+type errorWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *errorWriter) Write(b []byte) (int, error) {
+	if w.Status() < http.StatusBadRequest {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.body.Write(b)
+}
+
+func (w *errorWriter) WriteString(s string) (int, error) {
+	if w.Status() < http.StatusBadRequest {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+// ProblemDetailsMiddleware answers the errors as RFC 7807 problem details to
+// the clients accepting application/problem+json. The other clients and the
+// error responses without an error code are left as they are.
+func ProblemDetailsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.GetHeader("Accept"), ProblemJSONMediaType) {
+			c.Next()
+			return
+		}
+
+		writer := &errorWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.body.Len() == 0 {
+			return
+		}
+		body := writer.body.Bytes()
+
+		var errResponse api.APIErrorResponse
+		if err := json.Unmarshal(body, &errResponse); err == nil && errResponse.ErrorCode != "" {
+			problem, err := json.Marshal(&ProblemDetails{
+				Type:     problemTypePrefix + errResponse.ErrorCode,
+				Title:    errResponse.Message,
+				Status:   errResponse.Code,
+				Instance: c.Request.URL.Path,
+				Code:     errResponse.ErrorCode,
+				Details:  errResponse.Details,
+			})
+			if err == nil {
+				body = problem
+				c.Header("Content-Type", ProblemJSONMediaType)
+			}
+		}
+		c.Writer.Write(body)
+	}
+}
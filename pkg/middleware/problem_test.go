@@ -0,0 +1,61 @@
+package mw
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+func TestProblemDetailsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ProblemDetailsMiddleware())
+	r.GET("/products/:id", func(c *gin.Context) {
+		c.JSON(httpErr.ErrorResponse(httpErr.InsufficientStockError.WithDetails(map[string]interface{}{"available": 2})))
+	})
+	r.GET("/ok", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	r.GET("/unauthorized", func(c *gin.Context) {
+		c.AbortWithStatusJSON(401, gin.H{"error": "You are not authorized!"})
+	})
+	request := func(path string, accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", accept)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// the error codes are answered in both formats
+	w := request("/products/1", "application/json")
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	body := map[string]interface{}{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	assert.Equal(t, "insufficient_stock", body["errorCode"])
+
+	w = request("/products/1", "application/problem+json, application/json")
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, ProblemJSONMediaType, w.Header().Get("Content-Type"))
+	problem := ProblemDetails{}
+	assert.Equal(t, nil, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, "urn:patika-ecommerce:error:insufficient_stock", problem.Type)
+	assert.Equal(t, "Product stock is not enough", problem.Title)
+	assert.Equal(t, int64(400), problem.Status)
+	assert.Equal(t, "/products/1", problem.Instance)
+	assert.Equal(t, map[string]interface{}{"available": float64(2)}, problem.Details)
+
+	// successful responses and errors without a code are left as they are
+	w = request("/ok", ProblemJSONMediaType)
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"status":"ok"}`, w.Body.String())
+	w = request("/unauthorized", ProblemJSONMediaType)
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `{"error":"You are not authorized!"}`, w.Body.String())
+}
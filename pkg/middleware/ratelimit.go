@@ -0,0 +1,101 @@
+package mw
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/pkg/ratelimit"
+	"patika-ecommerce/pkg/tracing"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RateLimitRule limits the requests with the same key
+type RateLimitRule struct {
+	Name  string
+	Limit ratelimit.Limit
+	// Key returns the key of the request, a request without a key is not limited by the rule
+	Key func(c *gin.Context) string
+}
+
+// RateLimitMiddleware takes a token for every rule of the request and rejects
+// it with 429 and a Retry-After header when a bucket is empty. The
+// RateLimit-* headers describe the rule closest to its limit. Rules without a
+// burst are disabled. A failing store does not block the requests.
+func RateLimitMiddleware(store ratelimit.Store, rules ...RateLimitRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var tightest *ratelimit.Result
+
+		for _, rule := range rules {
+			if rule.Limit.Burst <= 0 || rule.Limit.Period <= 0 {
+				continue
+			}
+			key := rule.Key(c)
+			if key == "" {
+				continue
+			}
+
+			result, err := store.Take(c.Request.Context(), rule.Name+":"+key, rule.Limit)
+			if err != nil {
+				zap.L().Error("mw.RateLimitMiddleware", tracing.Field(c.Request.Context()), zap.String("rule", rule.Name), zap.Error(err))
+				continue
+			}
+			if !result.Allowed {
+				zap.L().Warn("rate limit exceeded", tracing.Field(c.Request.Context()), zap.String("rule", rule.Name), zap.String("ip", c.ClientIP()))
+				setRateLimitHeaders(c, result)
+				c.Header("Retry-After", headerSeconds(result.RetryAfter))
+				c.AbortWithStatusJSON(httpErr.ErrorResponse(httpErr.TooManyRequestsError))
+				return
+			}
+			if tightest == nil || result.Remaining < tightest.Remaining {
+				tightest = &result
+			}
+		}
+
+		if tightest != nil {
+			setRateLimitHeaders(c, *tightest)
+		}
+		c.Next()
+	}
+}
+
+// ClientIPKey keys the requests by the client IP
+func ClientIPKey(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// JSONFieldKey keys the requests by a string field of the JSON body, e.g. the
+// email of a login request. The value is compared case insensitively.
+func JSONFieldKey(field string) func(c *gin.Context) string {
+	return func(c *gin.Context) string {
+		body, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			return ""
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		payload := map[string]interface{}{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return ""
+		}
+		value, _ := payload[field].(string)
+		return strings.ToLower(strings.TrimSpace(value))
+	}
+}
+
+func setRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", headerSeconds(result.Reset))
+}
+
+// headerSeconds returns the duration in whole seconds, rounded up
+func headerSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,102 @@
+package mw
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"patika-ecommerce/pkg/ratelimit"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store is down")
+}
+
+func newRateLimitRouter(store ratelimit.Store, trustedProxies ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.SetTrustedProxies(trustedProxies)
+	r.POST("/login", RateLimitMiddleware(store,
+		RateLimitRule{Name: "login:ip", Limit: ratelimit.Limit{Burst: 3, Period: time.Minute}, Key: ClientIPKey},
+		RateLimitRule{Name: "login:account", Limit: ratelimit.Limit{Burst: 2, Period: time.Minute}, Key: JSONFieldKey("email")},
+	), func(c *gin.Context) {
+		var body struct{ Email string }
+		c.ShouldBindJSON(&body)
+		c.JSON(http.StatusOK, body.Email)
+	})
+	return r
+}
+
+func login(r *gin.Engine, email string) *httptest.ResponseRecorder {
+	return loginForwarded(r, email, "")
+}
+
+func loginForwarded(r *gin.Engine, email string, forwardedFor string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(`{"email":"`+email+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	req.RemoteAddr = "10.0.0.1:1234"
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	r := newRateLimitRouter(ratelimit.NewMemoryStore())
+
+	w := login(r, "user@example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"user@example.com"`, w.Body.String())
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+
+	// the account limit is case insensitive
+	w = login(r, "USER@example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+	w = login(r, "user@example.com")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+
+	// the third request used the last token of the IP
+	w = login(r, "other@example.com")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "3", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "20", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimitMiddleware_forwardedFor(t *testing.T) {
+	// a forged header does not get a fresh bucket of an untrusted peer
+	r := newRateLimitRouter(ratelimit.NewMemoryStore())
+	for i, ip := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		assert.Equal(t, http.StatusOK, loginForwarded(r, strconv.Itoa(i)+"@example.com", ip).Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, loginForwarded(r, "other@example.com", "192.0.2.4").Code)
+
+	// the header of a trusted proxy gives the client IP
+	r = newRateLimitRouter(ratelimit.NewMemoryStore(), "10.0.0.1")
+	for i, ip := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		assert.Equal(t, http.StatusOK, loginForwarded(r, strconv.Itoa(i)+"@example.com", ip).Code)
+	}
+	assert.Equal(t, http.StatusOK, loginForwarded(r, "other@example.com", "192.0.2.4").Code)
+}
+
+func TestRateLimitMiddleware_failingStore(t *testing.T) {
+	r := newRateLimitRouter(failingRateLimitStore{})
+
+	w := login(r, "user@example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "", w.Header().Get("RateLimit-Limit"))
+}
@@ -0,0 +1,36 @@
+package mw
+
+import (
+	"context"
+	httpErr "patika-ecommerce/internal/httpErrors"
+	"patika-ecommerce/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// EmailVerificationChecker tells whether a user verified the email address
+type EmailVerificationChecker interface {
+	IsEmailVerified(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+// RequireVerifiedEmail is a middleware that blocks the users who did not verify
+// their email address. The state is read from the checker, so a verification
+// applies without a new token.
+func RequireVerifiedEmail(checker EmailVerificationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := c.MustGet("user").(*model.User)
+
+		verified, err := checker.IsEmailVerified(c.Request.Context(), user.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(httpErr.ErrorResponse(err))
+			return
+		}
+		if !verified {
+			c.AbortWithStatusJSON(httpErr.ErrorResponse(httpErr.EmailNotVerifiedError))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package mw
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"patika-ecommerce/internal/model"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/google/uuid"
+)
+
+type mockEmailVerificationChecker map[uuid.UUID]bool
+
+func (m mockEmailVerificationChecker) IsEmailVerified(ctx context.Context, id uuid.UUID) (bool, error) {
+	return m[id], nil
+}
+
+func TestRequireVerifiedEmail(t *testing.T) {
+	verified, unverified := uuid.New(), uuid.New()
+	checker := mockEmailVerificationChecker{verified: true}
+
+	tests := []struct {
+		name     string
+		userID   uuid.UUID
+		wantCode int
+	}{
+		{name: "verified", userID: verified, wantCode: http.StatusOK},
+		{name: "unverified", userID: unverified, wantCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.POST("/orders", func(c *gin.Context) {
+				c.Set("user", &model.User{Base: model.Base{ID: tt.userID}})
+			}, RequireVerifiedEmail(checker), func(c *gin.Context) {
+				c.JSON(http.StatusOK, nil)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/orders", nil)
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package migration
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestLoadFS(t *testing.T) {
+	migrations, err := LoadFS(fstest.MapFS{
+		"0002_add_brand.up.sql":        {Data: []byte("ALTER TABLE products ADD COLUMN brand text;")},
+		"0002_add_brand.down.sql":      {Data: []byte("ALTER TABLE products DROP COLUMN brand;")},
+		"0001_initial_schema.up.sql":   {Data: []byte("CREATE TABLE products (id uuid);")},
+		"0001_initial_schema.down.sql": {Data: []byte("DROP TABLE products;")},
+		"0003_backfill.up.sql":         {Data: []byte("UPDATE products SET brand = '';")},
+		"README.md":                    {Data: []byte("not a migration")},
+	})
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "initial_schema", Up: "CREATE TABLE products (id uuid);", Down: "DROP TABLE products;"},
+		{Version: 2, Name: "add_brand", Up: "ALTER TABLE products ADD COLUMN brand text;", Down: "ALTER TABLE products DROP COLUMN brand;"},
+		{Version: 3, Name: "backfill", Up: "UPDATE products SET brand = '';"},
+	}, migrations)
+}
+
+func TestLoadFS_Invalid(t *testing.T) {
+	_, err := LoadFS(fstest.MapFS{
+		"0001_initial_schema.down.sql": {Data: []byte("DROP TABLE products;")},
+	})
+	assert.NotEqual(t, nil, err)
+
+	_, err = LoadFS(fstest.MapFS{
+		"0001_initial_schema.up.sql": {Data: []byte("CREATE TABLE products (id uuid);")},
+		"0001_other.down.sql":        {Data: []byte("DROP TABLE products;")},
+	})
+	assert.NotEqual(t, nil, err)
+
+	_, err = LoadFS(fstest.MapFS{
+		"README.md": {Data: []byte("not a migration")},
+	})
+	assert.Equal(t, ErrNoMigrations, err)
+}
+
+func TestLoad_RepositoryMigrations(t *testing.T) {
+	migrations, err := Load("../../migrations")
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	for _, migration := range migrations {
+		assert.NotEqual(t, "", migration.Down)
+	}
+}
@@ -0,0 +1,146 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-playground/assert/v2"
+)
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "initial_schema", Up: "CREATE TABLE products (id uuid)", Down: "DROP TABLE products"},
+	{Version: 2, Name: "add_brand", Up: "ALTER TABLE products ADD COLUMN brand text", Down: "ALTER TABLE products DROP COLUMN brand"},
+}
+
+func expectLock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WithArgs(LockKey()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WithArgs(LockKey()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func expectApplied(mock sqlmock.Sqlmock, versions ...int64) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT to_regclass('schema_migrations') IS NOT NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, version := range versions {
+		rows.AddRow(version, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT version, applied_at FROM schema_migrations")).WillReturnRows(rows)
+}
+
+func TestMigrator_Up(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	expectLock(mock)
+	expectApplied(mock, 1)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(testMigrations[1].Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name) VALUES ($1, $2)")).
+		WithArgs(int64(2), "add_brand").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	applied, err := NewMigrator(db, testMigrations).Up(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Migration{testMigrations[1]}, applied)
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Up_Failure(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	expectLock(mock)
+	expectApplied(mock)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(testMigrations[0].Up)).WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	expectUnlock(mock)
+
+	applied, err := NewMigrator(db, testMigrations).Up(context.Background())
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 0, len(applied))
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	expectLock(mock)
+	expectApplied(mock, 1, 2)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(testMigrations[1].Down)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = $1")).
+		WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectUnlock(mock)
+
+	rolledBack, err := NewMigrator(db, testMigrations).Down(context.Background(), 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Migration{testMigrations[1]}, rolledBack)
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Down_WithoutDownFile(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	expectLock(mock)
+	expectApplied(mock, 1)
+	expectUnlock(mock)
+
+	migrations := []Migration{{Version: 1, Name: "backfill", Up: "UPDATE products SET stock = 0"}}
+	_, err := NewMigrator(db, migrations).Down(context.Background(), 1)
+	assert.Equal(t, true, errors.Is(err, ErrNoDownMigration))
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+}
+
+func TestMigrator_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied []int64
+		pending bool
+	}{
+		{name: "upToDate", applied: []int64{1, 2}},
+		{name: "newerSchema", applied: []int64{1, 2, 3}},
+		{name: "pending", applied: []int64{1}, pending: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, _ := sqlmock.New()
+			defer db.Close()
+
+			expectApplied(mock, tt.applied...)
+
+			err := NewMigrator(db, testMigrations).Verify(context.Background())
+			assert.Equal(t, tt.pending, errors.Is(err, ErrPendingMigrations))
+			assert.Equal(t, nil, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrator_Status_EmptyDatabase(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT to_regclass('schema_migrations') IS NOT NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	statuses, err := NewMigrator(db, testMigrations).Status(context.Background())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(statuses))
+	assert.Equal(t, true, statuses[0].AppliedAt == nil)
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+}
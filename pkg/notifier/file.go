@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileNotifier appends notifications to a file, one JSON document per line
+type FileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier creates a new FileNotifier
+func NewFileNotifier(path string) (*FileNotifier, error) {
+	if path == "" {
+		return nil, errors.New("notifier file path is required")
+	}
+	return &FileNotifier{path: path}, nil
+}
+
+// Notify appends the notification to the file
+func (n *FileNotifier) Notify(notification *Notification) error {
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	line, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,42 @@
+package notifier
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestFileNotifier_Notify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	n, err := NewFileNotifier(path)
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, nil, n.Notify(&Notification{Kind: "low_stock", Recipient: "admin@example.com", Subject: "first"}))
+	assert.Equal(t, nil, n.Notify(&Notification{Kind: "back_in_stock", Recipient: "user@example.com", Subject: "second"}))
+
+	file, err := os.Open(path)
+	assert.Equal(t, nil, err)
+	defer file.Close()
+
+	notifications := []Notification{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		notification := Notification{}
+		assert.Equal(t, nil, json.Unmarshal(scanner.Bytes(), &notification))
+		notifications = append(notifications, notification)
+	}
+
+	assert.Equal(t, 2, len(notifications))
+	assert.Equal(t, "first", notifications[0].Subject)
+	assert.Equal(t, "user@example.com", notifications[1].Recipient)
+	assert.Equal(t, false, notifications[1].CreatedAt.IsZero())
+}
+
+func TestNewFileNotifier_emptyPath(t *testing.T) {
+	_, err := NewFileNotifier("")
+	assert.NotEqual(t, nil, err)
+}
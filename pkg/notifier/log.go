@@ -0,0 +1,30 @@
+package notifier
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LogNotifier writes notifications to the application log
+type LogNotifier struct{}
+
+// NewLogNotifier creates a new LogNotifier
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify logs the notification
+func (n *LogNotifier) Notify(notification *Notification) error {
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+
+	zap.L().Info("notification",
+		zap.String("kind", notification.Kind),
+		zap.String("recipient", notification.Recipient),
+		zap.String("subject", notification.Subject),
+		zap.String("body", notification.Body),
+	)
+	return nil
+}
@@ -0,0 +1,36 @@
+package notifier
+
+import (
+	"fmt"
+	"patika-ecommerce/pkg/config"
+	"time"
+)
+
+// Notification is a message sent to a single recipient
+type Notification struct {
+	Kind      string    `json:"kind"`
+	Recipient string    `json:"recipient"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(notification *Notification) error
+}
+
+// NewNotifier creates the notifier of the configured type
+func NewNotifier(cfg *config.Config) (Notifier, error) {
+	switch cfg.NotifierConfig.Type {
+	case "", "log":
+		return NewLogNotifier(), nil
+	case "file":
+		return NewFileNotifier(cfg.NotifierConfig.FilePath)
+	case "smtp":
+		c := cfg.NotifierConfig
+		return NewSMTPNotifier(c.SMTPHost, c.SMTPPort, c.SMTPUsername, c.SMTPPassword, c.SMTPFrom)
+	default:
+		return nil, fmt.Errorf("unknown notifier type: %s", cfg.NotifierConfig.Type)
+	}
+}
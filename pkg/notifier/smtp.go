@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// sendMailFunc has the signature of smtp.SendMail
+type sendMailFunc func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+// SMTPNotifier mails notifications through an SMTP server
+type SMTPNotifier struct {
+	addr     string
+	auth     smtp.Auth
+	from     string
+	sendMail sendMailFunc
+}
+
+// NewSMTPNotifier creates a new SMTPNotifier. Authentication is used only
+// when a username is given.
+func NewSMTPNotifier(host string, port int, username, password, from string) (*SMTPNotifier, error) {
+	if host == "" || from == "" {
+		return nil, errors.New("smtp host and from address are required")
+	}
+	if port == 0 {
+		port = 587
+	}
+
+	n := &SMTPNotifier{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		from:     from,
+		sendMail: smtp.SendMail,
+	}
+	if username != "" {
+		n.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return n, nil
+}
+
+// Notify mails the notification to its recipient
+func (n *SMTPNotifier) Notify(notification *Notification) error {
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now()
+	}
+	if notification.Recipient == "" {
+		return errors.New("notification recipient is required")
+	}
+
+	return n.sendMail(n.addr, n.auth, n.from, []string{notification.Recipient}, n.message(notification))
+}
+
+// message returns the notification as a plain text mail
+func (n *SMTPNotifier) message(notification *Notification) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", n.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", notification.Recipient)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", notification.CreatedAt.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(notification.Body)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
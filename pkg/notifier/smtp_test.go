@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestSMTPNotifier_Notify(t *testing.T) {
+	n, err := NewSMTPNotifier("mail.example.com", 0, "", "", "shop@example.com")
+	assert.Equal(t, nil, err)
+
+	var (
+		addr string
+		to   []string
+		msg  string
+	)
+	n.sendMail = func(a string, _ smtp.Auth, from string, recipients []string, m []byte) error {
+		addr, to, msg = a, recipients, string(m)
+		return nil
+	}
+
+	err = n.Notify(&Notification{Kind: "password_reset", Recipient: "user@example.com", Subject: "Reset your password", Body: "link"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "mail.example.com:587", addr)
+	assert.Equal(t, []string{"user@example.com"}, to)
+	assert.Equal(t, true, strings.Contains(msg, "To: user@example.com\r\n"))
+	assert.Equal(t, true, strings.Contains(msg, "Subject: Reset your password\r\n"))
+	assert.Equal(t, true, strings.HasSuffix(msg, "\r\n\r\nlink\r\n"))
+}
+
+func TestNewSMTPNotifier_missingHost(t *testing.T) {
+	_, err := NewSMTPNotifier("", 25, "", "", "shop@example.com")
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit allows Burst requests at once, the tokens are refilled evenly over Period
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// Result is the state of a bucket after a request
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is the time until the next token, set when the request is denied
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps the token buckets. MemoryStore is enough for a single instance.
+// When several instances serve the API the buckets must be kept in a shared
+// store (Redis, the database, ...) implementing Store, otherwise a client can
+// spread its requests over the instances.
+type Store interface {
+	// Take takes a token from the bucket of the key
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Bucket is the state of a token bucket. It is kept as it is by the stores,
+// so every store limits the same way.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Take refills the bucket for the time passed since its last update and takes
+// a token when there is one. A new bucket starts full.
+func (b *Bucket) Take(limit Limit, now time.Time) Result {
+	burst := float64(limit.Burst)
+	rate := burst / limit.Period.Seconds()
+
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = burst
+	} else if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(burst, b.Tokens+elapsed*rate)
+	}
+	b.UpdatedAt = now
+
+	result := Result{Limit: limit.Burst}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.Tokens) / rate)
+	}
+	result.Remaining = int(math.Floor(b.Tokens))
+	result.Reset = seconds((burst - b.Tokens) / rate)
+	return result
+}
+
+// IsFull returns true when the bucket is refilled completely at the given
+// time, so it can be dropped and started again
+func (b *Bucket) IsFull(limit Limit, now time.Time) bool {
+	return now.Sub(b.UpdatedAt) >= limit.Period
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestBucket_Take(t *testing.T) {
+	limit := Limit{Burst: 3, Period: time.Minute}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	bucket := &Bucket{}
+
+	for i := 2; i >= 0; i-- {
+		result := bucket.Take(limit, now)
+		assert.Equal(t, true, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+		assert.Equal(t, 3, result.Limit)
+	}
+
+	result := bucket.Take(limit, now)
+	assert.Equal(t, false, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 20*time.Second, result.RetryAfter)
+	assert.Equal(t, time.Minute, result.Reset)
+
+	// a token is refilled every 20 seconds
+	result = bucket.Take(limit, now.Add(20*time.Second))
+	assert.Equal(t, true, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// the bucket never holds more than the burst
+	result = bucket.Take(limit, now.Add(time.Hour))
+	assert.Equal(t, true, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Burst: 1, Period: time.Minute}
+
+	result, err := store.Take(context.Background(), "ip:1.1.1.1", limit)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, result.Allowed)
+
+	result, _ = store.Take(context.Background(), "ip:1.1.1.1", limit)
+	assert.Equal(t, false, result.Allowed)
+
+	// keys do not share a bucket
+	result, _ = store.Take(context.Background(), "ip:2.2.2.2", limit)
+	assert.Equal(t, true, result.Allowed)
+	assert.Equal(t, 2, store.Len())
+
+	// full buckets are removed
+	now = now.Add(time.Hour)
+	store.sweep(now)
+	assert.Equal(t, 0, store.Len())
+}
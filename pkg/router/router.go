@@ -4,57 +4,197 @@ import (
 	auth "patika-ecommerce/internal/auth"
 	cart "patika-ecommerce/internal/cart"
 	category "patika-ecommerce/internal/category"
+	"patika-ecommerce/internal/idempotency"
+	"patika-ecommerce/internal/inventory"
+	"patika-ecommerce/internal/job"
 	"patika-ecommerce/internal/order"
+	"patika-ecommerce/internal/privacy"
 	product "patika-ecommerce/internal/product"
+	"patika-ecommerce/internal/role"
+	"patika-ecommerce/internal/stockalert"
 	user "patika-ecommerce/internal/user"
+	"patika-ecommerce/internal/wishlist"
 
 	"patika-ecommerce/pkg/config"
+	jwtHelper "patika-ecommerce/pkg/jwt"
+	mw "patika-ecommerce/pkg/middleware"
+	"patika-ecommerce/pkg/notifier"
+	"patika-ecommerce/pkg/ratelimit"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
-// InitializeRoutes
-func InitializeRoutes(rootRouter *gin.RouterGroup, db *gorm.DB, cfg *config.Config) {
+// InitializeRoutes initializes the routes and returns the job runner, which
+// is started when jobs are enabled and must be stopped on shutdown
+func InitializeRoutes(rootRouter *gin.RouterGroup, db *gorm.DB, cfg *config.Config) *job.Runner {
 
 	// Initialize the router groups
 	authGroup := rootRouter.Group("/")
+	meGroup := rootRouter.Group("/me")
+	privacyGroup := rootRouter.Group("/me")
 	categoryGroup := rootRouter.Group("/categories")
 	productGroup := rootRouter.Group("/products")
 	cartGroup := rootRouter.Group("/cart")
 	orderGroup := rootRouter.Group("/orders")
+	inventoryGroup := rootRouter.Group("/inventory")
+	stockAlertGroup := rootRouter.Group("/stock-alerts")
+	wishlistGroup := rootRouter.Group("/wishlists")
+	sharedWishlistGroup := rootRouter.Group("/shared-wishlists")
+	jobGroup := rootRouter.Group("/jobs")
+	adminGroup := rootRouter.Group("/admin")
+	adminUserGroup := rootRouter.Group("/admin/users")
+
+	// Signing keys are loaded once, a broken key stops the service
+	if _, err := jwtHelper.KeyRingFor(cfg); err != nil {
+		zap.L().Fatal("cannot load jwt signing keys", zap.Error(err))
+	}
+
+	// Notifier
+	appNotifier, err := notifier.NewNotifier(cfg)
+	if err != nil {
+		zap.L().Fatal("cannot create notifier", zap.Error(err))
+	}
+
+	// Idempotency repository
+	idempotencyRepo := idempotency.NewIdempotencyRepository(db)
+	idempotencyMiddleware := mw.IdempotencyMiddleware(idempotencyRepo, time.Duration(cfg.IdempotencyConfig.TTLHours)*time.Hour)
 
 	// User repository
 	userRepo := user.NewUserRepository(db)
-	userRepo.Migration()
+	authMiddleware := mw.AuthenticationMiddleware(cfg, userRepo)
 	// Auth service
-	authService := auth.NewAuthService(cfg, userRepo)
-	auth.NewAuthHandler(authGroup, cfg, authService)
+	refreshTokenRepo := auth.NewRefreshTokenRepository(db)
+	userTokenRepo := auth.NewUserTokenRepository(db)
+	twoFactorRepo := auth.NewTwoFactorRepository(db)
+	authService := auth.NewAuthService(cfg, userRepo, refreshTokenRepo, userTokenRepo, twoFactorRepo, appNotifier)
+	loginRateLimit, registerRateLimit := authRateLimits(cfg)
+	auth.NewAuthHandler(authGroup, cfg, authMiddleware, authService, loginRateLimit, registerRateLimit)
+	oidcRepo := auth.NewOIDCRepository(db)
+	oidcService := auth.NewOIDCService(cfg, authService, userRepo, oidcRepo)
+	auth.NewOIDCHandler(authGroup, oidcService, loginRateLimit)
+	auth.NewAccountAdminHandler(adminGroup, cfg, authMiddleware, authService)
+	userService := user.NewUserService(userRepo, authService)
+	user.NewUserHandler(meGroup, authMiddleware, userService)
+
+	// Role repository
+	roleRepo := role.NewRoleRepository(db)
+	roleService := role.NewRoleService(roleRepo, authService)
+	role.NewRoleHandler(adminGroup, authMiddleware, roleService)
 
 	// Category repository
 	categoryRepo := category.NewCategoryrRepository(db)
-	categoryRepo.Migration()
 	categoryService := category.NewCategoryService(categoryRepo)
-	category.NewCategoryHandler(categoryGroup, cfg, categoryService)
+	category.NewCategoryHandler(categoryGroup, authMiddleware, categoryService)
 
 	// Product repository
 	productRepo := product.NewProductRepository(db)
-	productRepo.Migration()
-	product.NewProductHandler(productGroup, cfg, productRepo)
+
+	// Stock alert repository
+	stockAlertRepo := stockalert.NewStockAlertRepository(db)
+	stockAlertService := stockalert.NewStockAlertService(stockAlertRepo, appNotifier)
+	stockalert.NewStockAlertHandler(stockAlertGroup, authMiddleware, stockAlertService)
+
+	product.NewProductHandler(productGroup, authMiddleware, productRepo, stockAlertService)
+
+	// Inventory repository
+	inventoryRepo := inventory.NewInventoryRepository(db)
+	inventoryService := inventory.NewInventoryService(inventoryRepo, stockAlertService)
+	inventory.NewInventoryHandler(inventoryGroup, authMiddleware, inventoryService)
 
 	// Cart repository
 	cartRepo := cart.NewCartRepository(db)
-	cartRepo.Migration()
 	cartItemRepo := cart.NewCartItemRepository(db)
-	cartItemRepo.Migration()
 	cartService := cart.NewCartService(cartRepo, productRepo, cartItemRepo)
-	cart.NewCartHandler(cartGroup, cfg, cartService)
+	cart.NewCartHandler(cartGroup, authMiddleware, cartService, idempotencyMiddleware)
+
+	// Wishlist repository
+	wishlistRepo := wishlist.NewWishlistRepository(db)
+	wishlistService := wishlist.NewWishlistService(wishlistRepo, productRepo)
+	wishlist.NewWishlistHandler(wishlistGroup, authMiddleware, wishlistService)
+	wishlist.NewSharedWishlistHandler(sharedWishlistGroup, wishlistService)
 
 	// Order repository
 	orderRepo := order.NewOrderRepository(db)
-	orderRepo.Migration()
-	orderItemRepo := order.NewOrderItemRepository(db)
-	orderItemRepo.Migration()
-	order.NewOrderHandler(orderGroup, cfg, orderRepo)
+	// a rejected checkout is not stored for its Idempotency-Key
+	checkoutMiddlewares := []gin.HandlerFunc{}
+	if cfg.AccountConfig.RequireVerifiedEmailForCheckout {
+		checkoutMiddlewares = append(checkoutMiddlewares, mw.RequireVerifiedEmail(userRepo))
+	}
+	checkoutMiddlewares = append(checkoutMiddlewares, idempotencyMiddleware)
+	order.NewOrderHandler(orderGroup, authMiddleware, orderRepo, stockAlertService, checkoutMiddlewares...)
+
+	// User administration
+	userAdminService := user.NewUserAdminService(userRepo, authService, orderRepo, cartRepo)
+	user.NewUserAdminHandler(adminUserGroup, authMiddleware, userAdminService)
+
+	// Privacy repository
+	privacyRepo := privacy.NewPrivacyRepository(db)
+	privacyService := privacy.NewPrivacyService(privacyRepo, cfg.PrivacyConfig.ExportFolder,
+		time.Duration(cfg.PrivacyConfig.ExportRetentionHours)*time.Hour)
+	privacy.NewPrivacyHandler(privacyGroup, authMiddleware, privacyService)
+
+	// Job repository
+	sqlDB, err := db.DB()
+	if err != nil {
+		zap.L().Fatal("cannot get sql database instance", zap.Error(err))
+	}
+	jobRepo := job.NewJobRepository(db)
+	runner := job.NewRunner(jobRepo, job.NewPostgresLocker(sqlDB))
+	runner.Register(
+		cart.NewAbandonedCartJob(cartRepo, appNotifier,
+			time.Duration(cfg.JobConfig.AbandonedCartIdleHours)*time.Hour,
+			time.Duration(cfg.JobConfig.AbandonedCartExpireHours)*time.Hour),
+		time.Duration(cfg.JobConfig.AbandonedCartIntervalMinutes)*time.Minute,
+	)
+	stockAlertInterval := time.Duration(cfg.JobConfig.StockAlertIntervalSeconds) * time.Second
+	if stockAlertInterval <= 0 {
+		stockAlertInterval = stockalert.DefaultCheckInterval
+	}
+	runner.Register(stockalert.NewAlertJob(stockAlertService), stockAlertInterval)
+	runner.Register(
+		idempotency.NewPurgeJob(idempotencyRepo),
+		time.Duration(cfg.IdempotencyConfig.PurgeIntervalMinutes)*time.Minute,
+	)
+	runner.Register(
+		privacy.NewExportJob(privacyService),
+		time.Duration(cfg.PrivacyConfig.ExportIntervalMinutes)*time.Minute,
+	)
+	job.NewJobHandler(jobGroup, authMiddleware, jobRepo, runner)
+	if cfg.JobConfig.Enabled {
+		runner.Start()
+	}
+
+	return runner
+}
+
+// authRateLimits returns the rate limit middlewares of the login and register endpoints
+func authRateLimits(cfg *config.Config) (gin.HandlerFunc, gin.HandlerFunc) {
+	var store ratelimit.Store
+	switch cfg.RateLimitConfig.Store {
+	case "", "memory":
+		store = ratelimit.NewMemoryStore()
+	default:
+		zap.L().Fatal("unknown rate limit store", zap.String("store", cfg.RateLimitConfig.Store))
+	}
+
+	c := cfg.RateLimitConfig
+	loginPeriod := time.Duration(c.LoginPeriodSeconds) * time.Second
+	registerPeriod := time.Duration(c.RegisterPeriodSeconds) * time.Second
+
+	login := mw.RateLimitMiddleware(store,
+		mw.RateLimitRule{Name: "login:ip", Limit: ratelimit.Limit{Burst: c.LoginPerIP, Period: loginPeriod}, Key: mw.ClientIPKey},
+		mw.RateLimitRule{Name: "login:account", Limit: ratelimit.Limit{Burst: c.LoginPerAccount, Period: loginPeriod}, Key: mw.JSONFieldKey("email")},
+	)
+	register := mw.RateLimitMiddleware(store,
+		mw.RateLimitRule{Name: "register:ip", Limit: ratelimit.Limit{Burst: c.RegisterPerIP, Period: registerPeriod}, Key: mw.ClientIPKey},
+	)
+	return login, register
+}
 
+// InitializeWellKnownRoutes initializes the routes served outside the route prefix
+func InitializeWellKnownRoutes(wellKnownRouter *gin.RouterGroup, cfg *config.Config) {
+	auth.NewJWKSHandler(wellKnownRouter, cfg)
 }
@@ -0,0 +1,95 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// querySpanKey keeps the span of a query in the statement
+const querySpanKey = "tracing:query_span"
+
+type querySpan struct {
+	span   trace.Span
+	parent context.Context
+}
+
+// GormPlugin starts a span for every GORM query, a child of the span of the
+// context given with db.WithContext. Register it with db.Use(tracing.GormPlugin{}).
+type GormPlugin struct{}
+
+// Name returns the name of the plugin
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the callbacks around every kind of query
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	errs := []error{
+		callback.Create().Before("gorm:create").Register("tracing:before_create", before("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", after),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", before("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", after),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", before("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", after),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", before("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", after),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", before("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", after),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", before("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", after),
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		parent := db.Statement.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, span := Start(parent, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationKey.String(operation)),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(querySpanKey, querySpan{span: span, parent: parent})
+	}
+}
+
+func after(db *gorm.DB) {
+	value, ok := db.InstanceGet(querySpanKey)
+	if !ok {
+		return
+	}
+	query, ok := value.(querySpan)
+	if !ok {
+		return
+	}
+	defer query.span.End()
+	db.Statement.Context = query.parent
+
+	// the values are bound as parameters, the statement only has placeholders
+	// unless literals were written in a raw query
+	query.span.SetAttributes(
+		semconv.DBStatementKey.String(SanitizeSQL(db.Statement.SQL.String())),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Statement.Table != "" {
+		query.span.SetAttributes(semconv.DBSQLTableKey.String(db.Statement.Table))
+	}
+	// a missing record is an answer, not a failure of the query
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		RecordError(query.span, db.Error)
+	}
+}
@@ -0,0 +1,28 @@
+package tracing
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxStatementLength cuts the long statements, like the bulk inserts
+const maxStatementLength = 2048
+
+// literalPattern matches the string and number literals and the placeholders
+var literalPattern = regexp.MustCompile(`'(?:[^']|'')*'|\$\d+|\b\d+(?:\.\d+)?\b`)
+
+// SanitizeSQL replaces the literals of a statement with ?, so the personal data
+// written in a query does not end up in the traces. The placeholders are kept.
+func SanitizeSQL(sql string) string {
+	sql = literalPattern.ReplaceAllStringFunc(sql, func(literal string) string {
+		if strings.HasPrefix(literal, "$") {
+			return literal
+		}
+		return "?"
+	})
+	sql = strings.Join(strings.Fields(sql), " ")
+	if len(sql) > maxStatementLength {
+		sql = sql[:maxStatementLength] + "..."
+	}
+	return sql
+}
@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert/v2"
+)
+
+func TestSanitizeSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "placeholders are kept",
+			sql:  `SELECT * FROM "users" WHERE email = $1 AND "users"."deleted_at" IS NULL LIMIT 1`,
+			want: `SELECT * FROM "users" WHERE email = $1 AND "users"."deleted_at" IS NULL LIMIT ?`,
+		},
+		{
+			name: "string literals",
+			sql:  `UPDATE users SET email = 'jane@example.com', note = 'it''s me' WHERE id = 42`,
+			want: `UPDATE users SET email = ?, note = ? WHERE id = ?`,
+		},
+		{
+			name: "identifiers with digits",
+			sql:  `SELECT t1.price FROM products t1 WHERE t1.price > 10.5`,
+			want: `SELECT t1.price FROM products t1 WHERE t1.price > ?`,
+		},
+		{
+			name: "whitespace",
+			sql:  "SELECT id\n\tFROM carts",
+			want: "SELECT id FROM carts",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SanitizeSQL(tt.sql))
+		})
+	}
+
+	long := SanitizeSQL("INSERT INTO items VALUES " + strings.Repeat("($1, $2),", 1000))
+	assert.Equal(t, maxStatementLength+len("..."), len(long))
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-playground/assert/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newRecorder() *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder
+}
+
+func attributeValue(attributes []attribute.KeyValue, key attribute.Key) string {
+	for _, kv := range attributes {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestField(t *testing.T) {
+	newRecorder()
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	ctx, span := Start(context.Background(), "test")
+	logger.Info("in a span", Field(ctx))
+	span.End()
+	logger.Info("outside of a span", Field(context.Background()))
+
+	entries := logs.All()
+	assert.Equal(t, 2, len(entries))
+	fields := entries[0].ContextMap()
+	assert.Equal(t, span.SpanContext().TraceID().String(), fields["trace_id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), fields["span_id"])
+	assert.Equal(t, 0, len(entries[1].ContextMap()))
+}
+
+func TestDetach(t *testing.T) {
+	newRecorder()
+	ctx, span := Start(context.Background(), "test")
+	defer span.End()
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	detached := Detach(ctx)
+	assert.Equal(t, nil, detached.Err())
+	assert.Equal(t, span.SpanContext(), trace.SpanContextFromContext(detached))
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := newRecorder()
+
+	var db *sql.DB
+	db, mock, _ := sqlmock.New()
+	DB, _ := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	assert.Equal(t, nil, DB.Use(GormPlugin{}))
+
+	type Product struct {
+		ID   int
+		Name string
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "products" WHERE name = $1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "phone"))
+
+	ctx, parent := Start(context.Background(), "product.repo.Search")
+	var products []Product
+	assert.Equal(t, nil, DB.WithContext(ctx).Where("name = ?", "phone").Find(&products).Error)
+	parent.End()
+	assert.Equal(t, nil, mock.ExpectationsWereMet())
+
+	spans := recorder.Ended()
+	assert.Equal(t, 2, len(spans))
+	query := spans[0]
+	assert.Equal(t, "gorm.query", query.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Equal(t, `SELECT * FROM "products" WHERE name = $1`, attributeValue(query.Attributes(), "db.statement"))
+	assert.Equal(t, "products", attributeValue(query.Attributes(), "db.sql.table"))
+	assert.Equal(t, "postgresql", attributeValue(query.Attributes(), "db.system"))
+}
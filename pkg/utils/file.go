@@ -3,8 +3,8 @@ package utils
 import (
 	"bytes"
 	"encoding/csv"
-	"errors"
 	"mime/multipart"
+	httpErr "patika-ecommerce/internal/httpErrors"
 
 	"path/filepath"
 )
@@ -13,8 +13,7 @@ import (
 func CheckFileIsValid(file *multipart.FileHeader) error {
 	extension := filepath.Ext(file.Filename)
 	if extension != ".csv" {
-		err := errors.New("Unsupported Media type")
-		return err
+		return httpErr.MediaTypeNotSupported
 	}
 	return nil
 }
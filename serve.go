@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"patika-ecommerce/pkg/graceful"
+	"patika-ecommerce/pkg/metrics"
+	mw "patika-ecommerce/pkg/middleware"
+	"patika-ecommerce/pkg/router"
+	"patika-ecommerce/pkg/tracing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultMetricsPath is used when MetricsConfig.Path is not set
+const defaultMetricsPath = "/metrics"
+
+// runServe starts the API server and blocks until it is shut down
+func runServe(a *app, args []string) error {
+	log.Println("Starting server...")
+	cfg, DB := a.cfg, a.db
+
+	if err := a.verifySchema(); err != nil {
+		return err
+	}
+
+	if err := registerMetrics(a); err != nil {
+		return err
+	}
+
+	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.ServerConfig.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	r.Use(mw.TracingMiddleware())
+	r.Use(mw.MetricsMiddleware())
+	r.Use(mw.ProblemDetailsMiddleware())
+	r.Use(mw.LocalizationMiddleware())
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%s", cfg.ServerConfig.Port),
+		Handler:      r,
+		ReadTimeout:  time.Duration(cfg.ServerConfig.ReadTimeoutSecs) * time.Second,
+		WriteTimeout: time.Duration(cfg.ServerConfig.WriteTimeoutSecs) * time.Second,
+	}
+
+	metricsPath := cfg.MetricsConfig.Path
+	if metricsPath == "" {
+		metricsPath = defaultMetricsPath
+	}
+	r.GET(metricsPath, mw.MetricsAuthMiddleware(cfg.MetricsConfig.Token), gin.WrapH(metrics.Handler()))
+
+	router.InitializeWellKnownRoutes(r.Group("/.well-known"), cfg)
+
+	rootRouter := r.Group(cfg.ServerConfig.RoutePrefix)
+	jobRunner := router.InitializeRoutes(rootRouter, DB, cfg)
+
+	rootRouter.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, nil)
+	})
+
+	rootRouter.GET("/readyz", func(c *gin.Context) {
+		db, err := DB.DB()
+		if err != nil {
+			zap.L().Fatal("cannot get sql database instance", tracing.Field(c.Request.Context()), zap.Error(err))
+		}
+		if err := db.Ping(); err != nil {
+			zap.L().Fatal("cannot ping database", tracing.Field(c.Request.Context()), zap.Error(err))
+		}
+		c.JSON(http.StatusOK, nil)
+	})
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Listen error: %v", err)
+		}
+	}()
+	log.Println("Patika ecommerce service started")
+
+	// Wait for interrupt signal to gracefully shutdown the server with
+	graceful.ShutdownGin(srv, time.Duration(cfg.ServerConfig.TimeoutSecs*int64(time.Second)))
+	jobRunner.Stop()
+	return nil
+}
+
+// registerMetrics times the queries and exposes the connection pool statistics
+func registerMetrics(a *app) error {
+	if err := a.db.Use(metrics.GormPlugin{}); err != nil {
+		return err
+	}
+	sqlDB, err := a.db.DB()
+	if err != nil {
+		return err
+	}
+	return metrics.RegisterDBStats(sqlDB, "postgres")
+}